@@ -7,6 +7,7 @@ import (
 	"log"
 	"math/rand" // Required for random numbers
 	"os"
+	"strings"
 	"time" // Added for time.Now()
 
 	"github.com/go-faker/faker/v4"                        // Added for faker data
@@ -14,8 +15,6 @@ import (
 	"github.com/icchon/matcha/api/internal/domain/entity" // Added for entity.User, entity.Tag
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
-	"io/ioutil" // Added for reading HTTP response body
-	"net/http"  // Added for making HTTP requests
 )
 
 const (
@@ -40,20 +39,14 @@ const (
 // 	_ "github.com/lib/pq"
 // )
 
-func getDogImageUrl() string {
-	resp, err := http.Get("http://filesrv/dog")
-	if err != nil {
-		log.Printf("Failed to fetch dog image URL: %v. Using placeholder.", err)
-		return "https://example.com/placeholder-dog.jpg" // Fallback
+// avatarURL は filesrv がユーザー ID などの seed から生成する既定の画像の URL を返す
+// 画像はリクエストされたときに filesrv がその場で作るので、シード時にネットワークは要らない
+func avatarURL(seed string) string {
+	baseURL := os.Getenv("AVATAR_BASE_URL")
+	if baseURL == "" {
+		baseURL = "/avatars"
 	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("Failed to read dog image URL response body: %v. Using placeholder.", err)
-		return "https://example.com/placeholder-dog.jpg" // Fallback
-	}
-	return string(body)
+	return fmt.Sprintf("%s/%s.png", strings.TrimRight(baseURL, "/"), seed)
 }
 
 func createViews(db *sqlx.DB, users []entity.User) {
@@ -197,8 +190,8 @@ func createNotifications(db *sqlx.DB, users []entity.User) {
 			CreatedAt:   time.Now().Add(time.Duration(-rand.Intn(720)) * time.Hour),
 		}
 
-		tx.NamedExec(`INSERT INTO notifications (recipient_id, sender_id, type, is_read, created_at)
-            VALUES (:recipient_id, :sender_id, :type, :is_read, :created_at)`, notification)
+		tx.NamedExec(`INSERT INTO notifications (recipient_id, sender_id, type, is_read, created_at, updated_at)
+            VALUES (:recipient_id, :sender_id, :type, :is_read, :created_at, :created_at)`, notification)
 		notificationCount++
 	}
 	if err := tx.Commit(); err != nil {
@@ -378,14 +371,22 @@ func createPictures(db *sqlx.DB, users []entity.User) {
 			if i == 0 { // First picture can be a profile pic
 				isProfilePic.Bool = true
 			}
+			// 1 枚目は写真がないときの既定の画像と同じ。2 枚目以降は seed を変えて別の画像にする
+			seed := user.ID.String()
+			if i > 0 {
+				seed = fmt.Sprintf("%s-%d", user.ID, i)
+			}
 			picture := entity.Picture{
 				UserID:       user.ID,
-				URL:          getDogImageUrl(),
+				URL:          avatarURL(seed),
 				IsProfilePic: isProfilePic,
-				CreatedAt:    time.Now(),
+				Position:     i,
+				// 生成した画像なので検査しない
+				Status:    entity.PictureApproved,
+				CreatedAt: time.Now(),
 			}
-			tx.NamedExec(`INSERT INTO pictures (user_id, url, is_profile_pic, created_at)
-                VALUES (:user_id, :url, :is_profile_pic, :created_at)`, picture)
+			tx.NamedExec(`INSERT INTO pictures (user_id, url, is_profile_pic, position, status, created_at)
+                VALUES (:user_id, :url, :is_profile_pic, :position, :status, :created_at)`, picture)
 			pictureCount++
 		}
 	}
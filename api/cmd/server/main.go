@@ -7,12 +7,15 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 
@@ -56,6 +59,41 @@ func getEnv(key string) string {
 	return ""
 }
 
+// getEnvList はカンマ区切りの環境変数を読む。空の要素は捨てる
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value := getEnv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", key, err)
+	}
+	return n
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := getEnv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", key, err)
+	}
+	return d
+}
+
 func main() {
 	err := godotenv.Load()
 	if err != nil {
@@ -81,6 +119,47 @@ func main() {
 		SmtpSender:          getEnv("SMTP_SENDER"),
 		BaseUrl:             getEnv("BASE_URL"),
 		ImageUploadEndpoint: getEnv("IMAGE_UPLOAD_ENDPOINT"),
+		ImageAssetEndpoint:  getEnv("IMAGE_ASSET_ENDPOINT"),
+		ImageBaseUrl:        getEnv("IMAGE_BASE_URL"),
+
+		FilePublicThumbnails: getEnv("FILE_PUBLIC_THUMBNAILS") == "true",
+
+		AttachmentUploadEndpoint: getEnv("ATTACHMENT_UPLOAD_ENDPOINT"),
+		AttachmentBaseUrl:        getEnv("ATTACHMENT_BASE_URL"),
+		FileURLSigningKey:        getEnv("FILE_URL_SIGNING_KEY"),
+		ResumableUploadEndpoint:  getEnv("RESUMABLE_UPLOAD_ENDPOINT"),
+		AvatarBaseUrl:            getEnv("AVATAR_BASE_URL"),
+		FileServiceAuthKey:       getEnv("FILE_SERVICE_AUTH_KEY"),
+
+		BannedWords:            getEnvList("BANNED_WORDS"),
+		ChatRateLimitPerMinute: getEnvInt("CHAT_RATE_LIMIT_PER_MINUTE", 30),
+
+		PictureClassifier:         getEnv("PICTURE_CLASSIFIER"),
+		PictureModerationInterval: getEnvDuration("PICTURE_MODERATION_INTERVAL", 30*time.Second),
+
+		DigestQuietPeriod: getEnvDuration("DIGEST_QUIET_PERIOD", 24*time.Hour),
+		DigestInterval:    getEnvDuration("DIGEST_INTERVAL", 15*time.Minute),
+
+		MailPreviewEnabled: getEnv("MAIL_PREVIEW_ENABLED") == "true",
+		MailTransport:      getEnv("MAIL_TRANSPORT"),
+		MailDropDir:        getEnv("MAIL_DROP_DIR"),
+		MailOutboxInterval: getEnvDuration("MAIL_OUTBOX_INTERVAL", 10*time.Second),
+
+		VAPIDPrivateKey: getEnv("VAPID_PRIVATE_KEY"),
+		VAPIDSubject:    getEnv("VAPID_SUBJECT"),
+
+		PushAllowPrivateEndpoints: getEnv("PUSH_ALLOW_PRIVATE_ENDPOINTS") == "true",
+		PushSendWorkers:           getEnvInt("PUSH_SEND_WORKERS", 4),
+	}
+	if cfg.MailDropDir == "" {
+		cfg.MailDropDir = "tmp/mail"
+	}
+	for _, id := range getEnvList("ADMIN_USER_IDS") {
+		adminID, err := uuid.Parse(id)
+		if err != nil {
+			log.Fatalf("Invalid ADMIN_USER_IDS: %v", err)
+		}
+		cfg.AdminUserIDs = append(cfg.AdminUserIDs, adminID)
 	}
 
 	db, err := sqlx.Connect("postgres", getEnv("DATABASE_URL"))
@@ -3,10 +3,14 @@ package apperrors
 import "errors"
 
 var (
-	ErrNotFound       = errors.New("resource not found")
-	ErrInvalidInput   = errors.New("invalid input provided")
-	ErrUnauthorized   = errors.New("unauthorized")
-	ErrUnhandled      = errors.New("unhandled error")
-	ErrInternalServer = errors.New("internal server error")
-	ErrNotImplemented = errors.New("not implemented")
+	ErrNotFound        = errors.New("resource not found")
+	ErrInvalidInput    = errors.New("invalid input provided")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrForbidden       = errors.New("forbidden")
+	ErrConflict        = errors.New("conflict")
+	ErrTooManyRequests = errors.New("too many requests")
+	ErrRejected        = errors.New("rejected by moderation")
+	ErrUnhandled       = errors.New("unhandled error")
+	ErrInternalServer  = errors.New("internal server error")
+	ErrNotImplemented  = errors.New("not implemented")
 )
@@ -1,5 +1,85 @@
 package client
 
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrFileRejected は filesrv が形式・大きさ・画素数を理由に受け付けなかったことを表す
+	ErrFileRejected = errors.New("file rejected by file service")
+	// ErrFileQuotaExceeded はユーザーのアップロード上限に達したことを表す
+	ErrFileQuotaExceeded = errors.New("file upload quota exceeded")
+	// ErrUploadIncomplete は再開可能アップロードがまだ全部届いていないことを表す
+	ErrUploadIncomplete = errors.New("resumable upload is incomplete")
+	// ErrUploadNotFound は再開可能アップロードがない (期限切れ・他人のもの・使用済み) ことを表す
+	ErrUploadNotFound = errors.New("resumable upload not found")
+	// ErrAssetNotFound は filesrv に画像の変種がないことを表す。何度読み直してもない
+	ErrAssetNotFound = errors.New("image asset not found")
+)
+
+// FileSource は filesrv に送るファイル。Reader はメモリに溜めずにそのまま filesrv に流す
+// UploadID があれば Reader の代わりに、再開可能アップロードで送り終えたものを使う
+type FileSource struct {
+	Reader   io.Reader
+	Filename string
+	UploadID string
+}
+
+// Upload は filesrv の再開可能アップロード (tus) の枠。クライアントは URL に PATCH で送る
+type Upload struct {
+	ID        string    `json:"upload_id"`
+	URL       string    `json:"upload_url"`
+	Length    int64     `json:"length"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Attachment は filesrv に保存したチャット添付ファイルのメタデータ
+type Attachment struct {
+	ID     string `json:"id"`
+	Mime   string `json:"mime"`
+	Size   int64  `json:"size"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// Image は filesrv に保存した画像。同じ asset の大きさ違いの URL を持つ
+type Image struct {
+	AssetID      string
+	ThumbnailURL string
+	CardURL      string
+	FullURL      string
+}
+
 type FileClient interface {
-	SaveImage(data []byte, filename string) (string, error)
+	// SaveImage は EXIF を取り除いた thumbnail / card / full の変種を保存する
+	// 同じ内容の画像は同じ AssetID になり、filesrv 側で参照数を数える
+	// userID はアップロードしたユーザーで、filesrv はユーザーごとの上限に使う
+	SaveImage(userID uuid.UUID, src *FileSource) (*Image, error)
+	// DeleteImage は SaveImage 1 回分の参照を外す。参照がなくなった画像は猶予期間の後に消える
+	DeleteImage(assetID string) error
+	// OpenImage は写真を検査するために asset の card の変種を読む。閉じるのは呼び出し側
+	// 変種がなければ ErrAssetNotFound
+	OpenImage(assetID string) (io.ReadCloser, error)
+	SaveAttachment(userID uuid.UUID, src *FileSource) (*Attachment, error)
+	// CreateUpload は userID が length バイトを送る再開可能アップロードを作る
+	// 送り終えたら Upload.ID を FileSource.UploadID にして SaveImage / SaveAttachment に渡す
+	CreateUpload(userID uuid.UUID, length int64) (*Upload, error)
+	// SignAttachmentURL は expiresAt まで添付ファイルを取得できる署名付き URL を返す
+	SignAttachmentURL(id string, expiresAt time.Time) string
+	// SignImageURL は SaveImage が返した URL に、expiresAt まで取得できる署名を付ける
+	// viewerID は発行先として URL に入るだけで、filesrv は取得する人を確かめない
+	// filesrv の画像でない URL や、公開にしているサムネイルはそのまま返す
+	SignImageURL(rawURL string, viewerID uuid.UUID, expiresAt time.Time) string
+	// AvatarURL は写真のないユーザーに使う、ユーザー ID から決まる既定の画像の URL を返す
+	AvatarURL(userID uuid.UUID) string
+}
+
+// ImageURLExpiry は画像 URL の有効期限を ttl 単位に切り上げて返す
+// 同じ期間に発行した URL は同じになるので、ブラウザのキャッシュが効く (有効な長さは ttl から 2*ttl)
+func ImageURLExpiry(now time.Time, ttl time.Duration) time.Time {
+	return now.Truncate(ttl).Add(2 * ttl)
 }
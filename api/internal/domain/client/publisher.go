@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
 )
 
 type MessagePayload struct {
@@ -13,14 +14,71 @@ type MessagePayload struct {
 	RecipientID uuid.UUID `json:"recipient_id"`
 	Content     string    `json:"content"`
 	SentAt      time.Time `json:"sent_at"`
+	// 送信時にクライアントが指定する、アップロード済み添付ファイルの ID
+	AttachmentIDs []string `json:"attachment_ids,omitempty"`
+	// 配信時の添付ファイル。url は期限付きの署名付き URL
+	Attachments []AttachmentPayload `json:"attachments,omitempty"`
 }
 
+// NewMessagePayload は保存済みのメッセージから配信用の payload を作る
+func NewMessagePayload(msg *entity.Message) *MessagePayload {
+	payload := &MessagePayload{
+		ID:          msg.ID,
+		SenderID:    msg.SenderID,
+		RecipientID: msg.RecipientID,
+		Content:     msg.Content,
+		SentAt:      msg.SentAt,
+	}
+	for _, attachment := range msg.Attachments {
+		payload.Attachments = append(payload.Attachments, AttachmentPayload{
+			ID:     attachment.ID,
+			Mime:   attachment.Mime,
+			Size:   attachment.Size,
+			Width:  attachment.Width,
+			Height: attachment.Height,
+			URL:    attachment.URL,
+		})
+	}
+	return payload
+}
+
+type AttachmentPayload struct {
+	ID     string `json:"id"`
+	Mime   string `json:"mime"`
+	Size   int64  `json:"size"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
+}
+
+// NotificationPayload は同じ id で count が増えたものが再送されることがある (まとめられた通知)
 type NotificationPayload struct {
-	ID          int64     `json:"id"`
-	RecipientID uuid.UUID `json:"recipient_id"`
-	SenderID    string    `json:"sender_id"`
-	Type        string    `json:"type"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID                 int64     `json:"id"`
+	RecipientID        uuid.UUID `json:"recipient_id"`
+	SenderID           string    `json:"sender_id"`
+	SenderName         string    `json:"sender_name"`
+	SenderThumbnailURL string    `json:"sender_thumbnail_url"`
+	Type               string    `json:"type"`
+	Count              int       `json:"count"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+const (
+	NotificationReadActionRead    = "read"
+	NotificationReadActionUnread  = "unread"
+	NotificationReadActionReadAll = "read_all"
+	NotificationReadActionDelete  = "delete"
+)
+
+// NotificationReadPayload は通知の既読状態の変化を本人 (UserID) の他のタブに知らせる
+// read_all のときは NotificationID が 0
+type NotificationReadPayload struct {
+	UserID         uuid.UUID `json:"user_id"`
+	Action         string    `json:"action"`
+	NotificationID int64     `json:"notification_id,omitempty"`
+	UnreadCount    int       `json:"unread_count"`
+	Timestamp      int64     `json:"timestamp"`
 }
 
 type AckPayload struct {
@@ -35,12 +93,91 @@ type PresencePayload struct {
 	Status      string    `json:"status"`
 }
 
+// ReadPayload は UserID が RecipientID から受け取ったメッセージを LastReadMessageID まで読んだことを表す
+// 受信時の LastReadMessageID が 0 なら最新のメッセージまで既読にする
 type ReadPayload struct {
+	UserID            uuid.UUID `json:"user_id"`
+	RecipientID       uuid.UUID `json:"recipient_id"`
+	LastReadMessageID int64     `json:"last_read_message_id"`
+	Timestamp         int64     `json:"timestamp"`
+}
+
+type TypingPayload struct {
+	UserID      uuid.UUID `json:"user_id"`
+	RecipientID uuid.UUID `json:"recipient_id"`
+	IsTyping    bool      `json:"is_typing"`
+	Timestamp   int64     `json:"timestamp"`
+}
+
+type PresenceQueryPayload struct {
+	RequestID string      `json:"request_id"`
+	ConnID    string      `json:"conn_id"`
+	UserID    uuid.UUID   `json:"user_id"`
+	UserIDs   []uuid.UUID `json:"user_ids"`
+}
+
+type PresenceStatus struct {
+	UserID         uuid.UUID  `json:"user_id"`
+	Status         string     `json:"status"`
+	LastConnection *time.Time `json:"last_connection"`
+}
+
+type PresenceQueryResultPayload struct {
+	RequestID   string            `json:"request_id"`
+	ConnID      string            `json:"conn_id"`
+	RecipientID uuid.UUID         `json:"recipient_id"`
+	Presences   []*PresenceStatus `json:"presences"`
+}
+
+// 編集・送信取り消し・リアクションは UserID (操作したユーザー) と RecipientID (会話の相手) の両方に配信する
+type MessageEditPayload struct {
+	MessageID   int64     `json:"message_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	RecipientID uuid.UUID `json:"recipient_id"`
+	Content     string    `json:"content"`
+	EditedAt    time.Time `json:"edited_at"`
+}
+
+type MessageDeletePayload struct {
+	MessageID   int64     `json:"message_id"`
 	UserID      uuid.UUID `json:"user_id"`
 	RecipientID uuid.UUID `json:"recipient_id"`
+	DeletedAt   time.Time `json:"deleted_at"`
+}
+
+const (
+	ReactionActionAdd    = "add"
+	ReactionActionRemove = "remove"
+)
+
+type ReactionPayload struct {
+	MessageID   int64     `json:"message_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	RecipientID uuid.UUID `json:"recipient_id"`
+	Emoji       string    `json:"emoji"`
+	Action      string    `json:"action"`
 	Timestamp   int64     `json:"timestamp"`
 }
 
+const (
+	ModerationEventHold    = "hold"
+	ModerationEventReject  = "reject"
+	ModerationEventApprove = "approve"
+)
+
+// ModerationPayload は送ったメッセージのモデレーション結果を送信者 (UserID) に知らせる
+// hold は審査待ち、reject は送信拒否 (content は下書きに戻すための本文)、approve は審査で承認され配信されたこと
+type ModerationPayload struct {
+	UserID        uuid.UUID `json:"user_id"`
+	RecipientID   uuid.UUID `json:"recipient_id"`
+	Action        string    `json:"action"`
+	Reason        string    `json:"reason,omitempty"`
+	Content       string    `json:"content,omitempty"`
+	HeldMessageID int64     `json:"held_message_id,omitempty"`
+	MessageID     int64     `json:"message_id,omitempty"`
+	SentAt        time.Time `json:"sent_at"`
+}
+
 type Publisher interface {
 	Publish(ctx context.Context, data interface{}) error
 }
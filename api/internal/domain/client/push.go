@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/icchon/matcha/api/internal/domain/entity"
+)
+
+// PushUrgency は Web Push (RFC 8030) の Urgency ヘッダー
+type PushUrgency string
+
+const (
+	PushUrgencyNormal PushUrgency = "normal"
+	PushUrgencyHigh   PushUrgency = "high"
+)
+
+type PushMessage struct {
+	// 暗号化前のペイロード。暗号化後に 4096 バイトに収まる必要がある
+	Payload []byte
+	// 端末がオフラインのとき、プッシュサービスが保持しておく時間
+	TTL     time.Duration
+	Urgency PushUrgency
+}
+
+// ErrPushSubscriptionGone はプッシュサービスが購読の失効 (404 / 410) を返したことを表す
+var ErrPushSubscriptionGone = errors.New("push subscription is gone")
+
+// ErrPushEndpointNotPublic は endpoint が内部のアドレスを指していることを表す
+var ErrPushEndpointNotPublic = errors.New("push endpoint is not a public address")
+
+// IsPublicIP はプッシュの送信先にしてよいアドレスかを返す
+// ループバック・プライベート・リンクローカル・マルチキャストなどは内部の宛先とみなす
+func IsPublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate()
+}
+
+type PushClient interface {
+	Send(ctx context.Context, sub *entity.PushSubscription, msg *PushMessage) error
+	// PublicKey は VAPID の公開鍵 (base64url)。ブラウザが購読時に applicationServerKey として使う
+	PublicKey() string
+}
@@ -0,0 +1,14 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// ConversationRead は UserID が PeerID から受け取ったメッセージの既読位置 (watermark)
+type ConversationRead struct {
+	UserID            uuid.UUID `db:"user_id" json:"user_id"`
+	PeerID            uuid.UUID `db:"peer_id" json:"peer_id"`
+	LastReadMessageID int64     `db:"last_read_message_id" json:"last_read_message_id"`
+	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,13 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailDigest はユーザーに最後にまとめ通知メールを送った時刻
+type EmailDigest struct {
+	UserID     uuid.UUID `db:"user_id"`
+	LastSentAt time.Time `db:"last_sent_at"`
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type HeldMessageStatus string
+
+const (
+	HeldMessagePending  HeldMessageStatus = "pending"
+	HeldMessageApproved HeldMessageStatus = "approved"
+	HeldMessageRejected HeldMessageStatus = "rejected"
+)
+
+// HeldMessage はモデレーションで保留され、管理者の審査を待つメッセージ
+// EditMessageID があれば新しいメッセージではなく、そのメッセージの編集を保留している
+type HeldMessage struct {
+	ID            int64             `db:"id" json:"id"`
+	SenderID      uuid.UUID         `db:"sender_id" json:"sender_id"`
+	RecipientID   uuid.UUID         `db:"recipient_id" json:"recipient_id"`
+	Content       string            `db:"content" json:"content"`
+	AttachmentIDs pq.StringArray    `db:"attachment_ids" json:"attachment_ids"`
+	Reason        string            `db:"reason" json:"reason"`
+	Status        HeldMessageStatus `db:"status" json:"status"`
+	SentAt        time.Time         `db:"sent_at" json:"sent_at"`
+	CreatedAt     time.Time         `db:"created_at" json:"created_at"`
+	ReviewedBy    uuid.NullUUID     `db:"reviewed_by" json:"reviewed_by"`
+	ReviewedAt    sql.NullTime      `db:"reviewed_at" json:"-"`
+	MessageID     sql.NullInt64     `db:"message_id" json:"-"`
+	EditMessageID sql.NullInt64     `db:"edit_message_id" json:"edit_message_id"`
+}
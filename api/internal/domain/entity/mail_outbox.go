@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+type OutboxMailStatus string
+
+const (
+	OutboxMailPending OutboxMailStatus = "pending"
+	OutboxMailSent    OutboxMailStatus = "sent"
+	// 再送の上限に達したもの
+	OutboxMailFailed OutboxMailStatus = "failed"
+)
+
+// OutboxMail は送信待ちのメール。ワーカーが取り出して送る
+type OutboxMail struct {
+	ID            int64            `db:"id"`
+	ToEmail       string           `db:"to_email"`
+	Subject       string           `db:"subject"`
+	HTMLBody      string           `db:"html_body"`
+	TextBody      string           `db:"text_body"`
+	Status        OutboxMailStatus `db:"status"`
+	Attempts      int              `db:"attempts"`
+	NextAttemptAt time.Time        `db:"next_attempt_at"`
+	LastError     sql.NullString   `db:"last_error"`
+	CreatedAt     time.Time        `db:"created_at"`
+	SentAt        sql.NullTime     `db:"sent_at"`
+}
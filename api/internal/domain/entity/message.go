@@ -13,4 +13,8 @@ type Message struct {
 	Content     string       `db:"content"`
 	SentAt      time.Time    `db:"sent_at"`
 	IsRead      sql.NullBool `db:"is_read"`
+	EditedAt    sql.NullTime `db:"edited_at"`
+	DeletedAt   sql.NullTime `db:"deleted_at"`
+
+	Attachments []*MessageAttachment `db:"-"`
 }
@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"time"
+)
+
+type MessageAttachment struct {
+	ID          string        `db:"id" json:"id"`
+	MessageID   sql.NullInt64 `db:"message_id" json:"-"`
+	UploaderID  uuid.UUID     `db:"uploader_id" json:"uploader_id"`
+	RecipientID uuid.UUID     `db:"recipient_id" json:"recipient_id"`
+	Mime        string        `db:"mime" json:"mime"`
+	Size        int64         `db:"size" json:"size"`
+	Width       int           `db:"width" json:"width"`
+	Height      int           `db:"height" json:"height"`
+	CreatedAt   time.Time     `db:"created_at" json:"created_at"`
+
+	// 閲覧のたびに発行する期限付きの署名付き URL
+	URL string `db:"-" json:"url,omitempty"`
+}
+
+// IsParticipant は userID が添付ファイルを送った会話の参加者かを返す
+func (a *MessageAttachment) IsParticipant(userID uuid.UUID) bool {
+	return a.UploaderID == userID || a.RecipientID == userID
+}
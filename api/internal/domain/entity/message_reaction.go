@@ -0,0 +1,13 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type MessageReaction struct {
+	MessageID int64     `db:"message_id" json:"message_id"`
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	Emoji     string    `db:"emoji" json:"emoji"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
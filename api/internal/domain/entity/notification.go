@@ -12,5 +12,7 @@ type Notification struct {
 	SenderID    sql.NullString   `db:"sender_id"` // ON DELETE SET NULL のため NullString (UUID)
 	Type        NotificationType `db:"type"`
 	IsRead      sql.NullBool     `db:"is_read"`
+	Count       int              `db:"count"` // まとめた件数
 	CreatedAt   time.Time        `db:"created_at"`
+	UpdatedAt   time.Time        `db:"updated_at"` // 最後にまとめた時刻
 }
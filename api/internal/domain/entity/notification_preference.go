@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationPreference は通知の種類ごとの受け取り設定
+type NotificationPreference struct {
+	UserID      uuid.UUID        `db:"user_id"`
+	Type        NotificationType `db:"type"`
+	InApp       bool             `db:"in_app"`
+	EmailDigest bool             `db:"email_digest"`
+	UpdatedAt   time.Time        `db:"updated_at"`
+}
+
+// NotificationMute は UserID が MutedUntil まで MutedUserID からの通知を受け取らないことを表す
+type NotificationMute struct {
+	UserID      uuid.UUID `db:"user_id"`
+	MutedUserID uuid.UUID `db:"muted_user_id"`
+	MutedUntil  time.Time `db:"muted_until"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// NotificationTypes はすべての通知の種類
+var NotificationTypes = []NotificationType{NotifLike, NotifView, NotifMatch, NotifUnlike, NotifMessage}
@@ -6,10 +6,51 @@ import (
 	"time"
 )
 
+type PictureStatus string
+
+const (
+	PicturePending  PictureStatus = "pending"
+	PictureApproved PictureStatus = "approved"
+	PictureRejected PictureStatus = "rejected"
+)
+
 type Picture struct {
-	ID           int32        `db:"id"`
-	UserID       uuid.UUID    `db:"user_id"`
-	URL          string       `db:"url"`
+	ID     int32     `db:"id"`
+	UserID uuid.UUID `db:"user_id"`
+	// full の URL
+	URL string `db:"url"`
+	// filesrv の asset ID と小さい変種の URL。変種がない古い写真は空
+	AssetID      string       `db:"asset_id"`
+	ThumbnailURL string       `db:"thumbnail_url"`
+	CardURL      string       `db:"card_url"`
 	IsProfilePic sql.NullBool `db:"is_profile_pic"`
-	CreatedAt    time.Time    `db:"created_at"`
+	// 表示順 (0 から)
+	Position int `db:"position"`
+	// approved になるまで本人以外には見えない
+	Status PictureStatus `db:"status"`
+	// ImageClassifier が疑わしいとした理由。空でなければ管理者の審査を待つ
+	FlagReason string `db:"flag_reason"`
+	// card の変種の知覚ハッシュ (64 ビットをそのまま int64 にしたもの)
+	DHash        sql.NullInt64 `db:"dhash"`
+	PHash        sql.NullInt64 `db:"phash"`
+	ClassifiedAt sql.NullTime  `db:"classified_at"`
+	ReviewedBy   uuid.NullUUID `db:"reviewed_by"`
+	ReviewedAt   sql.NullTime  `db:"reviewed_at"`
+	CreatedAt    time.Time     `db:"created_at"`
+}
+
+// Thumbnail は一覧で使う URL を返す。サムネイルがなければ full
+func (p *Picture) Thumbnail() string {
+	if p.ThumbnailURL != "" {
+		return p.ThumbnailURL
+	}
+	return p.URL
+}
+
+// Card はカード表示で使う URL を返す。カード用の変種がなければ full
+func (p *Picture) Card() string {
+	if p.CardURL != "" {
+		return p.CardURL
+	}
+	return p.URL
 }
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PictureMatch は後からアップロードされた写真 PictureID が、他のユーザーの写真 MatchedPictureID と
+// 知覚ハッシュで似ていたことを表す。偽アカウントの兆候の根拠になる
+type PictureMatch struct {
+	PictureID        int32     `db:"picture_id" json:"picture_id"`
+	UserID           uuid.UUID `db:"user_id" json:"user_id"`
+	MatchedPictureID int32     `db:"matched_picture_id" json:"matched_picture_id"`
+	MatchedUserID    uuid.UUID `db:"matched_user_id" json:"matched_user_id"`
+	// dHash・pHash のハミング距離 (0 から 64)
+	DHashDistance int       `db:"dhash_distance" json:"dhash_distance"`
+	PHashDistance int       `db:"phash_distance" json:"phash_distance"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
+
+// FakeAccountSignal はユーザーの写真が他のユーザーの写真の使い回しに見える度合い
+type FakeAccountSignal struct {
+	UserID uuid.UUID `db:"user_id" json:"user_id"`
+	// 他のユーザーの写真と似ていた写真の数と、その持ち主の数
+	MatchedPictures int `db:"matched_pictures" json:"matched_pictures"`
+	MatchedUsers    int `db:"matched_users" json:"matched_users"`
+	TotalPictures   int `db:"total_pictures" json:"total_pictures"`
+	// 管理者が却下した写真の数
+	RejectedPictures int        `db:"rejected_pictures" json:"rejected_pictures"`
+	LastMatchedAt    *time.Time `db:"last_matched_at" json:"last_matched_at"`
+}
+
+// Score は今の写真のうち、他のユーザーの写真と似ていたものの割合 (0 から 1) を返す
+func (s *FakeAccountSignal) Score() float64 {
+	if s.TotalPictures == 0 {
+		return 0
+	}
+	return float64(s.MatchedPictures) / float64(s.TotalPictures)
+}
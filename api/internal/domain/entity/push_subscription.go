@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PushSubscription はブラウザ (端末) ごとの Web Push の購読
+type PushSubscription struct {
+	ID       int64     `db:"id" json:"id"`
+	UserID   uuid.UUID `db:"user_id" json:"user_id"`
+	Endpoint string    `db:"endpoint" json:"endpoint"`
+	// ペイロードの暗号化に使うブラウザの公開鍵と認証シークレット (base64url)
+	P256dh    string         `db:"p256dh" json:"-"`
+	Auth      string         `db:"auth" json:"-"`
+	UserAgent sql.NullString `db:"user_agent" json:"-"`
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"`
+}
@@ -18,4 +18,5 @@ type UserProfile struct {
 	FameRating       sql.NullInt32   `db:"fame_rating" json:"fame_rating"`
 	LocationName     sql.NullString  `db:"location_name" json:"location_name"`
 	Distance         sql.NullFloat64 `db:"distance" json:"distance,omitempty"`
+	Locale           sql.NullString  `db:"locale" json:"locale"` // メールなどの言語。NULL なら Accept-Language
 }
@@ -0,0 +1,21 @@
+package repo
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+)
+
+type ConversationReadQueryRepository interface {
+	Find(ctx context.Context, userID, peerID uuid.UUID) (*entity.ConversationRead, error)
+}
+
+type ConversationReadCommandRepository interface {
+	// Advance は peerID から受け取った upTo 以下の最新メッセージまで既読位置を進める。既読位置は戻らない
+	Advance(ctx context.Context, userID, peerID uuid.UUID, upTo int64) (*entity.ConversationRead, error)
+}
+
+type ConversationReadRepository interface {
+	ConversationReadQueryRepository
+	ConversationReadCommandRepository
+}
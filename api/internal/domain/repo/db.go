@@ -13,9 +13,19 @@ type RepositoryManager interface {
 	AuthRepo() AuthRepository
 	ConnectionRepo() ConnectionRepository
 	MessageRepo() MessageRepository
+	MessageReactionRepo() MessageReactionRepository
+	MessageAttachmentRepo() MessageAttachmentRepository
+	ConversationReadRepo() ConversationReadRepository
+	HeldMessageRepo() HeldMessageRepository
 	NotificationRepo() NotificationRepository
+	NotificationPreferenceRepo() NotificationPreferenceRepository
+	NotificationMuteRepo() NotificationMuteRepository
+	EmailDigestRepo() EmailDigestRepository
+	MailOutboxRepo() MailOutboxRepository
+	PushSubscriptionRepo() PushSubscriptionRepository
 	PasswordResetRepo() PasswordResetRepository
 	PictureRepo() PictureRepository
+	PictureMatchRepo() PictureMatchRepository
 	RefreshTokenRepo() RefreshTokenRepository
 	UserTagRepo() UserTagRepository
 	VerificationTokenRepo() VerificationTokenRepository
@@ -0,0 +1,21 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+)
+
+type EmailDigestQueryRepository interface {
+	Find(ctx context.Context, userID uuid.UUID) (*entity.EmailDigest, error)
+}
+
+type EmailDigestCommandRepository interface {
+	Upsert(ctx context.Context, digest *entity.EmailDigest) error
+}
+
+type EmailDigestRepository interface {
+	EmailDigestQueryRepository
+	EmailDigestCommandRepository
+}
@@ -0,0 +1,30 @@
+package repo
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+)
+
+type HeldMessageQuery struct {
+	SenderID *uuid.UUID
+	Status   *entity.HeldMessageStatus
+	Limit    int
+	Offset   int
+}
+
+type HeldMessageQueryRepository interface {
+	Find(ctx context.Context, id int64) (*entity.HeldMessage, error)
+	Query(ctx context.Context, q *HeldMessageQuery) ([]*entity.HeldMessage, error)
+}
+
+type HeldMessageCommandRepository interface {
+	Create(ctx context.Context, held *entity.HeldMessage) error
+	// Review は pending のものだけを審査済みにする。すでに審査済みなら false を返す
+	Review(ctx context.Context, held *entity.HeldMessage) (bool, error)
+}
+
+type HeldMessageRepository interface {
+	HeldMessageQueryRepository
+	HeldMessageCommandRepository
+}
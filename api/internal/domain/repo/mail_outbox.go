@@ -0,0 +1,24 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/icchon/matcha/api/internal/domain/entity"
+)
+
+type MailOutboxQueryRepository interface {
+	// FindDue は now までに送るべき pending のメールを古い順に limit 件ロックして返す
+	// 他のワーカーがロックしている行は飛ばすので、トランザクションの中で呼ぶ
+	FindDue(ctx context.Context, now time.Time, limit int) ([]*entity.OutboxMail, error)
+}
+
+type MailOutboxCommandRepository interface {
+	Create(ctx context.Context, mail *entity.OutboxMail) error
+	Update(ctx context.Context, mail *entity.OutboxMail) error
+}
+
+type MailOutboxRepository interface {
+	MailOutboxQueryRepository
+	MailOutboxCommandRepository
+}
@@ -18,16 +18,33 @@ type MessageQuery struct {
 	Offset      *int
 }
 
+// MessageSearchQuery は UserID が参加している会話のメッセージを全文検索する条件
+// ブロック関係にある相手とのメッセージと送信取り消し済みのメッセージは含めない
+type MessageSearchQuery struct {
+	UserID uuid.UUID
+	Text   string
+	// カーソル: この ID より前 (古い) のメッセージを返す
+	BeforeID *int64
+	Limit    int
+}
+
 type MessageQueryRepository interface {
 	Find(ctx context.Context, messageID int64) (*entity.Message, error)
 	Query(ctx context.Context, q *MessageQuery) ([]*entity.Message, error)
 	GetLatest(ctx context.Context, userID1, userID2 uuid.UUID) (*entity.Message, error)
+	// Search は新しい順 (ID の降順) に返す
+	Search(ctx context.Context, q *MessageSearchQuery) ([]*entity.Message, error)
 }
 
 type MessageCommandRepository interface {
 	Create(ctx context.Context, message *entity.Message) error
-	Update(ctx context.Context, message *entity.Message) error
+	// UpdateContent は取り消されていないメッセージの本文を書き換える。既読などの他の列は変えない
+	UpdateContent(ctx context.Context, messageID int64, content string, editedAt time.Time) (bool, error)
+	// MarkDeleted は取り消されていないメッセージの本文を消して取り消し済みにする
+	MarkDeleted(ctx context.Context, messageID int64, deletedAt time.Time) (bool, error)
 	MarkAsRead(ctx context.Context, messageID int64) error
+	// MarkConversationRead は senderID から recipientID への upTo 以下の未読メッセージを 1 回の UPDATE で既読にする
+	MarkConversationRead(ctx context.Context, recipientID, senderID uuid.UUID, upTo int64) (int64, error)
 	Delete(ctx context.Context, messageID int64) error
 }
 
@@ -0,0 +1,28 @@
+package repo
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+)
+
+type MessageAttachmentQuery struct {
+	MessageIDs []int64
+	UploaderID *uuid.UUID
+}
+
+type MessageAttachmentQueryRepository interface {
+	Find(ctx context.Context, id string) (*entity.MessageAttachment, error)
+	Query(ctx context.Context, q *MessageAttachmentQuery) ([]*entity.MessageAttachment, error)
+}
+
+type MessageAttachmentCommandRepository interface {
+	Create(ctx context.Context, attachment *entity.MessageAttachment) error
+	// AttachToMessage はまだメッセージに紐付いていない添付ファイルを messageID に紐付ける
+	AttachToMessage(ctx context.Context, id string, messageID int64) error
+}
+
+type MessageAttachmentRepository interface {
+	MessageAttachmentQueryRepository
+	MessageAttachmentCommandRepository
+}
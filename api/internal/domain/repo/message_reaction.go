@@ -0,0 +1,29 @@
+package repo
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+)
+
+type MessageReactionQuery struct {
+	MessageID *int64
+	UserID    *uuid.UUID
+	Emoji     *string
+}
+
+type MessageReactionQueryRepository interface {
+	Find(ctx context.Context, messageID int64, userID uuid.UUID, emoji string) (*entity.MessageReaction, error)
+	Query(ctx context.Context, q *MessageReactionQuery) ([]*entity.MessageReaction, error)
+}
+
+type MessageReactionCommandRepository interface {
+	// Create はリアクションを追加し、追加したかを返す。同じリアクションが既にあれば何もせず false を返す
+	Create(ctx context.Context, reaction *entity.MessageReaction) (bool, error)
+	Delete(ctx context.Context, messageID int64, userID uuid.UUID, emoji string) error
+}
+
+type MessageReactionRepository interface {
+	MessageReactionQueryRepository
+	MessageReactionCommandRepository
+}
@@ -14,17 +14,46 @@ type NotificationQuery struct {
 	Type        *entity.NotificationType
 	IsRead      *bool
 	CreatedAt   *time.Time
+	// Types を指定すると、そのいずれかの種類の通知だけを返す
+	Types []entity.NotificationType
+	// UpdatedAfter より後に更新された通知だけを返す
+	UpdatedAfter *time.Time
+	// Before より後ろに並ぶ通知だけを返す。カーソルページング用
+	Before *NotificationCursor
+	// 0 なら件数を制限しない。最後にまとめた時刻の新しい順 (updated_at, id の降順) に返す
+	Limit int
+}
+
+// NotificationCursor は一覧の並び (updated_at, id の降順) での位置
+// まとめて更新された通知は一覧の先頭に戻るため、id だけでは位置が決まらない
+type NotificationCursor struct {
+	UpdatedAt time.Time
+	ID        int64
 }
 
 type NotificationQueryRepository interface {
 	Find(ctx context.Context, notificationID int64) (*entity.Notification, error)
 	Query(ctx context.Context, q *NotificationQuery) ([]*entity.Notification, error)
+	CountUnread(ctx context.Context, recipientID uuid.UUID) (int, error)
+	// FindLatest は since 以降に更新された、同じ相手・種類の最新の通知を返す
+	FindLatest(ctx context.Context, recipientID, senderID uuid.UUID, notifType entity.NotificationType, since time.Time) (*entity.Notification, error)
+	// FindDigestRecipients はまとめ通知メールを送るべきユーザーを返す
+	// quietSince 以降に接続がなく、前回のメールより後・quietSince 以前に更新された types の未読通知があり、
+	// その種類のメール通知を止めていないユーザーが対象
+	FindDigestRecipients(ctx context.Context, types []entity.NotificationType, quietSince time.Time) ([]uuid.UUID, error)
 }
 
 type NotificationCommandRepository interface {
 	Create(ctx context.Context, notification *entity.Notification) error
 	Update(ctx context.Context, notification *entity.Notification) error
 	Delete(ctx context.Context, notificationID int64) error
+	// MarkAllRead は recipientID の未読通知をまとめて既読にし、更新した件数を返す
+	MarkAllRead(ctx context.Context, recipientID uuid.UUID) (int64, error)
+	// LockAggregation はトランザクションが終わるまで同じ受信者・送信者・種類の通知の作成を直列化する
+	// 同時に届いたいいね・閲覧が別々の通知にならないよう、FindLatest の前に取る
+	LockAggregation(ctx context.Context, recipientID, senderID uuid.UUID, notifType entity.NotificationType) error
+	// Increment はまとめた件数を 1 増やし、未読に戻して updated_at を更新する
+	Increment(ctx context.Context, notification *entity.Notification) error
 }
 
 type NotificationRepository interface {
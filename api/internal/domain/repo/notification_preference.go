@@ -0,0 +1,43 @@
+package repo
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"time"
+)
+
+type NotificationPreferenceQueryRepository interface {
+	Find(ctx context.Context, userID uuid.UUID, notifType entity.NotificationType) (*entity.NotificationPreference, error)
+	Query(ctx context.Context, userID uuid.UUID) ([]*entity.NotificationPreference, error)
+}
+
+type NotificationPreferenceCommandRepository interface {
+	Upsert(ctx context.Context, pref *entity.NotificationPreference) error
+}
+
+type NotificationPreferenceRepository interface {
+	NotificationPreferenceQueryRepository
+	NotificationPreferenceCommandRepository
+}
+
+type NotificationMuteQuery struct {
+	UserID      *uuid.UUID
+	MutedUserID *uuid.UUID
+	// ActiveAt を指定すると、その時刻にまだ有効なミュートだけを返す
+	ActiveAt *time.Time
+}
+
+type NotificationMuteQueryRepository interface {
+	Query(ctx context.Context, q *NotificationMuteQuery) ([]*entity.NotificationMute, error)
+}
+
+type NotificationMuteCommandRepository interface {
+	Upsert(ctx context.Context, mute *entity.NotificationMute) error
+	DeleteAll(ctx context.Context, userID uuid.UUID) error
+}
+
+type NotificationMuteRepository interface {
+	NotificationMuteQueryRepository
+	NotificationMuteCommandRepository
+}
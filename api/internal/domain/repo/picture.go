@@ -7,23 +7,50 @@ import (
 	"time"
 )
 
+// MaxPictureHashDistance は FindSimilar がインデックスで漏れなく探せるハミング距離の上限
+// ハッシュを 8 ビットずつ 8 つに分けて、どれか 1 つが一致する写真を候補にするため
+const MaxPictureHashDistance = 7
+
 type PictureQuery struct {
 	ID           *int32
 	UserID       *uuid.UUID
 	URL          *string
 	IsProfilePic *bool
+	Status       *entity.PictureStatus
 	CreatedAt    *time.Time
 }
 
+// PictureModerationQuery は検査・審査する写真を古い順に探す
+type PictureModerationQuery struct {
+	Status entity.PictureStatus
+	// true なら ImageClassifier が検査済みの写真だけ、false なら未検査の写真だけ。nil なら両方
+	Classified *bool
+	Limit      int
+	Offset     int
+}
+
 type PictureQueryRepository interface {
 	Find(ctx context.Context, pictureID int32) (*entity.Picture, error)
 	Query(ctx context.Context, q *PictureQuery) ([]*entity.Picture, error)
+	QueryModeration(ctx context.Context, q *PictureModerationQuery) ([]*entity.Picture, error)
+	// FindSimilar は userID 以外のユーザーの写真のうち、dHash か pHash のハミング距離が maxDistance 以下のものを返す
+	// maxDistance は MaxPictureHashDistance まで
+	FindSimilar(ctx context.Context, userID uuid.UUID, dhash, phash int64, maxDistance int) ([]*entity.Picture, error)
 }
 
 type PictureCommandRepository interface {
+	// LockByUser はトランザクションが終わるまで同じユーザーの写真の変更を直列化する
+	// 枚数の上限とプロフィール写真の付け替えを、同時のアップロードに対しても守るために使う
+	LockByUser(ctx context.Context, userID uuid.UUID) error
 	Create(ctx context.Context, picture *entity.Picture) error
 	Update(ctx context.Context, picture *entity.Picture) error
 	Delete(ctx context.Context, pictureID int32) error
+	// Classify は未検査の写真に検査の結果 (status, flag_reason, dhash, classified_at) を書く
+	// 既に検査・審査されていたら false
+	Classify(ctx context.Context, picture *entity.Picture) (bool, error)
+	// Review は審査待ちの写真に審査の結果 (status, flag_reason, reviewed_by, reviewed_at) を書く
+	// 既に審査されていたら false
+	Review(ctx context.Context, picture *entity.Picture) (bool, error)
 }
 
 type PictureRepository interface {
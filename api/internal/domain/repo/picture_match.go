@@ -0,0 +1,36 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+)
+
+type PictureMatchQuery struct {
+	UserID *uuid.UUID
+	Limit  int
+	Offset int
+}
+
+// FakeAccountSignalQuery は UserID がなければ、他のユーザーの写真と似た写真を持つユーザーを疑わしい順に探す
+type FakeAccountSignalQuery struct {
+	UserID *uuid.UUID
+	Limit  int
+	Offset int
+}
+
+type PictureMatchQueryRepository interface {
+	Query(ctx context.Context, q *PictureMatchQuery) ([]*entity.PictureMatch, error)
+	QueryFakeAccountSignals(ctx context.Context, q *FakeAccountSignalQuery) ([]*entity.FakeAccountSignal, error)
+}
+
+type PictureMatchCommandRepository interface {
+	// Create は同じ組み合わせが既にあれば何もしない
+	Create(ctx context.Context, match *entity.PictureMatch) error
+}
+
+type PictureMatchRepository interface {
+	PictureMatchQueryRepository
+	PictureMatchCommandRepository
+}
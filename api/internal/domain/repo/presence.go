@@ -0,0 +1,15 @@
+package repo
+
+import (
+	"context"
+	"github.com/google/uuid"
+)
+
+type PresenceQueryRepository interface {
+	IsOnline(ctx context.Context, userID uuid.UUID) (bool, error)
+	FindOnline(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+}
+
+type PresenceRepository interface {
+	PresenceQueryRepository
+}
@@ -0,0 +1,12 @@
+package repo
+
+import (
+	"context"
+	"time"
+)
+
+// PushDeliveryRepository は api の全レプリカが同じイベントを受け取るため、1 つのレプリカだけが送るように印をつける
+type PushDeliveryRepository interface {
+	// Claim は key を ttl の間確保する。他のレプリカが先に確保していれば false
+	Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
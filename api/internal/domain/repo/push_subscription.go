@@ -0,0 +1,27 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+)
+
+// MaxPushSubscriptionsPerUser を超えた購読は、更新の古いものから削除する
+const MaxPushSubscriptionsPerUser = 10
+
+type PushSubscriptionQueryRepository interface {
+	Query(ctx context.Context, userID uuid.UUID) ([]*entity.PushSubscription, error)
+}
+
+type PushSubscriptionCommandRepository interface {
+	// Upsert は endpoint ごとに保存する。別のユーザーが同じ端末で登録し直したら持ち主を付け替える
+	// ユーザーの購読が MaxPushSubscriptionsPerUser を超えたら古いものを消す
+	Upsert(ctx context.Context, sub *entity.PushSubscription) error
+	Delete(ctx context.Context, endpoint string) error
+}
+
+type PushSubscriptionRepository interface {
+	PushSubscriptionQueryRepository
+	PushSubscriptionCommandRepository
+}
@@ -8,6 +8,8 @@ import (
 
 type UserQuery struct {
 	ID *uuid.UUID
+	// IDs があれば、そのいずれかのユーザーを 1 回のクエリで探す
+	IDs []uuid.UUID
 }
 
 type UserQueryRepository interface {
@@ -2,19 +2,73 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/client"
 	"github.com/icchon/matcha/api/internal/domain/entity"
 )
 
 type Chat struct {
 	OtherUser   entity.UserProfile `json:"other_user"`
 	LastMessage *entity.Message    `json:"last_message"` // Can be nil if no messages yet
+	ReadState
+}
+
+// ReadState は会話の既読位置。0 はまだ何も読んでいない
+type ReadState struct {
+	// 自分が相手のメッセージをどこまで読んだか
+	LastReadMessageID int64 `json:"last_read_message_id"`
+	// 相手が自分のメッセージをどこまで読んだか ("ここまで既読" の表示用)
+	PeerLastReadMessageID int64 `json:"peer_last_read_message_id"`
+}
+
+type ChatHistory struct {
+	Messages []*entity.Message `json:"messages"`
+	ReadState
 }
 
 type ChatService interface {
 	GetChatsForUser(ctx context.Context, userID uuid.UUID) ([]*Chat, error)
-	GetChatMessages(ctx context.Context, params *GetChatMessagesParams) ([]*entity.Message, error)
+	GetChatMessages(ctx context.Context, params *GetChatMessagesParams) (*ChatHistory, error)
+	// 送信者本人が送信後 一定時間内に限り編集できる。新しい本文は送信時と同じ MessageFilter を通す
+	// 保留されたら元のメッセージと保留した編集を返す。拒否されたら ErrRejected
+	EditMessage(ctx context.Context, userID, otherUserID uuid.UUID, messageID int64, content string) (*entity.Message, *entity.HeldMessage, error)
+	// 送信者本人による送信取り消し。行は残して本文を消す (tombstone)
+	DeleteMessage(ctx context.Context, userID, otherUserID uuid.UUID, messageID int64) (*entity.Message, error)
+	GetReactions(ctx context.Context, userID, otherUserID uuid.UUID, messageID int64) ([]*entity.MessageReaction, error)
+	AddReaction(ctx context.Context, userID, otherUserID uuid.UUID, messageID int64, emoji string) (*entity.MessageReaction, error)
+	RemoveReaction(ctx context.Context, userID, otherUserID uuid.UUID, messageID int64, emoji string) error
+	// UploadAttachment は otherUserID に送るための添付ファイルを保存する。メッセージへの紐付けは送信時に行う
+	// file は読みながら filesrv に流す。UploadID のときは送り終えた再開可能アップロードを使う
+	UploadAttachment(ctx context.Context, userID, otherUserID uuid.UUID, file *client.FileSource) (*entity.MessageAttachment, error)
+	// GetAttachmentURL は会話の参加者にだけ、期限付きの署名付き URL を発行する
+	GetAttachmentURL(ctx context.Context, userID, otherUserID uuid.UUID, attachmentID string) (string, error)
+	// ResolveAttachments は送信するメッセージに付ける添付ファイルを検証し、署名付き URL を付けて返す
+	ResolveAttachments(ctx context.Context, senderID, recipientID uuid.UUID, attachmentIDs []string) ([]*entity.MessageAttachment, error)
+	SearchMessages(ctx context.Context, params *SearchChatMessagesParams) (*ChatSearchResult, error)
+}
+
+type SearchChatMessagesParams struct {
+	UserID uuid.UUID
+	Query  string
+	// 前のページの next_cursor。空なら最新から
+	Cursor string
+	Limit  int
+}
+
+type ChatSearchHit struct {
+	MessageID   int64     `json:"message_id"`
+	OtherUserID uuid.UUID `json:"other_user_id"`
+	SenderID    uuid.UUID `json:"sender_id"`
+	SentAt      time.Time `json:"sent_at"`
+	// HTML エスケープ済みの本文の抜粋。一致した部分を <mark> で囲む
+	Snippet string `json:"snippet"`
+}
+
+type ChatSearchResult struct {
+	Hits       []*ChatSearchHit `json:"hits"`
+	NextCursor string           `json:"next_cursor,omitempty"`
 }
 
 type GetChatMessagesParams struct {
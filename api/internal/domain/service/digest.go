@@ -0,0 +1,12 @@
+package service
+
+import (
+	"context"
+)
+
+type DigestService interface {
+	// SendDigests は静かな期間を過ぎた未読通知をまとめてメールで送り、送った通数を返す
+	SendDigests(ctx context.Context) (int, error)
+	// Unsubscribe はメールの配信停止リンクのトークンを検証し、まとめ通知メールを止める
+	Unsubscribe(ctx context.Context, token string) error
+}
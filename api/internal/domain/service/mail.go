@@ -2,9 +2,34 @@ package service
 
 import (
 	"context"
+
+	"github.com/icchon/matcha/api/internal/domain/repo"
 )
 
+// DigestSummary はまとめ通知メールに載せる未読の件数
+type DigestSummary struct {
+	Matches  int
+	Likes    int
+	Messages int
+}
+
+// RenderedEmail は組み立て済みのメール
+type RenderedEmail struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+// 言語は ctx の i18n.Locale で決まる
+// Send 系は rm のトランザクションで送信待ちのメールを保存するだけで、実際の送信は MailDeliveryService が行う
 type MailService interface {
-	SendVerificationEmail(ctx context.Context, toEmail string, username string, token string) error
-	SendPasswordResetEmail(ctx context.Context, toEmail string, token string) error
+	SendVerificationEmail(ctx context.Context, rm repo.RepositoryManager, toEmail string, username string, token string) error
+	SendPasswordResetEmail(ctx context.Context, rm repo.RepositoryManager, toEmail string, token string) error
+	SendDigestEmail(ctx context.Context, rm repo.RepositoryManager, toEmail string, username string, summary *DigestSummary, unsubscribeToken string) error
+	PreviewEmail(ctx context.Context, name string) (*RenderedEmail, error)
+}
+
+type MailDeliveryService interface {
+	// DeliverPending は送信待ちのメールを送り、送れた通数を返す。失敗したメールはバックオフして再送する
+	DeliverPending(ctx context.Context) (int, error)
 }
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"image"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+)
+
+type ModerationAction string
+
+// 強い順に reject > hold > mask > allow
+const (
+	ModerationAllow  ModerationAction = "allow"
+	ModerationMask   ModerationAction = "mask"
+	ModerationHold   ModerationAction = "hold"
+	ModerationReject ModerationAction = "reject"
+)
+
+// ModerationResult は MessageFilter の判定結果
+// Content は mask の場合に書き換えた本文。Reason は hold / reject の理由で、送信者にも返す
+type ModerationResult struct {
+	Action  ModerationAction
+	Content string
+	Reason  string
+}
+
+// MessageFilter は保存前のメッセージを検査する
+// msg.Content は手前のフィルタで mask 済みの本文になっている
+type MessageFilter interface {
+	Filter(ctx context.Context, msg *entity.Message) (*ModerationResult, error)
+}
+
+type ListHeldMessagesParams struct {
+	Status entity.HeldMessageStatus
+	Limit  int
+	Offset int
+}
+
+type ModerationService interface {
+	ListHeldMessages(ctx context.Context, params *ListHeldMessagesParams) ([]*entity.HeldMessage, error)
+	// ApproveHeldMessage は保留中のメッセージを messages に保存して相手に配信する
+	ApproveHeldMessage(ctx context.Context, reviewerID uuid.UUID, heldMessageID int64) (*entity.Message, error)
+	// RejectHeldMessage は保留中のメッセージを破棄し、理由を送信者に通知する
+	RejectHeldMessage(ctx context.Context, reviewerID uuid.UUID, heldMessageID int64, reason string) (*entity.HeldMessage, error)
+}
+
+// ImageClassification は ImageClassifier の判定結果
+// Flagged なら写真を審査待ちに残す。Reason はその理由で、管理者に見せる
+// Matches は他のユーザーの写真と似ていたことの記録で、偽アカウントの兆候として残す
+type ImageClassification struct {
+	Flagged bool
+	Reason  string
+	Matches []*entity.PictureMatch
+}
+
+// ImageClassifier はアップロードされた写真を検査する
+// img は card の変種。pic.DHash と pic.PHash は計算済み
+type ImageClassifier interface {
+	Classify(ctx context.Context, pic *entity.Picture, img image.Image) (*ImageClassification, error)
+}
+
+type ListPicturesParams struct {
+	Status entity.PictureStatus
+	Limit  int
+	Offset int
+}
+
+type ListFakeAccountSignalsParams struct {
+	Limit  int
+	Offset int
+}
+
+type PictureModerationService interface {
+	// ClassifyPending は未検査の写真を ImageClassifier で検査し、検査した枚数を返す
+	// 疑わしくなければ承認し、疑わしければ審査待ちに残す
+	ClassifyPending(ctx context.Context) (int, error)
+	// ListPictures は審査待ち (検査済みで疑わしいもの) などの写真を、reviewerID が見られる URL を付けて返す
+	ListPictures(ctx context.Context, reviewerID uuid.UUID, params *ListPicturesParams) ([]*entity.Picture, error)
+	ApprovePicture(ctx context.Context, reviewerID uuid.UUID, pictureID int32) (*entity.Picture, error)
+	// RejectPicture は写真を本人以外に見せないままにする。reason を省略すると検査の理由を残す
+	RejectPicture(ctx context.Context, reviewerID uuid.UUID, pictureID int32, reason string) (*entity.Picture, error)
+	// ListFakeAccountSignals は他のユーザーの写真と似た写真を上げたユーザーを、一致の多い順に返す
+	ListFakeAccountSignals(ctx context.Context, params *ListFakeAccountSignalsParams) ([]*entity.FakeAccountSignal, error)
+	// FindFakeAccountSignal は userID の兆候と、その元になった一致を返す
+	FindFakeAccountSignal(ctx context.Context, userID uuid.UUID) (*entity.FakeAccountSignal, []*entity.PictureMatch, error)
+}
@@ -4,9 +4,68 @@ import (
 	"context"
 	"github.com/google/uuid"
 	"github.com/icchon/matcha/api/internal/domain/entity"
+	"time"
 )
 
+// NotificationChannels は通知をどのチャネルで受け取るか
+type NotificationChannels struct {
+	// WebSocket でのリアルタイム通知
+	InApp bool `json:"in_app"`
+	// メールのまとめ通知
+	EmailDigest bool `json:"email_digest"`
+}
+
+type NotificationMuteSetting struct {
+	UserID     uuid.UUID `json:"user_id"`
+	MutedUntil time.Time `json:"muted_until"`
+}
+
+type NotificationSettings struct {
+	Types map[entity.NotificationType]NotificationChannels `json:"types"`
+	// 期限切れのミュートは含まない
+	Mutes []*NotificationMuteSetting `json:"mutes"`
+}
+
+type GetNotificationsParams struct {
+	RecipientID uuid.UUID
+	UnreadOnly  bool
+	// 前のページの next_cursor。空なら最新から
+	Cursor string
+	Limit  int
+}
+
+type NotificationItem struct {
+	ID       int64      `json:"id"`
+	SenderID *uuid.UUID `json:"sender_id"`
+	// 送信者の表示名とサムネイル。送信者がいない・退会済みなら空
+	SenderName         string                  `json:"sender_name"`
+	SenderThumbnailURL string                  `json:"sender_thumbnail_url"`
+	Type               entity.NotificationType `json:"type"`
+	IsRead             bool                    `json:"is_read"`
+	// 同じ相手からの like / view をまとめた件数
+	Count     int       `json:"count"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type NotificationPage struct {
+	Notifications []*NotificationItem `json:"notifications"`
+	NextCursor    string              `json:"next_cursor,omitempty"`
+	// バッジ表示用。フィルタに関係なく未読の総数
+	UnreadCount int `json:"unread_count"`
+}
+
 type NotificationService interface {
-	GetNotifications(ctx context.Context, recipientID uuid.UUID) ([]*entity.Notification, error)
+	GetNotifications(ctx context.Context, params *GetNotificationsParams) (*NotificationPage, error)
+	// 既読・未読の変更、一括既読、削除は本人の他のタブにも通知する
+	MarkNotificationRead(ctx context.Context, userID uuid.UUID, notificationID int64, isRead bool) (*NotificationItem, error)
+	MarkAllNotificationsRead(ctx context.Context, userID uuid.UUID) (int64, error)
+	DeleteNotification(ctx context.Context, userID uuid.UUID, notificationID int64) error
+	// CreateAndSendNotification は受信者の設定を見て保存・配信する
+	// どのチャネルでも受け取らない場合とミュート中の相手からの場合は何もせず nil を返す
+	// 同じ相手からの like / view は一定時間内なら既存の通知の件数を増やして配信し直す
 	CreateAndSendNotification(ctx context.Context, senderID uuid.UUID, recipiendID uuid.UUID, notifType entity.NotificationType) (*entity.Notification, error)
+	GetSettings(ctx context.Context, userID uuid.UUID) (*NotificationSettings, error)
+	// UpdateSettings は設定を置き換える。types にない種類はすべてのチャネルで受け取る設定に戻る
+	UpdateSettings(ctx context.Context, userID uuid.UUID, settings *NotificationSettings) (*NotificationSettings, error)
 }
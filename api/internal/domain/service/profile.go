@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/client"
 	"github.com/icchon/matcha/api/internal/domain/entity"
 )
 
@@ -13,10 +14,18 @@ type ProfileService interface {
 	FindWhoViewedMeList(ctx context.Context, userID uuid.UUID) ([]*entity.View, error)
 	DeletePicture(ctx context.Context, pictureID int32, userID uuid.UUID) error
 	FindPicture(ctx context.Context, pictureID int32) (*entity.Picture, error)
-	FindPictures(ctx context.Context, userID uuid.UUID) ([]*entity.Picture, error)
-	UpdatePictureStatus(ctx context.Context, userID uuid.UUID, pictureID int32, isProfilePic bool) error
-	UploadPicture(ctx context.Context, userID uuid.UUID, image []byte) (*entity.Picture, error)
-	UploadPicutures(ctx context.Context, userID uuid.UUID, images [][]byte) ([]*entity.Picture, error)
+	// FindPictures は viewerID が見るための署名付き URL を付けて userID の写真を返す
+	FindPictures(ctx context.Context, viewerID, userID uuid.UUID) ([]*entity.Picture, error)
+	// AvatarURL は写真のないユーザーの代わりに表示する既定の画像の URL を返す
+	AvatarURL(userID uuid.UUID) string
+	// SetProfilePicture は pictureID をプロフィール写真にし、並び順どおりの全写真を返す
+	SetProfilePicture(ctx context.Context, userID uuid.UUID, pictureID int32) ([]*entity.Picture, error)
+	// ReorderPictures は pictureIDs の順に並べ替える。pictureIDs は自分の全写真を 1 回ずつ含む
+	ReorderPictures(ctx context.Context, userID uuid.UUID, pictureIDs []int32) ([]*entity.Picture, error)
+	// 上限 (5 枚) を超えるアップロードは ErrConflict
+	// image は読みながら filesrv に流す。UploadID のときは送り終えた再開可能アップロードを使う
+	UploadPicture(ctx context.Context, userID uuid.UUID, image *client.FileSource) (*entity.Picture, error)
+	UploadPicutures(ctx context.Context, userID uuid.UUID, images []*client.FileSource) ([]*entity.Picture, error)
 	FindWhoLikedMeList(ctx context.Context, userID uuid.UUID) ([]*entity.Like, error)
 	FindProfile(ctx context.Context, userID uuid.UUID) (*entity.UserProfile, error)
 	ListProfiles(ctx context.Context, selfUserID uuid.UUID, lat, lon, dist *float64, ageMin, ageMax *int, gender *entity.Gender) ([]*entity.UserProfile, error)
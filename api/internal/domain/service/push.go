@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+)
+
+// PushSubscriptionParams はブラウザの PushSubscription.toJSON() と同じ形
+type PushSubscriptionParams struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+	UserAgent string `json:"-"`
+}
+
+type PushService interface {
+	// GetPublicKey は VAPID の公開鍵を返す。Web Push が無効なら ErrNotFound
+	GetPublicKey(ctx context.Context) (string, error)
+	Subscribe(ctx context.Context, userID uuid.UUID, params *PushSubscriptionParams) (*entity.PushSubscription, error)
+	Unsubscribe(ctx context.Context, userID uuid.UUID, endpoint string) error
+}
+
+// PushDeliveryService は WebSocket で接続していないユーザーの端末に Web Push で届ける
+type PushDeliveryService interface {
+	PushNotification(ctx context.Context, payload *client.NotificationPayload) error
+	PushChatMessage(ctx context.Context, payload *client.MessagePayload) error
+}
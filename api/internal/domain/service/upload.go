@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/client"
+)
+
+type UploadService interface {
+	// CreateUpload は length バイトの再開可能アップロードを作る。上限を超える長さは ErrInvalidInput
+	CreateUpload(ctx context.Context, userID uuid.UUID, length int64) (*client.Upload, error)
+}
@@ -0,0 +1,88 @@
+package i18n
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Locale string
+
+const (
+	Ja Locale = "ja"
+	En Locale = "en"
+
+	// 言語が分からないときに使う
+	Default = Ja
+)
+
+// Supported はメールなどを用意している言語
+var Supported = []Locale{Ja, En}
+
+// Parse は "en" や "en-US" のような言語タグを対応している Locale にする
+func Parse(tag string) (Locale, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	primary, _, _ := strings.Cut(tag, "-")
+	for _, l := range Supported {
+		if primary == string(l) {
+			return l, true
+		}
+	}
+	return "", false
+}
+
+// FromAcceptLanguage は Accept-Language ヘッダーから q 値が最も高い対応言語を選ぶ
+func FromAcceptLanguage(header string) Locale {
+	type candidate struct {
+		locale Locale
+		q      float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		locale, ok := Parse(tag)
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{locale: locale, q: q})
+		}
+	}
+	if len(candidates) == 0 {
+		return Default
+	}
+	// 同じ q 値ならヘッダーで先に書かれたものを優先する
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].locale
+}
+
+type contextKey struct{}
+
+func WithLocale(ctx context.Context, locale Locale) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext は WithLocale で設定した言語を返す。なければ Default
+func FromContext(ctx context.Context) Locale {
+	if locale, ok := ctx.Value(contextKey{}).(Locale); ok {
+		return locale
+	}
+	return Default
+}
+
+// WithPreferred はユーザーが設定した言語があれば ctx の言語を上書きする
+func WithPreferred(ctx context.Context, preferred string) context.Context {
+	if locale, ok := Parse(preferred); ok {
+		return WithLocale(ctx, locale)
+	}
+	return ctx
+}
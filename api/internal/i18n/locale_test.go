@@ -0,0 +1,36 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromAcceptLanguage(t *testing.T) {
+	testCases := []struct {
+		header string
+		want   Locale
+	}{
+		{header: "", want: Default},
+		{header: "en-US,en;q=0.9", want: En},
+		{header: "fr-FR, en;q=0.5, ja;q=0.8", want: Ja},
+		{header: "ja;q=0, en;q=0.1", want: En},
+		{header: "de, fr", want: Default},
+		{header: "EN-gb;q=0.7, ja;q=0.7", want: En},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.header, func(t *testing.T) {
+			assert.Equal(t, tc.want, FromAcceptLanguage(tc.header))
+		})
+	}
+}
+
+func TestWithPreferred(t *testing.T) {
+	ctx := WithLocale(context.Background(), En)
+
+	assert.Equal(t, Ja, FromContext(WithPreferred(ctx, "ja")))
+	assert.Equal(t, En, FromContext(WithPreferred(ctx, "")))
+	assert.Equal(t, En, FromContext(WithPreferred(ctx, "xx")))
+	assert.Equal(t, Default, FromContext(context.Background()))
+}
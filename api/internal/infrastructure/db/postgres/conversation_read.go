@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+)
+
+type conversationReadRepository struct {
+	db DBTX
+}
+
+func NewConversationReadRepository(db DBTX) repo.ConversationReadRepository {
+	return &conversationReadRepository{db: db}
+}
+
+func (r *conversationReadRepository) Find(ctx context.Context, userID, peerID uuid.UUID) (*entity.ConversationRead, error) {
+	var read entity.ConversationRead
+	query := "SELECT * FROM conversation_reads WHERE user_id = $1 AND peer_id = $2"
+	err := r.db.GetContext(ctx, &read, query, userID, peerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &read, nil
+}
+
+func (r *conversationReadRepository) Advance(ctx context.Context, userID, peerID uuid.UUID, upTo int64) (*entity.ConversationRead, error) {
+	query := `
+		INSERT INTO conversation_reads (user_id, peer_id, last_read_message_id, updated_at)
+		SELECT $1, $2, COALESCE(MAX(id), 0), NOW()
+		FROM messages
+		WHERE sender_id = $2 AND recipient_id = $1 AND id <= $3
+		ON CONFLICT (user_id, peer_id) DO UPDATE SET
+			last_read_message_id = GREATEST(conversation_reads.last_read_message_id, EXCLUDED.last_read_message_id),
+			updated_at = EXCLUDED.updated_at
+		RETURNING *
+	`
+	var read entity.ConversationRead
+	if err := r.db.GetContext(ctx, &read, query, userID, peerID, upTo); err != nil {
+		return nil, err
+	}
+	return &read, nil
+}
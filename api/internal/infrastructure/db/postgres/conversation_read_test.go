@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConversationReadRepository_Advance(t *testing.T) {
+	userID := uuid.New()
+	peerID := uuid.New()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewConversationReadRepository(db)
+
+	t.Run("Advance watermark without moving it backwards", func(t *testing.T) {
+		expectedSQL := `INSERT INTO conversation_reads .* SELECT \$1, \$2, COALESCE\(MAX\(id\), 0\), NOW\(\) FROM messages WHERE sender_id = \$2 AND recipient_id = \$1 AND id <= \$3 ON CONFLICT \(user_id, peer_id\) DO UPDATE SET last_read_message_id = GREATEST\(.*\)`
+
+		rows := sqlmock.NewRows([]string{"user_id", "peer_id", "last_read_message_id", "updated_at"}).
+			AddRow(userID, peerID, 42, time.Now())
+		mock.ExpectQuery(expectedSQL).
+			WithArgs(userID, peerID, int64(100)).
+			WillReturnRows(rows)
+
+		read, err := r.Advance(context.Background(), userID, peerID, 100)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), read.LastReadMessageID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_MarkConversationRead(t *testing.T) {
+	userID := uuid.New()
+	peerID := uuid.New()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewMessageRepository(db)
+
+	t.Run("Mark unread messages up to the watermark in one update", func(t *testing.T) {
+		expectedSQL := `UPDATE messages SET is_read = TRUE WHERE recipient_id = \$1 AND sender_id = \$2 AND id <= \$3 AND is_read IS NOT TRUE`
+
+		mock.ExpectExec(expectedSQL).
+			WithArgs(userID, peerID, int64(100)).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+
+		n, err := r.MarkConversationRead(context.Background(), userID, peerID, 100)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+)
+
+type emailDigestRepository struct {
+	db DBTX
+}
+
+func NewEmailDigestRepository(db DBTX) repo.EmailDigestRepository {
+	return &emailDigestRepository{db: db}
+}
+
+func (r *emailDigestRepository) Upsert(ctx context.Context, digest *entity.EmailDigest) error {
+	query := `
+		INSERT INTO email_digests (user_id, last_sent_at)
+		VALUES (:user_id, :last_sent_at)
+		ON CONFLICT (user_id) DO UPDATE SET
+			last_sent_at = EXCLUDED.last_sent_at
+	`
+	_, err := r.db.NamedExecContext(ctx, query, digest)
+	return err
+}
+
+func (r *emailDigestRepository) Find(ctx context.Context, userID uuid.UUID) (*entity.EmailDigest, error) {
+	var digest entity.EmailDigest
+	query := "SELECT * FROM email_digests WHERE user_id = $1"
+	err := r.db.GetContext(ctx, &digest, query, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &digest, nil
+}
@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmailDigestRepository_Upsert(t *testing.T) {
+	userID := uuid.New()
+	now := time.Now()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewEmailDigestRepository(db)
+
+	expectedSQL := `INSERT INTO email_digests \(user_id, last_sent_at\) VALUES \(\?, \?\) ON CONFLICT \(user_id\) DO UPDATE SET last_sent_at = EXCLUDED.last_sent_at`
+	mock.ExpectExec(expectedSQL).WithArgs(userID, now).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = r.Upsert(context.Background(), &entity.EmailDigest{UserID: userID, LastSentAt: now})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEmailDigestRepository_Find(t *testing.T) {
+	userID := uuid.New()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewEmailDigestRepository(db)
+
+	expectedSQL := `SELECT \* FROM email_digests WHERE user_id = \$1`
+	mock.ExpectQuery(expectedSQL).WithArgs(userID).WillReturnRows(sqlmock.NewRows([]string{"user_id", "last_sent_at"}))
+
+	digest, err := r.Find(context.Background(), userID)
+
+	assert.NoError(t, err)
+	assert.Nil(t, digest)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+)
+
+type heldMessageRepository struct {
+	db DBTX
+}
+
+func NewHeldMessageRepository(db DBTX) repo.HeldMessageRepository {
+	return &heldMessageRepository{db: db}
+}
+
+func (r *heldMessageRepository) Create(ctx context.Context, held *entity.HeldMessage) error {
+	query := `
+		INSERT INTO held_messages (sender_id, recipient_id, content, attachment_ids, reason, sent_at, edit_message_id)
+		VALUES (:sender_id, :recipient_id, :content, :attachment_ids, :reason, :sent_at, :edit_message_id)
+		RETURNING *
+	`
+	stmt, err := r.db.PrepareNamedContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	return stmt.QueryRowxContext(ctx, held).StructScan(held)
+}
+
+func (r *heldMessageRepository) Review(ctx context.Context, held *entity.HeldMessage) (bool, error) {
+	query := `
+		UPDATE held_messages SET
+			status = :status,
+			reviewed_by = :reviewed_by,
+			reviewed_at = :reviewed_at,
+			message_id = :message_id
+		WHERE id = :id AND status = 'pending'
+	`
+	res, err := r.db.NamedExecContext(ctx, query, held)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *heldMessageRepository) Find(ctx context.Context, id int64) (*entity.HeldMessage, error) {
+	var held entity.HeldMessage
+	query := "SELECT * FROM held_messages WHERE id = $1"
+	err := r.db.GetContext(ctx, &held, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &held, nil
+}
+
+func (r *heldMessageRepository) Query(ctx context.Context, q *repo.HeldMessageQuery) ([]*entity.HeldMessage, error) {
+	query := "SELECT * FROM held_messages WHERE 1=1"
+	args := []interface{}{}
+	argCount := 1
+
+	if q.SenderID != nil {
+		query += fmt.Sprintf(" AND sender_id = $%d", argCount)
+		args = append(args, *q.SenderID)
+		argCount++
+	}
+	if q.Status != nil {
+		query += fmt.Sprintf(" AND status = $%d", argCount)
+		args = append(args, *q.Status)
+		argCount++
+	}
+
+	query += " ORDER BY created_at, id"
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, q.Limit)
+		argCount++
+	}
+	if q.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", argCount)
+		args = append(args, q.Offset)
+		argCount++
+	}
+
+	var helds []*entity.HeldMessage
+	if err := r.db.SelectContext(ctx, &helds, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return helds, nil
+}
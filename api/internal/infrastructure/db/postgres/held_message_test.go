@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeldMessageRepository_Review(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewHeldMessageRepository(db)
+
+	expectedSQL := `UPDATE held_messages SET .* WHERE id = \? AND status = 'pending'`
+	held := &entity.HeldMessage{
+		ID:         7,
+		Status:     entity.HeldMessageApproved,
+		ReviewedBy: uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		ReviewedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		MessageID:  sql.NullInt64{Int64: 42, Valid: true},
+	}
+
+	t.Run("Review a pending message", func(t *testing.T) {
+		mock.ExpectExec(expectedSQL).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		ok, err := r.Review(context.Background(), held)
+
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Already reviewed message is left untouched", func(t *testing.T) {
+		mock.ExpectExec(expectedSQL).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		ok, err := r.Review(context.Background(), held)
+
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+)
+
+type mailOutboxRepository struct {
+	db DBTX
+}
+
+func NewMailOutboxRepository(db DBTX) repo.MailOutboxRepository {
+	return &mailOutboxRepository{db: db}
+}
+
+func (r *mailOutboxRepository) Create(ctx context.Context, mail *entity.OutboxMail) error {
+	query := `
+		INSERT INTO mail_outbox (to_email, subject, html_body, text_body)
+		VALUES (:to_email, :subject, :html_body, :text_body)
+		RETURNING *
+	`
+	stmt, err := r.db.PrepareNamedContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	return stmt.QueryRowxContext(ctx, mail).StructScan(mail)
+}
+
+func (r *mailOutboxRepository) Update(ctx context.Context, mail *entity.OutboxMail) error {
+	query := `
+		UPDATE mail_outbox SET
+			status = :status,
+			attempts = :attempts,
+			next_attempt_at = :next_attempt_at,
+			last_error = :last_error,
+			sent_at = :sent_at
+		WHERE id = :id
+	`
+	_, err := r.db.NamedExecContext(ctx, query, mail)
+	return err
+}
+
+func (r *mailOutboxRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]*entity.OutboxMail, error) {
+	query := `
+		SELECT * FROM mail_outbox
+		WHERE status = 'pending' AND next_attempt_at <= $1
+		ORDER BY id
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`
+	var mails []*entity.OutboxMail
+	if err := r.db.SelectContext(ctx, &mails, query, now, limit); err != nil {
+		return nil, err
+	}
+	return mails, nil
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMailOutboxRepository_FindDue(t *testing.T) {
+	now := time.Now()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewMailOutboxRepository(db)
+
+	expectedSQL := `SELECT \* FROM mail_outbox WHERE status = 'pending' AND next_attempt_at <= \$1 ORDER BY id LIMIT \$2 FOR UPDATE SKIP LOCKED`
+	rows := sqlmock.NewRows([]string{"id", "to_email", "subject", "status", "attempts"}).
+		AddRow(1, "test@example.com", "subject", "pending", 0)
+	mock.ExpectQuery(expectedSQL).WithArgs(now, 20).WillReturnRows(rows)
+
+	mails, err := r.FindDue(context.Background(), now, 20)
+
+	assert.NoError(t, err)
+	assert.Len(t, mails, 1)
+	assert.Equal(t, "test@example.com", mails[0].ToEmail)
+	assert.Equal(t, entity.OutboxMailPending, mails[0].Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMailOutboxRepository_Update(t *testing.T) {
+	now := time.Now()
+	mail := &entity.OutboxMail{
+		ID:            1,
+		Status:        entity.OutboxMailPending,
+		Attempts:      2,
+		NextAttemptAt: now,
+		LastError:     sql.NullString{String: "smtp error", Valid: true},
+	}
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewMailOutboxRepository(db)
+
+	expectedSQL := `UPDATE mail_outbox SET status = \?, attempts = \?, next_attempt_at = \?, last_error = \?, sent_at = \? WHERE id = \?`
+	mock.ExpectExec(expectedSQL).WithArgs(mail.Status, 2, now, mail.LastError, mail.SentAt, int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = r.Update(context.Background(), mail)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
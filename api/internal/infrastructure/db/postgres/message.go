@@ -5,6 +5,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/icchon/matcha/api/internal/domain/entity"
 	"github.com/icchon/matcha/api/internal/domain/repo"
@@ -14,6 +18,9 @@ type messageRepository struct {
 	db DBTX
 }
 
+// messageColumns は entity.Message に読み込む列。検索用の content_tsv は読まない
+const messageColumns = "id, sender_id, recipient_id, content, sent_at, is_read, edited_at, deleted_at"
+
 func NewMessageRepository(db DBTX) repo.MessageRepository {
 	return &messageRepository{db: db}
 }
@@ -28,12 +35,24 @@ func (r *messageRepository) MarkAsRead(ctx context.Context, messageID int64) err
 	return err
 }
 
+func (r *messageRepository) MarkConversationRead(ctx context.Context, recipientID, senderID uuid.UUID, upTo int64) (int64, error) {
+	query := `
+		UPDATE messages SET
+			is_read = TRUE
+		WHERE recipient_id = $1 AND sender_id = $2 AND id <= $3 AND is_read IS NOT TRUE
+	`
+	res, err := r.db.ExecContext(ctx, query, recipientID, senderID, upTo)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 func (r *messageRepository) Create(ctx context.Context, message *entity.Message) error {
 	query := `
 		INSERT INTO messages (sender_id, recipient_id, content)
 		VALUES (:sender_id, :recipient_id, :content)
-		RETURNING *
-	`
+		RETURNING ` + messageColumns
 	stmt, err := r.db.PrepareNamedContext(ctx, query)
 	if err != nil {
 		return err
@@ -42,15 +61,37 @@ func (r *messageRepository) Create(ctx context.Context, message *entity.Message)
 	return stmt.QueryRowxContext(ctx, message).StructScan(message)
 }
 
-func (r *messageRepository) Update(ctx context.Context, message *entity.Message) error {
+func (r *messageRepository) UpdateContent(ctx context.Context, messageID int64, content string, editedAt time.Time) (bool, error) {
 	query := `
 		UPDATE messages SET
-			content = :content,
-			is_read = :is_read
-		WHERE id = :id
+			content = $2,
+			edited_at = $3
+		WHERE id = $1 AND deleted_at IS NULL
 	`
-	_, err := r.db.NamedExecContext(ctx, query, message)
-	return err
+	return r.execMessageUpdate(ctx, query, messageID, content, editedAt)
+}
+
+func (r *messageRepository) MarkDeleted(ctx context.Context, messageID int64, deletedAt time.Time) (bool, error) {
+	query := `
+		UPDATE messages SET
+			content = '',
+			deleted_at = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	return r.execMessageUpdate(ctx, query, messageID, deletedAt)
+}
+
+// execMessageUpdate は UPDATE を実行し、行が更新されたかを返す
+func (r *messageRepository) execMessageUpdate(ctx context.Context, query string, args ...interface{}) (bool, error) {
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
 }
 
 func (r *messageRepository) Delete(ctx context.Context, messageID int64) error {
@@ -61,7 +102,7 @@ func (r *messageRepository) Delete(ctx context.Context, messageID int64) error {
 
 func (r *messageRepository) Find(ctx context.Context, messageID int64) (*entity.Message, error) {
 	var message entity.Message
-	query := "SELECT * FROM messages WHERE id = $1"
+	query := "SELECT " + messageColumns + " FROM messages WHERE id = $1"
 	err := r.db.GetContext(ctx, &message, query, messageID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -73,7 +114,7 @@ func (r *messageRepository) Find(ctx context.Context, messageID int64) (*entity.
 }
 
 func (r *messageRepository) Query(ctx context.Context, q *repo.MessageQuery) ([]*entity.Message, error) {
-	query := "SELECT * FROM messages WHERE 1=1"
+	query := "SELECT " + messageColumns + " FROM messages WHERE 1=1"
 	args := []interface{}{}
 	argCount := 1
 
@@ -139,7 +180,7 @@ func (r *messageRepository) Query(ctx context.Context, q *repo.MessageQuery) ([]
 func (r *messageRepository) GetLatest(ctx context.Context, userID1, userID2 uuid.UUID) (*entity.Message, error) {
 	var message entity.Message
 	query := `
-		SELECT *
+		SELECT ` + messageColumns + `
 		FROM messages
 		WHERE (sender_id = $1 AND recipient_id = $2) OR (sender_id = $2 AND recipient_id = $1)
 		ORDER BY sent_at DESC
@@ -154,3 +195,62 @@ func (r *messageRepository) GetLatest(ctx context.Context, userID1, userID2 uuid
 	}
 	return &message, nil
 }
+
+// 日本語 (ひらがな・カタカナ・漢字) の連続部分。schema.sql の cjk_ngrams と同じ範囲
+var cjkRunPattern = regexp.MustCompile(`[\x{3040}-\x{30ff}\x{3400}-\x{9fff}\x{f900}-\x{faff}\x{ff66}-\x{ff9f}]+`)
+
+// searchTSQuery は検索文字列を english 設定に渡す部分と、日本語の 2-gram を AND でつないだ tsquery に分ける
+// content_tsv 側の n-gram は位置情報を持たないため、フレーズではなく AND で絞り込む
+func searchTSQuery(text string) (english string, cjk string) {
+	var bigrams []string
+	seen := map[string]bool{}
+	for _, run := range cjkRunPattern.FindAllString(text, -1) {
+		runes := []rune(run)
+		// 1 文字だけの場合はその 1 文字で探す。cjk_ngrams は単語の途中の文字も 1-gram として持つ
+		n := len(runes) - 1
+		if n < 1 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			bigram := string(runes[i:min(i+2, len(runes))])
+			if seen[bigram] {
+				continue
+			}
+			seen[bigram] = true
+			bigrams = append(bigrams, "'"+bigram+"'")
+		}
+	}
+	english = strings.TrimSpace(cjkRunPattern.ReplaceAllString(text, " "))
+	return english, strings.Join(bigrams, " & ")
+}
+
+func (r *messageRepository) Search(ctx context.Context, q *repo.MessageSearchQuery) ([]*entity.Message, error) {
+	english, cjk := searchTSQuery(q.Text)
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages m
+		WHERE (m.sender_id = $1 OR m.recipient_id = $1)
+			AND m.deleted_at IS NULL
+			AND m.content_tsv @@ (plainto_tsquery('english', $2) && $3::tsquery)
+			AND NOT EXISTS (
+				SELECT 1 FROM blocks b
+				WHERE (b.blocker_id = m.sender_id AND b.blocked_id = m.recipient_id)
+					OR (b.blocker_id = m.recipient_id AND b.blocked_id = m.sender_id)
+			)`
+	args := []interface{}{q.UserID, english, cjk}
+	argCount := 4
+
+	if q.BeforeID != nil {
+		query += fmt.Sprintf(" AND m.id < $%d", argCount)
+		args = append(args, *q.BeforeID)
+		argCount++
+	}
+	query += fmt.Sprintf(" ORDER BY m.id DESC LIMIT $%d", argCount)
+	args = append(args, q.Limit)
+
+	var messages []*entity.Message
+	if err := r.db.SelectContext(ctx, &messages, query, args...); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/lib/pq"
+)
+
+type messageAttachmentRepository struct {
+	db DBTX
+}
+
+func NewMessageAttachmentRepository(db DBTX) repo.MessageAttachmentRepository {
+	return &messageAttachmentRepository{db: db}
+}
+
+func (r *messageAttachmentRepository) Create(ctx context.Context, attachment *entity.MessageAttachment) error {
+	query := `
+		INSERT INTO message_attachments (id, uploader_id, recipient_id, mime, size, width, height)
+		VALUES (:id, :uploader_id, :recipient_id, :mime, :size, :width, :height)
+		RETURNING *
+	`
+	stmt, err := r.db.PrepareNamedContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	return stmt.QueryRowxContext(ctx, attachment).StructScan(attachment)
+}
+
+func (r *messageAttachmentRepository) AttachToMessage(ctx context.Context, id string, messageID int64) error {
+	query := "UPDATE message_attachments SET message_id = $1 WHERE id = $2 AND message_id IS NULL"
+	res, err := r.db.ExecContext(ctx, query, messageID, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("attachment %s is missing or already attached", id)
+	}
+	return nil
+}
+
+func (r *messageAttachmentRepository) Find(ctx context.Context, id string) (*entity.MessageAttachment, error) {
+	var attachment entity.MessageAttachment
+	query := "SELECT * FROM message_attachments WHERE id = $1"
+	err := r.db.GetContext(ctx, &attachment, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *messageAttachmentRepository) Query(ctx context.Context, q *repo.MessageAttachmentQuery) ([]*entity.MessageAttachment, error) {
+	query := "SELECT * FROM message_attachments WHERE 1=1"
+	args := []interface{}{}
+	argCount := 1
+
+	if q.MessageIDs != nil {
+		query += fmt.Sprintf(" AND message_id = ANY($%d)", argCount)
+		args = append(args, pq.Array(q.MessageIDs))
+		argCount++
+	}
+	if q.UploaderID != nil {
+		query += fmt.Sprintf(" AND uploader_id = $%d", argCount)
+		args = append(args, *q.UploaderID)
+		argCount++
+	}
+
+	query += " ORDER BY created_at"
+
+	var attachments []*entity.MessageAttachment
+	if err := r.db.SelectContext(ctx, &attachments, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return attachments, nil
+}
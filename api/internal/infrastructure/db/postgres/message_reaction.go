@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+)
+
+type messageReactionRepository struct {
+	db DBTX
+}
+
+func NewMessageReactionRepository(db DBTX) repo.MessageReactionRepository {
+	return &messageReactionRepository{db: db}
+}
+
+func (r *messageReactionRepository) Create(ctx context.Context, reaction *entity.MessageReaction) (bool, error) {
+	query := `
+		INSERT INTO message_reactions (message_id, user_id, emoji)
+		VALUES (:message_id, :user_id, :emoji)
+		ON CONFLICT (message_id, user_id, emoji) DO NOTHING
+		RETURNING *
+	`
+	stmt, err := r.db.PrepareNamedContext(ctx, query)
+	if err != nil {
+		return false, err
+	}
+	defer stmt.Close()
+	if err := stmt.QueryRowxContext(ctx, reaction).StructScan(reaction); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// The same reaction already exists.
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *messageReactionRepository) Delete(ctx context.Context, messageID int64, userID uuid.UUID, emoji string) error {
+	query := "DELETE FROM message_reactions WHERE message_id = $1 AND user_id = $2 AND emoji = $3"
+	_, err := r.db.ExecContext(ctx, query, messageID, userID, emoji)
+	return err
+}
+
+func (r *messageReactionRepository) Find(ctx context.Context, messageID int64, userID uuid.UUID, emoji string) (*entity.MessageReaction, error) {
+	var reaction entity.MessageReaction
+	query := "SELECT * FROM message_reactions WHERE message_id = $1 AND user_id = $2 AND emoji = $3"
+	err := r.db.GetContext(ctx, &reaction, query, messageID, userID, emoji)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &reaction, nil
+}
+
+func (r *messageReactionRepository) Query(ctx context.Context, q *repo.MessageReactionQuery) ([]*entity.MessageReaction, error) {
+	query := "SELECT * FROM message_reactions WHERE 1=1"
+	args := []interface{}{}
+	argCount := 1
+
+	if q.MessageID != nil {
+		query += fmt.Sprintf(" AND message_id = $%d", argCount)
+		args = append(args, *q.MessageID)
+		argCount++
+	}
+	if q.UserID != nil {
+		query += fmt.Sprintf(" AND user_id = $%d", argCount)
+		args = append(args, *q.UserID)
+		argCount++
+	}
+	if q.Emoji != nil {
+		query += fmt.Sprintf(" AND emoji = $%d", argCount)
+		args = append(args, *q.Emoji)
+		argCount++
+	}
+
+	query += " ORDER BY created_at"
+
+	var reactions []*entity.MessageReaction
+	if err := r.db.SelectContext(ctx, &reactions, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return reactions, nil
+}
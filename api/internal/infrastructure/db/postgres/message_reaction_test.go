@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageReactionRepository_Create(t *testing.T) {
+	userID := uuid.New()
+	expectedSQL := `INSERT INTO message_reactions .* ON CONFLICT \(message_id, user_id, emoji\) DO NOTHING RETURNING \*`
+
+	testCases := []struct {
+		name            string
+		rows            *sqlmock.Rows
+		expectedCreated bool
+	}{
+		{
+			name:            "New reaction",
+			rows:            sqlmock.NewRows([]string{"message_id", "user_id", "emoji", "created_at"}).AddRow(1, userID, "👍", time.Now()),
+			expectedCreated: true,
+		},
+		{
+			// 同じリアクションが既にあれば行は返らない
+			name:            "Existing reaction",
+			rows:            sqlmock.NewRows([]string{"message_id", "user_id", "emoji", "created_at"}),
+			expectedCreated: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer mockDB.Close()
+			r := NewMessageReactionRepository(sqlx.NewDb(mockDB, "sqlmock"))
+			mock.ExpectPrepare(expectedSQL).ExpectQuery().WillReturnRows(tc.rows)
+
+			created, err := r.Create(context.Background(), &entity.MessageReaction{MessageID: 1, UserID: userID, Emoji: "👍"})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCreated, created)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -25,7 +26,7 @@ func TestMessageRepository_GetLatest(t *testing.T) {
 	r := NewMessageRepository(db)
 
 	t.Run("Get latest message", func(t *testing.T) {
-		expectedSQL := `SELECT \* FROM messages WHERE \(sender_id = \$1 AND recipient_id = \$2\) OR \(sender_id = \$2 AND recipient_id = \$1\) ORDER BY sent_at DESC LIMIT 1`
+		expectedSQL := `SELECT id, sender_id, recipient_id, content, sent_at, is_read, edited_at, deleted_at FROM messages WHERE \(sender_id = \$1 AND recipient_id = \$2\) OR \(sender_id = \$2 AND recipient_id = \$1\) ORDER BY sent_at DESC LIMIT 1`
 
 		rows := sqlmock.NewRows([]string{"id", "sender_id", "recipient_id", "content", "sent_at", "is_read"}).
 			AddRow(1, userID1, userID2, "hello", time.Now(), false)
@@ -43,6 +44,50 @@ func TestMessageRepository_GetLatest(t *testing.T) {
 	})
 }
 
+func TestMessageRepository_UpdateContent(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewMessageRepository(db)
+
+	editedAt := time.Now()
+	// 既読は読んだ側が変えるので書き戻さない
+	mock.ExpectExec(`UPDATE messages SET\s+content = \$2,\s+edited_at = \$3\s+WHERE id = \$1 AND deleted_at IS NULL`).
+		WithArgs(int64(1), "hello", editedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ok, err := r.UpdateContent(context.Background(), 1, "hello", editedAt)
+
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMessageRepository_MarkDeleted(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewMessageRepository(db)
+
+	deletedAt := time.Now()
+	// 取り消し済みなら更新されない
+	mock.ExpectExec(`UPDATE messages SET\s+content = '',\s+deleted_at = \$2\s+WHERE id = \$1 AND deleted_at IS NULL`).
+		WithArgs(int64(1), deletedAt).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ok, err := r.MarkDeleted(context.Background(), 1, deletedAt)
+
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestMessageRepository_Query(t *testing.T) {
 	userID1 := uuid.New()
 	userID2 := uuid.New()
@@ -62,7 +107,7 @@ func TestMessageRepository_Query(t *testing.T) {
 			Limit:       func(i int) *int { return &i }(10),
 			Offset:      func(i int) *int { return &i }(0),
 		}
-		expectedSQL := `SELECT \* FROM messages WHERE 1=1 AND \(\(sender_id = \$1 AND recipient_id = \$2\) OR \(sender_id = \$2 AND recipient_id = \$1\)\) ORDER BY sent_at DESC LIMIT \$3 OFFSET \$4`
+		expectedSQL := `SELECT id, sender_id, recipient_id, content, sent_at, is_read, edited_at, deleted_at FROM messages WHERE 1=1 AND \(\(sender_id = \$1 AND recipient_id = \$2\) OR \(sender_id = \$2 AND recipient_id = \$1\)\) ORDER BY sent_at DESC LIMIT \$3 OFFSET \$4`
 
 		rows := sqlmock.NewRows([]string{"id", "sender_id", "recipient_id", "content", "sent_at", "is_read"}).
 			AddRow(1, userID1, userID2, "hello", time.Now(), false)
@@ -78,3 +123,86 @@ func TestMessageRepository_Query(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+func TestSearchTSQuery(t *testing.T) {
+	english, cjk := searchTSQuery("東京駅 tennis と 駅")
+	assert.Equal(t, "tennis", english)
+	assert.Equal(t, "'東京' & '京駅' & 'と' & '駅'", cjk)
+}
+
+// cjkNGrams は schema.sql の cjk_ngrams と同じく、日本語の連続部分の 1-gram と 2-gram を返す
+func cjkNGrams(text string) map[string]bool {
+	lexemes := map[string]bool{}
+	for _, run := range cjkRunPattern.FindAllString(text, -1) {
+		runes := []rune(run)
+		for i := range runes {
+			lexemes[string(runes[i])] = true
+			lexemes[string(runes[i:min(i+2, len(runes))])] = true
+		}
+	}
+	return lexemes
+}
+
+func TestSearchTSQuery_MatchesContent(t *testing.T) {
+	testCases := []struct {
+		name    string
+		query   string
+		content string
+		want    bool
+	}{
+		{name: "Single kanji inside a word", query: "駅", content: "東京駅で待ってる", want: true},
+		{name: "Single kanji at the start of a word", query: "東", content: "東京駅で待ってる", want: true},
+		{name: "Single kana", query: "で", content: "東京駅で待ってる", want: true},
+		{name: "Single kanji not in content", query: "港", content: "東京駅で待ってる", want: false},
+		{name: "Word", query: "東京", content: "東京駅で待ってる", want: true},
+		{name: "Word not in content", query: "京都", content: "東京駅で待ってる", want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, cjk := searchTSQuery(tc.query)
+			lexemes := cjkNGrams(tc.content)
+
+			got := true
+			for _, term := range strings.Split(cjk, " & ") {
+				got = got && lexemes[strings.Trim(term, "'")]
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestMessageRepository_Search(t *testing.T) {
+	userID := uuid.New()
+	otherID := uuid.New()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewMessageRepository(db)
+
+	t.Run("Search with cursor", func(t *testing.T) {
+		beforeID := int64(50)
+		expectedSQL := `FROM messages m WHERE \(m.sender_id = \$1 OR m.recipient_id = \$1\) AND m.deleted_at IS NULL AND m.content_tsv @@ \(plainto_tsquery\('english', \$2\) && \$3::tsquery\) AND NOT EXISTS \(.*blocks.*\) AND m.id < \$4 ORDER BY m.id DESC LIMIT \$5`
+
+		rows := sqlmock.NewRows([]string{"id", "sender_id", "recipient_id", "content", "sent_at", "is_read"}).
+			AddRow(42, otherID, userID, "tennis 東京", time.Now(), false)
+		mock.ExpectQuery(expectedSQL).
+			WithArgs(userID, "tennis", "'東京'", beforeID, 21).
+			WillReturnRows(rows)
+
+		messages, err := r.Search(context.Background(), &repo.MessageSearchQuery{
+			UserID:   userID,
+			Text:     "tennis 東京",
+			BeforeID: &beforeID,
+			Limit:    21,
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, messages, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -5,8 +5,11 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/icchon/matcha/api/internal/domain/entity"
 	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/lib/pq"
+	"time"
 )
 
 type notificationRepository struct {
@@ -47,6 +50,82 @@ func (r *notificationRepository) Delete(ctx context.Context, notificationID int6
 	return err
 }
 
+func (r *notificationRepository) Increment(ctx context.Context, notification *entity.Notification) error {
+	query := `
+		UPDATE notifications SET
+			count = count + 1,
+			is_read = FALSE,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING *
+	`
+	return r.db.QueryRowxContext(ctx, query, notification.ID).StructScan(notification)
+}
+
+func (r *notificationRepository) FindLatest(ctx context.Context, recipientID, senderID uuid.UUID, notifType entity.NotificationType, since time.Time) (*entity.Notification, error) {
+	var notification entity.Notification
+	query := `
+		SELECT * FROM notifications
+		WHERE recipient_id = $1 AND sender_id = $2 AND type = $3 AND updated_at >= $4
+		ORDER BY updated_at DESC
+		LIMIT 1
+	`
+	err := r.db.GetContext(ctx, &notification, query, recipientID, senderID, notifType, since)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &notification, nil
+}
+
+func (r *notificationRepository) LockAggregation(ctx context.Context, recipientID, senderID uuid.UUID, notifType entity.NotificationType) error {
+	query := "SELECT pg_advisory_xact_lock(hashtextextended('notifications:' || $1::text || ':' || $2::text || ':' || $3, 0))"
+	_, err := r.db.ExecContext(ctx, query, recipientID, senderID, string(notifType))
+	return err
+}
+
+func (r *notificationRepository) FindDigestRecipients(ctx context.Context, types []entity.NotificationType, quietSince time.Time) ([]uuid.UUID, error) {
+	query := `
+		SELECT DISTINCT n.recipient_id FROM notifications n
+		JOIN users u ON u.id = n.recipient_id
+		LEFT JOIN email_digests d ON d.user_id = n.recipient_id
+		WHERE n.is_read IS NOT TRUE
+			AND n.type = ANY($1)
+			AND n.updated_at <= $2
+			AND (d.last_sent_at IS NULL OR n.updated_at > d.last_sent_at)
+			AND (u.last_connection IS NULL OR u.last_connection <= $2)
+			AND NOT EXISTS (
+				SELECT 1 FROM notification_preferences p
+				WHERE p.user_id = n.recipient_id AND p.type = n.type AND p.email_digest = FALSE
+			)
+	`
+	var recipientIDs []uuid.UUID
+	if err := r.db.SelectContext(ctx, &recipientIDs, query, pq.Array(types), quietSince); err != nil {
+		return nil, err
+	}
+	return recipientIDs, nil
+}
+
+func (r *notificationRepository) MarkAllRead(ctx context.Context, recipientID uuid.UUID) (int64, error) {
+	query := "UPDATE notifications SET is_read = TRUE WHERE recipient_id = $1 AND is_read IS NOT TRUE"
+	res, err := r.db.ExecContext(ctx, query, recipientID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context, recipientID uuid.UUID) (int, error) {
+	var count int
+	query := "SELECT COUNT(*) FROM notifications WHERE recipient_id = $1 AND is_read IS NOT TRUE"
+	if err := r.db.GetContext(ctx, &count, query, recipientID); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 func (r *notificationRepository) Find(ctx context.Context, notificationID int64) (*entity.Notification, error) {
 	var notification entity.Notification
 	query := "SELECT * FROM notifications WHERE id = $1"
@@ -95,6 +174,28 @@ func (r *notificationRepository) Query(ctx context.Context, q *repo.Notification
 		args = append(args, *q.CreatedAt)
 		argCount++
 	}
+	if len(q.Types) > 0 {
+		query += fmt.Sprintf(" AND type = ANY($%d)", argCount)
+		args = append(args, pq.Array(q.Types))
+		argCount++
+	}
+	if q.UpdatedAfter != nil {
+		query += fmt.Sprintf(" AND updated_at > $%d", argCount)
+		args = append(args, *q.UpdatedAfter)
+		argCount++
+	}
+	if q.Before != nil {
+		query += fmt.Sprintf(" AND (updated_at, id) < ($%d, $%d)", argCount, argCount+1)
+		args = append(args, q.Before.UpdatedAt, q.Before.ID)
+		argCount += 2
+	}
+
+	query += " ORDER BY updated_at DESC, id DESC"
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, q.Limit)
+		argCount++
+	}
 
 	var notifications []*entity.Notification
 	if err := r.db.SelectContext(ctx, &notifications, query, args...); err != nil {
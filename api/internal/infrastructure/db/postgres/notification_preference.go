@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+)
+
+type notificationPreferenceRepository struct {
+	db DBTX
+}
+
+func NewNotificationPreferenceRepository(db DBTX) repo.NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{db: db}
+}
+
+func (r *notificationPreferenceRepository) Upsert(ctx context.Context, pref *entity.NotificationPreference) error {
+	query := `
+		INSERT INTO notification_preferences (user_id, type, in_app, email_digest, updated_at)
+		VALUES (:user_id, :type, :in_app, :email_digest, NOW())
+		ON CONFLICT (user_id, type) DO UPDATE SET
+			in_app = EXCLUDED.in_app,
+			email_digest = EXCLUDED.email_digest,
+			updated_at = NOW()
+		RETURNING *
+	`
+	stmt, err := r.db.PrepareNamedContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	return stmt.QueryRowxContext(ctx, pref).StructScan(pref)
+}
+
+func (r *notificationPreferenceRepository) Find(ctx context.Context, userID uuid.UUID, notifType entity.NotificationType) (*entity.NotificationPreference, error) {
+	var pref entity.NotificationPreference
+	query := "SELECT * FROM notification_preferences WHERE user_id = $1 AND type = $2"
+	err := r.db.GetContext(ctx, &pref, query, userID, notifType)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &pref, nil
+}
+
+func (r *notificationPreferenceRepository) Query(ctx context.Context, userID uuid.UUID) ([]*entity.NotificationPreference, error) {
+	var prefs []*entity.NotificationPreference
+	query := "SELECT * FROM notification_preferences WHERE user_id = $1 ORDER BY type"
+	if err := r.db.SelectContext(ctx, &prefs, query, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return prefs, nil
+}
+
+type notificationMuteRepository struct {
+	db DBTX
+}
+
+func NewNotificationMuteRepository(db DBTX) repo.NotificationMuteRepository {
+	return &notificationMuteRepository{db: db}
+}
+
+func (r *notificationMuteRepository) Upsert(ctx context.Context, mute *entity.NotificationMute) error {
+	query := `
+		INSERT INTO notification_mutes (user_id, muted_user_id, muted_until)
+		VALUES (:user_id, :muted_user_id, :muted_until)
+		ON CONFLICT (user_id, muted_user_id) DO UPDATE SET
+			muted_until = EXCLUDED.muted_until
+		RETURNING *
+	`
+	stmt, err := r.db.PrepareNamedContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	return stmt.QueryRowxContext(ctx, mute).StructScan(mute)
+}
+
+func (r *notificationMuteRepository) DeleteAll(ctx context.Context, userID uuid.UUID) error {
+	query := "DELETE FROM notification_mutes WHERE user_id = $1"
+	_, err := r.db.ExecContext(ctx, query, userID)
+	return err
+}
+
+func (r *notificationMuteRepository) Query(ctx context.Context, q *repo.NotificationMuteQuery) ([]*entity.NotificationMute, error) {
+	query := "SELECT * FROM notification_mutes WHERE 1=1"
+	args := []interface{}{}
+	argCount := 1
+
+	if q.UserID != nil {
+		query += fmt.Sprintf(" AND user_id = $%d", argCount)
+		args = append(args, *q.UserID)
+		argCount++
+	}
+	if q.MutedUserID != nil {
+		query += fmt.Sprintf(" AND muted_user_id = $%d", argCount)
+		args = append(args, *q.MutedUserID)
+		argCount++
+	}
+	if q.ActiveAt != nil {
+		query += fmt.Sprintf(" AND muted_until > $%d", argCount)
+		args = append(args, *q.ActiveAt)
+		argCount++
+	}
+
+	query += " ORDER BY muted_until"
+
+	var mutes []*entity.NotificationMute
+	if err := r.db.SelectContext(ctx, &mutes, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return mutes, nil
+}
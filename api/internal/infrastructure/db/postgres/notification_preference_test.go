@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationPreferenceRepository_Upsert(t *testing.T) {
+	userID := uuid.New()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewNotificationPreferenceRepository(db)
+
+	expectedSQL := `INSERT INTO notification_preferences .* ON CONFLICT \(user_id, type\) DO UPDATE SET .* RETURNING \*`
+	rows := sqlmock.NewRows([]string{"user_id", "type", "in_app", "email_digest", "updated_at"}).
+		AddRow(userID, "like", false, true, time.Now())
+	mock.ExpectPrepare(expectedSQL).ExpectQuery().WillReturnRows(rows)
+
+	pref := &entity.NotificationPreference{UserID: userID, Type: entity.NotifLike, InApp: false, EmailDigest: true}
+	err = r.Upsert(context.Background(), pref)
+
+	assert.NoError(t, err)
+	assert.False(t, pref.InApp)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationMuteRepository_Query(t *testing.T) {
+	userID := uuid.New()
+	mutedID := uuid.New()
+	now := time.Now()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewNotificationMuteRepository(db)
+
+	t.Run("Only active mutes", func(t *testing.T) {
+		expectedSQL := `SELECT \* FROM notification_mutes WHERE 1=1 AND user_id = \$1 AND muted_user_id = \$2 AND muted_until > \$3 ORDER BY muted_until`
+		rows := sqlmock.NewRows([]string{"user_id", "muted_user_id", "muted_until", "created_at"}).
+			AddRow(userID, mutedID, now.Add(time.Hour), now)
+		mock.ExpectQuery(expectedSQL).WithArgs(userID, mutedID, now).WillReturnRows(rows)
+
+		mutes, err := r.Query(context.Background(), &repo.NotificationMuteQuery{UserID: &userID, MutedUserID: &mutedID, ActiveAt: &now})
+
+		assert.NoError(t, err)
+		assert.Len(t, mutes, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
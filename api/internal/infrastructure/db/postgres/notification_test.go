@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationRepository_Query(t *testing.T) {
+	recipientID := uuid.New()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewNotificationRepository(db)
+
+	t.Run("Unread page before a cursor", func(t *testing.T) {
+		isRead := false
+		cursor := &repo.NotificationCursor{UpdatedAt: time.Now().Add(-time.Hour), ID: 50}
+		expectedSQL := `SELECT \* FROM notifications WHERE 1=1 AND recipient_id = \$1 AND is_read = \$2 AND \(updated_at, id\) < \(\$3, \$4\) ORDER BY updated_at DESC, id DESC LIMIT \$5`
+		rows := sqlmock.NewRows([]string{"id", "recipient_id", "sender_id", "type", "is_read", "created_at"}).
+			AddRow(49, recipientID, nil, "like", false, time.Now())
+		mock.ExpectQuery(expectedSQL).WithArgs(recipientID, false, cursor.UpdatedAt, int64(50), 21).WillReturnRows(rows)
+
+		notifications, err := r.Query(context.Background(), &repo.NotificationQuery{
+			RecipientID: &recipientID,
+			IsRead:      &isRead,
+			Before:      cursor,
+			Limit:       21,
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, notifications, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Aggregated notification moves back to the top", func(t *testing.T) {
+		now := time.Now()
+		// id 3 は古いが、さっき like がまとめられて updated_at が新しくなった
+		expectedSQL := `SELECT \* FROM notifications WHERE 1=1 AND recipient_id = \$1 ORDER BY updated_at DESC, id DESC LIMIT \$2`
+		rows := sqlmock.NewRows([]string{"id", "recipient_id", "type", "count", "created_at", "updated_at"}).
+			AddRow(3, recipientID, "like", 4, now.Add(-72*time.Hour), now).
+			AddRow(9, recipientID, "view", 1, now.Add(-time.Hour), now.Add(-time.Hour))
+		mock.ExpectQuery(expectedSQL).WithArgs(recipientID, 2).WillReturnRows(rows)
+
+		notifications, err := r.Query(context.Background(), &repo.NotificationQuery{RecipientID: &recipientID, Limit: 2})
+
+		assert.NoError(t, err)
+		if assert.Len(t, notifications, 2) {
+			assert.Equal(t, int64(3), notifications[0].ID)
+			assert.Equal(t, 4, notifications[0].Count)
+		}
+
+		// 次のページは先頭に戻った通知より後ろの位置から続ける
+		cursor := &repo.NotificationCursor{UpdatedAt: notifications[1].UpdatedAt, ID: notifications[1].ID}
+		mock.ExpectQuery(`AND \(updated_at, id\) < \(\$2, \$3\) ORDER BY updated_at DESC, id DESC`).
+			WithArgs(recipientID, now.Add(-time.Hour), int64(9), 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, err = r.Query(context.Background(), &repo.NotificationQuery{RecipientID: &recipientID, Before: cursor, Limit: 2})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestNotificationRepository_MarkAllRead(t *testing.T) {
+	recipientID := uuid.New()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewNotificationRepository(db)
+
+	expectedSQL := `UPDATE notifications SET is_read = TRUE WHERE recipient_id = \$1 AND is_read IS NOT TRUE`
+	mock.ExpectExec(expectedSQL).WithArgs(recipientID).WillReturnResult(sqlmock.NewResult(0, 4))
+
+	n, err := r.MarkAllRead(context.Background(), recipientID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationRepository_FindLatest(t *testing.T) {
+	recipientID := uuid.New()
+	senderID := uuid.New()
+	since := time.Now().Add(-24 * time.Hour)
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewNotificationRepository(db)
+
+	expectedSQL := `SELECT \* FROM notifications WHERE recipient_id = \$1 AND sender_id = \$2 AND type = \$3 AND updated_at >= \$4 ORDER BY updated_at DESC LIMIT 1`
+
+	t.Run("Found", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "recipient_id", "sender_id", "type", "is_read", "count", "created_at", "updated_at"}).
+			AddRow(7, recipientID, senderID.String(), "view", false, 3, time.Now(), time.Now())
+		mock.ExpectQuery(expectedSQL).WithArgs(recipientID, senderID, entity.NotifView, since).WillReturnRows(rows)
+
+		notification, err := r.FindLatest(context.Background(), recipientID, senderID, entity.NotifView, since)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), notification.ID)
+		assert.Equal(t, 3, notification.Count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mock.ExpectQuery(expectedSQL).WithArgs(recipientID, senderID, entity.NotifView, since).WillReturnError(sql.ErrNoRows)
+
+		notification, err := r.FindLatest(context.Background(), recipientID, senderID, entity.NotifView, since)
+
+		assert.NoError(t, err)
+		assert.Nil(t, notification)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestNotificationRepository_LockAggregation(t *testing.T) {
+	recipientID := uuid.New()
+	senderID := uuid.New()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewNotificationRepository(db)
+
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock\(hashtextextended\('notifications:' \|\| \$1::text \|\| ':' \|\| \$2::text \|\| ':' \|\| \$3, 0\)\)`).
+		WithArgs(recipientID, senderID, "like").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = r.LockAggregation(context.Background(), recipientID, senderID, entity.NotifLike)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationRepository_Increment(t *testing.T) {
+	recipientID := uuid.New()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewNotificationRepository(db)
+
+	expectedSQL := `UPDATE notifications SET count = count \+ 1, is_read = FALSE, updated_at = NOW\(\) WHERE id = \$1 RETURNING \*`
+	rows := sqlmock.NewRows([]string{"id", "recipient_id", "sender_id", "type", "is_read", "count", "created_at", "updated_at"}).
+		AddRow(7, recipientID, nil, "like", false, 2, time.Now(), time.Now())
+	mock.ExpectQuery(expectedSQL).WithArgs(int64(7)).WillReturnRows(rows)
+
+	notification := &entity.Notification{ID: 7, RecipientID: recipientID, Count: 1}
+	err = r.Increment(context.Background(), notification)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, notification.Count)
+	assert.False(t, notification.IsRead.Bool)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationRepository_FindDigestRecipients(t *testing.T) {
+	recipientID := uuid.New()
+	quietSince := time.Now().Add(-24 * time.Hour)
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewNotificationRepository(db)
+
+	expectedSQL := `SELECT DISTINCT n.recipient_id FROM notifications n .* LEFT JOIN email_digests d .* WHERE n.is_read IS NOT TRUE AND n.type = ANY\(\$1\) AND n.updated_at <= \$2 .* p.email_digest = FALSE \)`
+	rows := sqlmock.NewRows([]string{"recipient_id"}).AddRow(recipientID)
+	mock.ExpectQuery(expectedSQL).WithArgs(sqlmock.AnyArg(), quietSince).WillReturnRows(rows)
+
+	recipientIDs, err := r.FindDigestRecipients(context.Background(), []entity.NotificationType{entity.NotifMatch, entity.NotifLike}, quietSince)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{recipientID}, recipientIDs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/icchon/matcha/api/internal/domain/entity"
 	"github.com/icchon/matcha/api/internal/domain/repo"
 )
@@ -19,8 +20,8 @@ func NewPictureRepository(db DBTX) repo.PictureRepository {
 
 func (r *pictureRepository) Create(ctx context.Context, picture *entity.Picture) error {
 	query := `
-		INSERT INTO pictures (user_id, url, is_profile_pic)
-		VALUES (:user_id, :url, :is_profile_pic)
+		INSERT INTO pictures (user_id, url, asset_id, thumbnail_url, card_url, is_profile_pic, position)
+		VALUES (:user_id, :url, :asset_id, :thumbnail_url, :card_url, :is_profile_pic, :position)
 		RETURNING *
 	`
 	stmt, err := r.db.PrepareNamedContext(ctx, query)
@@ -35,7 +36,11 @@ func (r *pictureRepository) Update(ctx context.Context, picture *entity.Picture)
 	query := `
 		UPDATE pictures SET
 			url = :url,
-			is_profile_pic = :is_profile_pic
+			asset_id = :asset_id,
+			thumbnail_url = :thumbnail_url,
+			card_url = :card_url,
+			is_profile_pic = :is_profile_pic,
+			position = :position
 		WHERE id = :id
 	`
 	_, err := r.db.NamedExecContext(ctx, query, picture)
@@ -80,12 +85,54 @@ func (r *pictureRepository) Query(ctx context.Context, q *repo.PictureQuery) ([]
 		args = append(args, *q.IsProfilePic)
 		argCount++
 	}
+	if q.Status != nil {
+		query += fmt.Sprintf(" AND status = $%d", argCount)
+		args = append(args, *q.Status)
+		argCount++
+	}
 	if q.CreatedAt != nil {
 		query += fmt.Sprintf(" AND created_at = $%d", argCount)
 		args = append(args, *q.CreatedAt)
 		argCount++
 	}
 
+	query += " ORDER BY position, id"
+
+	var pictures []*entity.Picture
+	if err := r.db.SelectContext(ctx, &pictures, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return pictures, nil
+}
+
+func (r *pictureRepository) QueryModeration(ctx context.Context, q *repo.PictureModerationQuery) ([]*entity.Picture, error) {
+	query := "SELECT * FROM pictures WHERE status = $1"
+	args := []interface{}{q.Status}
+	argCount := 2
+
+	if q.Classified != nil {
+		if *q.Classified {
+			query += " AND classified_at IS NOT NULL"
+		} else {
+			query += " AND classified_at IS NULL"
+		}
+	}
+
+	query += " ORDER BY created_at, id"
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, q.Limit)
+		argCount++
+	}
+	if q.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", argCount)
+		args = append(args, q.Offset)
+		argCount++
+	}
+
 	var pictures []*entity.Picture
 	if err := r.db.SelectContext(ctx, &pictures, query, args...); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -96,6 +143,70 @@ func (r *pictureRepository) Query(ctx context.Context, q *repo.PictureQuery) ([]
 	return pictures, nil
 }
 
+// FindSimilar は hash_bands の GIN インデックスで候補を絞ってから、ハミング距離で確かめる
+func (r *pictureRepository) FindSimilar(ctx context.Context, userID uuid.UUID, dhash, phash int64, maxDistance int) ([]*entity.Picture, error) {
+	query := `
+		SELECT * FROM pictures
+		WHERE user_id <> $1 AND (
+			(dhash IS NOT NULL AND hash_bands(dhash) && hash_bands($2) AND hamming_distance(dhash, $2) <= $4)
+			OR (phash IS NOT NULL AND hash_bands(phash) && hash_bands($3) AND hamming_distance(phash, $3) <= $4)
+		)
+		ORDER BY id
+	`
+	var pictures []*entity.Picture
+	if err := r.db.SelectContext(ctx, &pictures, query, userID, dhash, phash, min(maxDistance, repo.MaxPictureHashDistance)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return pictures, nil
+}
+
+func (r *pictureRepository) Classify(ctx context.Context, picture *entity.Picture) (bool, error) {
+	query := `
+		UPDATE pictures SET
+			status = :status,
+			flag_reason = :flag_reason,
+			dhash = :dhash,
+			phash = :phash,
+			classified_at = :classified_at
+		WHERE id = :id AND status = 'pending' AND classified_at IS NULL
+	`
+	return r.execUpdate(ctx, query, picture)
+}
+
+func (r *pictureRepository) Review(ctx context.Context, picture *entity.Picture) (bool, error) {
+	query := `
+		UPDATE pictures SET
+			status = :status,
+			flag_reason = :flag_reason,
+			reviewed_by = :reviewed_by,
+			reviewed_at = :reviewed_at
+		WHERE id = :id AND status = 'pending'
+	`
+	return r.execUpdate(ctx, query, picture)
+}
+
+// execUpdate は更新した行があれば true を返す
+func (r *pictureRepository) execUpdate(ctx context.Context, query string, picture *entity.Picture) (bool, error) {
+	res, err := r.db.NamedExecContext(ctx, query, picture)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *pictureRepository) LockByUser(ctx context.Context, userID uuid.UUID) error {
+	query := "SELECT pg_advisory_xact_lock(hashtextextended('pictures:' || $1::text, 0))"
+	_, err := r.db.ExecContext(ctx, query, userID)
+	return err
+}
+
 func (r *pictureRepository) Delete(ctx context.Context, pictureID int32) error {
 	query := "DELETE FROM pictures WHERE id = $1"
 	_, err := r.db.ExecContext(ctx, query, pictureID)
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+)
+
+type pictureMatchRepository struct {
+	db DBTX
+}
+
+func NewPictureMatchRepository(db DBTX) repo.PictureMatchRepository {
+	return &pictureMatchRepository{db: db}
+}
+
+func (r *pictureMatchRepository) Create(ctx context.Context, match *entity.PictureMatch) error {
+	query := `
+		INSERT INTO picture_matches (picture_id, user_id, matched_picture_id, matched_user_id, dhash_distance, phash_distance)
+		VALUES (:picture_id, :user_id, :matched_picture_id, :matched_user_id, :dhash_distance, :phash_distance)
+		ON CONFLICT (picture_id, matched_picture_id) DO NOTHING
+	`
+	_, err := r.db.NamedExecContext(ctx, query, match)
+	return err
+}
+
+func (r *pictureMatchRepository) Query(ctx context.Context, q *repo.PictureMatchQuery) ([]*entity.PictureMatch, error) {
+	query := "SELECT * FROM picture_matches WHERE 1=1"
+	args := []interface{}{}
+	argCount := 1
+
+	if q.UserID != nil {
+		query += fmt.Sprintf(" AND user_id = $%d", argCount)
+		args = append(args, *q.UserID)
+		argCount++
+	}
+
+	query += " ORDER BY created_at DESC, picture_id, matched_picture_id"
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, q.Limit)
+		argCount++
+	}
+	if q.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", argCount)
+		args = append(args, q.Offset)
+		argCount++
+	}
+
+	var matches []*entity.PictureMatch
+	if err := r.db.SelectContext(ctx, &matches, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return matches, nil
+}
+
+// QueryFakeAccountSignals はユーザーごとに今の写真と一致を数える。消された写真の一致は残らない
+func (r *pictureMatchRepository) QueryFakeAccountSignals(ctx context.Context, q *repo.FakeAccountSignalQuery) ([]*entity.FakeAccountSignal, error) {
+	query := `
+		SELECT p.user_id,
+			COUNT(DISTINCT m.picture_id) AS matched_pictures,
+			COUNT(DISTINCT m.matched_user_id) AS matched_users,
+			COUNT(DISTINCT p.id) AS total_pictures,
+			COUNT(DISTINCT p.id) FILTER (WHERE p.status = 'rejected') AS rejected_pictures,
+			MAX(m.created_at) AS last_matched_at
+		FROM pictures p
+		LEFT JOIN picture_matches m ON m.picture_id = p.id
+		WHERE 1=1`
+	args := []interface{}{}
+	argCount := 1
+
+	if q.UserID != nil {
+		query += fmt.Sprintf(" AND p.user_id = $%d", argCount)
+		args = append(args, *q.UserID)
+		argCount++
+	} else {
+		query += " AND p.user_id IN (SELECT user_id FROM picture_matches)"
+	}
+
+	query += " GROUP BY p.user_id ORDER BY matched_pictures DESC, last_matched_at DESC NULLS LAST, p.user_id"
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, q.Limit)
+		argCount++
+	}
+	if q.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", argCount)
+		args = append(args, q.Offset)
+		argCount++
+	}
+
+	var signals []*entity.FakeAccountSignal
+	if err := r.db.SelectContext(ctx, &signals, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return signals, nil
+}
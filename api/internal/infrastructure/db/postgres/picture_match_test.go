@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPictureMatchRepository_Create(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewPictureMatchRepository(db)
+
+	match := &entity.PictureMatch{PictureID: 5, UserID: uuid.New(), MatchedPictureID: 2, MatchedUserID: uuid.New(), DHashDistance: 3, PHashDistance: 64}
+	mock.ExpectExec(`INSERT INTO picture_matches .* ON CONFLICT \(picture_id, matched_picture_id\) DO NOTHING`).
+		WithArgs(match.PictureID, match.UserID, match.MatchedPictureID, match.MatchedUserID, match.DHashDistance, match.PHashDistance).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = r.Create(context.Background(), match)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPictureMatchRepository_Query(t *testing.T) {
+	userID := uuid.New()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewPictureMatchRepository(db)
+
+	expectedSQL := `SELECT \* FROM picture_matches WHERE 1=1 AND user_id = \$1 ORDER BY created_at DESC, picture_id, matched_picture_id LIMIT \$2`
+	rows := sqlmock.NewRows([]string{"picture_id", "user_id", "matched_picture_id", "matched_user_id", "dhash_distance", "phash_distance"}).
+		AddRow(5, userID, 2, uuid.New(), 3, 64)
+	mock.ExpectQuery(expectedSQL).WithArgs(userID, 10).WillReturnRows(rows)
+
+	matches, err := r.Query(context.Background(), &repo.PictureMatchQuery{UserID: &userID, Limit: 10})
+
+	assert.NoError(t, err)
+	assert.Len(t, matches, 1)
+	assert.Equal(t, int32(2), matches[0].MatchedPictureID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPictureMatchRepository_QueryFakeAccountSignals(t *testing.T) {
+	columns := []string{"user_id", "matched_pictures", "matched_users", "total_pictures", "rejected_pictures", "last_matched_at"}
+
+	t.Run("Users with matches", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+		db := sqlx.NewDb(mockDB, "sqlmock")
+		r := NewPictureMatchRepository(db)
+
+		expectedSQL := `FROM pictures p\s+LEFT JOIN picture_matches m ON m.picture_id = p.id\s+WHERE 1=1 ` +
+			`AND p.user_id IN \(SELECT user_id FROM picture_matches\) GROUP BY p.user_id ` +
+			`ORDER BY matched_pictures DESC, last_matched_at DESC NULLS LAST, p.user_id LIMIT \$1 OFFSET \$2`
+		rows := sqlmock.NewRows(columns).AddRow(uuid.New(), 2, 1, 4, 1, time.Now())
+		mock.ExpectQuery(expectedSQL).WithArgs(20, 40).WillReturnRows(rows)
+
+		signals, err := r.QueryFakeAccountSignals(context.Background(), &repo.FakeAccountSignalQuery{Limit: 20, Offset: 40})
+
+		assert.NoError(t, err)
+		assert.Len(t, signals, 1)
+		assert.Equal(t, 0.5, signals[0].Score())
+		assert.NotNil(t, signals[0].LastMatchedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("One user", func(t *testing.T) {
+		userID := uuid.New()
+
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+		db := sqlx.NewDb(mockDB, "sqlmock")
+		r := NewPictureMatchRepository(db)
+
+		// 一致がなくても写真の数は返る
+		rows := sqlmock.NewRows(columns).AddRow(userID, 0, 0, 3, 0, nil)
+		mock.ExpectQuery(`WHERE 1=1 AND p.user_id = \$1 GROUP BY p.user_id`).WithArgs(userID).WillReturnRows(rows)
+
+		signals, err := r.QueryFakeAccountSignals(context.Background(), &repo.FakeAccountSignalQuery{UserID: &userID})
+
+		assert.NoError(t, err)
+		assert.Len(t, signals, 1)
+		assert.Equal(t, 3, signals[0].TotalPictures)
+		assert.Nil(t, signals[0].LastMatchedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
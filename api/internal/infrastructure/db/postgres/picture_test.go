@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPictureRepository_Query(t *testing.T) {
+	userID := uuid.New()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewPictureRepository(db)
+
+	expectedSQL := `SELECT \* FROM pictures WHERE 1=1 AND user_id = \$1 ORDER BY position, id`
+	rows := sqlmock.NewRows([]string{"id", "user_id", "url", "is_profile_pic", "position"}).
+		AddRow(2, userID, "http://files/b.jpg", true, 0).
+		AddRow(1, userID, "http://files/a.jpg", false, 1)
+	mock.ExpectQuery(expectedSQL).WithArgs(userID).WillReturnRows(rows)
+
+	pictures, err := r.Query(context.Background(), &repo.PictureQuery{UserID: &userID})
+
+	assert.NoError(t, err)
+	assert.Len(t, pictures, 2)
+	assert.Equal(t, 1, pictures[1].Position)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPictureRepository_LockByUser(t *testing.T) {
+	userID := uuid.New()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewPictureRepository(db)
+
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock\(hashtextextended\('pictures:' \|\| \$1::text, 0\)\)`).
+		WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = r.LockByUser(context.Background(), userID)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPictureRepository_QueryModeration(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewPictureRepository(db)
+
+	classified := true
+	expectedSQL := `SELECT \* FROM pictures WHERE status = \$1 AND classified_at IS NOT NULL ORDER BY created_at, id LIMIT \$2`
+	rows := sqlmock.NewRows([]string{"id", "status", "flag_reason"}).AddRow(3, "pending", "high_skin_tone_ratio")
+	mock.ExpectQuery(expectedSQL).WithArgs(entity.PicturePending, 50).WillReturnRows(rows)
+
+	pictures, err := r.QueryModeration(context.Background(), &repo.PictureModerationQuery{Status: entity.PicturePending, Classified: &classified, Limit: 50})
+
+	assert.NoError(t, err)
+	assert.Len(t, pictures, 1)
+	assert.Equal(t, "high_skin_tone_ratio", pictures[0].FlagReason)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPictureRepository_FindSimilar(t *testing.T) {
+	userID := uuid.New()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewPictureRepository(db)
+
+	expectedSQL := `SELECT \* FROM pictures\s+WHERE user_id <> \$1 AND \(\s+` +
+		`\(dhash IS NOT NULL AND hash_bands\(dhash\) && hash_bands\(\$2\) AND hamming_distance\(dhash, \$2\) <= \$4\)\s+` +
+		`OR \(phash IS NOT NULL AND hash_bands\(phash\) && hash_bands\(\$3\) AND hamming_distance\(phash, \$3\) <= \$4\)`
+	rows := sqlmock.NewRows([]string{"id", "user_id"}).AddRow(4, uuid.New())
+	// インデックスで見つけられる距離までに抑える
+	mock.ExpectQuery(expectedSQL).WithArgs(userID, int64(-1), int64(5), repo.MaxPictureHashDistance).WillReturnRows(rows)
+
+	pictures, err := r.FindSimilar(context.Background(), userID, -1, 5, 10)
+
+	assert.NoError(t, err)
+	assert.Len(t, pictures, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPictureRepository_Review(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewPictureRepository(db)
+
+	// 先に審査されていたら更新されない
+	mock.ExpectExec(`UPDATE pictures SET .* WHERE id = \? AND status = 'pending'`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ok, err := r.Review(context.Background(), &entity.Picture{ID: 3, Status: entity.PictureApproved})
+
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+)
+
+type pushSubscriptionRepository struct {
+	db DBTX
+}
+
+func NewPushSubscriptionRepository(db DBTX) repo.PushSubscriptionRepository {
+	return &pushSubscriptionRepository{db: db}
+}
+
+func (r *pushSubscriptionRepository) Upsert(ctx context.Context, sub *entity.PushSubscription) error {
+	query := `
+		INSERT INTO push_subscriptions (user_id, endpoint, p256dh, auth, user_agent)
+		VALUES (:user_id, :endpoint, :p256dh, :auth, :user_agent)
+		ON CONFLICT (endpoint) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			p256dh = EXCLUDED.p256dh,
+			auth = EXCLUDED.auth,
+			user_agent = EXCLUDED.user_agent,
+			updated_at = NOW()
+		RETURNING *
+	`
+	stmt, err := r.db.PrepareNamedContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	if err := stmt.QueryRowxContext(ctx, sub).StructScan(sub); err != nil {
+		return err
+	}
+
+	// 登録し直した端末は updated_at が新しいので残る
+	_, err = r.db.ExecContext(ctx, `
+		DELETE FROM push_subscriptions
+		WHERE user_id = $1 AND id NOT IN (
+			SELECT id FROM push_subscriptions WHERE user_id = $1 ORDER BY updated_at DESC, id DESC LIMIT $2
+		)
+	`, sub.UserID, repo.MaxPushSubscriptionsPerUser)
+	return err
+}
+
+func (r *pushSubscriptionRepository) Delete(ctx context.Context, endpoint string) error {
+	query := "DELETE FROM push_subscriptions WHERE endpoint = $1"
+	_, err := r.db.ExecContext(ctx, query, endpoint)
+	return err
+}
+
+func (r *pushSubscriptionRepository) Query(ctx context.Context, userID uuid.UUID) ([]*entity.PushSubscription, error) {
+	var subs []*entity.PushSubscription
+	query := "SELECT * FROM push_subscriptions WHERE user_id = $1 ORDER BY id"
+	if err := r.db.SelectContext(ctx, &subs, query, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return subs, nil
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushSubscriptionRepository_Upsert(t *testing.T) {
+	userID := uuid.New()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewPushSubscriptionRepository(db)
+
+	expectedSQL := `INSERT INTO push_subscriptions .* ON CONFLICT \(endpoint\) DO UPDATE SET user_id = EXCLUDED.user_id, .* RETURNING \*`
+	rows := sqlmock.NewRows([]string{"id", "user_id", "endpoint", "p256dh", "auth", "created_at", "updated_at"}).
+		AddRow(7, userID, "https://push.example.com/abc", "key", "secret", time.Now(), time.Now())
+	mock.ExpectPrepare(expectedSQL).ExpectQuery().WillReturnRows(rows)
+	mock.ExpectExec(`DELETE FROM push_subscriptions WHERE user_id = \$1 AND id NOT IN \( SELECT id FROM push_subscriptions WHERE user_id = \$1 ORDER BY updated_at DESC, id DESC LIMIT \$2 \)`).
+		WithArgs(userID, repo.MaxPushSubscriptionsPerUser).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	sub := &entity.PushSubscription{UserID: userID, Endpoint: "https://push.example.com/abc", P256dh: "key", Auth: "secret"}
+	err = r.Upsert(context.Background(), sub)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), sub.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPushSubscriptionRepository_Query(t *testing.T) {
+	userID := uuid.New()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewPushSubscriptionRepository(db)
+
+	expectedSQL := `SELECT \* FROM push_subscriptions WHERE user_id = \$1 ORDER BY id`
+	rows := sqlmock.NewRows([]string{"id", "user_id", "endpoint"}).
+		AddRow(1, userID, "https://push.example.com/a").
+		AddRow(2, userID, "https://push.example.com/b")
+	mock.ExpectQuery(expectedSQL).WithArgs(userID).WillReturnRows(rows)
+
+	subs, err := r.Query(context.Background(), userID)
+
+	assert.NoError(t, err)
+	assert.Len(t, subs, 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
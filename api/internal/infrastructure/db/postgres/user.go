@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/icchon/matcha/api/internal/domain/entity"
 	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/lib/pq"
 )
 
 type userRepository struct {
@@ -63,6 +64,11 @@ func (r *userRepository) Query(ctx context.Context, q *repo.UserQuery) ([]*entit
 		args = append(args, *q.ID)
 		argCount++
 	}
+	if q.IDs != nil {
+		query += fmt.Sprintf(" AND id = ANY($%d)", argCount)
+		args = append(args, pq.Array(q.IDs))
+		argCount++
+	}
 
 	var users []*entity.User
 	if err := r.db.SelectContext(ctx, &users, query, args...); err != nil {
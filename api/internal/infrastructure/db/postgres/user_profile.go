@@ -21,8 +21,8 @@ func NewUserProfileRepository(db DBTX) repo.UserProfileRepository {
 
 func (r *userProfileRepository) Create(ctx context.Context, userProfile *entity.UserProfile) error {
 	query := `
-		INSERT INTO user_profiles (user_id, first_name, last_name, username, gender, sexual_preference, birthday, occupation, biography, location_name)
-		VALUES (:user_id, :first_name, :last_name, :username, :gender, :sexual_preference, :birthday, :occupation, :biography, :location_name)
+		INSERT INTO user_profiles (user_id, first_name, last_name, username, gender, sexual_preference, birthday, occupation, biography, location_name, locale)
+		VALUES (:user_id, :first_name, :last_name, :username, :gender, :sexual_preference, :birthday, :occupation, :biography, :location_name, :locale)
 		RETURNING *
 	`
 	stmt, err := r.db.PrepareNamedContext(ctx, query)
@@ -45,7 +45,8 @@ func (r *userProfileRepository) Update(ctx context.Context, userProfile *entity.
 			occupation = :occupation,
 			biography = :biography,
 			fame_rating = :fame_rating,
-			location_name = :location_name
+			location_name = :location_name,
+			locale = :locale
 		WHERE user_id = :user_id
 		RETURNING *
 	`
@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserRepository_Query_IDs(t *testing.T) {
+	userID1 := uuid.New()
+	userID2 := uuid.New()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewUserRepository(db)
+
+	ids := []uuid.UUID{userID1, userID2}
+	rows := sqlmock.NewRows([]string{"id", "created_at", "last_connection"}).
+		AddRow(userID1, time.Now(), time.Now()).
+		AddRow(userID2, time.Now(), nil)
+	mock.ExpectQuery(`SELECT \* FROM users WHERE 1=1 AND id = ANY\(\$1\)`).
+		WithArgs(pq.Array(ids)).
+		WillReturnRows(rows)
+
+	users, err := r.Query(context.Background(), &repo.UserQuery{IDs: ids})
+
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.True(t, users[0].LastConnection.Valid)
+	assert.False(t, users[1].LastConnection.Valid)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1 +1,55 @@
 package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+)
+
+// wsgateway が接続ごとに有効期限 (unix ms) をスコアにして書き込む ZSET
+// キーの形式は wsgateway/internal/server/presence.go と揃えること
+func presenceUserKey(userID uuid.UUID) string {
+	return fmt.Sprintf("presence:user:%s", userID)
+}
+
+type presenceRepository struct {
+	rdb *redis.Client
+}
+
+func NewPresenceRepository(rdb *redis.Client) repo.PresenceRepository {
+	return &presenceRepository{rdb: rdb}
+}
+
+func (r *presenceRepository) IsOnline(ctx context.Context, userID uuid.UUID) (bool, error) {
+	online, err := r.FindOnline(ctx, []uuid.UUID{userID})
+	if err != nil {
+		return false, err
+	}
+	return online[userID], nil
+}
+
+// FindOnline は有効期限の切れていない接続を 1 つ以上持つユーザーを true にした map を返す
+func (r *presenceRepository) FindOnline(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	result := make(map[uuid.UUID]bool, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	pipe := r.rdb.Pipeline()
+	counts := make([]*redis.IntCmd, len(userIDs))
+	for i, userID := range userIDs {
+		counts[i] = pipe.ZCount(ctx, presenceUserKey(userID), "("+now, "+inf")
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+	for i, userID := range userIDs {
+		result[userID] = counts[i].Val() > 0
+	}
+	return result, nil
+}
@@ -0,0 +1,26 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+)
+
+func pushDeliveryKey(key string) string {
+	return "push:delivery:" + key
+}
+
+type pushDeliveryRepository struct {
+	rdb *redis.Client
+}
+
+func NewPushDeliveryRepository(rdb *redis.Client) repo.PushDeliveryRepository {
+	return &pushDeliveryRepository{rdb: rdb}
+}
+
+// Claim は SET NX で key を作れたレプリカだけが true になる
+func (r *pushDeliveryRepository) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return r.rdb.SetNX(ctx, pushDeliveryKey(key), 1, ttl).Result()
+}
@@ -9,9 +9,19 @@ type repositoryManager struct {
 	authRepo              repo.AuthRepository
 	connectionRepo        repo.ConnectionRepository
 	messageRepo           repo.MessageRepository
+	messageReactionRepo   repo.MessageReactionRepository
+	messageAttachmentRepo repo.MessageAttachmentRepository
+	conversationReadRepo  repo.ConversationReadRepository
+	heldMessageRepo       repo.HeldMessageRepository
 	notificationRepo      repo.NotificationRepository
+	notifPrefRepo         repo.NotificationPreferenceRepository
+	notifMuteRepo         repo.NotificationMuteRepository
+	emailDigestRepo       repo.EmailDigestRepository
+	mailOutboxRepo        repo.MailOutboxRepository
+	pushSubscriptionRepo  repo.PushSubscriptionRepository
 	passwordResetRepo     repo.PasswordResetRepository
 	pictureRepo           repo.PictureRepository
+	pictureMatchRepo      repo.PictureMatchRepository
 	refreshTokenRepo      repo.RefreshTokenRepository
 	userTagRepo           repo.UserTagRepository
 	verificationTokenRepo repo.VerificationTokenRepository
@@ -27,9 +37,19 @@ func NewRepositoryManager(
 	authRepo repo.AuthRepository,
 	connectionRepo repo.ConnectionRepository,
 	messageRepo repo.MessageRepository,
+	messageReactionRepo repo.MessageReactionRepository,
+	messageAttachmentRepo repo.MessageAttachmentRepository,
+	conversationReadRepo repo.ConversationReadRepository,
+	heldMessageRepo repo.HeldMessageRepository,
 	notificationRepo repo.NotificationRepository,
+	notifPrefRepo repo.NotificationPreferenceRepository,
+	notifMuteRepo repo.NotificationMuteRepository,
+	emailDigestRepo repo.EmailDigestRepository,
+	mailOutboxRepo repo.MailOutboxRepository,
+	pushSubscriptionRepo repo.PushSubscriptionRepository,
 	passwordResetRepo repo.PasswordResetRepository,
 	pictureRepo repo.PictureRepository,
+	pictureMatchRepo repo.PictureMatchRepository,
 	refreshTokenRepo repo.RefreshTokenRepository,
 	userTagRepo repo.UserTagRepository,
 	verificationTokenRepo repo.VerificationTokenRepository,
@@ -44,9 +64,19 @@ func NewRepositoryManager(
 		authRepo:              authRepo,
 		connectionRepo:        connectionRepo,
 		messageRepo:           messageRepo,
+		messageReactionRepo:   messageReactionRepo,
+		messageAttachmentRepo: messageAttachmentRepo,
+		conversationReadRepo:  conversationReadRepo,
+		heldMessageRepo:       heldMessageRepo,
 		notificationRepo:      notificationRepo,
+		notifPrefRepo:         notifPrefRepo,
+		notifMuteRepo:         notifMuteRepo,
+		emailDigestRepo:       emailDigestRepo,
+		mailOutboxRepo:        mailOutboxRepo,
+		pushSubscriptionRepo:  pushSubscriptionRepo,
 		passwordResetRepo:     passwordResetRepo,
 		pictureRepo:           pictureRepo,
+		pictureMatchRepo:      pictureMatchRepo,
 		refreshTokenRepo:      refreshTokenRepo,
 		userTagRepo:           userTagRepo,
 		verificationTokenRepo: verificationTokenRepo,
@@ -90,10 +120,50 @@ func (r *repositoryManager) MessageRepo() repo.MessageRepository {
 	return r.messageRepo
 }
 
+func (r *repositoryManager) MessageReactionRepo() repo.MessageReactionRepository {
+	return r.messageReactionRepo
+}
+
+func (r *repositoryManager) MessageAttachmentRepo() repo.MessageAttachmentRepository {
+	return r.messageAttachmentRepo
+}
+
+func (r *repositoryManager) ConversationReadRepo() repo.ConversationReadRepository {
+	return r.conversationReadRepo
+}
+
+func (r *repositoryManager) PictureMatchRepo() repo.PictureMatchRepository {
+	return r.pictureMatchRepo
+}
+
+func (r *repositoryManager) HeldMessageRepo() repo.HeldMessageRepository {
+	return r.heldMessageRepo
+}
+
 func (r *repositoryManager) NotificationRepo() repo.NotificationRepository {
 	return r.notificationRepo
 }
 
+func (r *repositoryManager) NotificationPreferenceRepo() repo.NotificationPreferenceRepository {
+	return r.notifPrefRepo
+}
+
+func (r *repositoryManager) NotificationMuteRepo() repo.NotificationMuteRepository {
+	return r.notifMuteRepo
+}
+
+func (r *repositoryManager) EmailDigestRepo() repo.EmailDigestRepository {
+	return r.emailDigestRepo
+}
+
+func (r *repositoryManager) MailOutboxRepo() repo.MailOutboxRepository {
+	return r.mailOutboxRepo
+}
+
+func (r *repositoryManager) PushSubscriptionRepo() repo.PushSubscriptionRepository {
+	return r.pushSubscriptionRepo
+}
+
 func (r *repositoryManager) PasswordResetRepo() repo.PasswordResetRepository {
 	return r.passwordResetRepo
 }
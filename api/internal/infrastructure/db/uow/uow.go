@@ -28,9 +28,19 @@ func (u *unitOfWork) Do(ctx context.Context, fn func(m repo.RepositoryManager) e
 		postgres.NewAuthRepository(tx),
 		postgres.NewConnectionRepository(tx),
 		postgres.NewMessageRepository(tx),
+		postgres.NewMessageReactionRepository(tx),
+		postgres.NewMessageAttachmentRepository(tx),
+		postgres.NewConversationReadRepository(tx),
+		postgres.NewHeldMessageRepository(tx),
 		postgres.NewNotificationRepository(tx),
+		postgres.NewNotificationPreferenceRepository(tx),
+		postgres.NewNotificationMuteRepository(tx),
+		postgres.NewEmailDigestRepository(tx),
+		postgres.NewMailOutboxRepository(tx),
+		postgres.NewPushSubscriptionRepository(tx),
 		postgres.NewPasswordResetRepository(tx),
 		postgres.NewPictureRepository(tx),
+		postgres.NewPictureMatchRepository(tx),
 		postgres.NewRefreshTokenRepository(tx),
 		postgres.NewUserTagRepository(tx),
 		postgres.NewVerificationTokenRepository(tx),
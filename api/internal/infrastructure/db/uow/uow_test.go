@@ -79,6 +79,15 @@ func TestUnitOfWork_Do(t *testing.T) {
 			assert.NotNil(t, rm.AuthRepo())
 			assert.NotNil(t, rm.ConnectionRepo())
 			assert.NotNil(t, rm.MessageRepo())
+			assert.NotNil(t, rm.MessageReactionRepo())
+			assert.NotNil(t, rm.MessageAttachmentRepo())
+			assert.NotNil(t, rm.ConversationReadRepo())
+			assert.NotNil(t, rm.HeldMessageRepo())
+			assert.NotNil(t, rm.NotificationPreferenceRepo())
+			assert.NotNil(t, rm.NotificationMuteRepo())
+			assert.NotNil(t, rm.EmailDigestRepo())
+			assert.NotNil(t, rm.MailOutboxRepo())
+			assert.NotNil(t, rm.PushSubscriptionRepo())
 			assert.NotNil(t, rm.NotificationRepo())
 			assert.NotNil(t, rm.PasswordResetRepo())
 			assert.NotNil(t, rm.PictureRepo())
@@ -1,70 +1,305 @@
 package file
 
 import (
-	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/icchon/matcha/api/internal/domain/client"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// filesrv の書き込み系エンドポイントに付けるサービス間認証のヘッダー
+const (
+	serviceTimestampHeader = "X-Service-Timestamp"
+	serviceUserHeader      = "X-Service-User"
+	serviceSignatureHeader = "X-Service-Signature"
 )
 
 type filesrvClient struct {
-	imageUploadEndpoint string
+	imageUploadEndpoint      string
+	imageAssetEndpoint       string
+	imageBaseUrl             string
+	publicThumbnails         bool
+	attachmentUploadEndpoint string
+	attachmentBaseUrl        string
+	resumableUploadEndpoint  string
+	avatarBaseUrl            string
+	urlSigningKey            []byte
+	serviceAuthKey           []byte
 }
 
 var _ client.FileClient = (*filesrvClient)(nil)
 
-func NewFilesrvClient(imageUploadEndpoint string) *filesrvClient {
-	return &filesrvClient{imageUploadEndpoint: imageUploadEndpoint}
+func NewFilesrvClient(imageUploadEndpoint, imageAssetEndpoint, imageBaseUrl string, publicThumbnails bool, attachmentUploadEndpoint, attachmentBaseUrl, resumableUploadEndpoint, avatarBaseUrl, urlSigningKey, serviceAuthKey string) *filesrvClient {
+	return &filesrvClient{
+		imageUploadEndpoint:      imageUploadEndpoint,
+		imageAssetEndpoint:       strings.TrimRight(imageAssetEndpoint, "/"),
+		imageBaseUrl:             strings.TrimRight(imageBaseUrl, "/"),
+		publicThumbnails:         publicThumbnails,
+		attachmentUploadEndpoint: attachmentUploadEndpoint,
+		attachmentBaseUrl:        strings.TrimRight(attachmentBaseUrl, "/"),
+		resumableUploadEndpoint:  strings.TrimRight(resumableUploadEndpoint, "/"),
+		avatarBaseUrl:            strings.TrimRight(avatarBaseUrl, "/"),
+		urlSigningKey:            []byte(urlSigningKey),
+		serviceAuthKey:           []byte(serviceAuthKey),
+	}
 }
 
-type uploadResponse struct {
+type errorResponse struct {
+	Code    string `json:"code"`
 	Message string `json:"message"`
-	URL     string `json:"url"`
 }
 
-func (c *filesrvClient) SaveImage(data []byte, filename string) (string, error) {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
+// do は filesrv が検証する署名を付けて送る。userID は上限を数えるユーザー (なければ空)
+func (c *filesrvClient) do(req *http.Request, userID string) (*http.Response, error) {
+	timestamp := time.Now().Unix()
+	mac := hmac.New(sha256.New, c.serviceAuthKey)
+	fmt.Fprintf(mac, "%s\n%s\n%d\n%s", req.Method, req.URL.Path, timestamp, userID)
+	req.Header.Set(serviceTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(serviceUserHeader, userID)
+	req.Header.Set(serviceSignatureHeader, base64.RawURLEncoding.EncodeToString(mac.Sum(nil)))
 
-	part, err := writer.CreateFormFile("image", filename)
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to create form file part: %w", err)
+		return nil, fmt.Errorf("failed to send request to file service: %w", err)
 	}
+	return resp, nil
+}
 
-	_, err = part.Write(data)
-	if err != nil {
-		return "", fmt.Errorf("failed to write data to form file: %w", err)
+// uploadError は filesrv のエラー応答を client のエラーにする
+func uploadError(resp *http.Response) error {
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var body errorResponse
+	_ = json.Unmarshal(respBody, &body)
+
+	var kind error
+	switch resp.StatusCode {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity:
+		kind = client.ErrFileRejected
+	case http.StatusTooManyRequests:
+		kind = client.ErrFileQuotaExceeded
+	case http.StatusNotFound:
+		kind = client.ErrUploadNotFound
+	case http.StatusConflict:
+		kind = client.ErrUploadIncomplete
 	}
+	if kind != nil {
+		return fmt.Errorf("%w: %s: %s", kind, body.Code, body.Message)
+	}
+	return fmt.Errorf("file service returned non-OK status code %d: %s", resp.StatusCode, respBody)
+}
 
-	if err := writer.Close(); err != nil {
-		return "", fmt.Errorf("failed to close multipart writer: %w", err)
+type uploadResponse struct {
+	Message  string            `json:"message"`
+	AssetID  string            `json:"asset_id"`
+	URL      string            `json:"url"`
+	Variants map[string]string `json:"variants"`
+}
+
+// send は src を filesrv に送る。UploadID があれば送り終えた再開可能アップロードを kind ("image" か "attachment") として保存させる
+// なければ src.Reader を field に入れた multipart として endpoint に流す。本文はメモリに溜めない
+func (c *filesrvClient) send(userID uuid.UUID, src *client.FileSource, endpoint, field, kind string) (*http.Response, error) {
+	if src.UploadID != "" {
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/%s/%s", c.resumableUploadEndpoint, url.PathEscape(src.UploadID), kind), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		return c.do(req, userID.String())
 	}
 
-	req, err := http.NewRequest(http.MethodPost, c.imageUploadEndpoint, body)
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		part, err := writer.CreateFormFile(field, src.Filename)
+		if err == nil {
+			_, err = io.Copy(part, src.Reader)
+		}
+		if err == nil {
+			err = writer.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	// filesrv が途中で応答したときも、戻る前に src.Reader を読み終える (または止める)
+	defer func() {
+		pr.Close()
+		<-done
+	}()
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, pr)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-
 	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return c.do(req, userID.String())
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+func (c *filesrvClient) SaveImage(userID uuid.UUID, src *client.FileSource) (*client.Image, error) {
+	resp, err := c.send(userID, src, c.imageUploadEndpoint, "image", "image")
 	if err != nil {
-		return "", fmt.Errorf("failed to send request to file service: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("file service returned non-OK status code %d: %s", resp.StatusCode, respBody)
+		return nil, uploadError(resp)
 	}
 
 	var result uploadResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("failed to decode response body: %w", err)
+		return nil, fmt.Errorf("failed to decode response body: %w", err)
+	}
+	image := &client.Image{
+		AssetID:      result.AssetID,
+		ThumbnailURL: result.Variants["thumbnail"],
+		CardURL:      result.Variants["card"],
+		FullURL:      result.Variants["full"],
+	}
+	if image.FullURL == "" {
+		image.FullURL = result.URL
+	}
+	return image, nil
+}
+
+func (c *filesrvClient) DeleteImage(assetID string) error {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/%s", c.imageAssetEndpoint, url.PathEscape(assetID)), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.do(req, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// 既に消えているものは消せたことにする
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("file service returned non-OK status code %d: %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+func (c *filesrvClient) OpenImage(assetID string) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/%s/card", c.imageAssetEndpoint, url.PathEscape(assetID)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.do(req, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s", client.ErrAssetNotFound, assetID)
+		}
+		return nil, fmt.Errorf("file service returned non-OK status code %d: %s", resp.StatusCode, respBody)
+	}
+	return resp.Body, nil
+}
+
+func (c *filesrvClient) SaveAttachment(userID uuid.UUID, src *client.FileSource) (*client.Attachment, error) {
+	resp, err := c.send(userID, src, c.attachmentUploadEndpoint, "file", "attachment")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, uploadError(resp)
+	}
+
+	var attachment client.Attachment
+	if err := json.NewDecoder(resp.Body).Decode(&attachment); err != nil {
+		return nil, fmt.Errorf("failed to decode response body: %w", err)
+	}
+	return &attachment, nil
+}
+
+type createUploadResponse struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Length    int64     `json:"length"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CreateUpload は filesrv の POST /uploads (tus の creation) で枠を作る
+func (c *filesrvClient) CreateUpload(userID uuid.UUID, length int64) (*client.Upload, error) {
+	req, err := http.NewRequest(http.MethodPost, c.resumableUploadEndpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	req.Header.Set("Upload-Length", strconv.FormatInt(length, 10))
+
+	resp, err := c.do(req, userID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, uploadError(resp)
+	}
+
+	var result createUploadResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response body: %w", err)
+	}
+	return &client.Upload{ID: result.ID, URL: result.URL, Length: result.Length, ExpiresAt: result.ExpiresAt}, nil
+}
+
+// SignAttachmentURL は filesrv の GET /attachments/{id} が検証する exp と sig を付けた URL を返す
+func (c *filesrvClient) SignAttachmentURL(id string, expiresAt time.Time) string {
+	expires := expiresAt.Unix()
+	mac := hmac.New(sha256.New, c.urlSigningKey)
+	fmt.Fprintf(mac, "%s.%d", id, expires)
+	sig := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	q := url.Values{}
+	q.Set("exp", fmt.Sprintf("%d", expires))
+	q.Set("sig", sig)
+	return fmt.Sprintf("%s/%s?%s", c.attachmentBaseUrl, url.PathEscape(id), q.Encode())
+}
+
+// SignImageURL は filesrv の GET /images/* が検証する exp, v, sig を付けた URL を返す
+// v は発行先の記録で、URL を持っていれば誰でも失効まで取得できる
+func (c *filesrvClient) SignImageURL(rawURL string, viewerID uuid.UUID, expiresAt time.Time) string {
+	if c.imageBaseUrl == "" || !strings.HasPrefix(rawURL, c.imageBaseUrl+"/") {
+		return rawURL
+	}
+	path := strings.TrimPrefix(rawURL, c.imageBaseUrl+"/")
+	if c.publicThumbnails && strings.HasSuffix(path, "/thumbnail.jpg") {
+		return rawURL
+	}
+	expires := expiresAt.Unix()
+	viewer := viewerID.String()
+	mac := hmac.New(sha256.New, c.urlSigningKey)
+	fmt.Fprintf(mac, "image\n%s\n%d\n%s", path, expires, viewer)
+
+	q := url.Values{}
+	q.Set("exp", strconv.FormatInt(expires, 10))
+	q.Set("v", viewer)
+	q.Set("sig", base64.RawURLEncoding.EncodeToString(mac.Sum(nil)))
+	return fmt.Sprintf("%s?%s", rawURL, q.Encode())
+}
+
+// AvatarURL は filesrv の GET /avatars/{seed}.png の URL を返す。公開の画像なので署名はない
+func (c *filesrvClient) AvatarURL(userID uuid.UUID) string {
+	if c.avatarBaseUrl == "" {
+		return ""
 	}
-	return result.URL, nil
+	return fmt.Sprintf("%s/%s.png", c.avatarBaseUrl, userID)
 }
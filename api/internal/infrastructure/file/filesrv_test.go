@@ -0,0 +1,42 @@
+package file
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// filesrv の SignImage (filesrv/internal/server/image_access_test.go) と同じ値。どちらかの計算を変えたら両方を直す
+const (
+	sharedImageSigningKey = "url-key"
+	sharedImageURL        = "http://files.example.com/images/9f/9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08/card.jpg" +
+		"?exp=4102444800&sig=P8JT6pT_l7TA0RA_xgooJma0qtLllhgDVynjYncJy6g&v=3f1c2b6e-8a4d-4e2f-9b7a-1c2d3e4f5a6b"
+)
+
+func TestFilesrvClient_SignImageURL(t *testing.T) {
+	viewerID := uuid.MustParse("3f1c2b6e-8a4d-4e2f-9b7a-1c2d3e4f5a6b")
+	expiresAt := time.Unix(4102444800, 0)
+	assetURL := "http://files.example.com/images/9f/9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08/"
+
+	testCases := []struct {
+		name             string
+		publicThumbnails bool
+		rawURL           string
+		expected         string
+	}{
+		{name: "Signed for the viewer", rawURL: assetURL + "card.jpg", expected: sharedImageURL},
+		{name: "Public thumbnail is not signed", publicThumbnails: true, rawURL: assetURL + "thumbnail.jpg", expected: assetURL + "thumbnail.jpg"},
+		{name: "Card is signed with public thumbnails", publicThumbnails: true, rawURL: assetURL + "card.jpg", expected: sharedImageURL},
+		{name: "Other hosts are left as they are", rawURL: "https://example.com/photo.jpg", expected: "https://example.com/photo.jpg"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewFilesrvClient("", "", "http://files.example.com/images/", tc.publicThumbnails, "", "", "", "", sharedImageSigningKey, "")
+
+			assert.Equal(t, tc.expected, c.SignImageURL(tc.rawURL, viewerID, expiresAt))
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/icchon/matcha/api/internal/domain/client"
+)
+
+var _ client.MailClient = (*FileMailClient)(nil)
+
+// FileMailClient は開発用。メールを送らずに dir へ .eml ファイルとして書き出す
+type FileMailClient struct {
+	dir  string
+	from string
+}
+
+func NewFileMailClient(dir string, from string) *FileMailClient {
+	return &FileMailClient{dir: dir, from: from}
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func (c *FileMailClient) SendRawEmail(ctx context.Context, toEmail, subject, htmlBody, textBody string) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mail drop directory: %w", err)
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", textBody},
+		{"text/html; charset=utf-8", htmlBody},
+	} {
+		if part.content == "" {
+			continue
+		}
+		w, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType}})
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write([]byte(part.content)); err != nil {
+			return err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", c.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", toEmail)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.BEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405.000000000"), unsafeFileChars.ReplaceAllString(toEmail, "_"))
+	path := filepath.Join(c.dir, name)
+	if err := os.WriteFile(path, msg.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+	log.Printf("Mail to %s written to %s", toEmail, path)
+	return nil
+}
@@ -32,16 +32,7 @@ func NewSmtpClient(cfg client.MailConfig) *SmtpClient {
 }
 
 func (c *SmtpClient) SendRawEmail(ctx context.Context, toEmail, subject, htmlBody, textBody string) error {
-	m := mail.NewMessage()
-	m.SetHeader("From", c.from)
-	m.SetHeader("To", toEmail)
-	m.SetHeader("Subject", subject)
-
-	// マルチパートメールとして設定
-	m.SetBody("text/html", htmlBody)
-	if textBody != "" {
-		m.AddAlternative("text/plain", textBody)
-	}
+	m := c.newMessage(toEmail, subject, htmlBody, textBody)
 
 	_, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
@@ -52,3 +43,20 @@ func (c *SmtpClient) SendRawEmail(ctx context.Context, toEmail, subject, htmlBod
 	log.Println("メール送信成功")
 	return nil
 }
+
+// newMessage は FileMailClient と同じく text/plain, text/html の順に並べる
+// multipart/alternative ではメールクライアントは表示できる最後のパートを使う
+func (c *SmtpClient) newMessage(toEmail, subject, htmlBody, textBody string) *mail.Message {
+	m := mail.NewMessage()
+	m.SetHeader("From", c.from)
+	m.SetHeader("To", toEmail)
+	m.SetHeader("Subject", subject)
+
+	if textBody == "" {
+		m.SetBody("text/html", htmlBody)
+		return m
+	}
+	m.SetBody("text/plain", textBody)
+	m.AddAlternative("text/html", htmlBody)
+	return m
+}
@@ -0,0 +1,85 @@
+package mail
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	netmail "net/mail"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parts はメールを読み、パートの Content-Type (パラメータなし) を並び順で返す
+func parts(t *testing.T, raw []byte) (string, []string) {
+	msg, err := netmail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	if mediaType != "multipart/alternative" {
+		return mediaType, nil
+	}
+	var types []string
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		partType, _, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		types = append(types, partType)
+	}
+	return mediaType, types
+}
+
+func TestSmtpClient_newMessage(t *testing.T) {
+	c := NewSmtpClient(client.MailConfig{Host: "smtp.example.com", Port: 587, From: "noreply@example.com"})
+
+	testCases := []struct {
+		name          string
+		textBody      string
+		wantMediaType string
+		wantParts     []string
+	}{
+		// HTML を最後に置かないとクライアントはテキストを表示する
+		{name: "Text and HTML", textBody: "hello", wantMediaType: "multipart/alternative", wantParts: []string{"text/plain", "text/html"}},
+		{name: "HTML only", textBody: "", wantMediaType: "text/html"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := c.newMessage("user@example.com", "subject", "<p>hello</p>", tc.textBody)
+			var buf bytes.Buffer
+			_, err := m.WriteTo(&buf)
+			require.NoError(t, err)
+
+			mediaType, got := parts(t, buf.Bytes())
+
+			assert.Equal(t, tc.wantMediaType, mediaType)
+			assert.Equal(t, tc.wantParts, got)
+		})
+	}
+}
+
+func TestFileMailClient_PartOrder(t *testing.T) {
+	dir := t.TempDir()
+	c := NewFileMailClient(dir, "noreply@example.com")
+	require.NoError(t, c.SendRawEmail(t.Context(), "user@example.com", "subject", "<p>hello</p>", "hello"))
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	raw, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+
+	// SMTP と同じ並び
+	_, got := parts(t, raw)
+
+	assert.Equal(t, []string{"text/plain", "text/html"}, got)
+}
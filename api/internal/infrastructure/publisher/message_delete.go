@@ -0,0 +1,27 @@
+package publisher
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"github.com/icchon/matcha/api/internal/domain/client"
+)
+
+const messageDeleteChannel string = "message_delete_outgoing"
+
+type messageDeletePublisher struct {
+	rdb     *redis.Client
+	channel string
+}
+
+var _ client.Publisher = (*messageDeletePublisher)(nil)
+
+func NewMessageDeletePublisher(rdb *redis.Client) *messageDeletePublisher {
+	return &messageDeletePublisher{
+		rdb:     rdb,
+		channel: messageDeleteChannel,
+	}
+}
+
+func (p *messageDeletePublisher) Publish(ctx context.Context, data interface{}) error {
+	return p.rdb.Publish(ctx, p.channel, data).Err()
+}
@@ -0,0 +1,27 @@
+package publisher
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"github.com/icchon/matcha/api/internal/domain/client"
+)
+
+const messageEditChannel string = "message_edit_outgoing"
+
+type messageEditPublisher struct {
+	rdb     *redis.Client
+	channel string
+}
+
+var _ client.Publisher = (*messageEditPublisher)(nil)
+
+func NewMessageEditPublisher(rdb *redis.Client) *messageEditPublisher {
+	return &messageEditPublisher{
+		rdb:     rdb,
+		channel: messageEditChannel,
+	}
+}
+
+func (p *messageEditPublisher) Publish(ctx context.Context, data interface{}) error {
+	return p.rdb.Publish(ctx, p.channel, data).Err()
+}
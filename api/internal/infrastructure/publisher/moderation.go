@@ -0,0 +1,27 @@
+package publisher
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"github.com/icchon/matcha/api/internal/domain/client"
+)
+
+const moderationChannel string = "moderation_outgoing"
+
+type moderationPublisher struct {
+	rdb     *redis.Client
+	channel string
+}
+
+var _ client.Publisher = (*moderationPublisher)(nil)
+
+func NewModerationPublisher(rdb *redis.Client) *moderationPublisher {
+	return &moderationPublisher{
+		rdb:     rdb,
+		channel: moderationChannel,
+	}
+}
+
+func (p *moderationPublisher) Publish(ctx context.Context, data interface{}) error {
+	return p.rdb.Publish(ctx, p.channel, data).Err()
+}
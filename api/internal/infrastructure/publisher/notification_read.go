@@ -0,0 +1,27 @@
+package publisher
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"github.com/icchon/matcha/api/internal/domain/client"
+)
+
+const notificationReadChannel string = "notification_read_outgoing"
+
+type notificationReadPublisher struct {
+	rdb     *redis.Client
+	channel string
+}
+
+var _ client.Publisher = (*notificationReadPublisher)(nil)
+
+func NewNotificationReadPublisher(rdb *redis.Client) *notificationReadPublisher {
+	return &notificationReadPublisher{
+		rdb:     rdb,
+		channel: notificationReadChannel,
+	}
+}
+
+func (p *notificationReadPublisher) Publish(ctx context.Context, data interface{}) error {
+	return p.rdb.Publish(ctx, p.channel, data).Err()
+}
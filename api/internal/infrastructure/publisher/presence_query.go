@@ -0,0 +1,27 @@
+package publisher
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"github.com/icchon/matcha/api/internal/domain/client"
+)
+
+const presenceQueryChannel string = "presence_query_outgoing"
+
+type presenceQueryPublisher struct {
+	rdb     *redis.Client
+	channel string
+}
+
+var _ client.Publisher = (*presenceQueryPublisher)(nil)
+
+func NewPresenceQueryPublisher(rdb *redis.Client) *presenceQueryPublisher {
+	return &presenceQueryPublisher{
+		rdb:     rdb,
+		channel: presenceQueryChannel,
+	}
+}
+
+func (p *presenceQueryPublisher) Publish(ctx context.Context, data interface{}) error {
+	return p.rdb.Publish(ctx, p.channel, data).Err()
+}
@@ -0,0 +1,27 @@
+package publisher
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"github.com/icchon/matcha/api/internal/domain/client"
+)
+
+const reactionChannel string = "reaction_outgoing"
+
+type reactionPublisher struct {
+	rdb     *redis.Client
+	channel string
+}
+
+var _ client.Publisher = (*reactionPublisher)(nil)
+
+func NewReactionPublisher(rdb *redis.Client) *reactionPublisher {
+	return &reactionPublisher{
+		rdb:     rdb,
+		channel: reactionChannel,
+	}
+}
+
+func (p *reactionPublisher) Publish(ctx context.Context, data interface{}) error {
+	return p.rdb.Publish(ctx, p.channel, data).Err()
+}
@@ -0,0 +1,27 @@
+package publisher
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"github.com/icchon/matcha/api/internal/domain/client"
+)
+
+const typingChannel string = "typing_outgoing"
+
+type typingPublisher struct {
+	rdb     *redis.Client
+	channel string
+}
+
+var _ client.Publisher = (*typingPublisher)(nil)
+
+func NewTypingPublisher(rdb *redis.Client) *typingPublisher {
+	return &typingPublisher{
+		rdb:     rdb,
+		channel: typingChannel,
+	}
+}
+
+func (p *typingPublisher) Publish(ctx context.Context, data interface{}) error {
+	return p.rdb.Publish(ctx, p.channel, data).Err()
+}
@@ -0,0 +1,26 @@
+package push
+
+import (
+	"context"
+
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+)
+
+var _ client.PushClient = (*mockPushClient)(nil)
+
+// mockPushClient は VAPID 鍵が設定されていないときに使う。何も送らず、公開鍵も返さない
+type mockPushClient struct {
+}
+
+func NewMockPushClient() *mockPushClient {
+	return &mockPushClient{}
+}
+
+func (c *mockPushClient) Send(ctx context.Context, sub *entity.PushSubscription, msg *client.PushMessage) error {
+	return nil
+}
+
+func (c *mockPushClient) PublicKey() string {
+	return ""
+}
@@ -0,0 +1,226 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+)
+
+const (
+	// aes128gcm のレコードサイズ。ペイロードは 1 レコードに収める
+	recordSize = 4096
+	// VAPID の JWT の有効期限 (最大 24 時間)
+	vapidTokenTTL = 12 * time.Hour
+	sendTimeout   = 10 * time.Second
+)
+
+// WebPushClient は RFC 8291 (aes128gcm) で暗号化し、RFC 8292 (VAPID) で署名してプッシュサービスに送る
+type WebPushClient struct {
+	httpClient *http.Client
+	vapidKey   *ecdsa.PrivateKey
+	publicKey  string
+	subject    string
+}
+
+var _ client.PushClient = (*WebPushClient)(nil)
+
+// NewWebPushClient は base64url の VAPID 秘密鍵 (P-256 の 32 バイト) と連絡先 (mailto: か https:) から作る
+// allowPrivateEndpoints でなければ、名前解決の結果が内部のアドレスになる endpoint には接続しない
+func NewWebPushClient(vapidPrivateKey, subject string, allowPrivateEndpoints bool) (*WebPushClient, error) {
+	raw, err := decodeBase64(vapidPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	publicKey, err := key.PublicKey.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	httpClient := &http.Client{Timeout: sendTimeout}
+	if !allowPrivateEndpoints {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.DialContext = (&net.Dialer{Timeout: sendTimeout, Control: publicAddressOnly}).DialContext
+		httpClient.Transport = transport
+	}
+	return &WebPushClient{
+		httpClient: httpClient,
+		vapidKey:   key,
+		publicKey:  base64.RawURLEncoding.EncodeToString(publicKey),
+		subject:    subject,
+	}, nil
+}
+
+// publicAddressOnly は接続の直前に宛先を確かめる。DNS で内部のアドレスに向けられても接続しない
+func publicAddressOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !client.IsPublicIP(ip) {
+		return fmt.Errorf("%w: %s", client.ErrPushEndpointNotPublic, host)
+	}
+	return nil
+}
+
+func (c *WebPushClient) PublicKey() string {
+	return c.publicKey
+}
+
+func (c *WebPushClient) Send(ctx context.Context, sub *entity.PushSubscription, msg *client.PushMessage) error {
+	body, err := encrypt(sub, msg.Payload)
+	if err != nil {
+		return err
+	}
+	authorization, err := c.vapidAuthorization(sub.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(msg.TTL.Seconds())))
+	if msg.Urgency != "" {
+		req.Header.Set("Urgency", string(msg.Urgency))
+	}
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return client.ErrPushSubscriptionGone
+	case resp.StatusCode >= 300:
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("push service returned %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// vapidAuthorization は endpoint のオリジン宛ての VAPID ヘッダーを作る
+func (c *WebPushClient) vapidAuthorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(vapidTokenTTL).Unix(),
+		"sub": c.subject,
+	})
+	signed, err := token.SignedString(c.vapidKey)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("vapid t=%s, k=%s", signed, c.publicKey), nil
+}
+
+// encrypt は RFC 8291 のとおり、使い捨ての鍵と購読の鍵の ECDH から導いた鍵で 1 レコードの aes128gcm にする
+func encrypt(sub *entity.PushSubscription, payload []byte) ([]byte, error) {
+	uaPublicBytes, err := decodeBase64(sub.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh: %w", err)
+	}
+	authSecret, err := decodeBase64(sub.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth secret: %w", err)
+	}
+	curve := ecdh.P256()
+	uaPublic, err := curve.NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh: %w", err)
+	}
+	asPrivate, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublicBytes := asPrivate.PublicKey().Bytes()
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	keyInfo := "WebPush: info\x00" + string(uaPublicBytes) + string(asPublicBytes)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, 16+4+1+len(asPublicBytes))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublicBytes)))
+	header = append(header, asPublicBytes...)
+
+	// 最後のレコードの区切りは 0x02。パディングは付けない
+	record := make([]byte, 0, len(payload)+1)
+	record = append(record, payload...)
+	record = append(record, 0x02)
+	if len(header)+len(record)+gcm.Overhead() > recordSize {
+		return nil, errors.New("push payload is too large")
+	}
+	return gcm.Seal(header, nonce, record, nil), nil
+}
+
+// decodeBase64 はブラウザが返す base64url (パディングなし) を基本に、パディング付きや標準の base64 も受け付ける
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+	if data, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return data, nil
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
@@ -0,0 +1,203 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBrowser は購読したブラウザの鍵を持ち、届いたペイロードを復号する
+type fakeBrowser struct {
+	privateKey *ecdh.PrivateKey
+	authSecret []byte
+}
+
+func newFakeBrowser(t *testing.T) *fakeBrowser {
+	privateKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	authSecret := make([]byte, 16)
+	_, err = rand.Read(authSecret)
+	require.NoError(t, err)
+	return &fakeBrowser{privateKey: privateKey, authSecret: authSecret}
+}
+
+func (b *fakeBrowser) subscription(endpoint string) *entity.PushSubscription {
+	return &entity.PushSubscription{
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(b.privateKey.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(b.authSecret),
+	}
+}
+
+func (b *fakeBrowser) decrypt(t *testing.T, body []byte) []byte {
+	salt := body[:16]
+	assert.Equal(t, uint32(recordSize), binary.BigEndian.Uint32(body[16:20]))
+	idLen := int(body[20])
+	asPublicBytes := body[21 : 21+idLen]
+	ciphertext := body[21+idLen:]
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicBytes)
+	require.NoError(t, err)
+	sharedSecret, err := b.privateKey.ECDH(asPublic)
+	require.NoError(t, err)
+	uaPublicBytes := b.privateKey.PublicKey().Bytes()
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, b.authSecret, "WebPush: info\x00"+string(uaPublicBytes)+string(asPublicBytes), 32)
+	require.NoError(t, err)
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	require.NoError(t, err)
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	require.NoError(t, err)
+
+	block, err := aes.NewCipher(cek)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	require.NoError(t, err)
+	require.Equal(t, byte(0x02), plaintext[len(plaintext)-1])
+	return plaintext[:len(plaintext)-1]
+}
+
+func newVAPIDKey(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	raw, err := key.Bytes()
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// verifyVAPID は Authorization ヘッダーの JWT を k の公開鍵で検証し、claims を返す
+func verifyVAPID(t *testing.T, header string) jwt.MapClaims {
+	require.True(t, strings.HasPrefix(header, "vapid "))
+	params := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(header, "vapid "), ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		require.Len(t, kv, 2)
+		params[kv[0]] = kv[1]
+	}
+	rawPublicKey, err := base64.RawURLEncoding.DecodeString(params["k"])
+	require.NoError(t, err)
+	publicKey, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), rawPublicKey)
+	require.NoError(t, err)
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(params["t"], claims, func(token *jwt.Token) (interface{}, error) {
+		return publicKey, nil
+	}, jwt.WithValidMethods([]string{"ES256"}))
+	require.NoError(t, err)
+	return claims
+}
+
+func TestWebPushClient_Send(t *testing.T) {
+	browser := newFakeBrowser(t)
+	var received []byte
+	var headers http.Header
+	pushService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		received, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer pushService.Close()
+
+	c, err := NewWebPushClient(newVAPIDKey(t), "mailto:admin@example.com", true)
+	require.NoError(t, err)
+
+	payload := []byte(`{"type":"chat_event","payload":{"content":"こんにちは"}}`)
+	err = c.Send(context.Background(), browser.subscription(pushService.URL+"/push/abc"), &client.PushMessage{
+		Payload: payload,
+		TTL:     time.Hour,
+		Urgency: client.PushUrgencyHigh,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "aes128gcm", headers.Get("Content-Encoding"))
+	assert.Equal(t, "3600", headers.Get("TTL"))
+	assert.Equal(t, "high", headers.Get("Urgency"))
+	assert.Equal(t, payload, browser.decrypt(t, received))
+
+	claims := verifyVAPID(t, headers.Get("Authorization"))
+	assert.Equal(t, pushService.URL, claims["aud"])
+	assert.Equal(t, "mailto:admin@example.com", claims["sub"])
+	assert.True(t, strings.Contains(headers.Get("Authorization"), "k="+c.PublicKey()))
+}
+
+func TestWebPushClient_Send_Errors(t *testing.T) {
+	browser := newFakeBrowser(t)
+	c, err := NewWebPushClient(newVAPIDKey(t), "mailto:admin@example.com", true)
+	require.NoError(t, err)
+
+	t.Run("Expired subscription", func(t *testing.T) {
+		pushService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusGone)
+		}))
+		defer pushService.Close()
+
+		err := c.Send(context.Background(), browser.subscription(pushService.URL), &client.PushMessage{Payload: []byte("{}")})
+
+		assert.ErrorIs(t, err, client.ErrPushSubscriptionGone)
+	})
+
+	t.Run("Push service error", func(t *testing.T) {
+		pushService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "rate limited", http.StatusTooManyRequests)
+		}))
+		defer pushService.Close()
+
+		err := c.Send(context.Background(), browser.subscription(pushService.URL), &client.PushMessage{Payload: []byte("{}")})
+
+		assert.ErrorContains(t, err, "429")
+		assert.NotErrorIs(t, err, client.ErrPushSubscriptionGone)
+	})
+
+	t.Run("Payload too large", func(t *testing.T) {
+		err := c.Send(context.Background(), browser.subscription("http://127.0.0.1:0"), &client.PushMessage{Payload: bytes.Repeat([]byte("a"), recordSize)})
+
+		assert.ErrorContains(t, err, "too large")
+	})
+}
+
+func TestWebPushClient_Send_PrivateEndpoint(t *testing.T) {
+	browser := newFakeBrowser(t)
+	called := false
+	pushService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer pushService.Close()
+
+	c, err := NewWebPushClient(newVAPIDKey(t), "mailto:admin@example.com", false)
+	require.NoError(t, err)
+
+	// 名前で登録されていても、接続先がループバックなら送らない
+	endpoint := strings.Replace(pushService.URL, "127.0.0.1", "localhost", 1)
+	err = c.Send(context.Background(), browser.subscription(endpoint), &client.PushMessage{Payload: []byte("{}")})
+
+	assert.ErrorIs(t, err, client.ErrPushEndpointNotPublic)
+	assert.False(t, called)
+}
+
+func TestNewWebPushClient_InvalidKey(t *testing.T) {
+	_, err := NewWebPushClient("not-a-key", "mailto:admin@example.com", true)
+	assert.Error(t, err)
+}
@@ -0,0 +1,61 @@
+package subscriber
+
+import (
+	"context"
+	"log"
+
+	"encoding/json"
+	"github.com/go-redis/redis/v8"
+	"github.com/icchon/matcha/api/internal/domain/client"
+)
+
+const chatOutgoingChannel = "chat_outgoing"
+
+type chatOutgoingSubscriber struct {
+	rdb     *redis.Client
+	channel string
+}
+
+// NewChatOutgoingSubscriber は配信待ちのチャットメッセージを購読する (Web Push 用)
+func NewChatOutgoingSubscriber(rdb *redis.Client) *chatOutgoingSubscriber {
+	return &chatOutgoingSubscriber{
+		rdb:     rdb,
+		channel: chatOutgoingChannel,
+	}
+}
+
+var _ client.Subscriber = (*chatOutgoingSubscriber)(nil)
+
+func (s *chatOutgoingSubscriber) SubscribeChannel(ctx context.Context, handler func(ctx context.Context, payload interface{}) error) error {
+	pubsub := s.rdb.Subscribe(ctx, string(s.channel))
+	ch := pubsub.Channel()
+
+	go func() {
+		defer pubsub.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				log.Printf("Context cancelled. Stopping subscription for channel: %s", s.channel)
+				return
+
+			case msg, ok := <-ch:
+				if !ok {
+					log.Printf("Redis channel closed for %s.", s.channel)
+					return
+				}
+
+				var payload client.MessagePayload
+				err := json.Unmarshal([]byte(msg.Payload), &payload)
+				if err != nil {
+					log.Printf("Error unmarshaling message from channel %s: %v", s.channel, err)
+					continue
+				}
+
+				if err := handler(ctx, &payload); err != nil {
+					log.Printf("Error handling message from channel %s: %v", s.channel, err)
+				}
+			}
+		}
+	}()
+	return nil
+}
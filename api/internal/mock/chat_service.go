@@ -14,6 +14,7 @@ import (
 	reflect "reflect"
 
 	uuid "github.com/google/uuid"
+	client "github.com/icchon/matcha/api/internal/domain/client"
 	entity "github.com/icchon/matcha/api/internal/domain/entity"
 	service "github.com/icchon/matcha/api/internal/domain/service"
 	gomock "go.uber.org/mock/gomock"
@@ -43,11 +44,72 @@ func (m *MockChatService) EXPECT() *MockChatServiceMockRecorder {
 	return m.recorder
 }
 
+// AddReaction mocks base method.
+func (m *MockChatService) AddReaction(ctx context.Context, userID, otherUserID uuid.UUID, messageID int64, emoji string) (*entity.MessageReaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddReaction", ctx, userID, otherUserID, messageID, emoji)
+	ret0, _ := ret[0].(*entity.MessageReaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddReaction indicates an expected call of AddReaction.
+func (mr *MockChatServiceMockRecorder) AddReaction(ctx, userID, otherUserID, messageID, emoji any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReaction", reflect.TypeOf((*MockChatService)(nil).AddReaction), ctx, userID, otherUserID, messageID, emoji)
+}
+
+// DeleteMessage mocks base method.
+func (m *MockChatService) DeleteMessage(ctx context.Context, userID, otherUserID uuid.UUID, messageID int64) (*entity.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMessage", ctx, userID, otherUserID, messageID)
+	ret0, _ := ret[0].(*entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteMessage indicates an expected call of DeleteMessage.
+func (mr *MockChatServiceMockRecorder) DeleteMessage(ctx, userID, otherUserID, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockChatService)(nil).DeleteMessage), ctx, userID, otherUserID, messageID)
+}
+
+// EditMessage mocks base method.
+func (m *MockChatService) EditMessage(ctx context.Context, userID, otherUserID uuid.UUID, messageID int64, content string) (*entity.Message, *entity.HeldMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditMessage", ctx, userID, otherUserID, messageID, content)
+	ret0, _ := ret[0].(*entity.Message)
+	ret1, _ := ret[1].(*entity.HeldMessage)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// EditMessage indicates an expected call of EditMessage.
+func (mr *MockChatServiceMockRecorder) EditMessage(ctx, userID, otherUserID, messageID, content any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditMessage", reflect.TypeOf((*MockChatService)(nil).EditMessage), ctx, userID, otherUserID, messageID, content)
+}
+
+// GetAttachmentURL mocks base method.
+func (m *MockChatService) GetAttachmentURL(ctx context.Context, userID, otherUserID uuid.UUID, attachmentID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttachmentURL", ctx, userID, otherUserID, attachmentID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttachmentURL indicates an expected call of GetAttachmentURL.
+func (mr *MockChatServiceMockRecorder) GetAttachmentURL(ctx, userID, otherUserID, attachmentID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttachmentURL", reflect.TypeOf((*MockChatService)(nil).GetAttachmentURL), ctx, userID, otherUserID, attachmentID)
+}
+
 // GetChatMessages mocks base method.
-func (m *MockChatService) GetChatMessages(ctx context.Context, params *service.GetChatMessagesParams) ([]*entity.Message, error) {
+func (m *MockChatService) GetChatMessages(ctx context.Context, params *service.GetChatMessagesParams) (*service.ChatHistory, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetChatMessages", ctx, params)
-	ret0, _ := ret[0].([]*entity.Message)
+	ret0, _ := ret[0].(*service.ChatHistory)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -72,3 +134,77 @@ func (mr *MockChatServiceMockRecorder) GetChatsForUser(ctx, userID any) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChatsForUser", reflect.TypeOf((*MockChatService)(nil).GetChatsForUser), ctx, userID)
 }
+
+// GetReactions mocks base method.
+func (m *MockChatService) GetReactions(ctx context.Context, userID, otherUserID uuid.UUID, messageID int64) ([]*entity.MessageReaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReactions", ctx, userID, otherUserID, messageID)
+	ret0, _ := ret[0].([]*entity.MessageReaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReactions indicates an expected call of GetReactions.
+func (mr *MockChatServiceMockRecorder) GetReactions(ctx, userID, otherUserID, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReactions", reflect.TypeOf((*MockChatService)(nil).GetReactions), ctx, userID, otherUserID, messageID)
+}
+
+// RemoveReaction mocks base method.
+func (m *MockChatService) RemoveReaction(ctx context.Context, userID, otherUserID uuid.UUID, messageID int64, emoji string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveReaction", ctx, userID, otherUserID, messageID, emoji)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveReaction indicates an expected call of RemoveReaction.
+func (mr *MockChatServiceMockRecorder) RemoveReaction(ctx, userID, otherUserID, messageID, emoji any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveReaction", reflect.TypeOf((*MockChatService)(nil).RemoveReaction), ctx, userID, otherUserID, messageID, emoji)
+}
+
+// ResolveAttachments mocks base method.
+func (m *MockChatService) ResolveAttachments(ctx context.Context, senderID, recipientID uuid.UUID, attachmentIDs []string) ([]*entity.MessageAttachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveAttachments", ctx, senderID, recipientID, attachmentIDs)
+	ret0, _ := ret[0].([]*entity.MessageAttachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveAttachments indicates an expected call of ResolveAttachments.
+func (mr *MockChatServiceMockRecorder) ResolveAttachments(ctx, senderID, recipientID, attachmentIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveAttachments", reflect.TypeOf((*MockChatService)(nil).ResolveAttachments), ctx, senderID, recipientID, attachmentIDs)
+}
+
+// SearchMessages mocks base method.
+func (m *MockChatService) SearchMessages(ctx context.Context, params *service.SearchChatMessagesParams) (*service.ChatSearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchMessages", ctx, params)
+	ret0, _ := ret[0].(*service.ChatSearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchMessages indicates an expected call of SearchMessages.
+func (mr *MockChatServiceMockRecorder) SearchMessages(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchMessages", reflect.TypeOf((*MockChatService)(nil).SearchMessages), ctx, params)
+}
+
+// UploadAttachment mocks base method.
+func (m *MockChatService) UploadAttachment(ctx context.Context, userID, otherUserID uuid.UUID, file *client.FileSource) (*entity.MessageAttachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadAttachment", ctx, userID, otherUserID, file)
+	ret0, _ := ret[0].(*entity.MessageAttachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadAttachment indicates an expected call of UploadAttachment.
+func (mr *MockChatServiceMockRecorder) UploadAttachment(ctx, userID, otherUserID, file any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadAttachment", reflect.TypeOf((*MockChatService)(nil).UploadAttachment), ctx, userID, otherUserID, file)
+}
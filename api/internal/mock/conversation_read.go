@@ -0,0 +1,151 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/repo/conversation_read.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/repo/conversation_read.go -destination=internal/mock/conversation_read.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	uuid "github.com/google/uuid"
+	entity "github.com/icchon/matcha/api/internal/domain/entity"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockConversationReadQueryRepository is a mock of ConversationReadQueryRepository interface.
+type MockConversationReadQueryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockConversationReadQueryRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockConversationReadQueryRepositoryMockRecorder is the mock recorder for MockConversationReadQueryRepository.
+type MockConversationReadQueryRepositoryMockRecorder struct {
+	mock *MockConversationReadQueryRepository
+}
+
+// NewMockConversationReadQueryRepository creates a new mock instance.
+func NewMockConversationReadQueryRepository(ctrl *gomock.Controller) *MockConversationReadQueryRepository {
+	mock := &MockConversationReadQueryRepository{ctrl: ctrl}
+	mock.recorder = &MockConversationReadQueryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConversationReadQueryRepository) EXPECT() *MockConversationReadQueryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Find mocks base method.
+func (m *MockConversationReadQueryRepository) Find(ctx context.Context, userID, peerID uuid.UUID) (*entity.ConversationRead, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, userID, peerID)
+	ret0, _ := ret[0].(*entity.ConversationRead)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockConversationReadQueryRepositoryMockRecorder) Find(ctx, userID, peerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockConversationReadQueryRepository)(nil).Find), ctx, userID, peerID)
+}
+
+// MockConversationReadCommandRepository is a mock of ConversationReadCommandRepository interface.
+type MockConversationReadCommandRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockConversationReadCommandRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockConversationReadCommandRepositoryMockRecorder is the mock recorder for MockConversationReadCommandRepository.
+type MockConversationReadCommandRepositoryMockRecorder struct {
+	mock *MockConversationReadCommandRepository
+}
+
+// NewMockConversationReadCommandRepository creates a new mock instance.
+func NewMockConversationReadCommandRepository(ctrl *gomock.Controller) *MockConversationReadCommandRepository {
+	mock := &MockConversationReadCommandRepository{ctrl: ctrl}
+	mock.recorder = &MockConversationReadCommandRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConversationReadCommandRepository) EXPECT() *MockConversationReadCommandRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Advance mocks base method.
+func (m *MockConversationReadCommandRepository) Advance(ctx context.Context, userID, peerID uuid.UUID, upTo int64) (*entity.ConversationRead, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Advance", ctx, userID, peerID, upTo)
+	ret0, _ := ret[0].(*entity.ConversationRead)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Advance indicates an expected call of Advance.
+func (mr *MockConversationReadCommandRepositoryMockRecorder) Advance(ctx, userID, peerID, upTo any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Advance", reflect.TypeOf((*MockConversationReadCommandRepository)(nil).Advance), ctx, userID, peerID, upTo)
+}
+
+// MockConversationReadRepository is a mock of ConversationReadRepository interface.
+type MockConversationReadRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockConversationReadRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockConversationReadRepositoryMockRecorder is the mock recorder for MockConversationReadRepository.
+type MockConversationReadRepositoryMockRecorder struct {
+	mock *MockConversationReadRepository
+}
+
+// NewMockConversationReadRepository creates a new mock instance.
+func NewMockConversationReadRepository(ctrl *gomock.Controller) *MockConversationReadRepository {
+	mock := &MockConversationReadRepository{ctrl: ctrl}
+	mock.recorder = &MockConversationReadRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConversationReadRepository) EXPECT() *MockConversationReadRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Advance mocks base method.
+func (m *MockConversationReadRepository) Advance(ctx context.Context, userID, peerID uuid.UUID, upTo int64) (*entity.ConversationRead, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Advance", ctx, userID, peerID, upTo)
+	ret0, _ := ret[0].(*entity.ConversationRead)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Advance indicates an expected call of Advance.
+func (mr *MockConversationReadRepositoryMockRecorder) Advance(ctx, userID, peerID, upTo any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Advance", reflect.TypeOf((*MockConversationReadRepository)(nil).Advance), ctx, userID, peerID, upTo)
+}
+
+// Find mocks base method.
+func (m *MockConversationReadRepository) Find(ctx context.Context, userID, peerID uuid.UUID) (*entity.ConversationRead, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, userID, peerID)
+	ret0, _ := ret[0].(*entity.ConversationRead)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockConversationReadRepositoryMockRecorder) Find(ctx, userID, peerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockConversationReadRepository)(nil).Find), ctx, userID, peerID)
+}
@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/service/digest.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/service/digest.go -destination=internal/mock/digest_service.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDigestService is a mock of DigestService interface.
+type MockDigestService struct {
+	ctrl     *gomock.Controller
+	recorder *MockDigestServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockDigestServiceMockRecorder is the mock recorder for MockDigestService.
+type MockDigestServiceMockRecorder struct {
+	mock *MockDigestService
+}
+
+// NewMockDigestService creates a new mock instance.
+func NewMockDigestService(ctrl *gomock.Controller) *MockDigestService {
+	mock := &MockDigestService{ctrl: ctrl}
+	mock.recorder = &MockDigestServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDigestService) EXPECT() *MockDigestServiceMockRecorder {
+	return m.recorder
+}
+
+// SendDigests mocks base method.
+func (m *MockDigestService) SendDigests(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDigests", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendDigests indicates an expected call of SendDigests.
+func (mr *MockDigestServiceMockRecorder) SendDigests(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDigests", reflect.TypeOf((*MockDigestService)(nil).SendDigests), ctx)
+}
+
+// Unsubscribe mocks base method.
+func (m *MockDigestService) Unsubscribe(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unsubscribe", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe.
+func (mr *MockDigestServiceMockRecorder) Unsubscribe(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockDigestService)(nil).Unsubscribe), ctx, token)
+}
@@ -0,0 +1,149 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/repo/email_digest.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/repo/email_digest.go -destination=internal/mock/email_digest.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	uuid "github.com/google/uuid"
+	entity "github.com/icchon/matcha/api/internal/domain/entity"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockEmailDigestQueryRepository is a mock of EmailDigestQueryRepository interface.
+type MockEmailDigestQueryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmailDigestQueryRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockEmailDigestQueryRepositoryMockRecorder is the mock recorder for MockEmailDigestQueryRepository.
+type MockEmailDigestQueryRepositoryMockRecorder struct {
+	mock *MockEmailDigestQueryRepository
+}
+
+// NewMockEmailDigestQueryRepository creates a new mock instance.
+func NewMockEmailDigestQueryRepository(ctrl *gomock.Controller) *MockEmailDigestQueryRepository {
+	mock := &MockEmailDigestQueryRepository{ctrl: ctrl}
+	mock.recorder = &MockEmailDigestQueryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEmailDigestQueryRepository) EXPECT() *MockEmailDigestQueryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Find mocks base method.
+func (m *MockEmailDigestQueryRepository) Find(ctx context.Context, userID uuid.UUID) (*entity.EmailDigest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, userID)
+	ret0, _ := ret[0].(*entity.EmailDigest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockEmailDigestQueryRepositoryMockRecorder) Find(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockEmailDigestQueryRepository)(nil).Find), ctx, userID)
+}
+
+// MockEmailDigestCommandRepository is a mock of EmailDigestCommandRepository interface.
+type MockEmailDigestCommandRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmailDigestCommandRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockEmailDigestCommandRepositoryMockRecorder is the mock recorder for MockEmailDigestCommandRepository.
+type MockEmailDigestCommandRepositoryMockRecorder struct {
+	mock *MockEmailDigestCommandRepository
+}
+
+// NewMockEmailDigestCommandRepository creates a new mock instance.
+func NewMockEmailDigestCommandRepository(ctrl *gomock.Controller) *MockEmailDigestCommandRepository {
+	mock := &MockEmailDigestCommandRepository{ctrl: ctrl}
+	mock.recorder = &MockEmailDigestCommandRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEmailDigestCommandRepository) EXPECT() *MockEmailDigestCommandRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Upsert mocks base method.
+func (m *MockEmailDigestCommandRepository) Upsert(ctx context.Context, digest *entity.EmailDigest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, digest)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockEmailDigestCommandRepositoryMockRecorder) Upsert(ctx, digest any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockEmailDigestCommandRepository)(nil).Upsert), ctx, digest)
+}
+
+// MockEmailDigestRepository is a mock of EmailDigestRepository interface.
+type MockEmailDigestRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmailDigestRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockEmailDigestRepositoryMockRecorder is the mock recorder for MockEmailDigestRepository.
+type MockEmailDigestRepositoryMockRecorder struct {
+	mock *MockEmailDigestRepository
+}
+
+// NewMockEmailDigestRepository creates a new mock instance.
+func NewMockEmailDigestRepository(ctrl *gomock.Controller) *MockEmailDigestRepository {
+	mock := &MockEmailDigestRepository{ctrl: ctrl}
+	mock.recorder = &MockEmailDigestRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEmailDigestRepository) EXPECT() *MockEmailDigestRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Find mocks base method.
+func (m *MockEmailDigestRepository) Find(ctx context.Context, userID uuid.UUID) (*entity.EmailDigest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, userID)
+	ret0, _ := ret[0].(*entity.EmailDigest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockEmailDigestRepositoryMockRecorder) Find(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockEmailDigestRepository)(nil).Find), ctx, userID)
+}
+
+// Upsert mocks base method.
+func (m *MockEmailDigestRepository) Upsert(ctx context.Context, digest *entity.EmailDigest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, digest)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockEmailDigestRepositoryMockRecorder) Upsert(ctx, digest any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockEmailDigestRepository)(nil).Upsert), ctx, digest)
+}
@@ -1,17 +1,21 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: domain/repo/file_client.go
+// Source: internal/domain/client/file.go
 //
 // Generated by this command:
 //
-//	mockgen -source domain/repo/file_client.go -destination mock/file_client.go -package mock
+//	mockgen -source=internal/domain/client/file.go -destination=internal/mock/file_client.go -package=mock
 //
 
 // Package mock is a generated GoMock package.
 package mock
 
 import (
+	io "io"
 	reflect "reflect"
+	time "time"
 
+	uuid "github.com/google/uuid"
+	client "github.com/icchon/matcha/api/internal/domain/client"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -39,17 +43,118 @@ func (m *MockFileClient) EXPECT() *MockFileClientMockRecorder {
 	return m.recorder
 }
 
-// SaveImage mocks base method.
-func (m *MockFileClient) SaveImage(data []byte, filename string) (string, error) {
+// AvatarURL mocks base method.
+func (m *MockFileClient) AvatarURL(userID uuid.UUID) string {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveImage", data, filename)
+	ret := m.ctrl.Call(m, "AvatarURL", userID)
 	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// AvatarURL indicates an expected call of AvatarURL.
+func (mr *MockFileClientMockRecorder) AvatarURL(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AvatarURL", reflect.TypeOf((*MockFileClient)(nil).AvatarURL), userID)
+}
+
+// CreateUpload mocks base method.
+func (m *MockFileClient) CreateUpload(userID uuid.UUID, length int64) (*client.Upload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUpload", userID, length)
+	ret0, _ := ret[0].(*client.Upload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUpload indicates an expected call of CreateUpload.
+func (mr *MockFileClientMockRecorder) CreateUpload(userID, length any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUpload", reflect.TypeOf((*MockFileClient)(nil).CreateUpload), userID, length)
+}
+
+// DeleteImage mocks base method.
+func (m *MockFileClient) DeleteImage(assetID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteImage", assetID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteImage indicates an expected call of DeleteImage.
+func (mr *MockFileClientMockRecorder) DeleteImage(assetID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteImage", reflect.TypeOf((*MockFileClient)(nil).DeleteImage), assetID)
+}
+
+// OpenImage mocks base method.
+func (m *MockFileClient) OpenImage(assetID string) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenImage", assetID)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenImage indicates an expected call of OpenImage.
+func (mr *MockFileClientMockRecorder) OpenImage(assetID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenImage", reflect.TypeOf((*MockFileClient)(nil).OpenImage), assetID)
+}
+
+// SaveAttachment mocks base method.
+func (m *MockFileClient) SaveAttachment(userID uuid.UUID, src *client.FileSource) (*client.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAttachment", userID, src)
+	ret0, _ := ret[0].(*client.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveAttachment indicates an expected call of SaveAttachment.
+func (mr *MockFileClientMockRecorder) SaveAttachment(userID, src any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAttachment", reflect.TypeOf((*MockFileClient)(nil).SaveAttachment), userID, src)
+}
+
+// SaveImage mocks base method.
+func (m *MockFileClient) SaveImage(userID uuid.UUID, src *client.FileSource) (*client.Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveImage", userID, src)
+	ret0, _ := ret[0].(*client.Image)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SaveImage indicates an expected call of SaveImage.
-func (mr *MockFileClientMockRecorder) SaveImage(data, filename any) *gomock.Call {
+func (mr *MockFileClientMockRecorder) SaveImage(userID, src any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveImage", reflect.TypeOf((*MockFileClient)(nil).SaveImage), userID, src)
+}
+
+// SignAttachmentURL mocks base method.
+func (m *MockFileClient) SignAttachmentURL(id string, expiresAt time.Time) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignAttachmentURL", id, expiresAt)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// SignAttachmentURL indicates an expected call of SignAttachmentURL.
+func (mr *MockFileClientMockRecorder) SignAttachmentURL(id, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignAttachmentURL", reflect.TypeOf((*MockFileClient)(nil).SignAttachmentURL), id, expiresAt)
+}
+
+// SignImageURL mocks base method.
+func (m *MockFileClient) SignImageURL(rawURL string, viewerID uuid.UUID, expiresAt time.Time) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignImageURL", rawURL, viewerID, expiresAt)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// SignImageURL indicates an expected call of SignImageURL.
+func (mr *MockFileClientMockRecorder) SignImageURL(rawURL, viewerID, expiresAt any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveImage", reflect.TypeOf((*MockFileClient)(nil).SaveImage), data, filename)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignImageURL", reflect.TypeOf((*MockFileClient)(nil).SignImageURL), rawURL, viewerID, expiresAt)
 }
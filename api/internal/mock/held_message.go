@@ -0,0 +1,209 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/repo/held_message.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/repo/held_message.go -destination=internal/mock/held_message.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/icchon/matcha/api/internal/domain/entity"
+	repo "github.com/icchon/matcha/api/internal/domain/repo"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockHeldMessageQueryRepository is a mock of HeldMessageQueryRepository interface.
+type MockHeldMessageQueryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHeldMessageQueryRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockHeldMessageQueryRepositoryMockRecorder is the mock recorder for MockHeldMessageQueryRepository.
+type MockHeldMessageQueryRepositoryMockRecorder struct {
+	mock *MockHeldMessageQueryRepository
+}
+
+// NewMockHeldMessageQueryRepository creates a new mock instance.
+func NewMockHeldMessageQueryRepository(ctrl *gomock.Controller) *MockHeldMessageQueryRepository {
+	mock := &MockHeldMessageQueryRepository{ctrl: ctrl}
+	mock.recorder = &MockHeldMessageQueryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHeldMessageQueryRepository) EXPECT() *MockHeldMessageQueryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Find mocks base method.
+func (m *MockHeldMessageQueryRepository) Find(ctx context.Context, id int64) (*entity.HeldMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, id)
+	ret0, _ := ret[0].(*entity.HeldMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockHeldMessageQueryRepositoryMockRecorder) Find(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockHeldMessageQueryRepository)(nil).Find), ctx, id)
+}
+
+// Query mocks base method.
+func (m *MockHeldMessageQueryRepository) Query(ctx context.Context, q *repo.HeldMessageQuery) ([]*entity.HeldMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", ctx, q)
+	ret0, _ := ret[0].([]*entity.HeldMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockHeldMessageQueryRepositoryMockRecorder) Query(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockHeldMessageQueryRepository)(nil).Query), ctx, q)
+}
+
+// MockHeldMessageCommandRepository is a mock of HeldMessageCommandRepository interface.
+type MockHeldMessageCommandRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHeldMessageCommandRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockHeldMessageCommandRepositoryMockRecorder is the mock recorder for MockHeldMessageCommandRepository.
+type MockHeldMessageCommandRepositoryMockRecorder struct {
+	mock *MockHeldMessageCommandRepository
+}
+
+// NewMockHeldMessageCommandRepository creates a new mock instance.
+func NewMockHeldMessageCommandRepository(ctrl *gomock.Controller) *MockHeldMessageCommandRepository {
+	mock := &MockHeldMessageCommandRepository{ctrl: ctrl}
+	mock.recorder = &MockHeldMessageCommandRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHeldMessageCommandRepository) EXPECT() *MockHeldMessageCommandRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockHeldMessageCommandRepository) Create(ctx context.Context, held *entity.HeldMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, held)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockHeldMessageCommandRepositoryMockRecorder) Create(ctx, held any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockHeldMessageCommandRepository)(nil).Create), ctx, held)
+}
+
+// Review mocks base method.
+func (m *MockHeldMessageCommandRepository) Review(ctx context.Context, held *entity.HeldMessage) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Review", ctx, held)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Review indicates an expected call of Review.
+func (mr *MockHeldMessageCommandRepositoryMockRecorder) Review(ctx, held any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Review", reflect.TypeOf((*MockHeldMessageCommandRepository)(nil).Review), ctx, held)
+}
+
+// MockHeldMessageRepository is a mock of HeldMessageRepository interface.
+type MockHeldMessageRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHeldMessageRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockHeldMessageRepositoryMockRecorder is the mock recorder for MockHeldMessageRepository.
+type MockHeldMessageRepositoryMockRecorder struct {
+	mock *MockHeldMessageRepository
+}
+
+// NewMockHeldMessageRepository creates a new mock instance.
+func NewMockHeldMessageRepository(ctrl *gomock.Controller) *MockHeldMessageRepository {
+	mock := &MockHeldMessageRepository{ctrl: ctrl}
+	mock.recorder = &MockHeldMessageRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHeldMessageRepository) EXPECT() *MockHeldMessageRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockHeldMessageRepository) Create(ctx context.Context, held *entity.HeldMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, held)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockHeldMessageRepositoryMockRecorder) Create(ctx, held any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockHeldMessageRepository)(nil).Create), ctx, held)
+}
+
+// Find mocks base method.
+func (m *MockHeldMessageRepository) Find(ctx context.Context, id int64) (*entity.HeldMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, id)
+	ret0, _ := ret[0].(*entity.HeldMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockHeldMessageRepositoryMockRecorder) Find(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockHeldMessageRepository)(nil).Find), ctx, id)
+}
+
+// Query mocks base method.
+func (m *MockHeldMessageRepository) Query(ctx context.Context, q *repo.HeldMessageQuery) ([]*entity.HeldMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", ctx, q)
+	ret0, _ := ret[0].([]*entity.HeldMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockHeldMessageRepositoryMockRecorder) Query(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockHeldMessageRepository)(nil).Query), ctx, q)
+}
+
+// Review mocks base method.
+func (m *MockHeldMessageRepository) Review(ctx context.Context, held *entity.HeldMessage) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Review", ctx, held)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Review indicates an expected call of Review.
+func (mr *MockHeldMessageRepositoryMockRecorder) Review(ctx, held any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Review", reflect.TypeOf((*MockHeldMessageRepository)(nil).Review), ctx, held)
+}
@@ -0,0 +1,177 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/repo/mail_outbox.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/repo/mail_outbox.go -destination=internal/mock/mail_outbox.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	entity "github.com/icchon/matcha/api/internal/domain/entity"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockMailOutboxQueryRepository is a mock of MailOutboxQueryRepository interface.
+type MockMailOutboxQueryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMailOutboxQueryRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockMailOutboxQueryRepositoryMockRecorder is the mock recorder for MockMailOutboxQueryRepository.
+type MockMailOutboxQueryRepositoryMockRecorder struct {
+	mock *MockMailOutboxQueryRepository
+}
+
+// NewMockMailOutboxQueryRepository creates a new mock instance.
+func NewMockMailOutboxQueryRepository(ctrl *gomock.Controller) *MockMailOutboxQueryRepository {
+	mock := &MockMailOutboxQueryRepository{ctrl: ctrl}
+	mock.recorder = &MockMailOutboxQueryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMailOutboxQueryRepository) EXPECT() *MockMailOutboxQueryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindDue mocks base method.
+func (m *MockMailOutboxQueryRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]*entity.OutboxMail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDue", ctx, now, limit)
+	ret0, _ := ret[0].([]*entity.OutboxMail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDue indicates an expected call of FindDue.
+func (mr *MockMailOutboxQueryRepositoryMockRecorder) FindDue(ctx, now, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDue", reflect.TypeOf((*MockMailOutboxQueryRepository)(nil).FindDue), ctx, now, limit)
+}
+
+// MockMailOutboxCommandRepository is a mock of MailOutboxCommandRepository interface.
+type MockMailOutboxCommandRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMailOutboxCommandRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockMailOutboxCommandRepositoryMockRecorder is the mock recorder for MockMailOutboxCommandRepository.
+type MockMailOutboxCommandRepositoryMockRecorder struct {
+	mock *MockMailOutboxCommandRepository
+}
+
+// NewMockMailOutboxCommandRepository creates a new mock instance.
+func NewMockMailOutboxCommandRepository(ctrl *gomock.Controller) *MockMailOutboxCommandRepository {
+	mock := &MockMailOutboxCommandRepository{ctrl: ctrl}
+	mock.recorder = &MockMailOutboxCommandRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMailOutboxCommandRepository) EXPECT() *MockMailOutboxCommandRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockMailOutboxCommandRepository) Create(ctx context.Context, mail *entity.OutboxMail) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, mail)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockMailOutboxCommandRepositoryMockRecorder) Create(ctx, mail any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockMailOutboxCommandRepository)(nil).Create), ctx, mail)
+}
+
+// Update mocks base method.
+func (m *MockMailOutboxCommandRepository) Update(ctx context.Context, mail *entity.OutboxMail) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, mail)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockMailOutboxCommandRepositoryMockRecorder) Update(ctx, mail any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockMailOutboxCommandRepository)(nil).Update), ctx, mail)
+}
+
+// MockMailOutboxRepository is a mock of MailOutboxRepository interface.
+type MockMailOutboxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMailOutboxRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockMailOutboxRepositoryMockRecorder is the mock recorder for MockMailOutboxRepository.
+type MockMailOutboxRepositoryMockRecorder struct {
+	mock *MockMailOutboxRepository
+}
+
+// NewMockMailOutboxRepository creates a new mock instance.
+func NewMockMailOutboxRepository(ctrl *gomock.Controller) *MockMailOutboxRepository {
+	mock := &MockMailOutboxRepository{ctrl: ctrl}
+	mock.recorder = &MockMailOutboxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMailOutboxRepository) EXPECT() *MockMailOutboxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockMailOutboxRepository) Create(ctx context.Context, mail *entity.OutboxMail) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, mail)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockMailOutboxRepositoryMockRecorder) Create(ctx, mail any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockMailOutboxRepository)(nil).Create), ctx, mail)
+}
+
+// FindDue mocks base method.
+func (m *MockMailOutboxRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]*entity.OutboxMail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDue", ctx, now, limit)
+	ret0, _ := ret[0].([]*entity.OutboxMail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDue indicates an expected call of FindDue.
+func (mr *MockMailOutboxRepositoryMockRecorder) FindDue(ctx, now, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDue", reflect.TypeOf((*MockMailOutboxRepository)(nil).FindDue), ctx, now, limit)
+}
+
+// Update mocks base method.
+func (m *MockMailOutboxRepository) Update(ctx context.Context, mail *entity.OutboxMail) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, mail)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockMailOutboxRepositoryMockRecorder) Update(ctx, mail any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockMailOutboxRepository)(nil).Update), ctx, mail)
+}
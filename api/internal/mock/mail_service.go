@@ -13,6 +13,8 @@ import (
 	context "context"
 	reflect "reflect"
 
+	repo "github.com/icchon/matcha/api/internal/domain/repo"
+	service "github.com/icchon/matcha/api/internal/domain/service"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,30 +42,98 @@ func (m *MockMailService) EXPECT() *MockMailServiceMockRecorder {
 	return m.recorder
 }
 
+// PreviewEmail mocks base method.
+func (m *MockMailService) PreviewEmail(ctx context.Context, name string) (*service.RenderedEmail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewEmail", ctx, name)
+	ret0, _ := ret[0].(*service.RenderedEmail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewEmail indicates an expected call of PreviewEmail.
+func (mr *MockMailServiceMockRecorder) PreviewEmail(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewEmail", reflect.TypeOf((*MockMailService)(nil).PreviewEmail), ctx, name)
+}
+
+// SendDigestEmail mocks base method.
+func (m *MockMailService) SendDigestEmail(ctx context.Context, rm repo.RepositoryManager, toEmail, username string, summary *service.DigestSummary, unsubscribeToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDigestEmail", ctx, rm, toEmail, username, summary, unsubscribeToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDigestEmail indicates an expected call of SendDigestEmail.
+func (mr *MockMailServiceMockRecorder) SendDigestEmail(ctx, rm, toEmail, username, summary, unsubscribeToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDigestEmail", reflect.TypeOf((*MockMailService)(nil).SendDigestEmail), ctx, rm, toEmail, username, summary, unsubscribeToken)
+}
+
 // SendPasswordResetEmail mocks base method.
-func (m *MockMailService) SendPasswordResetEmail(ctx context.Context, toEmail, token string) error {
+func (m *MockMailService) SendPasswordResetEmail(ctx context.Context, rm repo.RepositoryManager, toEmail, token string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendPasswordResetEmail", ctx, toEmail, token)
+	ret := m.ctrl.Call(m, "SendPasswordResetEmail", ctx, rm, toEmail, token)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SendPasswordResetEmail indicates an expected call of SendPasswordResetEmail.
-func (mr *MockMailServiceMockRecorder) SendPasswordResetEmail(ctx, toEmail, token any) *gomock.Call {
+func (mr *MockMailServiceMockRecorder) SendPasswordResetEmail(ctx, rm, toEmail, token any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPasswordResetEmail", reflect.TypeOf((*MockMailService)(nil).SendPasswordResetEmail), ctx, toEmail, token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPasswordResetEmail", reflect.TypeOf((*MockMailService)(nil).SendPasswordResetEmail), ctx, rm, toEmail, token)
 }
 
 // SendVerificationEmail mocks base method.
-func (m *MockMailService) SendVerificationEmail(ctx context.Context, toEmail, username, token string) error {
+func (m *MockMailService) SendVerificationEmail(ctx context.Context, rm repo.RepositoryManager, toEmail, username, token string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendVerificationEmail", ctx, toEmail, username, token)
+	ret := m.ctrl.Call(m, "SendVerificationEmail", ctx, rm, toEmail, username, token)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SendVerificationEmail indicates an expected call of SendVerificationEmail.
-func (mr *MockMailServiceMockRecorder) SendVerificationEmail(ctx, toEmail, username, token any) *gomock.Call {
+func (mr *MockMailServiceMockRecorder) SendVerificationEmail(ctx, rm, toEmail, username, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendVerificationEmail", reflect.TypeOf((*MockMailService)(nil).SendVerificationEmail), ctx, rm, toEmail, username, token)
+}
+
+// MockMailDeliveryService is a mock of MailDeliveryService interface.
+type MockMailDeliveryService struct {
+	ctrl     *gomock.Controller
+	recorder *MockMailDeliveryServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockMailDeliveryServiceMockRecorder is the mock recorder for MockMailDeliveryService.
+type MockMailDeliveryServiceMockRecorder struct {
+	mock *MockMailDeliveryService
+}
+
+// NewMockMailDeliveryService creates a new mock instance.
+func NewMockMailDeliveryService(ctrl *gomock.Controller) *MockMailDeliveryService {
+	mock := &MockMailDeliveryService{ctrl: ctrl}
+	mock.recorder = &MockMailDeliveryServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMailDeliveryService) EXPECT() *MockMailDeliveryServiceMockRecorder {
+	return m.recorder
+}
+
+// DeliverPending mocks base method.
+func (m *MockMailDeliveryService) DeliverPending(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeliverPending", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeliverPending indicates an expected call of DeliverPending.
+func (mr *MockMailDeliveryServiceMockRecorder) DeliverPending(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendVerificationEmail", reflect.TypeOf((*MockMailService)(nil).SendVerificationEmail), ctx, toEmail, username, token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeliverPending", reflect.TypeOf((*MockMailDeliveryService)(nil).DeliverPending), ctx)
 }
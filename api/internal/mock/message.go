@@ -12,6 +12,7 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	uuid "github.com/google/uuid"
 	entity "github.com/icchon/matcha/api/internal/domain/entity"
@@ -88,6 +89,21 @@ func (mr *MockMessageQueryRepositoryMockRecorder) Query(ctx, q any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockMessageQueryRepository)(nil).Query), ctx, q)
 }
 
+// Search mocks base method.
+func (m *MockMessageQueryRepository) Search(ctx context.Context, q *repo.MessageSearchQuery) ([]*entity.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, q)
+	ret0, _ := ret[0].([]*entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockMessageQueryRepositoryMockRecorder) Search(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockMessageQueryRepository)(nil).Search), ctx, q)
+}
+
 // MockMessageCommandRepository is a mock of MessageCommandRepository interface.
 type MockMessageCommandRepository struct {
 	ctrl     *gomock.Controller
@@ -154,18 +170,49 @@ func (mr *MockMessageCommandRepositoryMockRecorder) MarkAsRead(ctx, messageID an
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAsRead", reflect.TypeOf((*MockMessageCommandRepository)(nil).MarkAsRead), ctx, messageID)
 }
 
-// Update mocks base method.
-func (m *MockMessageCommandRepository) Update(ctx context.Context, message *entity.Message) error {
+// MarkConversationRead mocks base method.
+func (m *MockMessageCommandRepository) MarkConversationRead(ctx context.Context, recipientID, senderID uuid.UUID, upTo int64) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, message)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "MarkConversationRead", ctx, recipientID, senderID, upTo)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkConversationRead indicates an expected call of MarkConversationRead.
+func (mr *MockMessageCommandRepositoryMockRecorder) MarkConversationRead(ctx, recipientID, senderID, upTo any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkConversationRead", reflect.TypeOf((*MockMessageCommandRepository)(nil).MarkConversationRead), ctx, recipientID, senderID, upTo)
+}
+
+// MarkDeleted mocks base method.
+func (m *MockMessageCommandRepository) MarkDeleted(ctx context.Context, messageID int64, deletedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDeleted", ctx, messageID, deletedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkDeleted indicates an expected call of MarkDeleted.
+func (mr *MockMessageCommandRepositoryMockRecorder) MarkDeleted(ctx, messageID, deletedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDeleted", reflect.TypeOf((*MockMessageCommandRepository)(nil).MarkDeleted), ctx, messageID, deletedAt)
+}
+
+// UpdateContent mocks base method.
+func (m *MockMessageCommandRepository) UpdateContent(ctx context.Context, messageID int64, content string, editedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateContent", ctx, messageID, content, editedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// Update indicates an expected call of Update.
-func (mr *MockMessageCommandRepositoryMockRecorder) Update(ctx, message any) *gomock.Call {
+// UpdateContent indicates an expected call of UpdateContent.
+func (mr *MockMessageCommandRepositoryMockRecorder) UpdateContent(ctx, messageID, content, editedAt any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockMessageCommandRepository)(nil).Update), ctx, message)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateContent", reflect.TypeOf((*MockMessageCommandRepository)(nil).UpdateContent), ctx, messageID, content, editedAt)
 }
 
 // MockMessageRepository is a mock of MessageRepository interface.
@@ -264,6 +311,36 @@ func (mr *MockMessageRepositoryMockRecorder) MarkAsRead(ctx, messageID any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAsRead", reflect.TypeOf((*MockMessageRepository)(nil).MarkAsRead), ctx, messageID)
 }
 
+// MarkConversationRead mocks base method.
+func (m *MockMessageRepository) MarkConversationRead(ctx context.Context, recipientID, senderID uuid.UUID, upTo int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkConversationRead", ctx, recipientID, senderID, upTo)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkConversationRead indicates an expected call of MarkConversationRead.
+func (mr *MockMessageRepositoryMockRecorder) MarkConversationRead(ctx, recipientID, senderID, upTo any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkConversationRead", reflect.TypeOf((*MockMessageRepository)(nil).MarkConversationRead), ctx, recipientID, senderID, upTo)
+}
+
+// MarkDeleted mocks base method.
+func (m *MockMessageRepository) MarkDeleted(ctx context.Context, messageID int64, deletedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDeleted", ctx, messageID, deletedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkDeleted indicates an expected call of MarkDeleted.
+func (mr *MockMessageRepositoryMockRecorder) MarkDeleted(ctx, messageID, deletedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDeleted", reflect.TypeOf((*MockMessageRepository)(nil).MarkDeleted), ctx, messageID, deletedAt)
+}
+
 // Query mocks base method.
 func (m *MockMessageRepository) Query(ctx context.Context, q *repo.MessageQuery) ([]*entity.Message, error) {
 	m.ctrl.T.Helper()
@@ -279,16 +356,32 @@ func (mr *MockMessageRepositoryMockRecorder) Query(ctx, q any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockMessageRepository)(nil).Query), ctx, q)
 }
 
-// Update mocks base method.
-func (m *MockMessageRepository) Update(ctx context.Context, message *entity.Message) error {
+// Search mocks base method.
+func (m *MockMessageRepository) Search(ctx context.Context, q *repo.MessageSearchQuery) ([]*entity.Message, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, message)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "Search", ctx, q)
+	ret0, _ := ret[0].([]*entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockMessageRepositoryMockRecorder) Search(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockMessageRepository)(nil).Search), ctx, q)
+}
+
+// UpdateContent mocks base method.
+func (m *MockMessageRepository) UpdateContent(ctx context.Context, messageID int64, content string, editedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateContent", ctx, messageID, content, editedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// Update indicates an expected call of Update.
-func (mr *MockMessageRepositoryMockRecorder) Update(ctx, message any) *gomock.Call {
+// UpdateContent indicates an expected call of UpdateContent.
+func (mr *MockMessageRepositoryMockRecorder) UpdateContent(ctx, messageID, content, editedAt any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockMessageRepository)(nil).Update), ctx, message)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateContent", reflect.TypeOf((*MockMessageRepository)(nil).UpdateContent), ctx, messageID, content, editedAt)
 }
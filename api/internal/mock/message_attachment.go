@@ -0,0 +1,207 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/repo/message_attachment.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/repo/message_attachment.go -destination=internal/mock/message_attachment.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/icchon/matcha/api/internal/domain/entity"
+	repo "github.com/icchon/matcha/api/internal/domain/repo"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockMessageAttachmentQueryRepository is a mock of MessageAttachmentQueryRepository interface.
+type MockMessageAttachmentQueryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessageAttachmentQueryRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockMessageAttachmentQueryRepositoryMockRecorder is the mock recorder for MockMessageAttachmentQueryRepository.
+type MockMessageAttachmentQueryRepositoryMockRecorder struct {
+	mock *MockMessageAttachmentQueryRepository
+}
+
+// NewMockMessageAttachmentQueryRepository creates a new mock instance.
+func NewMockMessageAttachmentQueryRepository(ctrl *gomock.Controller) *MockMessageAttachmentQueryRepository {
+	mock := &MockMessageAttachmentQueryRepository{ctrl: ctrl}
+	mock.recorder = &MockMessageAttachmentQueryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessageAttachmentQueryRepository) EXPECT() *MockMessageAttachmentQueryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Find mocks base method.
+func (m *MockMessageAttachmentQueryRepository) Find(ctx context.Context, id string) (*entity.MessageAttachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, id)
+	ret0, _ := ret[0].(*entity.MessageAttachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockMessageAttachmentQueryRepositoryMockRecorder) Find(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockMessageAttachmentQueryRepository)(nil).Find), ctx, id)
+}
+
+// Query mocks base method.
+func (m *MockMessageAttachmentQueryRepository) Query(ctx context.Context, q *repo.MessageAttachmentQuery) ([]*entity.MessageAttachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", ctx, q)
+	ret0, _ := ret[0].([]*entity.MessageAttachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockMessageAttachmentQueryRepositoryMockRecorder) Query(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockMessageAttachmentQueryRepository)(nil).Query), ctx, q)
+}
+
+// MockMessageAttachmentCommandRepository is a mock of MessageAttachmentCommandRepository interface.
+type MockMessageAttachmentCommandRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessageAttachmentCommandRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockMessageAttachmentCommandRepositoryMockRecorder is the mock recorder for MockMessageAttachmentCommandRepository.
+type MockMessageAttachmentCommandRepositoryMockRecorder struct {
+	mock *MockMessageAttachmentCommandRepository
+}
+
+// NewMockMessageAttachmentCommandRepository creates a new mock instance.
+func NewMockMessageAttachmentCommandRepository(ctrl *gomock.Controller) *MockMessageAttachmentCommandRepository {
+	mock := &MockMessageAttachmentCommandRepository{ctrl: ctrl}
+	mock.recorder = &MockMessageAttachmentCommandRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessageAttachmentCommandRepository) EXPECT() *MockMessageAttachmentCommandRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AttachToMessage mocks base method.
+func (m *MockMessageAttachmentCommandRepository) AttachToMessage(ctx context.Context, id string, messageID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttachToMessage", ctx, id, messageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AttachToMessage indicates an expected call of AttachToMessage.
+func (mr *MockMessageAttachmentCommandRepositoryMockRecorder) AttachToMessage(ctx, id, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachToMessage", reflect.TypeOf((*MockMessageAttachmentCommandRepository)(nil).AttachToMessage), ctx, id, messageID)
+}
+
+// Create mocks base method.
+func (m *MockMessageAttachmentCommandRepository) Create(ctx context.Context, attachment *entity.MessageAttachment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, attachment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockMessageAttachmentCommandRepositoryMockRecorder) Create(ctx, attachment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockMessageAttachmentCommandRepository)(nil).Create), ctx, attachment)
+}
+
+// MockMessageAttachmentRepository is a mock of MessageAttachmentRepository interface.
+type MockMessageAttachmentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessageAttachmentRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockMessageAttachmentRepositoryMockRecorder is the mock recorder for MockMessageAttachmentRepository.
+type MockMessageAttachmentRepositoryMockRecorder struct {
+	mock *MockMessageAttachmentRepository
+}
+
+// NewMockMessageAttachmentRepository creates a new mock instance.
+func NewMockMessageAttachmentRepository(ctrl *gomock.Controller) *MockMessageAttachmentRepository {
+	mock := &MockMessageAttachmentRepository{ctrl: ctrl}
+	mock.recorder = &MockMessageAttachmentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessageAttachmentRepository) EXPECT() *MockMessageAttachmentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AttachToMessage mocks base method.
+func (m *MockMessageAttachmentRepository) AttachToMessage(ctx context.Context, id string, messageID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttachToMessage", ctx, id, messageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AttachToMessage indicates an expected call of AttachToMessage.
+func (mr *MockMessageAttachmentRepositoryMockRecorder) AttachToMessage(ctx, id, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachToMessage", reflect.TypeOf((*MockMessageAttachmentRepository)(nil).AttachToMessage), ctx, id, messageID)
+}
+
+// Create mocks base method.
+func (m *MockMessageAttachmentRepository) Create(ctx context.Context, attachment *entity.MessageAttachment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, attachment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockMessageAttachmentRepositoryMockRecorder) Create(ctx, attachment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockMessageAttachmentRepository)(nil).Create), ctx, attachment)
+}
+
+// Find mocks base method.
+func (m *MockMessageAttachmentRepository) Find(ctx context.Context, id string) (*entity.MessageAttachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, id)
+	ret0, _ := ret[0].(*entity.MessageAttachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockMessageAttachmentRepositoryMockRecorder) Find(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockMessageAttachmentRepository)(nil).Find), ctx, id)
+}
+
+// Query mocks base method.
+func (m *MockMessageAttachmentRepository) Query(ctx context.Context, q *repo.MessageAttachmentQuery) ([]*entity.MessageAttachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", ctx, q)
+	ret0, _ := ret[0].([]*entity.MessageAttachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockMessageAttachmentRepositoryMockRecorder) Query(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockMessageAttachmentRepository)(nil).Query), ctx, q)
+}
@@ -0,0 +1,210 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/repo/message_reaction.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/repo/message_reaction.go -destination=internal/mock/message_reaction.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	uuid "github.com/google/uuid"
+	entity "github.com/icchon/matcha/api/internal/domain/entity"
+	repo "github.com/icchon/matcha/api/internal/domain/repo"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockMessageReactionQueryRepository is a mock of MessageReactionQueryRepository interface.
+type MockMessageReactionQueryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessageReactionQueryRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockMessageReactionQueryRepositoryMockRecorder is the mock recorder for MockMessageReactionQueryRepository.
+type MockMessageReactionQueryRepositoryMockRecorder struct {
+	mock *MockMessageReactionQueryRepository
+}
+
+// NewMockMessageReactionQueryRepository creates a new mock instance.
+func NewMockMessageReactionQueryRepository(ctrl *gomock.Controller) *MockMessageReactionQueryRepository {
+	mock := &MockMessageReactionQueryRepository{ctrl: ctrl}
+	mock.recorder = &MockMessageReactionQueryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessageReactionQueryRepository) EXPECT() *MockMessageReactionQueryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Find mocks base method.
+func (m *MockMessageReactionQueryRepository) Find(ctx context.Context, messageID int64, userID uuid.UUID, emoji string) (*entity.MessageReaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, messageID, userID, emoji)
+	ret0, _ := ret[0].(*entity.MessageReaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockMessageReactionQueryRepositoryMockRecorder) Find(ctx, messageID, userID, emoji any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockMessageReactionQueryRepository)(nil).Find), ctx, messageID, userID, emoji)
+}
+
+// Query mocks base method.
+func (m *MockMessageReactionQueryRepository) Query(ctx context.Context, q *repo.MessageReactionQuery) ([]*entity.MessageReaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", ctx, q)
+	ret0, _ := ret[0].([]*entity.MessageReaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockMessageReactionQueryRepositoryMockRecorder) Query(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockMessageReactionQueryRepository)(nil).Query), ctx, q)
+}
+
+// MockMessageReactionCommandRepository is a mock of MessageReactionCommandRepository interface.
+type MockMessageReactionCommandRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessageReactionCommandRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockMessageReactionCommandRepositoryMockRecorder is the mock recorder for MockMessageReactionCommandRepository.
+type MockMessageReactionCommandRepositoryMockRecorder struct {
+	mock *MockMessageReactionCommandRepository
+}
+
+// NewMockMessageReactionCommandRepository creates a new mock instance.
+func NewMockMessageReactionCommandRepository(ctrl *gomock.Controller) *MockMessageReactionCommandRepository {
+	mock := &MockMessageReactionCommandRepository{ctrl: ctrl}
+	mock.recorder = &MockMessageReactionCommandRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessageReactionCommandRepository) EXPECT() *MockMessageReactionCommandRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockMessageReactionCommandRepository) Create(ctx context.Context, reaction *entity.MessageReaction) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, reaction)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockMessageReactionCommandRepositoryMockRecorder) Create(ctx, reaction any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockMessageReactionCommandRepository)(nil).Create), ctx, reaction)
+}
+
+// Delete mocks base method.
+func (m *MockMessageReactionCommandRepository) Delete(ctx context.Context, messageID int64, userID uuid.UUID, emoji string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, messageID, userID, emoji)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockMessageReactionCommandRepositoryMockRecorder) Delete(ctx, messageID, userID, emoji any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockMessageReactionCommandRepository)(nil).Delete), ctx, messageID, userID, emoji)
+}
+
+// MockMessageReactionRepository is a mock of MessageReactionRepository interface.
+type MockMessageReactionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessageReactionRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockMessageReactionRepositoryMockRecorder is the mock recorder for MockMessageReactionRepository.
+type MockMessageReactionRepositoryMockRecorder struct {
+	mock *MockMessageReactionRepository
+}
+
+// NewMockMessageReactionRepository creates a new mock instance.
+func NewMockMessageReactionRepository(ctrl *gomock.Controller) *MockMessageReactionRepository {
+	mock := &MockMessageReactionRepository{ctrl: ctrl}
+	mock.recorder = &MockMessageReactionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessageReactionRepository) EXPECT() *MockMessageReactionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockMessageReactionRepository) Create(ctx context.Context, reaction *entity.MessageReaction) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, reaction)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockMessageReactionRepositoryMockRecorder) Create(ctx, reaction any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockMessageReactionRepository)(nil).Create), ctx, reaction)
+}
+
+// Delete mocks base method.
+func (m *MockMessageReactionRepository) Delete(ctx context.Context, messageID int64, userID uuid.UUID, emoji string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, messageID, userID, emoji)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockMessageReactionRepositoryMockRecorder) Delete(ctx, messageID, userID, emoji any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockMessageReactionRepository)(nil).Delete), ctx, messageID, userID, emoji)
+}
+
+// Find mocks base method.
+func (m *MockMessageReactionRepository) Find(ctx context.Context, messageID int64, userID uuid.UUID, emoji string) (*entity.MessageReaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, messageID, userID, emoji)
+	ret0, _ := ret[0].(*entity.MessageReaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockMessageReactionRepositoryMockRecorder) Find(ctx, messageID, userID, emoji any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockMessageReactionRepository)(nil).Find), ctx, messageID, userID, emoji)
+}
+
+// Query mocks base method.
+func (m *MockMessageReactionRepository) Query(ctx context.Context, q *repo.MessageReactionQuery) ([]*entity.MessageReaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", ctx, q)
+	ret0, _ := ret[0].([]*entity.MessageReaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockMessageReactionRepositoryMockRecorder) Query(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockMessageReactionRepository)(nil).Query), ctx, q)
+}
@@ -0,0 +1,283 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/service/moderation.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/service/moderation.go -destination=internal/mock/moderation_service.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	image "image"
+	reflect "reflect"
+
+	uuid "github.com/google/uuid"
+	entity "github.com/icchon/matcha/api/internal/domain/entity"
+	service "github.com/icchon/matcha/api/internal/domain/service"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockMessageFilter is a mock of MessageFilter interface.
+type MockMessageFilter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessageFilterMockRecorder
+	isgomock struct{}
+}
+
+// MockMessageFilterMockRecorder is the mock recorder for MockMessageFilter.
+type MockMessageFilterMockRecorder struct {
+	mock *MockMessageFilter
+}
+
+// NewMockMessageFilter creates a new mock instance.
+func NewMockMessageFilter(ctrl *gomock.Controller) *MockMessageFilter {
+	mock := &MockMessageFilter{ctrl: ctrl}
+	mock.recorder = &MockMessageFilterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessageFilter) EXPECT() *MockMessageFilterMockRecorder {
+	return m.recorder
+}
+
+// Filter mocks base method.
+func (m *MockMessageFilter) Filter(ctx context.Context, msg *entity.Message) (*service.ModerationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Filter", ctx, msg)
+	ret0, _ := ret[0].(*service.ModerationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Filter indicates an expected call of Filter.
+func (mr *MockMessageFilterMockRecorder) Filter(ctx, msg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Filter", reflect.TypeOf((*MockMessageFilter)(nil).Filter), ctx, msg)
+}
+
+// MockModerationService is a mock of ModerationService interface.
+type MockModerationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockModerationServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockModerationServiceMockRecorder is the mock recorder for MockModerationService.
+type MockModerationServiceMockRecorder struct {
+	mock *MockModerationService
+}
+
+// NewMockModerationService creates a new mock instance.
+func NewMockModerationService(ctrl *gomock.Controller) *MockModerationService {
+	mock := &MockModerationService{ctrl: ctrl}
+	mock.recorder = &MockModerationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockModerationService) EXPECT() *MockModerationServiceMockRecorder {
+	return m.recorder
+}
+
+// ApproveHeldMessage mocks base method.
+func (m *MockModerationService) ApproveHeldMessage(ctx context.Context, reviewerID uuid.UUID, heldMessageID int64) (*entity.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveHeldMessage", ctx, reviewerID, heldMessageID)
+	ret0, _ := ret[0].(*entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveHeldMessage indicates an expected call of ApproveHeldMessage.
+func (mr *MockModerationServiceMockRecorder) ApproveHeldMessage(ctx, reviewerID, heldMessageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveHeldMessage", reflect.TypeOf((*MockModerationService)(nil).ApproveHeldMessage), ctx, reviewerID, heldMessageID)
+}
+
+// ListHeldMessages mocks base method.
+func (m *MockModerationService) ListHeldMessages(ctx context.Context, params *service.ListHeldMessagesParams) ([]*entity.HeldMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHeldMessages", ctx, params)
+	ret0, _ := ret[0].([]*entity.HeldMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHeldMessages indicates an expected call of ListHeldMessages.
+func (mr *MockModerationServiceMockRecorder) ListHeldMessages(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHeldMessages", reflect.TypeOf((*MockModerationService)(nil).ListHeldMessages), ctx, params)
+}
+
+// RejectHeldMessage mocks base method.
+func (m *MockModerationService) RejectHeldMessage(ctx context.Context, reviewerID uuid.UUID, heldMessageID int64, reason string) (*entity.HeldMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectHeldMessage", ctx, reviewerID, heldMessageID, reason)
+	ret0, _ := ret[0].(*entity.HeldMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectHeldMessage indicates an expected call of RejectHeldMessage.
+func (mr *MockModerationServiceMockRecorder) RejectHeldMessage(ctx, reviewerID, heldMessageID, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectHeldMessage", reflect.TypeOf((*MockModerationService)(nil).RejectHeldMessage), ctx, reviewerID, heldMessageID, reason)
+}
+
+// MockImageClassifier is a mock of ImageClassifier interface.
+type MockImageClassifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockImageClassifierMockRecorder
+	isgomock struct{}
+}
+
+// MockImageClassifierMockRecorder is the mock recorder for MockImageClassifier.
+type MockImageClassifierMockRecorder struct {
+	mock *MockImageClassifier
+}
+
+// NewMockImageClassifier creates a new mock instance.
+func NewMockImageClassifier(ctrl *gomock.Controller) *MockImageClassifier {
+	mock := &MockImageClassifier{ctrl: ctrl}
+	mock.recorder = &MockImageClassifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImageClassifier) EXPECT() *MockImageClassifierMockRecorder {
+	return m.recorder
+}
+
+// Classify mocks base method.
+func (m *MockImageClassifier) Classify(ctx context.Context, pic *entity.Picture, img image.Image) (*service.ImageClassification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Classify", ctx, pic, img)
+	ret0, _ := ret[0].(*service.ImageClassification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Classify indicates an expected call of Classify.
+func (mr *MockImageClassifierMockRecorder) Classify(ctx, pic, img any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Classify", reflect.TypeOf((*MockImageClassifier)(nil).Classify), ctx, pic, img)
+}
+
+// MockPictureModerationService is a mock of PictureModerationService interface.
+type MockPictureModerationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPictureModerationServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockPictureModerationServiceMockRecorder is the mock recorder for MockPictureModerationService.
+type MockPictureModerationServiceMockRecorder struct {
+	mock *MockPictureModerationService
+}
+
+// NewMockPictureModerationService creates a new mock instance.
+func NewMockPictureModerationService(ctrl *gomock.Controller) *MockPictureModerationService {
+	mock := &MockPictureModerationService{ctrl: ctrl}
+	mock.recorder = &MockPictureModerationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPictureModerationService) EXPECT() *MockPictureModerationServiceMockRecorder {
+	return m.recorder
+}
+
+// ApprovePicture mocks base method.
+func (m *MockPictureModerationService) ApprovePicture(ctx context.Context, reviewerID uuid.UUID, pictureID int32) (*entity.Picture, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApprovePicture", ctx, reviewerID, pictureID)
+	ret0, _ := ret[0].(*entity.Picture)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApprovePicture indicates an expected call of ApprovePicture.
+func (mr *MockPictureModerationServiceMockRecorder) ApprovePicture(ctx, reviewerID, pictureID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApprovePicture", reflect.TypeOf((*MockPictureModerationService)(nil).ApprovePicture), ctx, reviewerID, pictureID)
+}
+
+// ClassifyPending mocks base method.
+func (m *MockPictureModerationService) ClassifyPending(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClassifyPending", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClassifyPending indicates an expected call of ClassifyPending.
+func (mr *MockPictureModerationServiceMockRecorder) ClassifyPending(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClassifyPending", reflect.TypeOf((*MockPictureModerationService)(nil).ClassifyPending), ctx)
+}
+
+// FindFakeAccountSignal mocks base method.
+func (m *MockPictureModerationService) FindFakeAccountSignal(ctx context.Context, userID uuid.UUID) (*entity.FakeAccountSignal, []*entity.PictureMatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindFakeAccountSignal", ctx, userID)
+	ret0, _ := ret[0].(*entity.FakeAccountSignal)
+	ret1, _ := ret[1].([]*entity.PictureMatch)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindFakeAccountSignal indicates an expected call of FindFakeAccountSignal.
+func (mr *MockPictureModerationServiceMockRecorder) FindFakeAccountSignal(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindFakeAccountSignal", reflect.TypeOf((*MockPictureModerationService)(nil).FindFakeAccountSignal), ctx, userID)
+}
+
+// ListFakeAccountSignals mocks base method.
+func (m *MockPictureModerationService) ListFakeAccountSignals(ctx context.Context, params *service.ListFakeAccountSignalsParams) ([]*entity.FakeAccountSignal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFakeAccountSignals", ctx, params)
+	ret0, _ := ret[0].([]*entity.FakeAccountSignal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFakeAccountSignals indicates an expected call of ListFakeAccountSignals.
+func (mr *MockPictureModerationServiceMockRecorder) ListFakeAccountSignals(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFakeAccountSignals", reflect.TypeOf((*MockPictureModerationService)(nil).ListFakeAccountSignals), ctx, params)
+}
+
+// ListPictures mocks base method.
+func (m *MockPictureModerationService) ListPictures(ctx context.Context, reviewerID uuid.UUID, params *service.ListPicturesParams) ([]*entity.Picture, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPictures", ctx, reviewerID, params)
+	ret0, _ := ret[0].([]*entity.Picture)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPictures indicates an expected call of ListPictures.
+func (mr *MockPictureModerationServiceMockRecorder) ListPictures(ctx, reviewerID, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPictures", reflect.TypeOf((*MockPictureModerationService)(nil).ListPictures), ctx, reviewerID, params)
+}
+
+// RejectPicture mocks base method.
+func (m *MockPictureModerationService) RejectPicture(ctx context.Context, reviewerID uuid.UUID, pictureID int32, reason string) (*entity.Picture, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectPicture", ctx, reviewerID, pictureID, reason)
+	ret0, _ := ret[0].(*entity.Picture)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectPicture indicates an expected call of RejectPicture.
+func (mr *MockPictureModerationServiceMockRecorder) RejectPicture(ctx, reviewerID, pictureID, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectPicture", reflect.TypeOf((*MockPictureModerationService)(nil).RejectPicture), ctx, reviewerID, pictureID, reason)
+}
@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: domain/repo/notification.go
+// Source: internal/domain/repo/notification.go
 //
 // Generated by this command:
 //
-//	mockgen -source domain/repo/notification.go -destination mock/notification.go -package mock
+//	mockgen -source=internal/domain/repo/notification.go -destination=internal/mock/notification.go -package=mock
 //
 
 // Package mock is a generated GoMock package.
@@ -12,7 +12,9 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	uuid "github.com/google/uuid"
 	entity "github.com/icchon/matcha/api/internal/domain/entity"
 	repo "github.com/icchon/matcha/api/internal/domain/repo"
 	gomock "go.uber.org/mock/gomock"
@@ -42,6 +44,21 @@ func (m *MockNotificationQueryRepository) EXPECT() *MockNotificationQueryReposit
 	return m.recorder
 }
 
+// CountUnread mocks base method.
+func (m *MockNotificationQueryRepository) CountUnread(ctx context.Context, recipientID uuid.UUID) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnread", ctx, recipientID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnread indicates an expected call of CountUnread.
+func (mr *MockNotificationQueryRepositoryMockRecorder) CountUnread(ctx, recipientID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnread", reflect.TypeOf((*MockNotificationQueryRepository)(nil).CountUnread), ctx, recipientID)
+}
+
 // Find mocks base method.
 func (m *MockNotificationQueryRepository) Find(ctx context.Context, notificationID int64) (*entity.Notification, error) {
 	m.ctrl.T.Helper()
@@ -57,6 +74,36 @@ func (mr *MockNotificationQueryRepositoryMockRecorder) Find(ctx, notificationID
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockNotificationQueryRepository)(nil).Find), ctx, notificationID)
 }
 
+// FindDigestRecipients mocks base method.
+func (m *MockNotificationQueryRepository) FindDigestRecipients(ctx context.Context, types []entity.NotificationType, quietSince time.Time) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDigestRecipients", ctx, types, quietSince)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDigestRecipients indicates an expected call of FindDigestRecipients.
+func (mr *MockNotificationQueryRepositoryMockRecorder) FindDigestRecipients(ctx, types, quietSince any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDigestRecipients", reflect.TypeOf((*MockNotificationQueryRepository)(nil).FindDigestRecipients), ctx, types, quietSince)
+}
+
+// FindLatest mocks base method.
+func (m *MockNotificationQueryRepository) FindLatest(ctx context.Context, recipientID, senderID uuid.UUID, notifType entity.NotificationType, since time.Time) (*entity.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLatest", ctx, recipientID, senderID, notifType, since)
+	ret0, _ := ret[0].(*entity.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLatest indicates an expected call of FindLatest.
+func (mr *MockNotificationQueryRepositoryMockRecorder) FindLatest(ctx, recipientID, senderID, notifType, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLatest", reflect.TypeOf((*MockNotificationQueryRepository)(nil).FindLatest), ctx, recipientID, senderID, notifType, since)
+}
+
 // Query mocks base method.
 func (m *MockNotificationQueryRepository) Query(ctx context.Context, q *repo.NotificationQuery) ([]*entity.Notification, error) {
 	m.ctrl.T.Helper()
@@ -124,6 +171,49 @@ func (mr *MockNotificationCommandRepositoryMockRecorder) Delete(ctx, notificatio
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockNotificationCommandRepository)(nil).Delete), ctx, notificationID)
 }
 
+// Increment mocks base method.
+func (m *MockNotificationCommandRepository) Increment(ctx context.Context, notification *entity.Notification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Increment", ctx, notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Increment indicates an expected call of Increment.
+func (mr *MockNotificationCommandRepositoryMockRecorder) Increment(ctx, notification any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Increment", reflect.TypeOf((*MockNotificationCommandRepository)(nil).Increment), ctx, notification)
+}
+
+// LockAggregation mocks base method.
+func (m *MockNotificationCommandRepository) LockAggregation(ctx context.Context, recipientID, senderID uuid.UUID, notifType entity.NotificationType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockAggregation", ctx, recipientID, senderID, notifType)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockAggregation indicates an expected call of LockAggregation.
+func (mr *MockNotificationCommandRepositoryMockRecorder) LockAggregation(ctx, recipientID, senderID, notifType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockAggregation", reflect.TypeOf((*MockNotificationCommandRepository)(nil).LockAggregation), ctx, recipientID, senderID, notifType)
+}
+
+// MarkAllRead mocks base method.
+func (m *MockNotificationCommandRepository) MarkAllRead(ctx context.Context, recipientID uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAllRead", ctx, recipientID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkAllRead indicates an expected call of MarkAllRead.
+func (mr *MockNotificationCommandRepositoryMockRecorder) MarkAllRead(ctx, recipientID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAllRead", reflect.TypeOf((*MockNotificationCommandRepository)(nil).MarkAllRead), ctx, recipientID)
+}
+
 // Update mocks base method.
 func (m *MockNotificationCommandRepository) Update(ctx context.Context, notification *entity.Notification) error {
 	m.ctrl.T.Helper()
@@ -162,6 +252,21 @@ func (m *MockNotificationRepository) EXPECT() *MockNotificationRepositoryMockRec
 	return m.recorder
 }
 
+// CountUnread mocks base method.
+func (m *MockNotificationRepository) CountUnread(ctx context.Context, recipientID uuid.UUID) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnread", ctx, recipientID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnread indicates an expected call of CountUnread.
+func (mr *MockNotificationRepositoryMockRecorder) CountUnread(ctx, recipientID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnread", reflect.TypeOf((*MockNotificationRepository)(nil).CountUnread), ctx, recipientID)
+}
+
 // Create mocks base method.
 func (m *MockNotificationRepository) Create(ctx context.Context, notification *entity.Notification) error {
 	m.ctrl.T.Helper()
@@ -205,6 +310,79 @@ func (mr *MockNotificationRepositoryMockRecorder) Find(ctx, notificationID any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockNotificationRepository)(nil).Find), ctx, notificationID)
 }
 
+// FindDigestRecipients mocks base method.
+func (m *MockNotificationRepository) FindDigestRecipients(ctx context.Context, types []entity.NotificationType, quietSince time.Time) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDigestRecipients", ctx, types, quietSince)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDigestRecipients indicates an expected call of FindDigestRecipients.
+func (mr *MockNotificationRepositoryMockRecorder) FindDigestRecipients(ctx, types, quietSince any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDigestRecipients", reflect.TypeOf((*MockNotificationRepository)(nil).FindDigestRecipients), ctx, types, quietSince)
+}
+
+// FindLatest mocks base method.
+func (m *MockNotificationRepository) FindLatest(ctx context.Context, recipientID, senderID uuid.UUID, notifType entity.NotificationType, since time.Time) (*entity.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLatest", ctx, recipientID, senderID, notifType, since)
+	ret0, _ := ret[0].(*entity.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLatest indicates an expected call of FindLatest.
+func (mr *MockNotificationRepositoryMockRecorder) FindLatest(ctx, recipientID, senderID, notifType, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLatest", reflect.TypeOf((*MockNotificationRepository)(nil).FindLatest), ctx, recipientID, senderID, notifType, since)
+}
+
+// Increment mocks base method.
+func (m *MockNotificationRepository) Increment(ctx context.Context, notification *entity.Notification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Increment", ctx, notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Increment indicates an expected call of Increment.
+func (mr *MockNotificationRepositoryMockRecorder) Increment(ctx, notification any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Increment", reflect.TypeOf((*MockNotificationRepository)(nil).Increment), ctx, notification)
+}
+
+// LockAggregation mocks base method.
+func (m *MockNotificationRepository) LockAggregation(ctx context.Context, recipientID, senderID uuid.UUID, notifType entity.NotificationType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockAggregation", ctx, recipientID, senderID, notifType)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockAggregation indicates an expected call of LockAggregation.
+func (mr *MockNotificationRepositoryMockRecorder) LockAggregation(ctx, recipientID, senderID, notifType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockAggregation", reflect.TypeOf((*MockNotificationRepository)(nil).LockAggregation), ctx, recipientID, senderID, notifType)
+}
+
+// MarkAllRead mocks base method.
+func (m *MockNotificationRepository) MarkAllRead(ctx context.Context, recipientID uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAllRead", ctx, recipientID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkAllRead indicates an expected call of MarkAllRead.
+func (mr *MockNotificationRepositoryMockRecorder) MarkAllRead(ctx, recipientID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAllRead", reflect.TypeOf((*MockNotificationRepository)(nil).MarkAllRead), ctx, recipientID)
+}
+
 // Query mocks base method.
 func (m *MockNotificationRepository) Query(ctx context.Context, q *repo.NotificationQuery) ([]*entity.Notification, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,338 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/repo/notification_preference.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/repo/notification_preference.go -destination=internal/mock/notification_preference.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	uuid "github.com/google/uuid"
+	entity "github.com/icchon/matcha/api/internal/domain/entity"
+	repo "github.com/icchon/matcha/api/internal/domain/repo"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockNotificationPreferenceQueryRepository is a mock of NotificationPreferenceQueryRepository interface.
+type MockNotificationPreferenceQueryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationPreferenceQueryRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockNotificationPreferenceQueryRepositoryMockRecorder is the mock recorder for MockNotificationPreferenceQueryRepository.
+type MockNotificationPreferenceQueryRepositoryMockRecorder struct {
+	mock *MockNotificationPreferenceQueryRepository
+}
+
+// NewMockNotificationPreferenceQueryRepository creates a new mock instance.
+func NewMockNotificationPreferenceQueryRepository(ctrl *gomock.Controller) *MockNotificationPreferenceQueryRepository {
+	mock := &MockNotificationPreferenceQueryRepository{ctrl: ctrl}
+	mock.recorder = &MockNotificationPreferenceQueryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationPreferenceQueryRepository) EXPECT() *MockNotificationPreferenceQueryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Find mocks base method.
+func (m *MockNotificationPreferenceQueryRepository) Find(ctx context.Context, userID uuid.UUID, notifType entity.NotificationType) (*entity.NotificationPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, userID, notifType)
+	ret0, _ := ret[0].(*entity.NotificationPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockNotificationPreferenceQueryRepositoryMockRecorder) Find(ctx, userID, notifType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockNotificationPreferenceQueryRepository)(nil).Find), ctx, userID, notifType)
+}
+
+// Query mocks base method.
+func (m *MockNotificationPreferenceQueryRepository) Query(ctx context.Context, userID uuid.UUID) ([]*entity.NotificationPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", ctx, userID)
+	ret0, _ := ret[0].([]*entity.NotificationPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockNotificationPreferenceQueryRepositoryMockRecorder) Query(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockNotificationPreferenceQueryRepository)(nil).Query), ctx, userID)
+}
+
+// MockNotificationPreferenceCommandRepository is a mock of NotificationPreferenceCommandRepository interface.
+type MockNotificationPreferenceCommandRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationPreferenceCommandRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockNotificationPreferenceCommandRepositoryMockRecorder is the mock recorder for MockNotificationPreferenceCommandRepository.
+type MockNotificationPreferenceCommandRepositoryMockRecorder struct {
+	mock *MockNotificationPreferenceCommandRepository
+}
+
+// NewMockNotificationPreferenceCommandRepository creates a new mock instance.
+func NewMockNotificationPreferenceCommandRepository(ctrl *gomock.Controller) *MockNotificationPreferenceCommandRepository {
+	mock := &MockNotificationPreferenceCommandRepository{ctrl: ctrl}
+	mock.recorder = &MockNotificationPreferenceCommandRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationPreferenceCommandRepository) EXPECT() *MockNotificationPreferenceCommandRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Upsert mocks base method.
+func (m *MockNotificationPreferenceCommandRepository) Upsert(ctx context.Context, pref *entity.NotificationPreference) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, pref)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockNotificationPreferenceCommandRepositoryMockRecorder) Upsert(ctx, pref any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockNotificationPreferenceCommandRepository)(nil).Upsert), ctx, pref)
+}
+
+// MockNotificationPreferenceRepository is a mock of NotificationPreferenceRepository interface.
+type MockNotificationPreferenceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationPreferenceRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockNotificationPreferenceRepositoryMockRecorder is the mock recorder for MockNotificationPreferenceRepository.
+type MockNotificationPreferenceRepositoryMockRecorder struct {
+	mock *MockNotificationPreferenceRepository
+}
+
+// NewMockNotificationPreferenceRepository creates a new mock instance.
+func NewMockNotificationPreferenceRepository(ctrl *gomock.Controller) *MockNotificationPreferenceRepository {
+	mock := &MockNotificationPreferenceRepository{ctrl: ctrl}
+	mock.recorder = &MockNotificationPreferenceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationPreferenceRepository) EXPECT() *MockNotificationPreferenceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Find mocks base method.
+func (m *MockNotificationPreferenceRepository) Find(ctx context.Context, userID uuid.UUID, notifType entity.NotificationType) (*entity.NotificationPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, userID, notifType)
+	ret0, _ := ret[0].(*entity.NotificationPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockNotificationPreferenceRepositoryMockRecorder) Find(ctx, userID, notifType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockNotificationPreferenceRepository)(nil).Find), ctx, userID, notifType)
+}
+
+// Query mocks base method.
+func (m *MockNotificationPreferenceRepository) Query(ctx context.Context, userID uuid.UUID) ([]*entity.NotificationPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", ctx, userID)
+	ret0, _ := ret[0].([]*entity.NotificationPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockNotificationPreferenceRepositoryMockRecorder) Query(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockNotificationPreferenceRepository)(nil).Query), ctx, userID)
+}
+
+// Upsert mocks base method.
+func (m *MockNotificationPreferenceRepository) Upsert(ctx context.Context, pref *entity.NotificationPreference) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, pref)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockNotificationPreferenceRepositoryMockRecorder) Upsert(ctx, pref any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockNotificationPreferenceRepository)(nil).Upsert), ctx, pref)
+}
+
+// MockNotificationMuteQueryRepository is a mock of NotificationMuteQueryRepository interface.
+type MockNotificationMuteQueryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationMuteQueryRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockNotificationMuteQueryRepositoryMockRecorder is the mock recorder for MockNotificationMuteQueryRepository.
+type MockNotificationMuteQueryRepositoryMockRecorder struct {
+	mock *MockNotificationMuteQueryRepository
+}
+
+// NewMockNotificationMuteQueryRepository creates a new mock instance.
+func NewMockNotificationMuteQueryRepository(ctrl *gomock.Controller) *MockNotificationMuteQueryRepository {
+	mock := &MockNotificationMuteQueryRepository{ctrl: ctrl}
+	mock.recorder = &MockNotificationMuteQueryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationMuteQueryRepository) EXPECT() *MockNotificationMuteQueryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Query mocks base method.
+func (m *MockNotificationMuteQueryRepository) Query(ctx context.Context, q *repo.NotificationMuteQuery) ([]*entity.NotificationMute, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", ctx, q)
+	ret0, _ := ret[0].([]*entity.NotificationMute)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockNotificationMuteQueryRepositoryMockRecorder) Query(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockNotificationMuteQueryRepository)(nil).Query), ctx, q)
+}
+
+// MockNotificationMuteCommandRepository is a mock of NotificationMuteCommandRepository interface.
+type MockNotificationMuteCommandRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationMuteCommandRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockNotificationMuteCommandRepositoryMockRecorder is the mock recorder for MockNotificationMuteCommandRepository.
+type MockNotificationMuteCommandRepositoryMockRecorder struct {
+	mock *MockNotificationMuteCommandRepository
+}
+
+// NewMockNotificationMuteCommandRepository creates a new mock instance.
+func NewMockNotificationMuteCommandRepository(ctrl *gomock.Controller) *MockNotificationMuteCommandRepository {
+	mock := &MockNotificationMuteCommandRepository{ctrl: ctrl}
+	mock.recorder = &MockNotificationMuteCommandRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationMuteCommandRepository) EXPECT() *MockNotificationMuteCommandRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteAll mocks base method.
+func (m *MockNotificationMuteCommandRepository) DeleteAll(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAll", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAll indicates an expected call of DeleteAll.
+func (mr *MockNotificationMuteCommandRepositoryMockRecorder) DeleteAll(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAll", reflect.TypeOf((*MockNotificationMuteCommandRepository)(nil).DeleteAll), ctx, userID)
+}
+
+// Upsert mocks base method.
+func (m *MockNotificationMuteCommandRepository) Upsert(ctx context.Context, mute *entity.NotificationMute) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, mute)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockNotificationMuteCommandRepositoryMockRecorder) Upsert(ctx, mute any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockNotificationMuteCommandRepository)(nil).Upsert), ctx, mute)
+}
+
+// MockNotificationMuteRepository is a mock of NotificationMuteRepository interface.
+type MockNotificationMuteRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationMuteRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockNotificationMuteRepositoryMockRecorder is the mock recorder for MockNotificationMuteRepository.
+type MockNotificationMuteRepositoryMockRecorder struct {
+	mock *MockNotificationMuteRepository
+}
+
+// NewMockNotificationMuteRepository creates a new mock instance.
+func NewMockNotificationMuteRepository(ctrl *gomock.Controller) *MockNotificationMuteRepository {
+	mock := &MockNotificationMuteRepository{ctrl: ctrl}
+	mock.recorder = &MockNotificationMuteRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationMuteRepository) EXPECT() *MockNotificationMuteRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteAll mocks base method.
+func (m *MockNotificationMuteRepository) DeleteAll(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAll", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAll indicates an expected call of DeleteAll.
+func (mr *MockNotificationMuteRepositoryMockRecorder) DeleteAll(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAll", reflect.TypeOf((*MockNotificationMuteRepository)(nil).DeleteAll), ctx, userID)
+}
+
+// Query mocks base method.
+func (m *MockNotificationMuteRepository) Query(ctx context.Context, q *repo.NotificationMuteQuery) ([]*entity.NotificationMute, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", ctx, q)
+	ret0, _ := ret[0].([]*entity.NotificationMute)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockNotificationMuteRepositoryMockRecorder) Query(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockNotificationMuteRepository)(nil).Query), ctx, q)
+}
+
+// Upsert mocks base method.
+func (m *MockNotificationMuteRepository) Upsert(ctx context.Context, mute *entity.NotificationMute) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, mute)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockNotificationMuteRepositoryMockRecorder) Upsert(ctx, mute any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockNotificationMuteRepository)(nil).Upsert), ctx, mute)
+}
@@ -15,6 +15,7 @@ import (
 
 	uuid "github.com/google/uuid"
 	entity "github.com/icchon/matcha/api/internal/domain/entity"
+	service "github.com/icchon/matcha/api/internal/domain/service"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -57,17 +58,91 @@ func (mr *MockNotificationServiceMockRecorder) CreateAndSendNotification(ctx, se
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAndSendNotification", reflect.TypeOf((*MockNotificationService)(nil).CreateAndSendNotification), ctx, senderID, recipiendID, notifType)
 }
 
+// DeleteNotification mocks base method.
+func (m *MockNotificationService) DeleteNotification(ctx context.Context, userID uuid.UUID, notificationID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNotification", ctx, userID, notificationID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteNotification indicates an expected call of DeleteNotification.
+func (mr *MockNotificationServiceMockRecorder) DeleteNotification(ctx, userID, notificationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNotification", reflect.TypeOf((*MockNotificationService)(nil).DeleteNotification), ctx, userID, notificationID)
+}
+
 // GetNotifications mocks base method.
-func (m *MockNotificationService) GetNotifications(ctx context.Context, recipientID uuid.UUID) ([]*entity.Notification, error) {
+func (m *MockNotificationService) GetNotifications(ctx context.Context, params *service.GetNotificationsParams) (*service.NotificationPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetNotifications", ctx, recipientID)
-	ret0, _ := ret[0].([]*entity.Notification)
+	ret := m.ctrl.Call(m, "GetNotifications", ctx, params)
+	ret0, _ := ret[0].(*service.NotificationPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetNotifications indicates an expected call of GetNotifications.
-func (mr *MockNotificationServiceMockRecorder) GetNotifications(ctx, recipientID any) *gomock.Call {
+func (mr *MockNotificationServiceMockRecorder) GetNotifications(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifications", reflect.TypeOf((*MockNotificationService)(nil).GetNotifications), ctx, params)
+}
+
+// GetSettings mocks base method.
+func (m *MockNotificationService) GetSettings(ctx context.Context, userID uuid.UUID) (*service.NotificationSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSettings", ctx, userID)
+	ret0, _ := ret[0].(*service.NotificationSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSettings indicates an expected call of GetSettings.
+func (mr *MockNotificationServiceMockRecorder) GetSettings(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSettings", reflect.TypeOf((*MockNotificationService)(nil).GetSettings), ctx, userID)
+}
+
+// MarkAllNotificationsRead mocks base method.
+func (m *MockNotificationService) MarkAllNotificationsRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAllNotificationsRead", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkAllNotificationsRead indicates an expected call of MarkAllNotificationsRead.
+func (mr *MockNotificationServiceMockRecorder) MarkAllNotificationsRead(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAllNotificationsRead", reflect.TypeOf((*MockNotificationService)(nil).MarkAllNotificationsRead), ctx, userID)
+}
+
+// MarkNotificationRead mocks base method.
+func (m *MockNotificationService) MarkNotificationRead(ctx context.Context, userID uuid.UUID, notificationID int64, isRead bool) (*service.NotificationItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkNotificationRead", ctx, userID, notificationID, isRead)
+	ret0, _ := ret[0].(*service.NotificationItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkNotificationRead indicates an expected call of MarkNotificationRead.
+func (mr *MockNotificationServiceMockRecorder) MarkNotificationRead(ctx, userID, notificationID, isRead any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNotificationRead", reflect.TypeOf((*MockNotificationService)(nil).MarkNotificationRead), ctx, userID, notificationID, isRead)
+}
+
+// UpdateSettings mocks base method.
+func (m *MockNotificationService) UpdateSettings(ctx context.Context, userID uuid.UUID, settings *service.NotificationSettings) (*service.NotificationSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSettings", ctx, userID, settings)
+	ret0, _ := ret[0].(*service.NotificationSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSettings indicates an expected call of UpdateSettings.
+func (mr *MockNotificationServiceMockRecorder) UpdateSettings(ctx, userID, settings any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifications", reflect.TypeOf((*MockNotificationService)(nil).GetNotifications), ctx, recipientID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSettings", reflect.TypeOf((*MockNotificationService)(nil).UpdateSettings), ctx, userID, settings)
 }
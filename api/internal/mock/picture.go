@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: domain/repo/picture.go
+// Source: internal/domain/repo/picture.go
 //
 // Generated by this command:
 //
-//	mockgen -source domain/repo/picture.go -destination mock/picture.go -package mock
+//	mockgen -source=internal/domain/repo/picture.go -destination=internal/mock/picture.go -package=mock
 //
 
 // Package mock is a generated GoMock package.
@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	uuid "github.com/google/uuid"
 	entity "github.com/icchon/matcha/api/internal/domain/entity"
 	repo "github.com/icchon/matcha/api/internal/domain/repo"
 	gomock "go.uber.org/mock/gomock"
@@ -57,6 +58,21 @@ func (mr *MockPictureQueryRepositoryMockRecorder) Find(ctx, pictureID any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockPictureQueryRepository)(nil).Find), ctx, pictureID)
 }
 
+// FindSimilar mocks base method.
+func (m *MockPictureQueryRepository) FindSimilar(ctx context.Context, userID uuid.UUID, dhash, phash int64, maxDistance int) ([]*entity.Picture, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSimilar", ctx, userID, dhash, phash, maxDistance)
+	ret0, _ := ret[0].([]*entity.Picture)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSimilar indicates an expected call of FindSimilar.
+func (mr *MockPictureQueryRepositoryMockRecorder) FindSimilar(ctx, userID, dhash, phash, maxDistance any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSimilar", reflect.TypeOf((*MockPictureQueryRepository)(nil).FindSimilar), ctx, userID, dhash, phash, maxDistance)
+}
+
 // Query mocks base method.
 func (m *MockPictureQueryRepository) Query(ctx context.Context, q *repo.PictureQuery) ([]*entity.Picture, error) {
 	m.ctrl.T.Helper()
@@ -72,6 +88,21 @@ func (mr *MockPictureQueryRepositoryMockRecorder) Query(ctx, q any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockPictureQueryRepository)(nil).Query), ctx, q)
 }
 
+// QueryModeration mocks base method.
+func (m *MockPictureQueryRepository) QueryModeration(ctx context.Context, q *repo.PictureModerationQuery) ([]*entity.Picture, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryModeration", ctx, q)
+	ret0, _ := ret[0].([]*entity.Picture)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryModeration indicates an expected call of QueryModeration.
+func (mr *MockPictureQueryRepositoryMockRecorder) QueryModeration(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryModeration", reflect.TypeOf((*MockPictureQueryRepository)(nil).QueryModeration), ctx, q)
+}
+
 // MockPictureCommandRepository is a mock of PictureCommandRepository interface.
 type MockPictureCommandRepository struct {
 	ctrl     *gomock.Controller
@@ -96,6 +127,21 @@ func (m *MockPictureCommandRepository) EXPECT() *MockPictureCommandRepositoryMoc
 	return m.recorder
 }
 
+// Classify mocks base method.
+func (m *MockPictureCommandRepository) Classify(ctx context.Context, picture *entity.Picture) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Classify", ctx, picture)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Classify indicates an expected call of Classify.
+func (mr *MockPictureCommandRepositoryMockRecorder) Classify(ctx, picture any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Classify", reflect.TypeOf((*MockPictureCommandRepository)(nil).Classify), ctx, picture)
+}
+
 // Create mocks base method.
 func (m *MockPictureCommandRepository) Create(ctx context.Context, picture *entity.Picture) error {
 	m.ctrl.T.Helper()
@@ -124,6 +170,35 @@ func (mr *MockPictureCommandRepositoryMockRecorder) Delete(ctx, pictureID any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPictureCommandRepository)(nil).Delete), ctx, pictureID)
 }
 
+// LockByUser mocks base method.
+func (m *MockPictureCommandRepository) LockByUser(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockByUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockByUser indicates an expected call of LockByUser.
+func (mr *MockPictureCommandRepositoryMockRecorder) LockByUser(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockByUser", reflect.TypeOf((*MockPictureCommandRepository)(nil).LockByUser), ctx, userID)
+}
+
+// Review mocks base method.
+func (m *MockPictureCommandRepository) Review(ctx context.Context, picture *entity.Picture) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Review", ctx, picture)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Review indicates an expected call of Review.
+func (mr *MockPictureCommandRepositoryMockRecorder) Review(ctx, picture any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Review", reflect.TypeOf((*MockPictureCommandRepository)(nil).Review), ctx, picture)
+}
+
 // Update mocks base method.
 func (m *MockPictureCommandRepository) Update(ctx context.Context, picture *entity.Picture) error {
 	m.ctrl.T.Helper()
@@ -162,6 +237,21 @@ func (m *MockPictureRepository) EXPECT() *MockPictureRepositoryMockRecorder {
 	return m.recorder
 }
 
+// Classify mocks base method.
+func (m *MockPictureRepository) Classify(ctx context.Context, picture *entity.Picture) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Classify", ctx, picture)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Classify indicates an expected call of Classify.
+func (mr *MockPictureRepositoryMockRecorder) Classify(ctx, picture any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Classify", reflect.TypeOf((*MockPictureRepository)(nil).Classify), ctx, picture)
+}
+
 // Create mocks base method.
 func (m *MockPictureRepository) Create(ctx context.Context, picture *entity.Picture) error {
 	m.ctrl.T.Helper()
@@ -205,6 +295,35 @@ func (mr *MockPictureRepositoryMockRecorder) Find(ctx, pictureID any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockPictureRepository)(nil).Find), ctx, pictureID)
 }
 
+// FindSimilar mocks base method.
+func (m *MockPictureRepository) FindSimilar(ctx context.Context, userID uuid.UUID, dhash, phash int64, maxDistance int) ([]*entity.Picture, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSimilar", ctx, userID, dhash, phash, maxDistance)
+	ret0, _ := ret[0].([]*entity.Picture)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSimilar indicates an expected call of FindSimilar.
+func (mr *MockPictureRepositoryMockRecorder) FindSimilar(ctx, userID, dhash, phash, maxDistance any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSimilar", reflect.TypeOf((*MockPictureRepository)(nil).FindSimilar), ctx, userID, dhash, phash, maxDistance)
+}
+
+// LockByUser mocks base method.
+func (m *MockPictureRepository) LockByUser(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockByUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockByUser indicates an expected call of LockByUser.
+func (mr *MockPictureRepositoryMockRecorder) LockByUser(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockByUser", reflect.TypeOf((*MockPictureRepository)(nil).LockByUser), ctx, userID)
+}
+
 // Query mocks base method.
 func (m *MockPictureRepository) Query(ctx context.Context, q *repo.PictureQuery) ([]*entity.Picture, error) {
 	m.ctrl.T.Helper()
@@ -220,6 +339,36 @@ func (mr *MockPictureRepositoryMockRecorder) Query(ctx, q any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockPictureRepository)(nil).Query), ctx, q)
 }
 
+// QueryModeration mocks base method.
+func (m *MockPictureRepository) QueryModeration(ctx context.Context, q *repo.PictureModerationQuery) ([]*entity.Picture, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryModeration", ctx, q)
+	ret0, _ := ret[0].([]*entity.Picture)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryModeration indicates an expected call of QueryModeration.
+func (mr *MockPictureRepositoryMockRecorder) QueryModeration(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryModeration", reflect.TypeOf((*MockPictureRepository)(nil).QueryModeration), ctx, q)
+}
+
+// Review mocks base method.
+func (m *MockPictureRepository) Review(ctx context.Context, picture *entity.Picture) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Review", ctx, picture)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Review indicates an expected call of Review.
+func (mr *MockPictureRepositoryMockRecorder) Review(ctx, picture any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Review", reflect.TypeOf((*MockPictureRepository)(nil).Review), ctx, picture)
+}
+
 // Update mocks base method.
 func (m *MockPictureRepository) Update(ctx context.Context, picture *entity.Picture) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,179 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/repo/picture_match.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/repo/picture_match.go -destination=internal/mock/picture_match.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/icchon/matcha/api/internal/domain/entity"
+	repo "github.com/icchon/matcha/api/internal/domain/repo"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPictureMatchQueryRepository is a mock of PictureMatchQueryRepository interface.
+type MockPictureMatchQueryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPictureMatchQueryRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPictureMatchQueryRepositoryMockRecorder is the mock recorder for MockPictureMatchQueryRepository.
+type MockPictureMatchQueryRepositoryMockRecorder struct {
+	mock *MockPictureMatchQueryRepository
+}
+
+// NewMockPictureMatchQueryRepository creates a new mock instance.
+func NewMockPictureMatchQueryRepository(ctrl *gomock.Controller) *MockPictureMatchQueryRepository {
+	mock := &MockPictureMatchQueryRepository{ctrl: ctrl}
+	mock.recorder = &MockPictureMatchQueryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPictureMatchQueryRepository) EXPECT() *MockPictureMatchQueryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Query mocks base method.
+func (m *MockPictureMatchQueryRepository) Query(ctx context.Context, q *repo.PictureMatchQuery) ([]*entity.PictureMatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", ctx, q)
+	ret0, _ := ret[0].([]*entity.PictureMatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockPictureMatchQueryRepositoryMockRecorder) Query(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockPictureMatchQueryRepository)(nil).Query), ctx, q)
+}
+
+// QueryFakeAccountSignals mocks base method.
+func (m *MockPictureMatchQueryRepository) QueryFakeAccountSignals(ctx context.Context, q *repo.FakeAccountSignalQuery) ([]*entity.FakeAccountSignal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryFakeAccountSignals", ctx, q)
+	ret0, _ := ret[0].([]*entity.FakeAccountSignal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryFakeAccountSignals indicates an expected call of QueryFakeAccountSignals.
+func (mr *MockPictureMatchQueryRepositoryMockRecorder) QueryFakeAccountSignals(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryFakeAccountSignals", reflect.TypeOf((*MockPictureMatchQueryRepository)(nil).QueryFakeAccountSignals), ctx, q)
+}
+
+// MockPictureMatchCommandRepository is a mock of PictureMatchCommandRepository interface.
+type MockPictureMatchCommandRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPictureMatchCommandRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPictureMatchCommandRepositoryMockRecorder is the mock recorder for MockPictureMatchCommandRepository.
+type MockPictureMatchCommandRepositoryMockRecorder struct {
+	mock *MockPictureMatchCommandRepository
+}
+
+// NewMockPictureMatchCommandRepository creates a new mock instance.
+func NewMockPictureMatchCommandRepository(ctrl *gomock.Controller) *MockPictureMatchCommandRepository {
+	mock := &MockPictureMatchCommandRepository{ctrl: ctrl}
+	mock.recorder = &MockPictureMatchCommandRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPictureMatchCommandRepository) EXPECT() *MockPictureMatchCommandRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockPictureMatchCommandRepository) Create(ctx context.Context, match *entity.PictureMatch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, match)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockPictureMatchCommandRepositoryMockRecorder) Create(ctx, match any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPictureMatchCommandRepository)(nil).Create), ctx, match)
+}
+
+// MockPictureMatchRepository is a mock of PictureMatchRepository interface.
+type MockPictureMatchRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPictureMatchRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPictureMatchRepositoryMockRecorder is the mock recorder for MockPictureMatchRepository.
+type MockPictureMatchRepositoryMockRecorder struct {
+	mock *MockPictureMatchRepository
+}
+
+// NewMockPictureMatchRepository creates a new mock instance.
+func NewMockPictureMatchRepository(ctrl *gomock.Controller) *MockPictureMatchRepository {
+	mock := &MockPictureMatchRepository{ctrl: ctrl}
+	mock.recorder = &MockPictureMatchRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPictureMatchRepository) EXPECT() *MockPictureMatchRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockPictureMatchRepository) Create(ctx context.Context, match *entity.PictureMatch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, match)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockPictureMatchRepositoryMockRecorder) Create(ctx, match any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPictureMatchRepository)(nil).Create), ctx, match)
+}
+
+// Query mocks base method.
+func (m *MockPictureMatchRepository) Query(ctx context.Context, q *repo.PictureMatchQuery) ([]*entity.PictureMatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", ctx, q)
+	ret0, _ := ret[0].([]*entity.PictureMatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockPictureMatchRepositoryMockRecorder) Query(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockPictureMatchRepository)(nil).Query), ctx, q)
+}
+
+// QueryFakeAccountSignals mocks base method.
+func (m *MockPictureMatchRepository) QueryFakeAccountSignals(ctx context.Context, q *repo.FakeAccountSignalQuery) ([]*entity.FakeAccountSignal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryFakeAccountSignals", ctx, q)
+	ret0, _ := ret[0].([]*entity.FakeAccountSignal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryFakeAccountSignals indicates an expected call of QueryFakeAccountSignals.
+func (mr *MockPictureMatchRepositoryMockRecorder) QueryFakeAccountSignals(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryFakeAccountSignals", reflect.TypeOf((*MockPictureMatchRepository)(nil).QueryFakeAccountSignals), ctx, q)
+}
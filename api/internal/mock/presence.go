@@ -0,0 +1,126 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/repo/presence.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/repo/presence.go -destination=internal/mock/presence.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPresenceQueryRepository is a mock of PresenceQueryRepository interface.
+type MockPresenceQueryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPresenceQueryRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPresenceQueryRepositoryMockRecorder is the mock recorder for MockPresenceQueryRepository.
+type MockPresenceQueryRepositoryMockRecorder struct {
+	mock *MockPresenceQueryRepository
+}
+
+// NewMockPresenceQueryRepository creates a new mock instance.
+func NewMockPresenceQueryRepository(ctrl *gomock.Controller) *MockPresenceQueryRepository {
+	mock := &MockPresenceQueryRepository{ctrl: ctrl}
+	mock.recorder = &MockPresenceQueryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPresenceQueryRepository) EXPECT() *MockPresenceQueryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindOnline mocks base method.
+func (m *MockPresenceQueryRepository) FindOnline(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOnline", ctx, userIDs)
+	ret0, _ := ret[0].(map[uuid.UUID]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOnline indicates an expected call of FindOnline.
+func (mr *MockPresenceQueryRepositoryMockRecorder) FindOnline(ctx, userIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOnline", reflect.TypeOf((*MockPresenceQueryRepository)(nil).FindOnline), ctx, userIDs)
+}
+
+// IsOnline mocks base method.
+func (m *MockPresenceQueryRepository) IsOnline(ctx context.Context, userID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsOnline", ctx, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsOnline indicates an expected call of IsOnline.
+func (mr *MockPresenceQueryRepositoryMockRecorder) IsOnline(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsOnline", reflect.TypeOf((*MockPresenceQueryRepository)(nil).IsOnline), ctx, userID)
+}
+
+// MockPresenceRepository is a mock of PresenceRepository interface.
+type MockPresenceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPresenceRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPresenceRepositoryMockRecorder is the mock recorder for MockPresenceRepository.
+type MockPresenceRepositoryMockRecorder struct {
+	mock *MockPresenceRepository
+}
+
+// NewMockPresenceRepository creates a new mock instance.
+func NewMockPresenceRepository(ctrl *gomock.Controller) *MockPresenceRepository {
+	mock := &MockPresenceRepository{ctrl: ctrl}
+	mock.recorder = &MockPresenceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPresenceRepository) EXPECT() *MockPresenceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindOnline mocks base method.
+func (m *MockPresenceRepository) FindOnline(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOnline", ctx, userIDs)
+	ret0, _ := ret[0].(map[uuid.UUID]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOnline indicates an expected call of FindOnline.
+func (mr *MockPresenceRepositoryMockRecorder) FindOnline(ctx, userIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOnline", reflect.TypeOf((*MockPresenceRepository)(nil).FindOnline), ctx, userIDs)
+}
+
+// IsOnline mocks base method.
+func (m *MockPresenceRepository) IsOnline(ctx context.Context, userID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsOnline", ctx, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsOnline indicates an expected call of IsOnline.
+func (mr *MockPresenceRepositoryMockRecorder) IsOnline(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsOnline", reflect.TypeOf((*MockPresenceRepository)(nil).IsOnline), ctx, userID)
+}
@@ -14,6 +14,7 @@ import (
 	reflect "reflect"
 
 	uuid "github.com/google/uuid"
+	client "github.com/icchon/matcha/api/internal/domain/client"
 	entity "github.com/icchon/matcha/api/internal/domain/entity"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -42,6 +43,20 @@ func (m *MockProfileService) EXPECT() *MockProfileServiceMockRecorder {
 	return m.recorder
 }
 
+// AvatarURL mocks base method.
+func (m *MockProfileService) AvatarURL(userID uuid.UUID) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AvatarURL", userID)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// AvatarURL indicates an expected call of AvatarURL.
+func (mr *MockProfileServiceMockRecorder) AvatarURL(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AvatarURL", reflect.TypeOf((*MockProfileService)(nil).AvatarURL), userID)
+}
+
 // CreateProfile mocks base method.
 func (m *MockProfileService) CreateProfile(ctx context.Context, profile *entity.UserProfile) (*entity.UserProfile, error) {
 	m.ctrl.T.Helper()
@@ -87,18 +102,18 @@ func (mr *MockProfileServiceMockRecorder) FindPicture(ctx, pictureID any) *gomoc
 }
 
 // FindPictures mocks base method.
-func (m *MockProfileService) FindPictures(ctx context.Context, userID uuid.UUID) ([]*entity.Picture, error) {
+func (m *MockProfileService) FindPictures(ctx context.Context, viewerID, userID uuid.UUID) ([]*entity.Picture, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindPictures", ctx, userID)
+	ret := m.ctrl.Call(m, "FindPictures", ctx, viewerID, userID)
 	ret0, _ := ret[0].([]*entity.Picture)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindPictures indicates an expected call of FindPictures.
-func (mr *MockProfileServiceMockRecorder) FindPictures(ctx, userID any) *gomock.Call {
+func (mr *MockProfileServiceMockRecorder) FindPictures(ctx, viewerID, userID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPictures", reflect.TypeOf((*MockProfileService)(nil).FindPictures), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPictures", reflect.TypeOf((*MockProfileService)(nil).FindPictures), ctx, viewerID, userID)
 }
 
 // FindProfile mocks base method.
@@ -176,18 +191,34 @@ func (mr *MockProfileServiceMockRecorder) RecommendProfiles(ctx, selfUserID any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecommendProfiles", reflect.TypeOf((*MockProfileService)(nil).RecommendProfiles), ctx, selfUserID)
 }
 
-// UpdatePictureStatus mocks base method.
-func (m *MockProfileService) UpdatePictureStatus(ctx context.Context, userID uuid.UUID, pictureID int32, isProfilePic bool) error {
+// ReorderPictures mocks base method.
+func (m *MockProfileService) ReorderPictures(ctx context.Context, userID uuid.UUID, pictureIDs []int32) ([]*entity.Picture, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePictureStatus", ctx, userID, pictureID, isProfilePic)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ReorderPictures", ctx, userID, pictureIDs)
+	ret0, _ := ret[0].([]*entity.Picture)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReorderPictures indicates an expected call of ReorderPictures.
+func (mr *MockProfileServiceMockRecorder) ReorderPictures(ctx, userID, pictureIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorderPictures", reflect.TypeOf((*MockProfileService)(nil).ReorderPictures), ctx, userID, pictureIDs)
+}
+
+// SetProfilePicture mocks base method.
+func (m *MockProfileService) SetProfilePicture(ctx context.Context, userID uuid.UUID, pictureID int32) ([]*entity.Picture, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetProfilePicture", ctx, userID, pictureID)
+	ret0, _ := ret[0].([]*entity.Picture)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// UpdatePictureStatus indicates an expected call of UpdatePictureStatus.
-func (mr *MockProfileServiceMockRecorder) UpdatePictureStatus(ctx, userID, pictureID, isProfilePic any) *gomock.Call {
+// SetProfilePicture indicates an expected call of SetProfilePicture.
+func (mr *MockProfileServiceMockRecorder) SetProfilePicture(ctx, userID, pictureID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePictureStatus", reflect.TypeOf((*MockProfileService)(nil).UpdatePictureStatus), ctx, userID, pictureID, isProfilePic)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProfilePicture", reflect.TypeOf((*MockProfileService)(nil).SetProfilePicture), ctx, userID, pictureID)
 }
 
 // UpdateProfile mocks base method.
@@ -206,7 +237,7 @@ func (mr *MockProfileServiceMockRecorder) UpdateProfile(ctx, userID, profile any
 }
 
 // UploadPicture mocks base method.
-func (m *MockProfileService) UploadPicture(ctx context.Context, userID uuid.UUID, image []byte) (*entity.Picture, error) {
+func (m *MockProfileService) UploadPicture(ctx context.Context, userID uuid.UUID, image *client.FileSource) (*entity.Picture, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UploadPicture", ctx, userID, image)
 	ret0, _ := ret[0].(*entity.Picture)
@@ -221,7 +252,7 @@ func (mr *MockProfileServiceMockRecorder) UploadPicture(ctx, userID, image any)
 }
 
 // UploadPicutures mocks base method.
-func (m *MockProfileService) UploadPicutures(ctx context.Context, userID uuid.UUID, images [][]byte) ([]*entity.Picture, error) {
+func (m *MockProfileService) UploadPicutures(ctx context.Context, userID uuid.UUID, images []*client.FileSource) ([]*entity.Picture, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UploadPicutures", ctx, userID, images)
 	ret0, _ := ret[0].([]*entity.Picture)
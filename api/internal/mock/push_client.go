@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/client/push.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/client/push.go -destination=internal/mock/push_client.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	client "github.com/icchon/matcha/api/internal/domain/client"
+	entity "github.com/icchon/matcha/api/internal/domain/entity"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPushClient is a mock of PushClient interface.
+type MockPushClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockPushClientMockRecorder
+	isgomock struct{}
+}
+
+// MockPushClientMockRecorder is the mock recorder for MockPushClient.
+type MockPushClientMockRecorder struct {
+	mock *MockPushClient
+}
+
+// NewMockPushClient creates a new mock instance.
+func NewMockPushClient(ctrl *gomock.Controller) *MockPushClient {
+	mock := &MockPushClient{ctrl: ctrl}
+	mock.recorder = &MockPushClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPushClient) EXPECT() *MockPushClientMockRecorder {
+	return m.recorder
+}
+
+// PublicKey mocks base method.
+func (m *MockPushClient) PublicKey() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublicKey")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// PublicKey indicates an expected call of PublicKey.
+func (mr *MockPushClientMockRecorder) PublicKey() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublicKey", reflect.TypeOf((*MockPushClient)(nil).PublicKey))
+}
+
+// Send mocks base method.
+func (m *MockPushClient) Send(ctx context.Context, sub *entity.PushSubscription, msg *client.PushMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, sub, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockPushClientMockRecorder) Send(ctx, sub, msg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockPushClient)(nil).Send), ctx, sub, msg)
+}
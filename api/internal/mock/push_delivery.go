@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/repo/push_delivery.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/repo/push_delivery.go -destination=internal/mock/push_delivery.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPushDeliveryRepository is a mock of PushDeliveryRepository interface.
+type MockPushDeliveryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPushDeliveryRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPushDeliveryRepositoryMockRecorder is the mock recorder for MockPushDeliveryRepository.
+type MockPushDeliveryRepositoryMockRecorder struct {
+	mock *MockPushDeliveryRepository
+}
+
+// NewMockPushDeliveryRepository creates a new mock instance.
+func NewMockPushDeliveryRepository(ctrl *gomock.Controller) *MockPushDeliveryRepository {
+	mock := &MockPushDeliveryRepository{ctrl: ctrl}
+	mock.recorder = &MockPushDeliveryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPushDeliveryRepository) EXPECT() *MockPushDeliveryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Claim mocks base method.
+func (m *MockPushDeliveryRepository) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Claim", ctx, key, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Claim indicates an expected call of Claim.
+func (mr *MockPushDeliveryRepositoryMockRecorder) Claim(ctx, key, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*MockPushDeliveryRepository)(nil).Claim), ctx, key, ttl)
+}
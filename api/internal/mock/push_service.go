@@ -0,0 +1,141 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/service/push.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/service/push.go -destination=internal/mock/push_service.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	uuid "github.com/google/uuid"
+	client "github.com/icchon/matcha/api/internal/domain/client"
+	entity "github.com/icchon/matcha/api/internal/domain/entity"
+	service "github.com/icchon/matcha/api/internal/domain/service"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPushService is a mock of PushService interface.
+type MockPushService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPushServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockPushServiceMockRecorder is the mock recorder for MockPushService.
+type MockPushServiceMockRecorder struct {
+	mock *MockPushService
+}
+
+// NewMockPushService creates a new mock instance.
+func NewMockPushService(ctrl *gomock.Controller) *MockPushService {
+	mock := &MockPushService{ctrl: ctrl}
+	mock.recorder = &MockPushServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPushService) EXPECT() *MockPushServiceMockRecorder {
+	return m.recorder
+}
+
+// GetPublicKey mocks base method.
+func (m *MockPushService) GetPublicKey(ctx context.Context) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublicKey", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPublicKey indicates an expected call of GetPublicKey.
+func (mr *MockPushServiceMockRecorder) GetPublicKey(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublicKey", reflect.TypeOf((*MockPushService)(nil).GetPublicKey), ctx)
+}
+
+// Subscribe mocks base method.
+func (m *MockPushService) Subscribe(ctx context.Context, userID uuid.UUID, params *service.PushSubscriptionParams) (*entity.PushSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx, userID, params)
+	ret0, _ := ret[0].(*entity.PushSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockPushServiceMockRecorder) Subscribe(ctx, userID, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockPushService)(nil).Subscribe), ctx, userID, params)
+}
+
+// Unsubscribe mocks base method.
+func (m *MockPushService) Unsubscribe(ctx context.Context, userID uuid.UUID, endpoint string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unsubscribe", ctx, userID, endpoint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe.
+func (mr *MockPushServiceMockRecorder) Unsubscribe(ctx, userID, endpoint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockPushService)(nil).Unsubscribe), ctx, userID, endpoint)
+}
+
+// MockPushDeliveryService is a mock of PushDeliveryService interface.
+type MockPushDeliveryService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPushDeliveryServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockPushDeliveryServiceMockRecorder is the mock recorder for MockPushDeliveryService.
+type MockPushDeliveryServiceMockRecorder struct {
+	mock *MockPushDeliveryService
+}
+
+// NewMockPushDeliveryService creates a new mock instance.
+func NewMockPushDeliveryService(ctrl *gomock.Controller) *MockPushDeliveryService {
+	mock := &MockPushDeliveryService{ctrl: ctrl}
+	mock.recorder = &MockPushDeliveryServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPushDeliveryService) EXPECT() *MockPushDeliveryServiceMockRecorder {
+	return m.recorder
+}
+
+// PushChatMessage mocks base method.
+func (m *MockPushDeliveryService) PushChatMessage(ctx context.Context, payload *client.MessagePayload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PushChatMessage", ctx, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PushChatMessage indicates an expected call of PushChatMessage.
+func (mr *MockPushDeliveryServiceMockRecorder) PushChatMessage(ctx, payload any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PushChatMessage", reflect.TypeOf((*MockPushDeliveryService)(nil).PushChatMessage), ctx, payload)
+}
+
+// PushNotification mocks base method.
+func (m *MockPushDeliveryService) PushNotification(ctx context.Context, payload *client.NotificationPayload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PushNotification", ctx, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PushNotification indicates an expected call of PushNotification.
+func (mr *MockPushDeliveryServiceMockRecorder) PushNotification(ctx, payload any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PushNotification", reflect.TypeOf((*MockPushDeliveryService)(nil).PushNotification), ctx, payload)
+}
@@ -0,0 +1,177 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/repo/push_subscription.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/repo/push_subscription.go -destination=internal/mock/push_subscription.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	uuid "github.com/google/uuid"
+	entity "github.com/icchon/matcha/api/internal/domain/entity"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPushSubscriptionQueryRepository is a mock of PushSubscriptionQueryRepository interface.
+type MockPushSubscriptionQueryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPushSubscriptionQueryRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPushSubscriptionQueryRepositoryMockRecorder is the mock recorder for MockPushSubscriptionQueryRepository.
+type MockPushSubscriptionQueryRepositoryMockRecorder struct {
+	mock *MockPushSubscriptionQueryRepository
+}
+
+// NewMockPushSubscriptionQueryRepository creates a new mock instance.
+func NewMockPushSubscriptionQueryRepository(ctrl *gomock.Controller) *MockPushSubscriptionQueryRepository {
+	mock := &MockPushSubscriptionQueryRepository{ctrl: ctrl}
+	mock.recorder = &MockPushSubscriptionQueryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPushSubscriptionQueryRepository) EXPECT() *MockPushSubscriptionQueryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Query mocks base method.
+func (m *MockPushSubscriptionQueryRepository) Query(ctx context.Context, userID uuid.UUID) ([]*entity.PushSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", ctx, userID)
+	ret0, _ := ret[0].([]*entity.PushSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockPushSubscriptionQueryRepositoryMockRecorder) Query(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockPushSubscriptionQueryRepository)(nil).Query), ctx, userID)
+}
+
+// MockPushSubscriptionCommandRepository is a mock of PushSubscriptionCommandRepository interface.
+type MockPushSubscriptionCommandRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPushSubscriptionCommandRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPushSubscriptionCommandRepositoryMockRecorder is the mock recorder for MockPushSubscriptionCommandRepository.
+type MockPushSubscriptionCommandRepositoryMockRecorder struct {
+	mock *MockPushSubscriptionCommandRepository
+}
+
+// NewMockPushSubscriptionCommandRepository creates a new mock instance.
+func NewMockPushSubscriptionCommandRepository(ctrl *gomock.Controller) *MockPushSubscriptionCommandRepository {
+	mock := &MockPushSubscriptionCommandRepository{ctrl: ctrl}
+	mock.recorder = &MockPushSubscriptionCommandRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPushSubscriptionCommandRepository) EXPECT() *MockPushSubscriptionCommandRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockPushSubscriptionCommandRepository) Delete(ctx context.Context, endpoint string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, endpoint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockPushSubscriptionCommandRepositoryMockRecorder) Delete(ctx, endpoint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPushSubscriptionCommandRepository)(nil).Delete), ctx, endpoint)
+}
+
+// Upsert mocks base method.
+func (m *MockPushSubscriptionCommandRepository) Upsert(ctx context.Context, sub *entity.PushSubscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, sub)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockPushSubscriptionCommandRepositoryMockRecorder) Upsert(ctx, sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockPushSubscriptionCommandRepository)(nil).Upsert), ctx, sub)
+}
+
+// MockPushSubscriptionRepository is a mock of PushSubscriptionRepository interface.
+type MockPushSubscriptionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPushSubscriptionRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPushSubscriptionRepositoryMockRecorder is the mock recorder for MockPushSubscriptionRepository.
+type MockPushSubscriptionRepositoryMockRecorder struct {
+	mock *MockPushSubscriptionRepository
+}
+
+// NewMockPushSubscriptionRepository creates a new mock instance.
+func NewMockPushSubscriptionRepository(ctrl *gomock.Controller) *MockPushSubscriptionRepository {
+	mock := &MockPushSubscriptionRepository{ctrl: ctrl}
+	mock.recorder = &MockPushSubscriptionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPushSubscriptionRepository) EXPECT() *MockPushSubscriptionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockPushSubscriptionRepository) Delete(ctx context.Context, endpoint string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, endpoint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockPushSubscriptionRepositoryMockRecorder) Delete(ctx, endpoint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPushSubscriptionRepository)(nil).Delete), ctx, endpoint)
+}
+
+// Query mocks base method.
+func (m *MockPushSubscriptionRepository) Query(ctx context.Context, userID uuid.UUID) ([]*entity.PushSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", ctx, userID)
+	ret0, _ := ret[0].([]*entity.PushSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockPushSubscriptionRepositoryMockRecorder) Query(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockPushSubscriptionRepository)(nil).Query), ctx, userID)
+}
+
+// Upsert mocks base method.
+func (m *MockPushSubscriptionRepository) Upsert(ctx context.Context, sub *entity.PushSubscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, sub)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockPushSubscriptionRepositoryMockRecorder) Upsert(ctx, sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockPushSubscriptionRepository)(nil).Upsert), ctx, sub)
+}
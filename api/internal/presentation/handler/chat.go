@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"encoding/json"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
@@ -78,11 +80,210 @@ func (h *ChatHandler) GetChatMessagesHandler(w http.ResponseWriter, r *http.Requ
 		Offset:  offset,
 	}
 
-	messages, err := h.chatSvc.GetChatMessages(r.Context(), params)
+	history, err := h.chatSvc.GetChatMessages(r.Context(), params)
 	if err != nil {
 		helper.HandleError(w, apperrors.ErrInternalServer) // Use helper.HandleError
 		return
 	}
 
-	helper.RespondWithJSON(w, http.StatusOK, messages) // Use helper.RespondWithJSON
+	helper.RespondWithJSON(w, http.StatusOK, history) // Use helper.RespondWithJSON
+}
+
+// chatMessageParams は /chats/{userID}/messages/{messageID} の自分・相手・メッセージ ID を取り出す
+func chatMessageParams(r *http.Request) (selfID, otherID uuid.UUID, messageID int64, err error) {
+	selfID, ok := r.Context().Value(middleware.UserIDContextKey).(uuid.UUID)
+	if !ok {
+		return uuid.Nil, uuid.Nil, 0, apperrors.ErrUnauthorized
+	}
+	otherID, err = uuid.Parse(chi.URLParam(r, string(helper.UserIDUrlParam)))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, 0, apperrors.ErrInvalidInput
+	}
+	messageID, err = strconv.ParseInt(chi.URLParam(r, string(helper.MessageIDParam)), 10, 64)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, 0, apperrors.ErrInvalidInput
+	}
+	return selfID, otherID, messageID, nil
+}
+
+type EditMessageRequest struct {
+	Content string `json:"content"`
+}
+
+// /chats/{userID}/messages/{messageID} PATCH
+func (h *ChatHandler) EditMessageHandler(w http.ResponseWriter, r *http.Request) {
+	selfID, otherID, messageID, err := chatMessageParams(r)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	var req EditMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.HandleError(w, apperrors.ErrInvalidInput)
+		return
+	}
+	message, held, err := h.chatSvc.EditMessage(r.Context(), selfID, otherID, messageID, req.Content)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	if held != nil {
+		// 編集は管理者の審査待ち。メッセージは元の本文のまま
+		helper.RespondWithJSON(w, http.StatusAccepted, held)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusOK, message)
+}
+
+// /chats/{userID}/messages/{messageID} DELETE
+func (h *ChatHandler) DeleteMessageHandler(w http.ResponseWriter, r *http.Request) {
+	selfID, otherID, messageID, err := chatMessageParams(r)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	message, err := h.chatSvc.DeleteMessage(r.Context(), selfID, otherID, messageID)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusOK, message)
+}
+
+// /chats/{userID}/messages/{messageID}/reactions GET
+func (h *ChatHandler) GetReactionsHandler(w http.ResponseWriter, r *http.Request) {
+	selfID, otherID, messageID, err := chatMessageParams(r)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	reactions, err := h.chatSvc.GetReactions(r.Context(), selfID, otherID, messageID)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusOK, reactions)
+}
+
+type AddReactionRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+// /chats/{userID}/messages/{messageID}/reactions POST
+func (h *ChatHandler) AddReactionHandler(w http.ResponseWriter, r *http.Request) {
+	selfID, otherID, messageID, err := chatMessageParams(r)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	var req AddReactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.HandleError(w, apperrors.ErrInvalidInput)
+		return
+	}
+	reaction, err := h.chatSvc.AddReaction(r.Context(), selfID, otherID, messageID, req.Emoji)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusCreated, reaction)
+}
+
+// /chats/{userID}/messages/{messageID}/reactions/{emoji} DELETE
+// emoji はパーセントエンコードして渡す
+func (h *ChatHandler) RemoveReactionHandler(w http.ResponseWriter, r *http.Request) {
+	selfID, otherID, messageID, err := chatMessageParams(r)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	emoji, err := url.PathUnescape(chi.URLParam(r, string(helper.EmojiParam)))
+	if err != nil || emoji == "" {
+		helper.HandleError(w, apperrors.ErrInvalidInput)
+		return
+	}
+	if err := h.chatSvc.RemoveReaction(r.Context(), selfID, otherID, messageID, emoji); err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// /chats/{userID}/attachments POST (multipart, field "file"。または JSON で {"upload_id": "..."})
+// 返した id を chat_event の attachment_ids に入れて送信する
+func (h *ChatHandler) UploadAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	selfID, ok := r.Context().Value(middleware.UserIDContextKey).(uuid.UUID)
+	if !ok {
+		helper.HandleError(w, apperrors.ErrUnauthorized)
+		return
+	}
+	otherID, err := uuid.Parse(chi.URLParam(r, string(helper.UserIDUrlParam)))
+	if err != nil {
+		helper.HandleError(w, apperrors.ErrInvalidInput)
+		return
+	}
+
+	file, err := readFileSource(w, r, "file")
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+
+	attachment, err := h.chatSvc.UploadAttachment(r.Context(), selfID, otherID, file)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusCreated, attachment)
+}
+
+// /chats/{userID}/attachments/{attachmentID} GET
+// 会話の参加者であれば、その場で発行した署名付き URL にリダイレクトする
+func (h *ChatHandler) GetAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	selfID, ok := r.Context().Value(middleware.UserIDContextKey).(uuid.UUID)
+	if !ok {
+		helper.HandleError(w, apperrors.ErrUnauthorized)
+		return
+	}
+	otherID, err := uuid.Parse(chi.URLParam(r, string(helper.UserIDUrlParam)))
+	if err != nil {
+		helper.HandleError(w, apperrors.ErrInvalidInput)
+		return
+	}
+	attachmentURL, err := h.chatSvc.GetAttachmentURL(r.Context(), selfID, otherID, chi.URLParam(r, string(helper.AttachmentIDParam)))
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, attachmentURL, http.StatusFound)
+}
+
+// /me/chats/search?q=&cursor=&limit= GET
+func (h *ChatHandler) SearchChatMessagesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(uuid.UUID)
+	if !ok {
+		helper.HandleError(w, apperrors.ErrUnauthorized)
+		return
+	}
+	params := &service.SearchChatMessagesParams{
+		UserID: userID,
+		Query:  r.URL.Query().Get("q"),
+		Cursor: r.URL.Query().Get("cursor"),
+	}
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			helper.HandleError(w, apperrors.ErrInvalidInput)
+			return
+		}
+		params.Limit = limit
+	}
+
+	result, err := h.chatSvc.SearchMessages(r.Context(), params)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusOK, result)
 }
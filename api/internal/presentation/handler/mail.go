@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/service"
+	"github.com/icchon/matcha/api/internal/i18n"
+	"github.com/icchon/matcha/api/internal/presentation/helper"
+)
+
+type MailHandler struct {
+	mailSvc service.MailService
+}
+
+func NewMailHandler(mailSvc service.MailService) *MailHandler {
+	return &MailHandler{mailSvc: mailSvc}
+}
+
+// /dev/mails/{template} GET
+// 開発用。?locale= で言語を、?format=html|text で本文だけを返す
+func (h *MailHandler) PreviewEmailHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if localeStr := r.URL.Query().Get("locale"); localeStr != "" {
+		locale, ok := i18n.Parse(localeStr)
+		if !ok {
+			helper.HandleError(w, apperrors.ErrInvalidInput)
+			return
+		}
+		ctx = i18n.WithLocale(ctx, locale)
+	}
+	mail, err := h.mailSvc.PreviewEmail(ctx, chi.URLParam(r, "template"))
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(mail.HTML))
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(mail.Text))
+	case "":
+		helper.RespondWithJSON(w, http.StatusOK, mail)
+	default:
+		helper.HandleError(w, apperrors.ErrInvalidInput)
+	}
+}
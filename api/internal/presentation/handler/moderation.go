@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/service"
+	"github.com/icchon/matcha/api/internal/presentation/helper"
+	"github.com/icchon/matcha/api/internal/presentation/middleware"
+)
+
+type ModerationHandler struct {
+	moderationSvc        service.ModerationService
+	pictureModerationSvc service.PictureModerationService
+}
+
+func NewModerationHandler(moderationSvc service.ModerationService, pictureModerationSvc service.PictureModerationService) *ModerationHandler {
+	return &ModerationHandler{moderationSvc: moderationSvc, pictureModerationSvc: pictureModerationSvc}
+}
+
+// /admin/held-messages GET
+func (h *ModerationHandler) ListHeldMessagesHandler(w http.ResponseWriter, r *http.Request) {
+	params := &service.ListHeldMessagesParams{
+		Status: entity.HeldMessageStatus(r.URL.Query().Get("status")),
+	}
+	var err error
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if params.Limit, err = strconv.Atoi(limitStr); err != nil {
+			helper.HandleError(w, apperrors.ErrInvalidInput)
+			return
+		}
+	}
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if params.Offset, err = strconv.Atoi(offsetStr); err != nil {
+			helper.HandleError(w, apperrors.ErrInvalidInput)
+			return
+		}
+	}
+	helds, err := h.moderationSvc.ListHeldMessages(r.Context(), params)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusOK, helds)
+}
+
+// heldMessageParams は審査する管理者と保留メッセージの ID を取り出す
+func heldMessageParams(r *http.Request) (reviewerID uuid.UUID, heldMessageID int64, err error) {
+	reviewerID, ok := r.Context().Value(middleware.UserIDContextKey).(uuid.UUID)
+	if !ok {
+		return uuid.Nil, 0, apperrors.ErrUnauthorized
+	}
+	heldMessageID, err = strconv.ParseInt(chi.URLParam(r, string(helper.HeldMessageIDParam)), 10, 64)
+	if err != nil {
+		return uuid.Nil, 0, apperrors.ErrInvalidInput
+	}
+	return reviewerID, heldMessageID, nil
+}
+
+// /admin/held-messages/{heldMessageID}/approve POST
+func (h *ModerationHandler) ApproveHeldMessageHandler(w http.ResponseWriter, r *http.Request) {
+	reviewerID, heldMessageID, err := heldMessageParams(r)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	message, err := h.moderationSvc.ApproveHeldMessage(r.Context(), reviewerID, heldMessageID)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusOK, message)
+}
+
+type RejectHeldMessageRequest struct {
+	Reason string `json:"reason"`
+}
+
+// /admin/held-messages/{heldMessageID}/reject POST
+// reason は省略可能。省略するとフィルタが付けた理由を送信者に返す
+func (h *ModerationHandler) RejectHeldMessageHandler(w http.ResponseWriter, r *http.Request) {
+	reviewerID, heldMessageID, err := heldMessageParams(r)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	var req RejectHeldMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		helper.HandleError(w, apperrors.ErrInvalidInput)
+		return
+	}
+	held, err := h.moderationSvc.RejectHeldMessage(r.Context(), reviewerID, heldMessageID, req.Reason)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusOK, held)
+}
+
+type ReviewPictureResponse struct {
+	PictureID    int32                `json:"picture_id"`
+	UserID       uuid.UUID            `json:"user_id"`
+	URL          string               `json:"url"`
+	ThumbnailURL string               `json:"thumbnail_url"`
+	CardURL      string               `json:"card_url"`
+	Status       entity.PictureStatus `json:"status"`
+	FlagReason   string               `json:"flag_reason"`
+	CreatedAt    time.Time            `json:"created_at"`
+	ReviewedBy   uuid.NullUUID        `json:"reviewed_by"`
+}
+
+func newReviewPictureResponse(pic *entity.Picture) *ReviewPictureResponse {
+	return &ReviewPictureResponse{
+		PictureID:    pic.ID,
+		UserID:       pic.UserID,
+		URL:          pic.URL,
+		ThumbnailURL: pic.Thumbnail(),
+		CardURL:      pic.Card(),
+		Status:       pic.Status,
+		FlagReason:   pic.FlagReason,
+		CreatedAt:    pic.CreatedAt,
+		ReviewedBy:   pic.ReviewedBy,
+	}
+}
+
+// /admin/pictures GET
+// status を省略すると、検査で疑わしいとされて審査を待つ写真を返す
+func (h *ModerationHandler) ListPicturesHandler(w http.ResponseWriter, r *http.Request) {
+	reviewerID, ok := r.Context().Value(middleware.UserIDContextKey).(uuid.UUID)
+	if !ok {
+		helper.HandleError(w, apperrors.ErrUnauthorized)
+		return
+	}
+	params := &service.ListPicturesParams{
+		Status: entity.PictureStatus(r.URL.Query().Get("status")),
+	}
+	var err error
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if params.Limit, err = strconv.Atoi(limitStr); err != nil {
+			helper.HandleError(w, apperrors.ErrInvalidInput)
+			return
+		}
+	}
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if params.Offset, err = strconv.Atoi(offsetStr); err != nil {
+			helper.HandleError(w, apperrors.ErrInvalidInput)
+			return
+		}
+	}
+	pictures, err := h.pictureModerationSvc.ListPictures(r.Context(), reviewerID, params)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	res := make([]*ReviewPictureResponse, 0, len(pictures))
+	for _, pic := range pictures {
+		res = append(res, newReviewPictureResponse(pic))
+	}
+	helper.RespondWithJSON(w, http.StatusOK, res)
+}
+
+// pictureParams は審査する管理者と写真の ID を取り出す
+func pictureParams(r *http.Request) (reviewerID uuid.UUID, pictureID int32, err error) {
+	reviewerID, ok := r.Context().Value(middleware.UserIDContextKey).(uuid.UUID)
+	if !ok {
+		return uuid.Nil, 0, apperrors.ErrUnauthorized
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, string(helper.PictureIDParam)), 10, 32)
+	if err != nil {
+		return uuid.Nil, 0, apperrors.ErrInvalidInput
+	}
+	return reviewerID, int32(id), nil
+}
+
+// /admin/pictures/{pictureID}/approve POST
+func (h *ModerationHandler) ApprovePictureHandler(w http.ResponseWriter, r *http.Request) {
+	reviewerID, pictureID, err := pictureParams(r)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	pic, err := h.pictureModerationSvc.ApprovePicture(r.Context(), reviewerID, pictureID)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusOK, newReviewPictureResponse(pic))
+}
+
+type RejectPictureRequest struct {
+	Reason string `json:"reason"`
+}
+
+// /admin/pictures/{pictureID}/reject POST
+// reason は省略可能。省略すると検査が付けた理由を残す
+func (h *ModerationHandler) RejectPictureHandler(w http.ResponseWriter, r *http.Request) {
+	reviewerID, pictureID, err := pictureParams(r)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	var req RejectPictureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		helper.HandleError(w, apperrors.ErrInvalidInput)
+		return
+	}
+	pic, err := h.pictureModerationSvc.RejectPicture(r.Context(), reviewerID, pictureID, req.Reason)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusOK, newReviewPictureResponse(pic))
+}
+
+type FakeAccountSignalResponse struct {
+	*entity.FakeAccountSignal
+	// 今の写真のうち、他のユーザーの写真と似ていたものの割合 (0 から 1)
+	Score float64 `json:"score"`
+}
+
+func newFakeAccountSignalResponse(signal *entity.FakeAccountSignal) *FakeAccountSignalResponse {
+	return &FakeAccountSignalResponse{FakeAccountSignal: signal, Score: signal.Score()}
+}
+
+// /admin/fake-account-signals GET
+// 他のユーザーの写真と似た写真を上げたユーザーを、一致の多い順に返す
+func (h *ModerationHandler) ListFakeAccountSignalsHandler(w http.ResponseWriter, r *http.Request) {
+	params := &service.ListFakeAccountSignalsParams{}
+	var err error
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if params.Limit, err = strconv.Atoi(limitStr); err != nil {
+			helper.HandleError(w, apperrors.ErrInvalidInput)
+			return
+		}
+	}
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if params.Offset, err = strconv.Atoi(offsetStr); err != nil {
+			helper.HandleError(w, apperrors.ErrInvalidInput)
+			return
+		}
+	}
+	signals, err := h.pictureModerationSvc.ListFakeAccountSignals(r.Context(), params)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	res := make([]*FakeAccountSignalResponse, 0, len(signals))
+	for _, signal := range signals {
+		res = append(res, newFakeAccountSignalResponse(signal))
+	}
+	helper.RespondWithJSON(w, http.StatusOK, res)
+}
+
+type GetFakeAccountSignalResponse struct {
+	*FakeAccountSignalResponse
+	Matches []*entity.PictureMatch `json:"matches"`
+}
+
+// /admin/users/{userID}/fake-account-signal GET
+func (h *ModerationHandler) GetFakeAccountSignalHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, string(helper.UserIDUrlParam)))
+	if err != nil {
+		helper.HandleError(w, apperrors.ErrInvalidInput)
+		return
+	}
+	signal, matches, err := h.pictureModerationSvc.FindFakeAccountSignal(r.Context(), userID)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusOK, &GetFakeAccountSignalResponse{
+		FakeAccountSignalResponse: newFakeAccountSignalResponse(signal),
+		Matches:                   matches,
+	})
+}
@@ -1,36 +1,181 @@
 package handler
 
 import (
+	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/icchon/matcha/api/internal/apperrors"
 	"github.com/icchon/matcha/api/internal/domain/service"
 	"github.com/icchon/matcha/api/internal/presentation/helper" // Add this import
+	"github.com/icchon/matcha/api/internal/presentation/middleware"
 )
 
 type NotificationHandler struct {
-	notifSvc service.NotificationService
+	notifSvc  service.NotificationService
+	digestSvc service.DigestService
 }
 
-func NewNotificationHandler(notifSvc service.NotificationService) *NotificationHandler {
-	return &NotificationHandler{notifSvc: notifSvc}
+func NewNotificationHandler(notifSvc service.NotificationService, digestSvc service.DigestService) *NotificationHandler {
+	return &NotificationHandler{notifSvc: notifSvc, digestSvc: digestSvc}
 }
 
+// /me/notifications GET
 func (h *NotificationHandler) GetUserNotifications(w http.ResponseWriter, r *http.Request) {
-	userIDStr := chi.URLParam(r, "userID")
-	userID, err := uuid.Parse(userIDStr)
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(uuid.UUID)
+	if !ok {
+		helper.HandleError(w, apperrors.ErrUnauthorized) // Use helper.HandleError
+		return
+	}
+
+	params := &service.GetNotificationsParams{
+		RecipientID: userID,
+		Cursor:      r.URL.Query().Get("cursor"),
+	}
+	if unreadStr := r.URL.Query().Get("unread"); unreadStr != "" {
+		unread, err := strconv.ParseBool(unreadStr)
+		if err != nil {
+			helper.HandleError(w, apperrors.ErrInvalidInput)
+			return
+		}
+		params.UnreadOnly = unread
+	}
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			helper.HandleError(w, apperrors.ErrInvalidInput)
+			return
+		}
+		params.Limit = limit
+	}
+
+	page, err := h.notifSvc.GetNotifications(r.Context(), params)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+
+	helper.RespondWithJSON(w, http.StatusOK, page) // Use helper.RespondWithJSON
+}
+
+// notificationParams は自分の ID と /me/notifications/{notificationID} の ID を取り出す
+func notificationParams(r *http.Request) (userID uuid.UUID, notificationID int64, err error) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(uuid.UUID)
+	if !ok {
+		return uuid.Nil, 0, apperrors.ErrUnauthorized
+	}
+	notificationID, err = strconv.ParseInt(chi.URLParam(r, string(helper.NotificationIDParam)), 10, 64)
+	if err != nil {
+		return uuid.Nil, 0, apperrors.ErrInvalidInput
+	}
+	return userID, notificationID, nil
+}
+
+type UpdateNotificationRequest struct {
+	IsRead *bool `json:"is_read"`
+}
+
+// /me/notifications/{notificationID} PATCH
+func (h *NotificationHandler) UpdateNotificationHandler(w http.ResponseWriter, r *http.Request) {
+	userID, notificationID, err := notificationParams(r)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	var req UpdateNotificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.IsRead == nil {
+		helper.HandleError(w, apperrors.ErrInvalidInput)
+		return
+	}
+	notification, err := h.notifSvc.MarkNotificationRead(r.Context(), userID, notificationID, *req.IsRead)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusOK, notification)
+}
+
+// /me/notifications/read-all POST
+func (h *NotificationHandler) ReadAllNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(uuid.UUID)
+	if !ok {
+		helper.HandleError(w, apperrors.ErrUnauthorized)
+		return
+	}
+	updated, err := h.notifSvc.MarkAllNotificationsRead(r.Context(), userID)
 	if err != nil {
-		helper.HandleError(w, apperrors.ErrInvalidInput) // Use helper.HandleError
+		helper.HandleError(w, err)
 		return
 	}
+	helper.RespondWithJSON(w, http.StatusOK, map[string]int64{"updated": updated})
+}
 
-	notifications, err := h.notifSvc.GetNotifications(r.Context(), userID)
+// /me/notifications/{notificationID} DELETE
+func (h *NotificationHandler) DeleteNotificationHandler(w http.ResponseWriter, r *http.Request) {
+	userID, notificationID, err := notificationParams(r)
 	if err != nil {
-		helper.HandleError(w, apperrors.ErrInternalServer) // Use helper.HandleError
+		helper.HandleError(w, err)
 		return
 	}
+	if err := h.notifSvc.DeleteNotification(r.Context(), userID, notificationID); err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
 
-	helper.RespondWithJSON(w, http.StatusOK, notifications) // Use helper.RespondWithJSON
+// /me/notification-settings GET
+func (h *NotificationHandler) GetNotificationSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(uuid.UUID)
+	if !ok {
+		helper.HandleError(w, apperrors.ErrUnauthorized)
+		return
+	}
+	settings, err := h.notifSvc.GetSettings(r.Context(), userID)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusOK, settings)
+}
+
+// /me/notification-settings PUT
+func (h *NotificationHandler) UpdateNotificationSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(uuid.UUID)
+	if !ok {
+		helper.HandleError(w, apperrors.ErrUnauthorized)
+		return
+	}
+	var req service.NotificationSettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.HandleError(w, apperrors.ErrInvalidInput)
+		return
+	}
+	settings, err := h.notifSvc.UpdateSettings(r.Context(), userID, &req)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusOK, settings)
+}
+
+// /notifications/unsubscribe POST
+// メールの配信停止リンクから開いたページが呼ぶ。ログインは不要
+type UnsubscribeRequest struct {
+	Token string `json:"token"`
+}
+
+func (h *NotificationHandler) UnsubscribeHandler(w http.ResponseWriter, r *http.Request) {
+	var req UnsubscribeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.HandleError(w, apperrors.ErrInvalidInput)
+		return
+	}
+	if err := h.digestSvc.Unsubscribe(r.Context(), req.Token); err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusOK, nil)
 }
@@ -1,8 +1,6 @@
 package handler
 
 import (
-	"errors"
-	"io"
 	"log"
 	"net/http"
 
@@ -36,6 +34,7 @@ type CreateProfileRequest struct {
 	Occupation       sql.NullString `db:"occupation"`
 	Biography        sql.NullString `db:"biography"`
 	LocationName     sql.NullString `db:"location_name"`
+	Locale           sql.NullString `db:"locale"`
 }
 type CreateProfileResponse struct {
 	UserID           uuid.UUID      `json:"user_id"`
@@ -48,6 +47,7 @@ type CreateProfileResponse struct {
 	Occupation       sql.NullString `json:"occupation"`
 	Biography        sql.NullString `json:"biography"`
 	LocationName     sql.NullString `json:"location_name"`
+	Locale           sql.NullString `json:"locale"`
 }
 
 // /profile POST
@@ -73,6 +73,7 @@ func (h *ProfileHandler) CreateProfileHandler(w http.ResponseWriter, r *http.Req
 		Occupation:       req.Occupation,
 		Biography:        req.Biography,
 		LocationName:     req.LocationName,
+		Locale:           req.Locale,
 	}
 	profile, err := h.profileSvc.CreateProfile(r.Context(), profile)
 	if err != nil {
@@ -90,6 +91,7 @@ func (h *ProfileHandler) CreateProfileHandler(w http.ResponseWriter, r *http.Req
 		Occupation:       profile.Occupation,
 		Biography:        profile.Biography,
 		LocationName:     profile.LocationName,
+		Locale:           profile.Locale,
 	}
 	helper.RespondWithJSON(w, http.StatusOK, res)
 }
@@ -104,6 +106,7 @@ type UpdateProfileRequest struct {
 	Occupation       sql.NullString `db:"occupation"`
 	Biography        sql.NullString `db:"biography"`
 	LocationName     sql.NullString `db:"location_name"`
+	Locale           sql.NullString `db:"locale"`
 }
 type UpdateProfileResponse struct {
 	UserID           uuid.UUID      `json:"user_id"`
@@ -116,6 +119,7 @@ type UpdateProfileResponse struct {
 	Occupation       sql.NullString `json:"occupation"`
 	Biography        sql.NullString `json:"biography"`
 	LocationName     sql.NullString `json:"location_name"`
+	Locale           sql.NullString `json:"locale"`
 }
 
 // /profile PUT
@@ -140,6 +144,7 @@ func (h *ProfileHandler) UpdateProfileHandler(w http.ResponseWriter, r *http.Req
 		Occupation:       req.Occupation,
 		Biography:        req.Biography,
 		LocationName:     req.LocationName,
+		Locale:           req.Locale,
 	}
 	profile, err := h.profileSvc.UpdateProfile(r.Context(), userID, profile)
 	if err != nil {
@@ -157,17 +162,22 @@ func (h *ProfileHandler) UpdateProfileHandler(w http.ResponseWriter, r *http.Req
 		Occupation:       profile.Occupation,
 		Biography:        profile.Biography,
 		LocationName:     profile.LocationName,
+		Locale:           profile.Locale,
 	}
 	helper.RespondWithJSON(w, http.StatusOK, res)
 }
 
 type UploadProfilePictureResponse struct {
-	PictureID int32     `json:"picture_id"`
-	UserID    uuid.UUID `json:"user_id"`
-	URL       string    `json:"url"`
+	PictureID    int32     `json:"picture_id"`
+	UserID       uuid.UUID `json:"user_id"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	CardURL      string    `json:"card_url"`
+	// 検査が終わって承認されるまで pending で、本人にしか見えない
+	Status entity.PictureStatus `json:"status"`
 }
 
-// /profile/pictures POST
+// /profile/pictures POST (multipart, field "image"。または JSON で {"upload_id": "..."})
 func (h *ProfileHandler) UploadProfilePictureHandler(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value(middleware.UserIDContextKey).(uuid.UUID)
 	if !ok {
@@ -175,38 +185,25 @@ func (h *ProfileHandler) UploadProfilePictureHandler(w http.ResponseWriter, r *h
 		return
 	}
 
-	if err := r.ParseMultipartForm(10 << 20); err != nil { // 10MB
-		helper.HandleError(w, apperrors.ErrInvalidInput)
-		return
-	}
-
-	file, _, err := r.FormFile("image")
+	image, err := readFileSource(w, r, "image")
 	if err != nil {
-		if errors.Is(err, http.ErrMissingFile) {
-			helper.HandleError(w, apperrors.ErrInvalidInput)
-		} else {
-			helper.HandleError(w, apperrors.ErrInternalServer)
-		}
-		return
-	}
-	defer file.Close()
-
-	imageBytes, err := io.ReadAll(file)
-	if err != nil {
-		helper.HandleError(w, apperrors.ErrInternalServer)
+		helper.HandleError(w, err)
 		return
 	}
 
-	picture, err := h.profileSvc.UploadPicture(r.Context(), userID, imageBytes)
+	picture, err := h.profileSvc.UploadPicture(r.Context(), userID, image)
 	if err != nil {
 		helper.HandleError(w, err)
 		return
 	}
 
 	res := UploadProfilePictureResponse{
-		PictureID: picture.ID,
-		UserID:    picture.UserID,
-		URL:       picture.URL,
+		PictureID:    picture.ID,
+		UserID:       picture.UserID,
+		URL:          picture.URL,
+		ThumbnailURL: picture.Thumbnail(),
+		CardURL:      picture.Card(),
+		Status:       picture.Status,
 	}
 	helper.RespondWithJSON(w, http.StatusOK, res)
 }
@@ -231,6 +228,105 @@ func (h *ProfileHandler) DeleteProfilePictureHandler(w http.ResponseWriter, r *h
 	helper.RespondWithJSON(w, http.StatusNoContent, map[string]string{"message": "Picture deleted successfully"})
 }
 
+type PictureResponse struct {
+	PictureID    int32     `json:"picture_id"`
+	UserID       uuid.UUID `json:"user_id"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	CardURL      string    `json:"card_url"`
+	IsProfilePic bool      `json:"is_profile_pic"`
+	Position     int       `json:"position"`
+	// 本人以外には approved の写真しか返さない
+	Status entity.PictureStatus `json:"status"`
+}
+
+type PicturesResponse struct {
+	Pictures []*PictureResponse `json:"pictures"`
+	// 写真がないときに代わりに表示する、ユーザー ID から決まる既定の画像
+	AvatarURL string `json:"avatar_url,omitempty"`
+}
+
+func newPicturesResponse(pictures []*entity.Picture, avatarURL string) *PicturesResponse {
+	res := &PicturesResponse{Pictures: make([]*PictureResponse, 0, len(pictures)), AvatarURL: avatarURL}
+	for _, pic := range pictures {
+		res.Pictures = append(res.Pictures, &PictureResponse{
+			PictureID:    pic.ID,
+			UserID:       pic.UserID,
+			URL:          pic.URL,
+			ThumbnailURL: pic.Thumbnail(),
+			CardURL:      pic.Card(),
+			IsProfilePic: pic.IsProfilePic.Bool,
+			Position:     pic.Position,
+			Status:       pic.Status,
+		})
+	}
+	return res
+}
+
+// /profile/pictures/{pictureID}/primary PUT
+func (h *ProfileHandler) SetProfilePictureHandler(w http.ResponseWriter, r *http.Request) {
+	pictureID, err := strconv.ParseInt(chi.URLParam(r, string(helper.PictureIDParam)), 10, 32)
+	if err != nil {
+		helper.HandleError(w, apperrors.ErrInvalidInput)
+		return
+	}
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(uuid.UUID)
+	if !ok {
+		helper.HandleError(w, apperrors.ErrInternalServer)
+		return
+	}
+	pictures, err := h.profileSvc.SetProfilePicture(r.Context(), userID, int32(pictureID))
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusOK, newPicturesResponse(pictures, h.profileSvc.AvatarURL(userID)))
+}
+
+type ReorderPicturesRequest struct {
+	PictureIDs []int32 `json:"picture_ids"`
+}
+
+// /profile/pictures/order PUT
+func (h *ProfileHandler) ReorderPicturesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(uuid.UUID)
+	if !ok {
+		helper.HandleError(w, apperrors.ErrInternalServer)
+		return
+	}
+	var req ReorderPicturesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.HandleError(w, apperrors.ErrInvalidInput)
+		return
+	}
+	pictures, err := h.profileSvc.ReorderPictures(r.Context(), userID, req.PictureIDs)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusOK, newPicturesResponse(pictures, h.profileSvc.AvatarURL(userID)))
+}
+
+// /users/{userID}/pictures GET
+func (h *ProfileHandler) GetUserPicturesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		helper.HandleError(w, apperrors.ErrInvalidInput)
+		return
+	}
+	viewerID, ok := r.Context().Value(middleware.UserIDContextKey).(uuid.UUID)
+	if !ok {
+		helper.HandleError(w, apperrors.ErrInternalServer)
+		return
+	}
+	pictures, err := h.profileSvc.FindPictures(r.Context(), viewerID, userID)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusOK, newPicturesResponse(pictures, h.profileSvc.AvatarURL(userID)))
+}
+
 type GetWhoLikedMeListResponse struct {
 	Likes []*entity.Like `json:"likes"`
 }
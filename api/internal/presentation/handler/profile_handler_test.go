@@ -9,11 +9,13 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/client"
 	"github.com/icchon/matcha/api/internal/domain/entity"
 	"go.uber.org/mock/gomock"
 
@@ -48,7 +50,7 @@ func TestProfileHandler_CreateProfileHandler(t *testing.T) {
 			body:           reqBody,
 			ctx:            context.WithValue(context.Background(), middleware.UserIDContextKey, userID),
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"user_id":"` + userID.String() + `","first_name":{"String":"","Valid":false},"last_name":{"String":"","Valid":false},"username":{"String":"testuser","Valid":true},"gender":{"String":"","Valid":false},"sexual_preference":{"String":"","Valid":false},"birthday":{"Time":"0001-01-01T00:00:00Z","Valid":false},"occupation":{"String":"","Valid":false},"biography":{"String":"","Valid":false},"location_name":{"String":"","Valid":false}}`,
+			expectedBody:   `{"user_id":"` + userID.String() + `","first_name":{"String":"","Valid":false},"last_name":{"String":"","Valid":false},"username":{"String":"testuser","Valid":true},"gender":{"String":"","Valid":false},"sexual_preference":{"String":"","Valid":false},"birthday":{"Time":"0001-01-01T00:00:00Z","Valid":false},"occupation":{"String":"","Valid":false},"biography":{"String":"","Valid":false},"location_name":{"String":"","Valid":false},"locale":{"String":"","Valid":false}}`,
 		},
 		{
 			name:           "Invalid JSON",
@@ -178,6 +180,7 @@ func TestProfileHandler_UploadProfilePictureHandler(t *testing.T) {
 		setupMocks     func(mockSvc *mock.MockProfileService)
 		ctx            context.Context
 		imageFile      []byte
+		jsonBody       string
 		expectedStatus int
 		expectedBody   string
 	}{
@@ -185,15 +188,17 @@ func TestProfileHandler_UploadProfilePictureHandler(t *testing.T) {
 			name: "Success",
 			setupMocks: func(mockSvc *mock.MockProfileService) {
 				mockSvc.EXPECT().UploadPicture(gomock.Any(), userID, gomock.Any()).Return(&entity.Picture{
-					ID:     pictureID,
-					UserID: userID,
-					URL:    pictureURL,
+					ID:           pictureID,
+					UserID:       userID,
+					URL:          pictureURL,
+					ThumbnailURL: "http://example.com/thumbnail.jpg",
+					Status:       entity.PicturePending,
 				}, nil)
 			},
 			ctx:            context.WithValue(context.Background(), middleware.UserIDContextKey, userID),
 			imageFile:      imageContent,
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"picture_id":1, "user_id":"` + userID.String() + `", "url":"http://example.com/image.jpg"}`,
+			expectedBody:   `{"picture_id":1, "user_id":"` + userID.String() + `", "url":"http://example.com/image.jpg", "thumbnail_url":"http://example.com/thumbnail.jpg", "card_url":"http://example.com/image.jpg", "status":"pending"}`,
 		},
 		{
 			name:           "No UserID in Context",
@@ -203,6 +208,29 @@ func TestProfileHandler_UploadProfilePictureHandler(t *testing.T) {
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   `{"message":"Internal server error."}`,
 		},
+		{
+			name: "Resumable upload",
+			setupMocks: func(mockSvc *mock.MockProfileService) {
+				mockSvc.EXPECT().UploadPicture(gomock.Any(), userID, &client.FileSource{UploadID: "u1"}).Return(&entity.Picture{
+					ID:     pictureID,
+					UserID: userID,
+					URL:    pictureURL,
+					Status: entity.PicturePending,
+				}, nil)
+			},
+			ctx:            context.WithValue(context.Background(), middleware.UserIDContextKey, userID),
+			jsonBody:       `{"upload_id":"u1"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"picture_id":1, "user_id":"` + userID.String() + `", "url":"http://example.com/image.jpg", "thumbnail_url":"http://example.com/image.jpg", "card_url":"http://example.com/image.jpg", "status":"pending"}`,
+		},
+		{
+			name:           "Resumable upload without ID",
+			setupMocks:     func(mockSvc *mock.MockProfileService) {},
+			ctx:            context.WithValue(context.Background(), middleware.UserIDContextKey, userID),
+			jsonBody:       `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Invalid input provided."}`,
+		},
 		{
 			name:           "No file provided",
 			setupMocks:     func(mockSvc *mock.MockProfileService) {},
@@ -247,6 +275,10 @@ func TestProfileHandler_UploadProfilePictureHandler(t *testing.T) {
 
 			req := httptest.NewRequest(http.MethodPost, "/me/profile/pictures", body).WithContext(tc.ctx)
 			req.Header.Set("Content-Type", writer.FormDataContentType())
+			if tc.jsonBody != "" {
+				req = httptest.NewRequest(http.MethodPost, "/me/profile/pictures", strings.NewReader(tc.jsonBody)).WithContext(tc.ctx)
+				req.Header.Set("Content-Type", "application/json")
+			}
 
 			rr := httptest.NewRecorder()
 			handler.UploadProfilePictureHandler(rr, req)
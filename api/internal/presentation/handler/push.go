@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/service"
+	"github.com/icchon/matcha/api/internal/presentation/helper"
+	"github.com/icchon/matcha/api/internal/presentation/middleware"
+)
+
+type PushHandler struct {
+	pushSvc service.PushService
+}
+
+func NewPushHandler(pushSvc service.PushService) *PushHandler {
+	return &PushHandler{pushSvc: pushSvc}
+}
+
+// /push/public-key GET
+// ブラウザが購読するときの applicationServerKey。ログインは不要
+func (h *PushHandler) GetPublicKeyHandler(w http.ResponseWriter, r *http.Request) {
+	publicKey, err := h.pushSvc.GetPublicKey(r.Context())
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusOK, map[string]string{"public_key": publicKey})
+}
+
+// /me/push-subscriptions POST
+// body は PushSubscription.toJSON() をそのまま送る
+func (h *PushHandler) SubscribeHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(uuid.UUID)
+	if !ok {
+		helper.HandleError(w, apperrors.ErrUnauthorized)
+		return
+	}
+	var req service.PushSubscriptionParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.HandleError(w, apperrors.ErrInvalidInput)
+		return
+	}
+	req.UserAgent = r.UserAgent()
+	sub, err := h.pushSvc.Subscribe(r.Context(), userID, &req)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusCreated, sub)
+}
+
+// /me/push-subscriptions DELETE
+type PushUnsubscribeRequest struct {
+	Endpoint string `json:"endpoint"`
+}
+
+func (h *PushHandler) UnsubscribeHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(uuid.UUID)
+	if !ok {
+		helper.HandleError(w, apperrors.ErrUnauthorized)
+		return
+	}
+	var req PushUnsubscribeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Endpoint == "" {
+		helper.HandleError(w, apperrors.ErrInvalidInput)
+		return
+	}
+	if err := h.pushSvc.Unsubscribe(r.Context(), userID, req.Endpoint); err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/service"
+	"github.com/icchon/matcha/api/internal/presentation/helper"
+	"github.com/icchon/matcha/api/internal/presentation/middleware"
+)
+
+// アップロードの本文の上限。filesrv の 1 ファイルの上限 (10MB) に multipart のヘッダーの分を足す
+const maxUploadBodySize = 10<<20 + 64<<10
+
+type UploadHandler struct {
+	uploadSvc service.UploadService
+}
+
+func NewUploadHandler(uploadSvc service.UploadService) *UploadHandler {
+	return &UploadHandler{uploadSvc: uploadSvc}
+}
+
+type CreateUploadRequest struct {
+	Length int64 `json:"length"`
+}
+
+// /me/uploads POST
+// 再開可能アップロードの枠を作る。upload_url に tus で送り終えたら、upload_id を写真や添付ファイルのアップロードに渡す
+func (h *UploadHandler) CreateUploadHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(uuid.UUID)
+	if !ok {
+		helper.HandleError(w, apperrors.ErrUnauthorized)
+		return
+	}
+	var req CreateUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.HandleError(w, apperrors.ErrInvalidInput)
+		return
+	}
+	upload, err := h.uploadSvc.CreateUpload(r.Context(), userID, req.Length)
+	if err != nil {
+		helper.HandleError(w, err)
+		return
+	}
+	helper.RespondWithJSON(w, http.StatusCreated, upload)
+}
+
+type UploadIDRequest struct {
+	UploadID string `json:"upload_id"`
+}
+
+// readFileSource は multipart の field のファイルを、読みながら filesrv に流す FileSource にする
+// application/json で {"upload_id": "..."} が来たときは、送り終えた再開可能アップロードを使う
+func readFileSource(w http.ResponseWriter, r *http.Request, field string) (*client.FileSource, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		var req UploadIDRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UploadID == "" {
+			return nil, apperrors.ErrInvalidInput
+		}
+		return &client.FileSource{UploadID: req.UploadID}, nil
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadBodySize)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, apperrors.ErrInvalidInput
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			// field のファイルがなかった
+			return nil, apperrors.ErrInvalidInput
+		}
+		if err != nil {
+			return nil, apperrors.ErrInvalidInput
+		}
+		if part.FormName() == field && part.FileName() != "" {
+			return &client.FileSource{Reader: part, Filename: part.FileName()}, nil
+		}
+	}
+}
@@ -9,7 +9,12 @@ import (
 type UrlParam string
 
 const (
-	UserIDUrlParam UrlParam = "userID"
-	TokenUrlParam  UrlParam = "token"
-	PictureIDParam UrlParam = "pictureID"
+	UserIDUrlParam      UrlParam = "userID"
+	TokenUrlParam       UrlParam = "token"
+	PictureIDParam      UrlParam = "pictureID"
+	MessageIDParam      UrlParam = "messageID"
+	EmojiParam          UrlParam = "emoji"
+	AttachmentIDParam   UrlParam = "attachmentID"
+	HeldMessageIDParam  UrlParam = "heldMessageID"
+	NotificationIDParam UrlParam = "notificationID"
 )
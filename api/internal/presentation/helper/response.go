@@ -50,6 +50,22 @@ func HandleError(w http.ResponseWriter, err error) {
 		RespondWithError(w, http.StatusUnauthorized, "Authentication failed.")
 		return
 	}
+	if errors.Is(err, apperrors.ErrForbidden) {
+		RespondWithError(w, http.StatusForbidden, "Permission denied.")
+		return
+	}
+	if errors.Is(err, apperrors.ErrConflict) {
+		RespondWithError(w, http.StatusConflict, "The request conflicts with the current state of the resource.")
+		return
+	}
+	if errors.Is(err, apperrors.ErrTooManyRequests) {
+		RespondWithError(w, http.StatusTooManyRequests, "Too many requests. Please try again later.")
+		return
+	}
+	if errors.Is(err, apperrors.ErrRejected) {
+		RespondWithError(w, http.StatusUnprocessableEntity, "The content was rejected by moderation.")
+		return
+	}
 	if errors.Is(err, apperrors.ErrUnhandled) {
 		RespondWithError(w, http.StatusInternalServerError, "An unhandled error occurred.")
 		return
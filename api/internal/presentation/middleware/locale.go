@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/icchon/matcha/api/internal/i18n"
+)
+
+// LocaleMiddleware は Accept-Language から選んだ言語をリクエストの context に入れる
+// ユーザーが言語を設定していれば、サービス側で i18n.WithPreferred で上書きする
+func LocaleMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale := i18n.FromAcceptLanguage(r.Header.Get("Accept-Language"))
+		next.ServeHTTP(w, r.WithContext(i18n.WithLocale(r.Context(), locale)))
+	})
+}
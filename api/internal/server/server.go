@@ -10,25 +10,33 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
 	"github.com/icchon/matcha/api/internal/infrastructure/db/postgres"
+	redisrepo "github.com/icchon/matcha/api/internal/infrastructure/db/redis"
 	"github.com/icchon/matcha/api/internal/infrastructure/db/uow"
 	"github.com/icchon/matcha/api/internal/infrastructure/file"
 	smtp "github.com/icchon/matcha/api/internal/infrastructure/mail"
 	"github.com/icchon/matcha/api/internal/infrastructure/oauth"
 	"github.com/icchon/matcha/api/internal/infrastructure/publisher"
+	webpush "github.com/icchon/matcha/api/internal/infrastructure/push"
 	"github.com/icchon/matcha/api/internal/infrastructure/subscriber"
 	"github.com/icchon/matcha/api/internal/presentation/handler"
 	appmiddleware "github.com/icchon/matcha/api/internal/presentation/middleware"
 	"github.com/icchon/matcha/api/internal/service/auth"
 	"github.com/icchon/matcha/api/internal/service/chat"
 	"github.com/icchon/matcha/api/internal/service/mail"
+	"github.com/icchon/matcha/api/internal/service/moderation"
 	"github.com/icchon/matcha/api/internal/service/notice"
 	"github.com/icchon/matcha/api/internal/service/profile"
+	"github.com/icchon/matcha/api/internal/service/push"
 	subsvc "github.com/icchon/matcha/api/internal/service/subscriber"
+	"github.com/icchon/matcha/api/internal/service/upload"
 	"github.com/icchon/matcha/api/internal/service/user"
 )
 
@@ -42,6 +50,54 @@ type Config struct {
 	GithubClientSecret  string
 	RidirectURI         string
 	ImageUploadEndpoint string
+	// 写真の参照を外す filesrv の DELETE /assets
+	ImageAssetEndpoint string
+	// filesrv の /images の公開 URL。この下の URL には期限付きの署名を付けて返す
+	ImageBaseUrl string
+	// filesrv の PUBLIC_THUMBNAILS と合わせる。true ならサムネイルには署名を付けない
+	FilePublicThumbnails bool
+
+	// チャット添付ファイル (filesrv)
+	AttachmentUploadEndpoint string
+	AttachmentBaseUrl        string
+	FileURLSigningKey        string
+	// 再開可能アップロードを作る・保存させる filesrv の /uploads
+	ResumableUploadEndpoint string
+	// filesrv の /avatars の公開 URL。写真のないユーザーの既定の画像に使う
+	AvatarBaseUrl string
+	// filesrv の書き込み系エンドポイントの署名鍵
+	FileServiceAuthKey string
+
+	// 管理者 (モデレーションの審査など) として扱うユーザー
+	AdminUserIDs []uuid.UUID
+
+	// チャットのモデレーション
+	BannedWords            []string
+	ChatRateLimitPerMinute int
+
+	// 写真の検査。PictureClassifier は "heuristic" (既定) か "none"
+	PictureClassifier         string
+	PictureModerationInterval time.Duration
+
+	// メールのまとめ通知。最後の接続・通知から DigestQuietPeriod 過ぎたら DigestInterval ごとに送る
+	DigestQuietPeriod time.Duration
+	DigestInterval    time.Duration
+
+	// 開発用のメールのプレビュー (/api/v1/dev/mails/{template}) を有効にする
+	MailPreviewEnabled bool
+
+	// メールの送り方: smtp / file (MailDropDir に .eml を書き出す) / mock (何もしない)
+	MailTransport      string
+	MailDropDir        string
+	MailOutboxInterval time.Duration
+
+	// Web Push。VAPIDPrivateKey が空なら送らない
+	VAPIDPrivateKey string
+	VAPIDSubject    string
+	// 開発用。http やプライベートアドレスの endpoint (ローカルの偽のプッシュサービス) を許す
+	PushAllowPrivateEndpoints bool
+	// プッシュサービスに送るワーカーの数
+	PushSendWorkers int
 
 	SmtpHost     string
 	SmtpPort     string
@@ -52,6 +108,58 @@ type Config struct {
 	BaseUrl string
 }
 
+// マッチしてからこの期間は URL・電話番号を含むメッセージを審査に回す
+const freshMatchWindow = 24 * time.Hour
+
+const (
+	// 肌色の画素がこの割合を超える写真を審査に回す
+	skinToneThreshold = 0.6
+	// 他のユーザーの写真と dHash か pHash がこのビット数以内なら審査に回す
+	duplicateMaxDistance = repo.MaxPictureHashDistance
+)
+
+func newImageClassifier(config *Config, pictureRepo repo.PictureQueryRepository) (service.ImageClassifier, error) {
+	switch config.PictureClassifier {
+	case "", "heuristic":
+		return moderation.NewClassifierChain(
+			moderation.NewSkinToneClassifier(skinToneThreshold),
+			moderation.NewDuplicateClassifier(pictureRepo, duplicateMaxDistance),
+		), nil
+	case "none":
+		return moderation.NewClassifierChain(), nil
+	}
+	return nil, fmt.Errorf("unknown picture classifier %q", config.PictureClassifier)
+}
+
+func newMailClient(config *Config) (client.MailClient, error) {
+	switch config.MailTransport {
+	case "smtp":
+		port, err := strconv.Atoi(config.SmtpPort)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SMTP_PORT: %w", err)
+		}
+		return smtp.NewSmtpClient(client.MailConfig{
+			Host:     config.SmtpHost,
+			Port:     port,
+			Username: config.SmtpUsername,
+			Password: config.SmtpPassword,
+			From:     config.SmtpSender,
+		}), nil
+	case "file":
+		return smtp.NewFileMailClient(config.MailDropDir, config.SmtpSender), nil
+	case "", "mock":
+		return smtp.NewMockMailClient(), nil
+	}
+	return nil, fmt.Errorf("unknown mail transport %q", config.MailTransport)
+}
+
+func newPushClient(config *Config) (client.PushClient, error) {
+	if config.VAPIDPrivateKey == "" {
+		return webpush.NewMockPushClient(), nil
+	}
+	return webpush.NewWebPushClient(config.VAPIDPrivateKey, config.VAPIDSubject, config.PushAllowPrivateEndpoints)
+}
+
 type Server struct {
 	router *chi.Mux
 
@@ -66,28 +174,32 @@ func NewServer(
 ) *Server {
 	unitOfWork := uow.NewUnitOfWork(db)
 
-	fileClient := file.NewFilesrvClient(config.ImageUploadEndpoint)
-	port, err := strconv.Atoi(config.SmtpPort)
+	fileClient := file.NewFilesrvClient(config.ImageUploadEndpoint, config.ImageAssetEndpoint, config.ImageBaseUrl, config.FilePublicThumbnails, config.AttachmentUploadEndpoint, config.AttachmentBaseUrl, config.ResumableUploadEndpoint, config.AvatarBaseUrl, config.FileURLSigningKey, config.FileServiceAuthKey)
+	mailClient, err := newMailClient(config)
 	if err != nil {
-		log.Printf("Invalid SMTP_PORT: %v", err)
+		log.Printf("Failed to setup mail transport: %v", err)
+		return nil
+	}
+	pushClient, err := newPushClient(config)
+	if err != nil {
+		log.Printf("Failed to setup web push: %v", err)
 		return nil
 	}
-	_ = smtp.NewSmtpClient(client.MailConfig{
-		Host:     config.SmtpHost,
-		Port:     port,
-		Username: config.SmtpUsername,
-		Password: config.SmtpPassword,
-		From:     config.SmtpSender,
-	})
-	mockMailClient := smtp.NewMockMailClient()
 	githubClient := oauth.NewGithubClient(config.GithubClientID, config.GithubClientSecret, config.RidirectURI)
 	googleClient := oauth.NewGoogleClient(config.GoogleClientID, config.GoogleClientSecret, config.RidirectURI)
 
 	notificationPub := publisher.NewNotificationPublisher(rdb)
+	notificationReadPub := publisher.NewNotificationReadPublisher(rdb)
 	ackPub := publisher.NewAckPublisher(rdb)
 	presencePub := publisher.NewPresencePublisher(rdb)
 	chatPub := publisher.NewChatPublisher(rdb)
 	readPub := publisher.NewReadPublisher(rdb)
+	typingPub := publisher.NewTypingPublisher(rdb)
+	presenceQueryPub := publisher.NewPresenceQueryPublisher(rdb)
+	messageEditPub := publisher.NewMessageEditPublisher(rdb)
+	messageDeletePub := publisher.NewMessageDeletePublisher(rdb)
+	reactionPub := publisher.NewReactionPublisher(rdb)
+	moderationPub := publisher.NewModerationPublisher(rdb)
 
 	userRepository := postgres.NewUserRepository(db)
 	authRepository := postgres.NewAuthRepository(db)
@@ -99,46 +211,103 @@ func NewServer(
 	connectionRepo := postgres.NewConnectionRepository(db)
 	profileRepository := postgres.NewUserProfileRepository(db)
 	pictureRepository := postgres.NewPictureRepository(db)
+	pictureMatchRepository := postgres.NewPictureMatchRepository(db)
+	blockRepository := postgres.NewBlockRepository(db)
 	messageRepository := postgres.NewMessageRepository(db)
+	messageReactionRepository := postgres.NewMessageReactionRepository(db)
+	messageAttachmentRepository := postgres.NewMessageAttachmentRepository(db)
+	conversationReadRepository := postgres.NewConversationReadRepository(db)
+	heldMessageRepository := postgres.NewHeldMessageRepository(db)
 	notificationRepository := postgres.NewNotificationRepository(db)
+	notificationPreferenceRepository := postgres.NewNotificationPreferenceRepository(db)
+	notificationMuteRepository := postgres.NewNotificationMuteRepository(db)
+	emailDigestRepository := postgres.NewEmailDigestRepository(db)
+	pushSubscriptionRepository := postgres.NewPushSubscriptionRepository(db)
 	userDataRepository := postgres.NewUserDataRepository(db)
 	userTagRepository := postgres.NewUserTagRepository(db)
 	tagRepository := postgres.NewTagRepository(db)
-
-	notificationService := notice.NewNotificationService(unitOfWork, notificationRepository, notificationPub)
-	userService := user.NewUserService(unitOfWork, likeRepository, viewRepository, connectionRepo, notificationService, userDataRepository, userTagRepository, tagRepository)
-	mailService := mail.NewApplicationMailService(mockMailClient, config.BaseUrl)
+	presenceRepository := redisrepo.NewPresenceRepository(rdb)
+	pushDeliveryRepository := redisrepo.NewPushDeliveryRepository(rdb)
+
+	notificationService := notice.NewNotificationService(unitOfWork, notificationRepository, notificationPreferenceRepository, notificationMuteRepository, profileRepository, pictureRepository, notificationPub, notificationReadPub, fileClient)
+	userService := user.NewUserService(unitOfWork, likeRepository, viewRepository, connectionRepo, notificationService, userDataRepository, userTagRepository, tagRepository, fileClient)
+	mailService := mail.NewApplicationMailService(config.BaseUrl)
+	mailDelivery := mail.NewOutboxWorker(unitOfWork, mailClient)
+	pushService := push.NewPushService(unitOfWork, pushSubscriptionRepository, presenceRepository, pushDeliveryRepository, pushClient, config.PushAllowPrivateEndpoints)
+	digestService := notice.NewDigestService(unitOfWork, notificationRepository, notificationPreferenceRepository, emailDigestRepository, authRepository, profileRepository, presenceRepository, mailService, config.HMACSecretKey, config.DigestQuietPeriod)
 	authService := auth.NewAuthService(unitOfWork, authRepository, userRepository, refreshRepository, passwordResetRepository, verificationRepository, googleClient, githubClient, mailService, config.HMACSecretKey, config.JWTSigningKey)
-	profileService := profile.NewProfileService(unitOfWork, profileRepository, fileClient, pictureRepository, viewRepository, likeRepository, notificationService, userTagRepository, userDataRepository)
-	chatService := chat.NewChatService(connectionRepo, messageRepository, profileService)
+	profileService := profile.NewProfileService(unitOfWork, profileRepository, fileClient, pictureRepository, viewRepository, likeRepository, notificationService, userTagRepository, userDataRepository, blockRepository)
+	// 送信と編集で同じフィルタを使う。送信数の上限は編集も数える
+	messageFilter := moderation.NewFilterChain(
+		moderation.NewRateLimitFilter(config.ChatRateLimitPerMinute, time.Minute),
+		moderation.NewBannedWordFilter(config.BannedWords),
+		moderation.NewContactInfoFilter(connectionRepo, freshMatchWindow),
+	)
+	chatService := chat.NewChatService(unitOfWork, connectionRepo, messageRepository, messageReactionRepository, messageAttachmentRepository, conversationReadRepository, profileService, fileClient, messageEditPub, messageDeletePub, reactionPub, moderationPub, messageFilter)
+	uploadService := upload.NewUploadService(fileClient)
+	moderationService := moderation.NewModerationService(unitOfWork, heldMessageRepository, chatService, notificationService, chatPub, messageEditPub, moderationPub)
+	imageClassifier, err := newImageClassifier(config, pictureRepository)
+	if err != nil {
+		log.Printf("Failed to setup picture classifier: %v", err)
+		return nil
+	}
+	pictureModerationService := moderation.NewPictureModerationService(unitOfWork, pictureRepository, pictureMatchRepository, fileClient, imageClassifier)
 
 	userHandler := handler.NewUserHandler(userService, profileService)
 	sampleHander := handler.NewSampleHandler()
 	authHandler := handler.NewAuthHandler(authService)
 	profileHandler := handler.NewProfileHandler(profileService)
 	chatHandler := handler.NewChatHandler(chatService)
-	notificationHandler := handler.NewNotificationHandler(notificationService)
+	notificationHandler := handler.NewNotificationHandler(notificationService, digestService)
+	moderationHandler := handler.NewModerationHandler(moderationService, pictureModerationService)
+	mailHandler := handler.NewMailHandler(mailService)
+	pushHandler := handler.NewPushHandler(pushService)
+	uploadHandler := handler.NewUploadHandler(uploadService)
 
 	presenceSub := subscriber.NewPresenceSubscriber(rdb)
 	chatSub := subscriber.NewchatSubscriber(rdb)
 	readSub := subscriber.NewreadSubscriber(rdb)
+	typingSub := subscriber.NewTypingSubscriber(rdb)
+	presenceQuerySub := subscriber.NewPresenceQuerySubscriber(rdb)
+	messageEditSub := subscriber.NewMessageEditSubscriber(rdb)
+	messageDeleteSub := subscriber.NewMessageDeleteSubscriber(rdb)
+	reactionSub := subscriber.NewReactionSubscriber(rdb)
+	notificationSub := subscriber.NewNotificationSubscriber(rdb)
+	chatOutgoingSub := subscriber.NewChatOutgoingSubscriber(rdb)
 
 	subscHandler := subsvc.NewSubscriberHandler(
 		unitOfWork,
 		messageRepository,
+		connectionRepo,
+		userRepository,
+		presenceRepository,
 		readPub,
 		ackPub,
 		chatPub,
 		presencePub,
+		typingPub,
+		presenceQueryPub,
+		moderationPub,
+		messageFilter,
 		userService,
 		notificationService,
+		chatService,
 	)
 
-	subscriverService := subsvc.NewSubscriberService(chatSub, presenceSub, readSub, subscHandler)
+	subscriverService := subsvc.NewSubscriberService(chatSub, presenceSub, readSub, typingSub, presenceQuerySub, messageEditSub, messageDeleteSub, reactionSub, subscHandler)
 	if err := subscriverService.Initialize(context.Background()); err != nil {
 		log.Printf("Failed to initialize subscriber service: %v", err)
 		return nil
 	}
+	if err := push.SubscribeDelivery(context.Background(), pushService, notificationSub, chatOutgoingSub); err != nil {
+		log.Printf("Failed to initialize web push delivery: %v", err)
+		return nil
+	}
+
+	go pushService.RunSenders(context.Background(), config.PushSendWorkers)
+	go notice.RunDigestJob(context.Background(), digestService, config.DigestInterval)
+	go mail.RunOutboxWorker(context.Background(), mailDelivery, config.MailOutboxInterval)
+	go moderation.RunPictureModeration(context.Background(), pictureModerationService, config.PictureModerationInterval)
 
 	mux := chi.NewRouter()
 
@@ -147,16 +316,17 @@ func NewServer(
 		config: config,
 	}
 
-	server.setupRoutes(userHandler, sampleHander, authHandler, profileHandler, chatHandler, notificationHandler)
+	server.setupRoutes(userHandler, sampleHander, authHandler, profileHandler, chatHandler, notificationHandler, moderationHandler, mailHandler, pushHandler, uploadHandler)
 
 	return server
 }
 
-func (s *Server) setupRoutes(uh *handler.UserHandler, sh *handler.SampleHandler, ah *handler.AuthHandler, ph *handler.ProfileHandler, ch *handler.ChatHandler, nh *handler.NotificationHandler) {
+func (s *Server) setupRoutes(uh *handler.UserHandler, sh *handler.SampleHandler, ah *handler.AuthHandler, ph *handler.ProfileHandler, ch *handler.ChatHandler, nh *handler.NotificationHandler, mh *handler.ModerationHandler, mailh *handler.MailHandler, pushh *handler.PushHandler, uploadh *handler.UploadHandler) {
 	s.router.Use(middleware.RequestID)
 	s.router.Use(middleware.Logger)
 	s.router.Use(middleware.Recoverer)
 	s.router.Use(middleware.Timeout(60 * time.Second))
+	s.router.Use(appmiddleware.LocaleMiddleware)
 
 	s.router.Route("/api/v1", func(r chi.Router) {
 		r.Get("/sample", sh.GreetingHandler)
@@ -184,6 +354,7 @@ func (s *Server) setupRoutes(uh *handler.UserHandler, sh *handler.SampleHandler,
 					r.Delete("/like", uh.UnlikeUserHandler)
 					r.Post("/block", uh.BlockUserHandler)
 					r.Get("/profile", ph.GetUserProfileHandler)
+					r.Get("/pictures", ph.GetUserPicturesHandler)
 				})
 			})
 		})
@@ -195,7 +366,16 @@ func (s *Server) setupRoutes(uh *handler.UserHandler, sh *handler.SampleHandler,
 			r.Get("/views", uh.GetMyViewedListHandler)
 			r.Get("/blocks", uh.GetMyBlockedListHandler)
 			r.Get("/chats", ch.GetUserChats)
+			r.Get("/chats/search", ch.SearchChatMessagesHandler)
 			r.Get("/notifications", nh.GetUserNotifications)
+			r.Post("/notifications/read-all", nh.ReadAllNotificationsHandler)
+			r.Patch("/notifications/{notificationID}", nh.UpdateNotificationHandler)
+			r.Delete("/notifications/{notificationID}", nh.DeleteNotificationHandler)
+			r.Get("/notification-settings", nh.GetNotificationSettingsHandler)
+			r.Put("/notification-settings", nh.UpdateNotificationSettingsHandler)
+			r.Post("/push-subscriptions", pushh.SubscribeHandler)
+			r.Delete("/push-subscriptions", pushh.UnsubscribeHandler)
+			r.Post("/uploads", uploadh.CreateUploadHandler)
 
 			r.Route("/data", func(r chi.Router) {
 				r.Get("/", uh.GetMyUserDataHandler)
@@ -213,7 +393,9 @@ func (s *Server) setupRoutes(uh *handler.UserHandler, sh *handler.SampleHandler,
 				r.Post("/", ph.CreateProfileHandler)
 				r.Put("/", ph.UpdateProfileHandler)
 				r.Post("/pictures", ph.UploadProfilePictureHandler)
+				r.Put("/pictures/order", ph.ReorderPicturesHandler)
 				r.Delete("/pictures/{pictureID}", ph.DeleteProfilePictureHandler)
+				r.Put("/pictures/{pictureID}/primary", ph.SetProfilePictureHandler)
 				r.Get("/likes", ph.GetWhoLikeMeListHandler)
 				r.Get("/views", ph.GetWhoViewedMeListHandler)
 			})
@@ -222,15 +404,42 @@ func (s *Server) setupRoutes(uh *handler.UserHandler, sh *handler.SampleHandler,
 		r.Route("/tags", func(r chi.Router) {
 			r.Get("/", uh.GetAllTagsHandler)
 		})
+		r.Post("/notifications/unsubscribe", nh.UnsubscribeHandler)
+		r.Get("/push/public-key", pushh.GetPublicKeyHandler)
 		r.Route("/profiles", func(r chi.Router) {
 			r.Use(appmiddleware.AuthMiddleware(s.config.JWTSigningKey))
 			r.Get("/", ph.ListProfilesHandler)
 			r.Get("/recommends", ph.RecommendProfilesHandler)
 		})
+		r.Route("/chats/{userID}/attachments", func(r chi.Router) {
+			r.Use(appmiddleware.AuthMiddleware(s.config.JWTSigningKey))
+			r.Post("/", ch.UploadAttachmentHandler)
+			r.Get("/{attachmentID}", ch.GetAttachmentHandler)
+		})
 		r.Route("/chats/{userID}/messages", func(r chi.Router) {
 			r.Use(appmiddleware.AuthMiddleware(s.config.JWTSigningKey))
 			r.Get("/", ch.GetChatMessagesHandler)
+			r.Patch("/{messageID}", ch.EditMessageHandler)
+			r.Delete("/{messageID}", ch.DeleteMessageHandler)
+			r.Get("/{messageID}/reactions", ch.GetReactionsHandler)
+			r.Post("/{messageID}/reactions", ch.AddReactionHandler)
+			r.Delete("/{messageID}/reactions/{emoji}", ch.RemoveReactionHandler)
+		})
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(appmiddleware.AuthMiddleware(s.config.JWTSigningKey))
+			r.Use(appmiddleware.AdminMiddleware(s.config.AdminUserIDs))
+			r.Get("/held-messages", mh.ListHeldMessagesHandler)
+			r.Post("/held-messages/{heldMessageID}/approve", mh.ApproveHeldMessageHandler)
+			r.Post("/held-messages/{heldMessageID}/reject", mh.RejectHeldMessageHandler)
+			r.Get("/pictures", mh.ListPicturesHandler)
+			r.Post("/pictures/{pictureID}/approve", mh.ApprovePictureHandler)
+			r.Post("/pictures/{pictureID}/reject", mh.RejectPictureHandler)
+			r.Get("/fake-account-signals", mh.ListFakeAccountSignalsHandler)
+			r.Get("/users/{userID}/fake-account-signal", mh.GetFakeAccountSignalHandler)
 		})
+		if s.config.MailPreviewEnabled {
+			r.Get("/dev/mails/{template}", mailh.PreviewEmailHandler)
+		}
 	})
 	log.Println("Routes registered.")
 }
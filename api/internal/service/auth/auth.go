@@ -12,6 +12,7 @@ import (
 	"github.com/icchon/matcha/api/internal/domain/entity"
 	"github.com/icchon/matcha/api/internal/domain/repo"
 	"github.com/icchon/matcha/api/internal/domain/service"
+	"github.com/icchon/matcha/api/internal/i18n"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -156,17 +157,26 @@ func (s *authService) SendPasswordResetEmail(ctx context.Context, email string)
 		return apperrors.ErrNotFound
 	}
 	token := GenerateEmailToken()
-	if err := s.uow.Do(ctx, func(m repo.RepositoryManager) error {
+	return s.uow.Do(ctx, func(m repo.RepositoryManager) error {
 		passwordReset := &entity.PasswordReset{
 			UserID:    auth[0].UserID,
 			Token:     token,
 			ExpiresAt: time.Now().Add(time.Hour),
 		}
-		return m.PasswordResetRepo().Create(ctx, passwordReset)
-	}); err != nil {
-		return err
-	}
-	return s.mailService.SendPasswordResetEmail(ctx, email, token)
+		if err := m.PasswordResetRepo().Create(ctx, passwordReset); err != nil {
+			return err
+		}
+		// 言語を設定していればリクエストの Accept-Language より優先する
+		profile, err := m.ProfileRepo().Find(ctx, auth[0].UserID)
+		if err != nil {
+			return err
+		}
+		mailCtx := ctx
+		if profile != nil {
+			mailCtx = i18n.WithPreferred(ctx, profile.Locale.String)
+		}
+		return s.mailService.SendPasswordResetEmail(mailCtx, m, email, token)
+	})
 }
 
 func (s *authService) VerifyEmail(ctx context.Context, token string) error {
@@ -201,11 +211,18 @@ func (s *authService) VerifyEmail(ctx context.Context, token string) error {
 }
 
 func (s *authService) SendVerificationEmail(ctx context.Context, email string, userID uuid.UUID) error {
-	emailToken, err := s.IssueEMailToken(ctx, userID)
+	return s.uow.Do(ctx, func(m repo.RepositoryManager) error {
+		return s.sendVerificationEmail(ctx, m, email, userID)
+	})
+}
+
+// sendVerificationEmail はトークンの発行とメールの送信待ちを m のトランザクションで行う
+func (s *authService) sendVerificationEmail(ctx context.Context, m repo.RepositoryManager, email string, userID uuid.UUID) error {
+	emailToken, err := s.issueEmailToken(ctx, m, userID)
 	if err != nil {
 		return err
 	}
-	return s.mailService.SendVerificationEmail(ctx, email, "", emailToken)
+	return s.mailService.SendVerificationEmail(ctx, m, email, "", emailToken)
 }
 
 func (s *authService) Signup(ctx context.Context, email string, password string) error {
@@ -248,16 +265,31 @@ func (s *authService) Signup(ctx context.Context, email string, password string)
 			IsVerified:   false,
 		}
 		log.Printf("Creating auth record for user ID: %s", user.ID)
-		return m.AuthRepo().Create(ctx, auth)
+		if err := m.AuthRepo().Create(ctx, auth); err != nil {
+			return err
+		}
+		return s.sendVerificationEmail(ctx, m, email, id)
 	}); err != nil {
 		return err
 	}
-	return s.SendVerificationEmail(ctx, email, id)
+	return nil
 }
 
 func (s *authService) IssueEMailToken(ctx context.Context, userID uuid.UUID) (string, error) {
+	var token string
+	if err := s.uow.Do(ctx, func(m repo.RepositoryManager) error {
+		var err error
+		token, err = s.issueEmailToken(ctx, m, userID)
+		return err
+	}); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *authService) issueEmailToken(ctx context.Context, m repo.RepositoryManager, userID uuid.UUID) (string, error) {
 	token := GenerateEmailToken()
-	tokens, err := s.verificationRepo.Query(ctx, &repo.VerificationTokenQuery{UserID: &userID})
+	tokens, err := m.VerificationTokenRepo().Query(ctx, &repo.VerificationTokenQuery{UserID: &userID})
 	if err != nil {
 		return "", apperrors.ErrInternalServer
 	}
@@ -266,21 +298,16 @@ func (s *authService) IssueEMailToken(ctx context.Context, userID uuid.UUID) (st
 	} else if len(tokens) == 1 {
 		tokens[0].ExpiresAt = time.Now().Add(time.Hour)
 		tokens[0].Token = token
-		if err := s.uow.Do(ctx, func(m repo.RepositoryManager) error {
-			m.VerificationTokenRepo().Update(ctx, tokens[0])
-			return nil
-		}); err != nil {
+		if err := m.VerificationTokenRepo().Update(ctx, tokens[0]); err != nil {
 			return "", err
 		}
 	} else if len(tokens) == 0 {
-		if err := s.uow.Do(ctx, func(m repo.RepositoryManager) error {
-			verificationToken := &entity.VerificationToken{
-				UserID:    userID,
-				Token:     token,
-				ExpiresAt: time.Now().Add(time.Hour),
-			}
-			return m.VerificationTokenRepo().Create(ctx, verificationToken)
-		}); err != nil {
+		verificationToken := &entity.VerificationToken{
+			UserID:    userID,
+			Token:     token,
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		if err := m.VerificationTokenRepo().Create(ctx, verificationToken); err != nil {
 			return "", err
 		}
 	}
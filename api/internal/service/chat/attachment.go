@@ -0,0 +1,151 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+)
+
+const (
+	// 署名付き URL の有効期間。閲覧のたびに発行し直す
+	attachmentURLTTL = 10 * time.Minute
+	// 1 メッセージに付けられる添付ファイルの数
+	maxAttachmentsPerMessage = 4
+)
+
+func (s *chatService) UploadAttachment(ctx context.Context, userID, otherUserID uuid.UUID, file *client.FileSource) (*entity.MessageAttachment, error) {
+	if file == nil || (file.Reader == nil && file.UploadID == "") {
+		return nil, apperrors.ErrInvalidInput
+	}
+	conn, err := s.connRepo.Find(ctx, userID, otherUserID)
+	if err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+	if conn == nil {
+		return nil, apperrors.ErrForbidden
+	}
+
+	saved, err := s.fileClient.SaveAttachment(userID, file)
+	if err != nil {
+		switch {
+		case errors.Is(err, client.ErrFileQuotaExceeded):
+			return nil, apperrors.ErrTooManyRequests
+		case errors.Is(err, client.ErrUploadNotFound):
+			return nil, apperrors.ErrNotFound
+		case errors.Is(err, client.ErrUploadIncomplete):
+			return nil, apperrors.ErrConflict
+		}
+		return nil, apperrors.ErrInvalidInput
+	}
+	attachment := &entity.MessageAttachment{
+		ID:          saved.ID,
+		UploaderID:  userID,
+		RecipientID: otherUserID,
+		Mime:        saved.Mime,
+		Size:        saved.Size,
+		Width:       saved.Width,
+		Height:      saved.Height,
+	}
+	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		return rm.MessageAttachmentRepo().Create(ctx, attachment)
+	}); err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+	s.signAttachments(attachment)
+	return attachment, nil
+}
+
+func (s *chatService) GetAttachmentURL(ctx context.Context, userID, otherUserID uuid.UUID, attachmentID string) (string, error) {
+	attachment, err := s.attachmentRepo.Find(ctx, attachmentID)
+	if err != nil {
+		return "", apperrors.ErrInternalServer
+	}
+	// 会話の参加者以外には存在を隠す
+	if attachment == nil || !attachment.IsParticipant(userID) || !attachment.IsParticipant(otherUserID) || userID == otherUserID {
+		return "", apperrors.ErrNotFound
+	}
+	// まだ送っていない (保留中を含む) 添付ファイルはアップロードした本人にだけ見せる
+	if !attachment.MessageID.Valid {
+		if attachment.UploaderID != userID {
+			return "", apperrors.ErrNotFound
+		}
+		s.signAttachments(attachment)
+		return attachment.URL, nil
+	}
+	// withAttachments と同じく、送信取り消ししたメッセージの添付ファイルは見せない
+	msg, err := s.messageRepo.Find(ctx, attachment.MessageID.Int64)
+	if err != nil {
+		return "", apperrors.ErrInternalServer
+	}
+	if msg == nil || msg.DeletedAt.Valid {
+		return "", apperrors.ErrNotFound
+	}
+	s.signAttachments(attachment)
+	return attachment.URL, nil
+}
+
+func (s *chatService) ResolveAttachments(ctx context.Context, senderID, recipientID uuid.UUID, attachmentIDs []string) ([]*entity.MessageAttachment, error) {
+	if len(attachmentIDs) > maxAttachmentsPerMessage {
+		return nil, apperrors.ErrInvalidInput
+	}
+	seen := make(map[string]bool, len(attachmentIDs))
+	attachments := make([]*entity.MessageAttachment, 0, len(attachmentIDs))
+	for _, id := range attachmentIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		attachment, err := s.attachmentRepo.Find(ctx, id)
+		if err != nil {
+			return nil, apperrors.ErrInternalServer
+		}
+		// 自分がこの相手宛てにアップロードし、まだ送っていないものだけを使える
+		if attachment == nil || attachment.UploaderID != senderID || attachment.RecipientID != recipientID || attachment.MessageID.Valid {
+			return nil, apperrors.ErrInvalidInput
+		}
+		attachments = append(attachments, attachment)
+	}
+	s.signAttachments(attachments...)
+	return attachments, nil
+}
+
+// withAttachments はメッセージに添付ファイルを読み込み、署名付き URL を付ける
+func (s *chatService) withAttachments(ctx context.Context, messages []*entity.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(messages))
+	byID := make(map[int64]*entity.Message, len(messages))
+	for _, msg := range messages {
+		ids = append(ids, msg.ID)
+		byID[msg.ID] = msg
+	}
+	attachments, err := s.attachmentRepo.Query(ctx, &repo.MessageAttachmentQuery{MessageIDs: ids})
+	if err != nil {
+		return err
+	}
+	for _, attachment := range attachments {
+		msg, ok := byID[attachment.MessageID.Int64]
+		// 送信取り消ししたメッセージの添付ファイルは見せない
+		if !ok || msg.DeletedAt.Valid {
+			continue
+		}
+		s.signAttachments(attachment)
+		msg.Attachments = append(msg.Attachments, attachment)
+	}
+	return nil
+}
+
+func (s *chatService) signAttachments(attachments ...*entity.MessageAttachment) {
+	expiresAt := time.Now().Add(attachmentURLTTL)
+	for _, attachment := range attachments {
+		attachment.URL = s.fileClient.SignAttachmentURL(attachment.ID, expiresAt)
+	}
+}
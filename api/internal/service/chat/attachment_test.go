@@ -0,0 +1,188 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestChatService_UploadAttachment(t *testing.T) {
+	userID := uuid.New()
+	otherUserID := uuid.New()
+	file := &client.FileSource{Reader: strings.NewReader("img"), Filename: "a.png"}
+
+	testCases := []struct {
+		name        string
+		connection  *entity.Connection
+		expectedErr error
+	}{
+		{
+			name:       "Success",
+			connection: &entity.Connection{User1ID: userID, User2ID: otherUserID},
+		},
+		{
+			name:        "Not connected",
+			connection:  nil,
+			expectedErr: apperrors.ErrForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			connRepo := mock.NewMockConnectionQueryRepository(ctrl)
+			attachmentRepo := mock.NewMockMessageAttachmentRepository(ctrl)
+			fileClient := mock.NewMockFileClient(ctrl)
+
+			connRepo.EXPECT().Find(gomock.Any(), userID, otherUserID).Return(tc.connection, nil)
+			if tc.expectedErr == nil {
+				fileClient.EXPECT().SaveAttachment(userID, file).Return(&client.Attachment{
+					ID: "abc", Mime: "image/png", Size: 3, Width: 10, Height: 20,
+				}, nil)
+				attachmentRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, a *entity.MessageAttachment) error {
+					assert.Equal(t, userID, a.UploaderID)
+					assert.Equal(t, otherUserID, a.RecipientID)
+					assert.Equal(t, 20, a.Height)
+					return nil
+				})
+				fileClient.EXPECT().SignAttachmentURL("abc", gomock.Any()).Return("https://example.com/attachments/abc?sig=x")
+			}
+
+			s := &chatService{
+				uow:        &mockUow{rm: &mockRepositoryManager{attachmentRepo: attachmentRepo}},
+				connRepo:   connRepo,
+				fileClient: fileClient,
+			}
+			attachment, err := s.UploadAttachment(context.Background(), userID, otherUserID, file)
+			assert.Equal(t, tc.expectedErr, err)
+			if tc.expectedErr == nil {
+				assert.Equal(t, "https://example.com/attachments/abc?sig=x", attachment.URL)
+			}
+		})
+	}
+}
+
+func TestChatService_GetAttachmentURL(t *testing.T) {
+	senderID := uuid.New()
+	recipientID := uuid.New()
+	strangerID := uuid.New()
+	sent := sql.NullInt64{Int64: 1, Valid: true}
+	message := &entity.Message{ID: 1, SenderID: senderID, RecipientID: recipientID}
+	deleted := &entity.Message{ID: 1, SenderID: senderID, RecipientID: recipientID, DeletedAt: sql.NullTime{Time: time.Now(), Valid: true}}
+
+	testCases := []struct {
+		name        string
+		userID      uuid.UUID
+		otherUserID uuid.UUID
+		messageID   sql.NullInt64
+		// messageID があるときに Find が返すメッセージ
+		message     *entity.Message
+		expectedErr error
+	}{
+		{name: "Sender", userID: senderID, otherUserID: recipientID, messageID: sent, message: message},
+		{name: "Recipient", userID: recipientID, otherUserID: senderID, messageID: sent, message: message},
+		{name: "Stranger", userID: strangerID, otherUserID: senderID, messageID: sent, expectedErr: apperrors.ErrNotFound},
+		{name: "Deleted message", userID: recipientID, otherUserID: senderID, messageID: sent, message: deleted, expectedErr: apperrors.ErrNotFound},
+		{name: "Deleted message for the sender", userID: senderID, otherUserID: recipientID, messageID: sent, message: deleted, expectedErr: apperrors.ErrNotFound},
+		{name: "Missing message", userID: recipientID, otherUserID: senderID, messageID: sent, message: nil, expectedErr: apperrors.ErrNotFound},
+		// 保留中のメッセージの添付ファイルも、承認されるまではメッセージに紐付かない
+		{name: "Unsent attachment for the recipient", userID: recipientID, otherUserID: senderID, expectedErr: apperrors.ErrNotFound},
+		{name: "Unsent attachment for the uploader", userID: senderID, otherUserID: recipientID},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			attachmentRepo := mock.NewMockMessageAttachmentQueryRepository(ctrl)
+			messageRepo := mock.NewMockMessageQueryRepository(ctrl)
+			fileClient := mock.NewMockFileClient(ctrl)
+
+			attachment := &entity.MessageAttachment{ID: "abc", UploaderID: senderID, RecipientID: recipientID, MessageID: tc.messageID}
+			attachmentRepo.EXPECT().Find(gomock.Any(), "abc").Return(attachment, nil)
+			if tc.messageID.Valid && tc.userID != strangerID {
+				messageRepo.EXPECT().Find(gomock.Any(), tc.messageID.Int64).Return(tc.message, nil)
+			}
+			if tc.expectedErr == nil {
+				fileClient.EXPECT().SignAttachmentURL("abc", gomock.Any()).DoAndReturn(func(id string, expiresAt time.Time) string {
+					assert.WithinDuration(t, time.Now().Add(attachmentURLTTL), expiresAt, time.Minute)
+					return "signed"
+				})
+			}
+
+			s := &chatService{attachmentRepo: attachmentRepo, messageRepo: messageRepo, fileClient: fileClient}
+			url, err := s.GetAttachmentURL(context.Background(), tc.userID, tc.otherUserID, "abc")
+			assert.Equal(t, tc.expectedErr, err)
+			if tc.expectedErr == nil {
+				assert.Equal(t, "signed", url)
+			}
+		})
+	}
+}
+
+func TestChatService_ResolveAttachments(t *testing.T) {
+	senderID := uuid.New()
+	recipientID := uuid.New()
+
+	testCases := []struct {
+		name        string
+		attachment  *entity.MessageAttachment
+		expectedErr error
+	}{
+		{
+			name:       "Unsent attachment of the sender",
+			attachment: &entity.MessageAttachment{ID: "abc", UploaderID: senderID, RecipientID: recipientID},
+		},
+		{
+			name:        "Uploaded by someone else",
+			attachment:  &entity.MessageAttachment{ID: "abc", UploaderID: recipientID, RecipientID: senderID},
+			expectedErr: apperrors.ErrInvalidInput,
+		},
+		{
+			name:        "Already attached to a message",
+			attachment:  &entity.MessageAttachment{ID: "abc", UploaderID: senderID, RecipientID: recipientID, MessageID: sql.NullInt64{Int64: 1, Valid: true}},
+			expectedErr: apperrors.ErrInvalidInput,
+		},
+		{
+			name:        "Unknown attachment",
+			attachment:  nil,
+			expectedErr: apperrors.ErrInvalidInput,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			attachmentRepo := mock.NewMockMessageAttachmentQueryRepository(ctrl)
+			fileClient := mock.NewMockFileClient(ctrl)
+
+			attachmentRepo.EXPECT().Find(gomock.Any(), "abc").Return(tc.attachment, nil)
+			if tc.expectedErr == nil {
+				fileClient.EXPECT().SignAttachmentURL("abc", gomock.Any()).Return("signed")
+			}
+
+			s := &chatService{attachmentRepo: attachmentRepo, fileClient: fileClient}
+			attachments, err := s.ResolveAttachments(context.Background(), senderID, recipientID, []string{"abc", "abc"})
+			assert.Equal(t, tc.expectedErr, err)
+			if tc.expectedErr == nil {
+				assert.Len(t, attachments, 1)
+				assert.Equal(t, "signed", attachments[0].URL)
+			}
+		})
+	}
+}
@@ -5,24 +5,44 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/icchon/matcha/api/internal/apperrors"
-	"github.com/icchon/matcha/api/internal/domain/entity" // Added import
+	"github.com/icchon/matcha/api/internal/domain/client"
 	"github.com/icchon/matcha/api/internal/domain/repo"
 	"github.com/icchon/matcha/api/internal/domain/service"
 )
 
 type chatService struct {
-	connRepo    repo.ConnectionQueryRepository
-	messageRepo repo.MessageQueryRepository
-	profileSvc  service.ProfileService
+	uow              repo.UnitOfWork
+	connRepo         repo.ConnectionQueryRepository
+	messageRepo      repo.MessageQueryRepository
+	reactionRepo     repo.MessageReactionQueryRepository
+	attachmentRepo   repo.MessageAttachmentQueryRepository
+	readRepo         repo.ConversationReadQueryRepository
+	profileSvc       service.ProfileService
+	fileClient       client.FileClient
+	messageEditPub   client.Publisher
+	messageDeletePub client.Publisher
+	reactionPub      client.Publisher
+	moderationPub    client.Publisher
+	messageFilter    service.MessageFilter
 }
 
 var _ service.ChatService = (*chatService)(nil)
 
-func NewChatService(connRepo repo.ConnectionQueryRepository, messageRepo repo.MessageQueryRepository, profileSvc service.ProfileService) *chatService {
+func NewChatService(uow repo.UnitOfWork, connRepo repo.ConnectionQueryRepository, messageRepo repo.MessageQueryRepository, reactionRepo repo.MessageReactionQueryRepository, attachmentRepo repo.MessageAttachmentQueryRepository, readRepo repo.ConversationReadQueryRepository, profileSvc service.ProfileService, fileClient client.FileClient, messageEditPub, messageDeletePub, reactionPub, moderationPub client.Publisher, messageFilter service.MessageFilter) *chatService {
 	return &chatService{
-		connRepo:    connRepo,
-		messageRepo: messageRepo,
-		profileSvc:  profileSvc,
+		uow:              uow,
+		connRepo:         connRepo,
+		messageRepo:      messageRepo,
+		reactionRepo:     reactionRepo,
+		attachmentRepo:   attachmentRepo,
+		readRepo:         readRepo,
+		profileSvc:       profileSvc,
+		fileClient:       fileClient,
+		messageEditPub:   messageEditPub,
+		messageDeletePub: messageDeletePub,
+		reactionPub:      reactionPub,
+		moderationPub:    moderationPub,
+		messageFilter:    messageFilter,
 	}
 }
 
@@ -56,16 +76,22 @@ func (s *chatService) GetChatsForUser(ctx context.Context, userID uuid.UUID) ([]
 			continue
 		}
 
+		readState, err := s.readState(ctx, userID, otherUserID)
+		if err != nil {
+			return nil, apperrors.ErrInternalServer
+		}
+
 		chats = append(chats, &service.Chat{
 			OtherUser:   *otherUserProfile,
 			LastMessage: latestMessage,
+			ReadState:   *readState,
 		})
 	}
 
 	return chats, nil
 }
 
-func (s *chatService) GetChatMessages(ctx context.Context, params *service.GetChatMessagesParams) ([]*entity.Message, error) {
+func (s *chatService) GetChatMessages(ctx context.Context, params *service.GetChatMessagesParams) (*service.ChatHistory, error) {
 	q := &repo.MessageQuery{
 		SenderID:    &params.UserID1,
 		RecipientID: &params.UserID2,
@@ -77,6 +103,36 @@ func (s *chatService) GetChatMessages(ctx context.Context, params *service.GetCh
 	if err != nil {
 		return nil, apperrors.ErrInternalServer
 	}
+	if err := s.withAttachments(ctx, messages); err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+	readState, err := s.readState(ctx, params.UserID1, params.UserID2)
+	if err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
 
-	return messages, nil
+	return &service.ChatHistory{
+		Messages:  messages,
+		ReadState: *readState,
+	}, nil
+}
+
+// readState は userID と otherUserID の会話の、双方向の既読位置を返す
+func (s *chatService) readState(ctx context.Context, userID, otherUserID uuid.UUID) (*service.ReadState, error) {
+	state := &service.ReadState{}
+	mine, err := s.readRepo.Find(ctx, userID, otherUserID)
+	if err != nil {
+		return nil, err
+	}
+	if mine != nil {
+		state.LastReadMessageID = mine.LastReadMessageID
+	}
+	peer, err := s.readRepo.Find(ctx, otherUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if peer != nil {
+		state.PeerLastReadMessageID = peer.LastReadMessageID
+	}
+	return state, nil
 }
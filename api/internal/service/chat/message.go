@@ -0,0 +1,301 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
+	"github.com/lib/pq"
+)
+
+const (
+	// 送信後に編集できる期間
+	messageEditWindow = 15 * time.Minute
+	// message_reactions.emoji の長さ (byte)
+	maxEmojiBytes = 32
+)
+
+// findConversationMessage は userID と otherUserID の会話に属するメッセージを返す
+// 他人の会話のメッセージは存在を隠すため ErrNotFound にする
+func (s *chatService) findConversationMessage(ctx context.Context, userID, otherUserID uuid.UUID, messageID int64) (*entity.Message, error) {
+	msg, err := s.messageRepo.Find(ctx, messageID)
+	if err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+	if msg == nil {
+		return nil, apperrors.ErrNotFound
+	}
+	if !(msg.SenderID == userID && msg.RecipientID == otherUserID) && !(msg.SenderID == otherUserID && msg.RecipientID == userID) {
+		return nil, apperrors.ErrNotFound
+	}
+	return msg, nil
+}
+
+func (s *chatService) EditMessage(ctx context.Context, userID, otherUserID uuid.UUID, messageID int64, content string) (*entity.Message, *entity.HeldMessage, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, nil, apperrors.ErrInvalidInput
+	}
+	msg, err := s.findConversationMessage(ctx, userID, otherUserID, messageID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if msg.SenderID != userID {
+		return nil, nil, apperrors.ErrForbidden
+	}
+	if msg.DeletedAt.Valid {
+		return nil, nil, apperrors.ErrNotFound
+	}
+	now := time.Now()
+	if now.Sub(msg.SentAt) > messageEditWindow {
+		return nil, nil, apperrors.ErrForbidden
+	}
+
+	// 編集で禁止語や連絡先を後から書き込めないよう、新しい本文も送信時と同じ検査を通す
+	edited := *msg
+	edited.Content = content
+	result, err := s.messageFilter.Filter(ctx, &edited)
+	if err != nil {
+		return nil, nil, apperrors.ErrInternalServer
+	}
+	switch result.Action {
+	case service.ModerationReject:
+		if err := s.publish(ctx, s.moderationPub, &client.ModerationPayload{
+			UserID:      userID,
+			RecipientID: otherUserID,
+			Action:      client.ModerationEventReject,
+			Reason:      result.Reason,
+			Content:     content,
+			MessageID:   msg.ID,
+			SentAt:      msg.SentAt,
+		}); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, apperrors.ErrRejected
+	case service.ModerationHold:
+		held, err := s.holdEdit(ctx, msg, result)
+		if err != nil {
+			return nil, nil, err
+		}
+		return msg, held, nil
+	}
+	content = result.Content
+
+	var ok bool
+	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		ok, err = rm.MessageRepo().UpdateContent(ctx, msg.ID, content, now)
+		return err
+	}); err != nil {
+		return nil, nil, apperrors.ErrInternalServer
+	}
+	if !ok {
+		// 同時に取り消された
+		return nil, nil, apperrors.ErrNotFound
+	}
+	msg.Content = content
+	msg.EditedAt = sql.NullTime{Time: now, Valid: true}
+
+	if err := s.publish(ctx, s.messageEditPub, &client.MessageEditPayload{
+		MessageID:   msg.ID,
+		UserID:      userID,
+		RecipientID: otherUserID,
+		Content:     msg.Content,
+		EditedAt:    now,
+	}); err != nil {
+		return nil, nil, err
+	}
+	return msg, nil, nil
+}
+
+// holdEdit は編集を管理者の審査に回す。承認されるまでメッセージは元の本文のまま
+func (s *chatService) holdEdit(ctx context.Context, msg *entity.Message, result *service.ModerationResult) (*entity.HeldMessage, error) {
+	held := &entity.HeldMessage{
+		SenderID:      msg.SenderID,
+		RecipientID:   msg.RecipientID,
+		Content:       result.Content,
+		AttachmentIDs: pq.StringArray{},
+		Reason:        result.Reason,
+		SentAt:        msg.SentAt,
+		EditMessageID: sql.NullInt64{Int64: msg.ID, Valid: true},
+	}
+	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		return rm.HeldMessageRepo().Create(ctx, held)
+	}); err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+	if err := s.publish(ctx, s.moderationPub, &client.ModerationPayload{
+		UserID:        held.SenderID,
+		RecipientID:   held.RecipientID,
+		Action:        client.ModerationEventHold,
+		Reason:        held.Reason,
+		HeldMessageID: held.ID,
+		MessageID:     msg.ID,
+		SentAt:        held.SentAt,
+	}); err != nil {
+		return nil, err
+	}
+	return held, nil
+}
+
+func (s *chatService) DeleteMessage(ctx context.Context, userID, otherUserID uuid.UUID, messageID int64) (*entity.Message, error) {
+	msg, err := s.findConversationMessage(ctx, userID, otherUserID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.SenderID != userID {
+		return nil, apperrors.ErrForbidden
+	}
+	// 取り消し済みなら何もしない (冪等)
+	if msg.DeletedAt.Valid {
+		return msg, nil
+	}
+
+	now := time.Now()
+	var ok bool
+	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		var err error
+		ok, err = rm.MessageRepo().MarkDeleted(ctx, msg.ID, now)
+		return err
+	}); err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+	msg.Content = ""
+	msg.DeletedAt = sql.NullTime{Time: now, Valid: true}
+	if !ok {
+		// 同時に取り消された。通知は先に取り消した側が送っている
+		return msg, nil
+	}
+
+	if err := s.publish(ctx, s.messageDeletePub, &client.MessageDeletePayload{
+		MessageID:   msg.ID,
+		UserID:      userID,
+		RecipientID: otherUserID,
+		DeletedAt:   now,
+	}); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (s *chatService) GetReactions(ctx context.Context, userID, otherUserID uuid.UUID, messageID int64) ([]*entity.MessageReaction, error) {
+	if _, err := s.findConversationMessage(ctx, userID, otherUserID, messageID); err != nil {
+		return nil, err
+	}
+	reactions, err := s.reactionRepo.Query(ctx, &repo.MessageReactionQuery{MessageID: &messageID})
+	if err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+	return reactions, nil
+}
+
+func (s *chatService) AddReaction(ctx context.Context, userID, otherUserID uuid.UUID, messageID int64, emoji string) (*entity.MessageReaction, error) {
+	if !isEmoji(emoji) {
+		return nil, apperrors.ErrInvalidInput
+	}
+	msg, err := s.findConversationMessage(ctx, userID, otherUserID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.DeletedAt.Valid {
+		return nil, apperrors.ErrNotFound
+	}
+
+	reaction := &entity.MessageReaction{
+		MessageID: messageID,
+		UserID:    userID,
+		Emoji:     emoji,
+		CreatedAt: time.Now(),
+	}
+	var created bool
+	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		var err error
+		created, err = rm.MessageReactionRepo().Create(ctx, reaction)
+		return err
+	}); err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+	// 同じリアクションを付け直しても、相手には何度も届けない
+	if !created {
+		existing, err := s.reactionRepo.Find(ctx, messageID, userID, emoji)
+		if err != nil {
+			return nil, apperrors.ErrInternalServer
+		}
+		if existing != nil {
+			reaction = existing
+		}
+		return reaction, nil
+	}
+
+	if err := s.publish(ctx, s.reactionPub, &client.ReactionPayload{
+		MessageID:   messageID,
+		UserID:      userID,
+		RecipientID: otherUserID,
+		Emoji:       emoji,
+		Action:      client.ReactionActionAdd,
+		Timestamp:   reaction.CreatedAt.UnixMilli(),
+	}); err != nil {
+		return nil, err
+	}
+	return reaction, nil
+}
+
+func (s *chatService) RemoveReaction(ctx context.Context, userID, otherUserID uuid.UUID, messageID int64, emoji string) error {
+	if _, err := s.findConversationMessage(ctx, userID, otherUserID, messageID); err != nil {
+		return err
+	}
+	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		return rm.MessageReactionRepo().Delete(ctx, messageID, userID, emoji)
+	}); err != nil {
+		return apperrors.ErrInternalServer
+	}
+
+	return s.publish(ctx, s.reactionPub, &client.ReactionPayload{
+		MessageID:   messageID,
+		UserID:      userID,
+		RecipientID: otherUserID,
+		Emoji:       emoji,
+		Action:      client.ReactionActionRemove,
+		Timestamp:   time.Now().UnixMilli(),
+	})
+}
+
+func (s *chatService) publish(ctx context.Context, pub client.Publisher, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return apperrors.ErrInternalServer
+	}
+	if err := pub.Publish(ctx, data); err != nil {
+		return apperrors.ErrInternalServer
+	}
+	return nil
+}
+
+// isEmoji は 1 つの絵文字 (ZWJ 連結・肌色修飾・国旗・キーキャップを含む) らしい文字列かを判定する
+// ASCII (キーキャップを除く)・文字・空白・制御文字を含むものは弾く
+func isEmoji(s string) bool {
+	if s == "" || len(s) > maxEmojiBytes || !utf8.ValidString(s) {
+		return false
+	}
+	// U+20E3 COMBINING ENCLOSING KEYCAP (1️⃣ など)
+	keycap := strings.ContainsRune(s, '\u20e3')
+	hasSymbol := false
+	for _, r := range s {
+		switch {
+		case keycap && (('0' <= r && r <= '9') || r == '#' || r == '*'):
+		case r < utf8.RuneSelf, unicode.IsLetter(r), unicode.IsSpace(r), unicode.IsControl(r):
+			return false
+		case unicode.Is(unicode.So, r):
+			hasSymbol = true
+		}
+	}
+	return hasSymbol || keycap
+}
@@ -0,0 +1,354 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
+	"github.com/icchon/matcha/api/internal/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// mockRepositoryManager is a mock for repo.RepositoryManager.
+type mockRepositoryManager struct {
+	repo.RepositoryManager
+	messageRepo     repo.MessageRepository
+	reactionRepo    repo.MessageReactionRepository
+	attachmentRepo  repo.MessageAttachmentRepository
+	heldMessageRepo repo.HeldMessageRepository
+}
+
+func (m *mockRepositoryManager) MessageRepo() repo.MessageRepository {
+	return m.messageRepo
+}
+func (m *mockRepositoryManager) MessageReactionRepo() repo.MessageReactionRepository {
+	return m.reactionRepo
+}
+func (m *mockRepositoryManager) MessageAttachmentRepo() repo.MessageAttachmentRepository {
+	return m.attachmentRepo
+}
+func (m *mockRepositoryManager) HeldMessageRepo() repo.HeldMessageRepository {
+	return m.heldMessageRepo
+}
+
+// mockUow is a mock for repo.UnitOfWork for testing services.
+type mockUow struct {
+	rm  repo.RepositoryManager
+	err error
+}
+
+func (u *mockUow) Do(ctx context.Context, fn func(rm repo.RepositoryManager) error) error {
+	if u.err != nil {
+		return u.err
+	}
+	return fn(u.rm)
+}
+
+func TestChatService_EditMessage(t *testing.T) {
+	userID := uuid.New()
+	otherUserID := uuid.New()
+	strangerID := uuid.New()
+	editable := func() *entity.Message {
+		return &entity.Message{ID: 1, SenderID: userID, RecipientID: otherUserID, Content: "helo", SentAt: time.Now().Add(-time.Minute)}
+	}
+	allow := func(content string) *service.ModerationResult {
+		return &service.ModerationResult{Action: service.ModerationAllow, Content: content}
+	}
+
+	testCases := []struct {
+		name    string
+		message *entity.Message
+		content string
+		// nil ならフィルタまで届かない
+		filterResult *service.ModerationResult
+		// 保存・配信される本文。空なら保存されない
+		savedContent string
+		// 送信者に返す moderation イベント
+		moderationAction string
+		expectHeld       bool
+		expectedErr      error
+	}{
+		{
+			name:         "Success",
+			message:      editable(),
+			content:      "hello",
+			filterResult: allow("hello"),
+			savedContent: "hello",
+		},
+		{
+			name:         "Masked edit",
+			message:      editable(),
+			content:      "hello badword",
+			filterResult: &service.ModerationResult{Action: service.ModerationMask, Content: "hello *******"},
+			savedContent: "hello *******",
+		},
+		{
+			name:             "Rejected edit",
+			message:          editable(),
+			content:          "hello",
+			filterResult:     &service.ModerationResult{Action: service.ModerationReject, Content: "hello", Reason: "rate_limited"},
+			moderationAction: client.ModerationEventReject,
+			expectedErr:      apperrors.ErrRejected,
+		},
+		{
+			name:             "Held edit",
+			message:          editable(),
+			content:          "call 090-1234-5678",
+			filterResult:     &service.ModerationResult{Action: service.ModerationHold, Content: "call 090-1234-5678", Reason: "contact_info_on_new_match"},
+			moderationAction: client.ModerationEventHold,
+			expectHeld:       true,
+		},
+		{
+			name:        "Not the sender",
+			message:     &entity.Message{ID: 1, SenderID: otherUserID, RecipientID: userID, SentAt: time.Now()},
+			content:     "hello",
+			expectedErr: apperrors.ErrForbidden,
+		},
+		{
+			name:        "Edit window expired",
+			message:     &entity.Message{ID: 1, SenderID: userID, RecipientID: otherUserID, SentAt: time.Now().Add(-messageEditWindow - time.Minute)},
+			content:     "hello",
+			expectedErr: apperrors.ErrForbidden,
+		},
+		{
+			name:        "Deleted message",
+			message:     &entity.Message{ID: 1, SenderID: userID, RecipientID: otherUserID, SentAt: time.Now(), DeletedAt: sql.NullTime{Time: time.Now(), Valid: true}},
+			content:     "hello",
+			expectedErr: apperrors.ErrNotFound,
+		},
+		{
+			name:        "Message of another conversation",
+			message:     &entity.Message{ID: 1, SenderID: userID, RecipientID: strangerID, SentAt: time.Now()},
+			content:     "hello",
+			expectedErr: apperrors.ErrNotFound,
+		},
+		{
+			name:        "Empty content",
+			content:     "  ",
+			expectedErr: apperrors.ErrInvalidInput,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			msgQueryRepo := mock.NewMockMessageQueryRepository(ctrl)
+			msgRepo := mock.NewMockMessageRepository(ctrl)
+			heldRepo := mock.NewMockHeldMessageRepository(ctrl)
+			filter := mock.NewMockMessageFilter(ctrl)
+			editPub := mock.NewMockPublisher(ctrl)
+			moderationPub := mock.NewMockPublisher(ctrl)
+
+			if tc.message != nil {
+				msgQueryRepo.EXPECT().Find(gomock.Any(), int64(1)).Return(tc.message, nil)
+			}
+			if tc.filterResult != nil {
+				filter.EXPECT().Filter(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, m *entity.Message) (*service.ModerationResult, error) {
+					assert.Equal(t, tc.content, m.Content)
+					assert.Equal(t, userID, m.SenderID)
+					return tc.filterResult, nil
+				})
+			}
+			if tc.savedContent != "" {
+				msgRepo.EXPECT().UpdateContent(gomock.Any(), int64(1), tc.savedContent, gomock.Any()).Return(true, nil)
+				editPub.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, data interface{}) error {
+					var payload client.MessageEditPayload
+					assert.NoError(t, json.Unmarshal(data.([]byte), &payload))
+					assert.Equal(t, int64(1), payload.MessageID)
+					assert.Equal(t, userID, payload.UserID)
+					assert.Equal(t, otherUserID, payload.RecipientID)
+					assert.Equal(t, tc.savedContent, payload.Content)
+					return nil
+				})
+			}
+			if tc.expectHeld {
+				heldRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, held *entity.HeldMessage) error {
+					assert.Equal(t, tc.content, held.Content)
+					assert.Equal(t, int64(1), held.EditMessageID.Int64)
+					held.ID = 9
+					return nil
+				})
+			}
+			if tc.moderationAction != "" {
+				moderationPub.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, data interface{}) error {
+					var payload client.ModerationPayload
+					assert.NoError(t, json.Unmarshal(data.([]byte), &payload))
+					assert.Equal(t, tc.moderationAction, payload.Action)
+					assert.Equal(t, userID, payload.UserID)
+					assert.Equal(t, int64(1), payload.MessageID)
+					assert.Equal(t, tc.filterResult.Reason, payload.Reason)
+					return nil
+				})
+			}
+
+			s := &chatService{
+				uow:            &mockUow{rm: &mockRepositoryManager{messageRepo: msgRepo, heldMessageRepo: heldRepo}},
+				messageRepo:    msgQueryRepo,
+				messageEditPub: editPub,
+				moderationPub:  moderationPub,
+				messageFilter:  filter,
+			}
+			msg, held, err := s.EditMessage(context.Background(), userID, otherUserID, 1, tc.content)
+			assert.Equal(t, tc.expectedErr, err)
+			if tc.expectHeld {
+				// 承認されるまで元の本文のまま
+				assert.Equal(t, int64(9), held.ID)
+				assert.Equal(t, "helo", msg.Content)
+				assert.False(t, msg.EditedAt.Valid)
+			} else {
+				assert.Nil(t, held)
+			}
+			if tc.savedContent != "" {
+				assert.Equal(t, tc.savedContent, msg.Content)
+			}
+		})
+	}
+}
+
+func TestChatService_EditMessage_DeletedConcurrently(t *testing.T) {
+	userID := uuid.New()
+	otherUserID := uuid.New()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	msgQueryRepo := mock.NewMockMessageQueryRepository(ctrl)
+	msgRepo := mock.NewMockMessageRepository(ctrl)
+
+	msgQueryRepo.EXPECT().Find(gomock.Any(), int64(1)).Return(&entity.Message{
+		ID: 1, SenderID: userID, RecipientID: otherUserID, Content: "helo", SentAt: time.Now(),
+	}, nil)
+	// 読み込んだ後に取り消された
+	filter := mock.NewMockMessageFilter(ctrl)
+	filter.EXPECT().Filter(gomock.Any(), gomock.Any()).Return(&service.ModerationResult{Action: service.ModerationAllow, Content: "hello"}, nil)
+	msgRepo.EXPECT().UpdateContent(gomock.Any(), int64(1), "hello", gomock.Any()).Return(false, nil)
+
+	s := &chatService{
+		uow:           &mockUow{rm: &mockRepositoryManager{messageRepo: msgRepo}},
+		messageRepo:   msgQueryRepo,
+		messageFilter: filter,
+	}
+	_, _, err := s.EditMessage(context.Background(), userID, otherUserID, 1, "hello")
+	assert.Equal(t, apperrors.ErrNotFound, err)
+}
+
+func TestChatService_DeleteMessage(t *testing.T) {
+	userID := uuid.New()
+	otherUserID := uuid.New()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	msgQueryRepo := mock.NewMockMessageQueryRepository(ctrl)
+	msgRepo := mock.NewMockMessageRepository(ctrl)
+	deletePub := mock.NewMockPublisher(ctrl)
+
+	msgQueryRepo.EXPECT().Find(gomock.Any(), int64(1)).Return(&entity.Message{
+		ID: 1, SenderID: userID, RecipientID: otherUserID, Content: "oops", SentAt: time.Now().Add(-time.Hour),
+	}, nil)
+	msgRepo.EXPECT().MarkDeleted(gomock.Any(), int64(1), gomock.Any()).Return(true, nil)
+	deletePub.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil)
+
+	s := &chatService{
+		uow:              &mockUow{rm: &mockRepositoryManager{messageRepo: msgRepo}},
+		messageRepo:      msgQueryRepo,
+		messageDeletePub: deletePub,
+	}
+	msg, err := s.DeleteMessage(context.Background(), userID, otherUserID, 1)
+	assert.NoError(t, err)
+	assert.True(t, msg.DeletedAt.Valid)
+	assert.Empty(t, msg.Content)
+}
+
+func TestChatService_AddReaction(t *testing.T) {
+	userID := uuid.New()
+	otherUserID := uuid.New()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	msgQueryRepo := mock.NewMockMessageQueryRepository(ctrl)
+	reactionRepo := mock.NewMockMessageReactionRepository(ctrl)
+	reactionPub := mock.NewMockPublisher(ctrl)
+
+	// 相手のメッセージにもリアクションできる
+	msgQueryRepo.EXPECT().Find(gomock.Any(), int64(1)).Return(&entity.Message{
+		ID: 1, SenderID: otherUserID, RecipientID: userID, SentAt: time.Now().Add(-time.Hour),
+	}, nil)
+	reactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(true, nil)
+	reactionPub.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, data interface{}) error {
+		var payload client.ReactionPayload
+		assert.NoError(t, json.Unmarshal(data.([]byte), &payload))
+		assert.Equal(t, "👍", payload.Emoji)
+		assert.Equal(t, client.ReactionActionAdd, payload.Action)
+		return nil
+	})
+
+	s := &chatService{
+		uow:         &mockUow{rm: &mockRepositoryManager{reactionRepo: reactionRepo}},
+		messageRepo: msgQueryRepo,
+		reactionPub: reactionPub,
+	}
+	reaction, err := s.AddReaction(context.Background(), userID, otherUserID, 1, "👍")
+	assert.NoError(t, err)
+	assert.Equal(t, userID, reaction.UserID)
+
+	_, err = s.AddReaction(context.Background(), userID, otherUserID, 1, "lol")
+	assert.Equal(t, apperrors.ErrInvalidInput, err)
+}
+
+func TestChatService_AddReaction_Existing(t *testing.T) {
+	userID := uuid.New()
+	otherUserID := uuid.New()
+	createdAt := time.Now().Add(-time.Hour)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	msgQueryRepo := mock.NewMockMessageQueryRepository(ctrl)
+	reactionRepo := mock.NewMockMessageReactionRepository(ctrl)
+	reactionPub := mock.NewMockPublisher(ctrl)
+
+	msgQueryRepo.EXPECT().Find(gomock.Any(), int64(1)).Return(&entity.Message{
+		ID: 1, SenderID: otherUserID, RecipientID: userID, SentAt: time.Now().Add(-time.Hour),
+	}, nil)
+	reactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(false, nil)
+	reactionRepo.EXPECT().Find(gomock.Any(), int64(1), userID, "👍").Return(&entity.MessageReaction{
+		MessageID: 1, UserID: userID, Emoji: "👍", CreatedAt: createdAt,
+	}, nil)
+	// 既にあるリアクションは相手に送り直さない
+	reactionPub.EXPECT().Publish(gomock.Any(), gomock.Any()).Times(0)
+
+	s := &chatService{
+		uow:          &mockUow{rm: &mockRepositoryManager{reactionRepo: reactionRepo}},
+		messageRepo:  msgQueryRepo,
+		reactionRepo: reactionRepo,
+		reactionPub:  reactionPub,
+	}
+	reaction, err := s.AddReaction(context.Background(), userID, otherUserID, 1, "👍")
+
+	assert.NoError(t, err)
+	assert.Equal(t, createdAt, reaction.CreatedAt)
+}
+
+func TestIsEmoji(t *testing.T) {
+	valid := []string{"👍", "❤️", "👍🏽", "👨‍👩‍👧", "🇯🇵", "1️⃣"}
+	invalid := []string{"", "a", "ok", "1", "あ", "👍 ", "<b>"}
+	for _, s := range valid {
+		assert.True(t, isEmoji(s), s)
+	}
+	for _, s := range invalid {
+		assert.False(t, isEmoji(s), s)
+	}
+}
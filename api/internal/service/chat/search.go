@@ -0,0 +1,224 @@
+package chat
+
+import (
+	"context"
+	"html"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+	maxSearchQueryLen  = 100
+	// 抜粋で最初の一致の前後に残す文字数
+	snippetRadius = 30
+)
+
+func (s *chatService) SearchMessages(ctx context.Context, params *service.SearchChatMessagesParams) (*service.ChatSearchResult, error) {
+	text := strings.TrimSpace(params.Query)
+	if text == "" || utf8.RuneCountInString(text) > maxSearchQueryLen {
+		return nil, apperrors.ErrInvalidInput
+	}
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	q := &repo.MessageSearchQuery{
+		UserID: params.UserID,
+		Text:   text,
+		// 次のページがあるかを知るために 1 件多く取る
+		Limit: limit + 1,
+	}
+	if params.Cursor != "" {
+		beforeID, err := strconv.ParseInt(params.Cursor, 10, 64)
+		if err != nil || beforeID <= 0 {
+			return nil, apperrors.ErrInvalidInput
+		}
+		q.BeforeID = &beforeID
+	}
+
+	messages, err := s.messageRepo.Search(ctx, q)
+	if err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+
+	result := &service.ChatSearchResult{Hits: make([]*service.ChatSearchHit, 0, len(messages))}
+	if len(messages) > limit {
+		messages = messages[:limit]
+		result.NextCursor = strconv.FormatInt(messages[len(messages)-1].ID, 10)
+	}
+	terms := searchTerms(text)
+	for _, msg := range messages {
+		otherUserID := msg.RecipientID
+		if msg.RecipientID == params.UserID {
+			otherUserID = msg.SenderID
+		}
+		result.Hits = append(result.Hits, &service.ChatSearchHit{
+			MessageID:   msg.ID,
+			OtherUserID: otherUserID,
+			SenderID:    msg.SenderID,
+			SentAt:      msg.SentAt,
+			Snippet:     highlightSnippet(msg.Content, terms),
+		})
+	}
+	return result, nil
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Hiragana, unicode.Katakana, unicode.Han)
+}
+
+func isWordRune(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !isCJK(r)
+}
+
+type searchTerm struct {
+	runes []rune
+	cjk   bool
+}
+
+// searchTerms は検索文字列を英単語 (小文字化して語尾を落としたもの) と日本語の連続部分に分ける
+func searchTerms(text string) []searchTerm {
+	var terms []searchTerm
+	var current []rune
+	currentCJK := false
+	flush := func() {
+		if len(current) > 0 {
+			term := searchTerm{runes: current, cjk: currentCJK}
+			if !currentCJK {
+				term.runes = []rune(stem(strings.ToLower(string(current))))
+			}
+			if len(term.runes) > 0 {
+				terms = append(terms, term)
+			}
+		}
+		current = nil
+	}
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			if !currentCJK {
+				flush()
+			}
+			currentCJK = true
+			current = append(current, r)
+		case isWordRune(r):
+			if currentCJK {
+				flush()
+			}
+			currentCJK = false
+			current = append(current, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return terms
+}
+
+// stem は PostgreSQL の english 設定ほど正確ではないが、抜粋の強調用に よくある語尾を落とす
+func stem(word string) string {
+	for _, suffix := range []string{"ing", "ed", "es", "s"} {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 3 {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
+
+// highlightSnippet は最初の一致の前後 snippetRadius 文字を切り出し、一致部分を <mark> で囲む
+// 本文は HTML エスケープする
+func highlightSnippet(content string, terms []searchTerm) string {
+	runes := []rune(content)
+	marked := make([]bool, len(runes))
+
+	// 英単語は単語の先頭が一致すれば強調する
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && isWordRune(runes[j]) {
+			j++
+		}
+		word := strings.ToLower(string(runes[i:j]))
+		for _, term := range terms {
+			if !term.cjk && strings.HasPrefix(word, string(term.runes)) {
+				for k := i; k < j; k++ {
+					marked[k] = true
+				}
+				break
+			}
+		}
+		i = j
+	}
+	// 日本語は検索語そのまま、なければ 2-gram ごとに強調する
+	for _, term := range terms {
+		if !term.cjk {
+			continue
+		}
+		if markAll(runes, marked, term.runes) {
+			continue
+		}
+		for i := 0; i+1 < len(term.runes); i++ {
+			markAll(runes, marked, term.runes[i:i+2])
+		}
+	}
+
+	first := 0
+	for i, m := range marked {
+		if m {
+			first = i
+			break
+		}
+	}
+	start := max(first-snippetRadius, 0)
+	end := min(first+snippetRadius, len(runes))
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; {
+		j := i
+		for j < end && marked[j] == marked[i] {
+			j++
+		}
+		segment := html.EscapeString(string(runes[i:j]))
+		if marked[i] {
+			b.WriteString("<mark>" + segment + "</mark>")
+		} else {
+			b.WriteString(segment)
+		}
+		i = j
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// markAll は runes 中の term の出現をすべて marked に記録し、1 つでもあれば true を返す
+func markAll(runes []rune, marked []bool, term []rune) bool {
+	found := false
+	for i := 0; i+len(term) <= len(runes); i++ {
+		if string(runes[i:i+len(term)]) == string(term) {
+			for k := i; k < i+len(term); k++ {
+				marked[k] = true
+			}
+			found = true
+		}
+	}
+	return found
+}
@@ -0,0 +1,101 @@
+package chat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
+	"github.com/icchon/matcha/api/internal/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHighlightSnippet(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		query    string
+		expected string
+	}{
+		{
+			name:     "English word with a different suffix",
+			content:  "We went running <fast>",
+			query:    "runs",
+			expected: "We went <mark>running</mark> &lt;fast&gt;",
+		},
+		{
+			name:     "Japanese phrase",
+			content:  "明日は東京駅で会いましょう",
+			query:    "東京駅",
+			expected: "明日は<mark>東京駅</mark>で会いましょう",
+		},
+		{
+			name:     "Japanese bigrams that are not contiguous",
+			content:  "東京の駅",
+			query:    "東京駅",
+			expected: "<mark>東京</mark>の駅",
+		},
+		{
+			name:     "Long content is cut around the first match",
+			content:  "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa tennis bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+			query:    "tennis",
+			expected: "…aaaaaaaaaaaaaaaaaaaaaaaaaaaaa <mark>tennis</mark> bbbbbbbbbbbbbbbbbbbbbbb…",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, highlightSnippet(tc.content, searchTerms(tc.query)))
+		})
+	}
+}
+
+func TestChatService_SearchMessages(t *testing.T) {
+	userID := uuid.New()
+	otherUserID := uuid.New()
+
+	t.Run("Returns a cursor when there are more results", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		msgRepo := mock.NewMockMessageQueryRepository(ctrl)
+		msgRepo.EXPECT().Search(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, q *repo.MessageSearchQuery) ([]*entity.Message, error) {
+			assert.Equal(t, userID, q.UserID)
+			assert.Equal(t, "hello", q.Text)
+			assert.Equal(t, int64(100), *q.BeforeID)
+			assert.Equal(t, 3, q.Limit)
+			return []*entity.Message{
+				{ID: 99, SenderID: otherUserID, RecipientID: userID, Content: "hello"},
+				{ID: 98, SenderID: userID, RecipientID: otherUserID, Content: "hello there"},
+				{ID: 97, SenderID: otherUserID, RecipientID: userID, Content: "hello again"},
+			}, nil
+		})
+
+		s := &chatService{messageRepo: msgRepo}
+		result, err := s.SearchMessages(context.Background(), &service.SearchChatMessagesParams{
+			UserID: userID,
+			Query:  " hello ",
+			Cursor: "100",
+			Limit:  2,
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, result.Hits, 2)
+		assert.Equal(t, "98", result.NextCursor)
+		assert.Equal(t, otherUserID, result.Hits[0].OtherUserID)
+		assert.Equal(t, otherUserID, result.Hits[1].OtherUserID)
+		assert.Equal(t, "<mark>hello</mark>", result.Hits[0].Snippet)
+	})
+
+	t.Run("Rejects an empty query and a broken cursor", func(t *testing.T) {
+		s := &chatService{}
+		_, err := s.SearchMessages(context.Background(), &service.SearchChatMessagesParams{UserID: userID, Query: "  "})
+		assert.Equal(t, apperrors.ErrInvalidInput, err)
+		_, err = s.SearchMessages(context.Background(), &service.SearchChatMessagesParams{UserID: userID, Query: "a", Cursor: "x"})
+		assert.Equal(t, apperrors.ErrInvalidInput, err)
+	})
+}
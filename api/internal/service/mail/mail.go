@@ -3,47 +3,103 @@ package mail
 import (
 	"context"
 	"fmt"
-	// "github.com/icchon/matcha/api/internal/domain/entity"
-	"github.com/icchon/matcha/api/internal/domain/client"
+	"net/url"
+
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
 	"github.com/icchon/matcha/api/internal/domain/service"
 )
 
 type applicationMailService struct {
-	mailClient client.MailClient
-	baseURL    string
+	baseURL string
 }
 
 var _ service.MailService = (*applicationMailService)(nil)
 
-func NewApplicationMailService(client client.MailClient, baseURL string) *applicationMailService {
+func NewApplicationMailService(baseURL string) *applicationMailService {
 	return &applicationMailService{
-		mailClient: client,
-		baseURL:    baseURL,
+		baseURL: baseURL,
 	}
 }
 
-func (s *applicationMailService) SendVerificationEmail(ctx context.Context, toEmail string, username string, token string) error {
-	subject := "Matcha: アカウントの確認が必要です"
-	verificationLink := fmt.Sprintf("%s/verify?token=%s", s.baseURL, token)
+type verificationData struct {
+	Username string
+	Link     string
+}
+
+type passwordResetData struct {
+	Link string
+}
+
+type digestData struct {
+	Username        string
+	Matches         int
+	Likes           int
+	Messages        int
+	AppLink         string
+	UnsubscribeLink string
+}
+
+func (s *applicationMailService) SendVerificationEmail(ctx context.Context, rm repo.RepositoryManager, toEmail string, username string, token string) error {
+	return s.send(ctx, rm, toEmail, templateVerification, verificationData{
+		Username: username,
+		Link:     s.link("/verify", token),
+	})
+}
 
-	htmlBody := fmt.Sprintf(`
-		<h1>ようこそ、%sさん！</h1>
-		<p>以下のリンクをクリックしてアカウントを有効化してください。</p>
-		<a href="%s">アカウントを有効化</a>
-	`, username, verificationLink)
+func (s *applicationMailService) SendPasswordResetEmail(ctx context.Context, rm repo.RepositoryManager, toEmail string, token string) error {
+	return s.send(ctx, rm, toEmail, templatePasswordReset, passwordResetData{
+		Link: s.link("/reset-password", token),
+	})
+}
 
-	return s.mailClient.SendRawEmail(ctx, toEmail, subject, htmlBody, "")
+func (s *applicationMailService) SendDigestEmail(ctx context.Context, rm repo.RepositoryManager, toEmail string, username string, summary *service.DigestSummary, unsubscribeToken string) error {
+	return s.send(ctx, rm, toEmail, templateDigest, digestData{
+		Username:        username,
+		Matches:         summary.Matches,
+		Likes:           summary.Likes,
+		Messages:        summary.Messages,
+		AppLink:         s.baseURL,
+		UnsubscribeLink: s.link("/unsubscribe", unsubscribeToken),
+	})
 }
 
-func (s *applicationMailService) SendPasswordResetEmail(ctx context.Context, toEmail string, token string) error {
-	subject := "Matcha: パスワードのリセット依頼"
-	resetLink := fmt.Sprintf("%s/reset-password?token=%s", s.baseURL, token)
+// PreviewEmail は開発用に、サンプルのデータでメールを組み立てて返す
+func (s *applicationMailService) PreviewEmail(ctx context.Context, name string) (*service.RenderedEmail, error) {
+	samples := map[string]any{
+		templateVerification:  verificationData{Username: "matcha_user", Link: s.link("/verify", "sample-token")},
+		templatePasswordReset: passwordResetData{Link: s.link("/reset-password", "sample-token")},
+		templateDigest: digestData{
+			Username:        "matcha_user",
+			Matches:         1,
+			Likes:           3,
+			Messages:        2,
+			AppLink:         s.baseURL,
+			UnsubscribeLink: s.link("/unsubscribe", "sample-token"),
+		},
+	}
+	data, ok := samples[name]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	return render(ctx, name, data)
+}
 
-	htmlBody := fmt.Sprintf(`
-        <h1>パスワードリセット</h1>
-        <p>以下のリンクから新しいパスワードを設定してください。</p>
-        <a href="%s">パスワードをリセット</a>
-    `, resetLink)
+// send はメールを組み立てて送信待ちに積む
+func (s *applicationMailService) send(ctx context.Context, rm repo.RepositoryManager, toEmail string, name string, data any) error {
+	mail, err := render(ctx, name, data)
+	if err != nil {
+		return err
+	}
+	return rm.MailOutboxRepo().Create(ctx, &entity.OutboxMail{
+		ToEmail:  toEmail,
+		Subject:  mail.Subject,
+		HTMLBody: mail.HTML,
+		TextBody: mail.Text,
+	})
+}
 
-	return s.mailClient.SendRawEmail(ctx, toEmail, subject, htmlBody, "")
+func (s *applicationMailService) link(path string, token string) string {
+	return fmt.Sprintf("%s%s?token=%s", s.baseURL, path, url.QueryEscape(token))
 }
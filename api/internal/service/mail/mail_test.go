@@ -5,49 +5,68 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	domainService "github.com/icchon/matcha/api/internal/domain/service"
+	"github.com/icchon/matcha/api/internal/i18n"
 	"github.com/icchon/matcha/api/internal/mock"
 	mailService "github.com/icchon/matcha/api/internal/service/mail"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
 
+// mockRepositoryManager is a mock for repo.RepositoryManager.
+type mockRepositoryManager struct {
+	repo.RepositoryManager
+	outboxRepo repo.MailOutboxRepository
+}
+
+func (m *mockRepositoryManager) MailOutboxRepo() repo.MailOutboxRepository {
+	return m.outboxRepo
+}
+
+// mockUow is a mock for repo.UnitOfWork for testing services.
+type mockUow struct {
+	rm repo.RepositoryManager
+}
+
+func (u *mockUow) Do(ctx context.Context, fn func(rm repo.RepositoryManager) error) error {
+	return fn(u.rm)
+}
+
 func TestSendVerificationEmail(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockMailClient := mock.NewMockMailClient(ctrl)
-	service := mailService.NewApplicationMailService(mockMailClient, "http://localhost:3100")
+	outboxRepo := mock.NewMockMailOutboxRepository(ctrl)
+	rm := &mockRepositoryManager{outboxRepo: outboxRepo}
+	service := mailService.NewApplicationMailService("http://localhost:3100")
 
 	ctx := context.Background()
 	toEmail := "test@example.com"
 	username := "testuser"
 	token := "verification-token-123"
 
-	t.Run("successful verification email sending", func(t *testing.T) {
-		mockMailClient.EXPECT().SendRawEmail(
-			ctx,
-			toEmail,
-			gomock.Any(), // Subject
-			gomock.Any(), // HTML Body
-			gomock.Any(), // Text Body
-		).Return(nil).Times(1)
+	t.Run("successful verification email queueing", func(t *testing.T) {
+		outboxRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, mail *entity.OutboxMail) error {
+			assert.Equal(t, toEmail, mail.ToEmail)
+			assert.NotEmpty(t, mail.Subject)
+			assert.Contains(t, mail.HTMLBody, "http://localhost:3100/verify?token=verification-token-123")
+			assert.Contains(t, mail.TextBody, "http://localhost:3100/verify?token=verification-token-123")
+			return nil
+		}).Times(1)
 
-		err := service.SendVerificationEmail(ctx, toEmail, username, token)
+		err := service.SendVerificationEmail(ctx, rm, toEmail, username, token)
 		assert.NoError(t, err)
 	})
 
-	t.Run("failed verification email sending", func(t *testing.T) {
-		mockMailClient.EXPECT().SendRawEmail(
-			ctx,
-			toEmail,
-			gomock.Any(),
-			gomock.Any(),
-			gomock.Any(),
-		).Return(errors.New("smtp error")).Times(1)
+	t.Run("failed verification email queueing", func(t *testing.T) {
+		outboxRepo.EXPECT().Create(ctx, gomock.Any()).Return(errors.New("db error")).Times(1)
 
-		err := service.SendVerificationEmail(ctx, toEmail, username, token)
+		err := service.SendVerificationEmail(ctx, rm, toEmail, username, token)
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "smtp error")
+		assert.Contains(t, err.Error(), "db error")
 	})
 }
 
@@ -55,37 +74,112 @@ func TestSendPasswordResetEmail(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockMailClient := mock.NewMockMailClient(ctrl)
-	service := mailService.NewApplicationMailService(mockMailClient, "http://localhost:3100")
+	outboxRepo := mock.NewMockMailOutboxRepository(ctrl)
+	rm := &mockRepositoryManager{outboxRepo: outboxRepo}
+	service := mailService.NewApplicationMailService("http://localhost:3100")
 
 	ctx := context.Background()
 	toEmail := "test@example.com"
 	token := "password-reset-token-456"
 
-	t.Run("successful password reset email sending", func(t *testing.T) {
-		mockMailClient.EXPECT().SendRawEmail(
-			ctx,
-			toEmail,
-			gomock.Any(), // Subject
-			gomock.Any(), // HTML Body
-			gomock.Any(), // Text Body
-		).Return(nil).Times(1)
+	t.Run("successful password reset email queueing", func(t *testing.T) {
+		outboxRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, mail *entity.OutboxMail) error {
+			assert.Equal(t, toEmail, mail.ToEmail)
+			assert.Contains(t, mail.TextBody, "http://localhost:3100/reset-password?token=password-reset-token-456")
+			return nil
+		}).Times(1)
 
-		err := service.SendPasswordResetEmail(ctx, toEmail, token)
+		err := service.SendPasswordResetEmail(ctx, rm, toEmail, token)
 		assert.NoError(t, err)
 	})
 
-	t.Run("failed password reset email sending", func(t *testing.T) {
-		mockMailClient.EXPECT().SendRawEmail(
-			ctx,
-			toEmail,
-			gomock.Any(),
-			gomock.Any(),
-			gomock.Any(),
-		).Return(errors.New("smtp error")).Times(1)
+	t.Run("failed password reset email queueing", func(t *testing.T) {
+		outboxRepo.EXPECT().Create(ctx, gomock.Any()).Return(errors.New("db error")).Times(1)
 
-		err := service.SendPasswordResetEmail(ctx, toEmail, token)
+		err := service.SendPasswordResetEmail(ctx, rm, toEmail, token)
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "smtp error")
+		assert.Contains(t, err.Error(), "db error")
+	})
+}
+
+func TestSendDigestEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	outboxRepo := mock.NewMockMailOutboxRepository(ctrl)
+	rm := &mockRepositoryManager{outboxRepo: outboxRepo}
+	service := mailService.NewApplicationMailService("http://localhost:3100")
+
+	ctx := context.Background()
+	toEmail := "test@example.com"
+
+	t.Run("only non-zero counts are listed", func(t *testing.T) {
+		outboxRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, mail *entity.OutboxMail) error {
+			assert.Contains(t, mail.HTMLBody, "&lt;b&gt;user&lt;/b&gt;")
+			assert.Contains(t, mail.HTMLBody, "新しいマッチ: 2件")
+			assert.NotContains(t, mail.HTMLBody, "いいね")
+			assert.Contains(t, mail.HTMLBody, "http://localhost:3100/unsubscribe?token=abc.def")
+			return nil
+		}).Times(1)
+
+		err := service.SendDigestEmail(ctx, rm, toEmail, "<b>user</b>", &domainService.DigestSummary{Matches: 2, Messages: 1}, "abc.def")
+		assert.NoError(t, err)
+	})
+}
+
+func TestSendVerificationEmail_Locale(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	outboxRepo := mock.NewMockMailOutboxRepository(ctrl)
+	rm := &mockRepositoryManager{outboxRepo: outboxRepo}
+	service := mailService.NewApplicationMailService("http://localhost:3100")
+
+	testCases := []struct {
+		locale        i18n.Locale
+		expectSubject string
+		expectText    string
+	}{
+		{locale: i18n.Ja, expectSubject: "Matcha: アカウントの確認が必要です", expectText: "ようこそ、testuserさん！"},
+		{locale: i18n.En, expectSubject: "Matcha: Please verify your account", expectText: "Welcome, testuser!"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.locale), func(t *testing.T) {
+			ctx := i18n.WithLocale(context.Background(), tc.locale)
+			outboxRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, mail *entity.OutboxMail) error {
+				assert.Equal(t, tc.expectSubject, mail.Subject)
+				assert.Contains(t, mail.TextBody, tc.expectText)
+				assert.Contains(t, mail.TextBody, "http://localhost:3100/verify?token=token-123")
+				assert.NotContains(t, mail.TextBody, "<")
+				assert.Contains(t, mail.HTMLBody, `href="http://localhost:3100/verify?token=token-123"`)
+				return nil
+			})
+
+			err := service.SendVerificationEmail(ctx, rm, "test@example.com", "testuser", "token-123")
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPreviewEmail(t *testing.T) {
+	service := mailService.NewApplicationMailService("http://localhost:3100")
+
+	for _, locale := range i18n.Supported {
+		for _, name := range []string{"verification", "password_reset", "digest"} {
+			t.Run(string(locale)+"/"+name, func(t *testing.T) {
+				mail, err := service.PreviewEmail(i18n.WithLocale(context.Background(), locale), name)
+
+				assert.NoError(t, err)
+				assert.NotEmpty(t, mail.Subject)
+				assert.Contains(t, mail.HTML, "<html>")
+				assert.Contains(t, mail.Text, "http://localhost:3100")
+			})
+		}
+	}
+
+	t.Run("unknown template", func(t *testing.T) {
+		_, err := service.PreviewEmail(context.Background(), "nope")
+		assert.ErrorIs(t, err, apperrors.ErrNotFound)
 	})
 }
@@ -0,0 +1,103 @@
+package mail
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
+)
+
+const (
+	// 1 回に取り出すメールの数
+	outboxBatchSize = 20
+	// これだけ失敗したら諦めて failed にする
+	maxDeliveryAttempts = 8
+	// 再送までの待ち時間は 30 秒から倍々にして 1 時間で頭打ち
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = time.Hour
+)
+
+var _ service.MailDeliveryService = (*outboxWorker)(nil)
+
+type outboxWorker struct {
+	uow        repo.UnitOfWork
+	mailClient client.MailClient
+	now        func() time.Time
+}
+
+func NewOutboxWorker(uow repo.UnitOfWork, mailClient client.MailClient) *outboxWorker {
+	return &outboxWorker{
+		uow:        uow,
+		mailClient: mailClient,
+		now:        time.Now,
+	}
+}
+
+// RunOutboxWorker は ctx が終わるまで interval ごとに送信待ちのメールを送る
+func RunOutboxWorker(ctx context.Context, s service.MailDeliveryService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.DeliverPending(ctx); err != nil {
+				log.Printf("Failed to deliver outbox mails: %v", err)
+			}
+		}
+	}
+}
+
+// 送信後にコミットが失敗すると同じメールをもう一度送ることになるが、取りこぼすよりはよい
+func (w *outboxWorker) DeliverPending(ctx context.Context) (int, error) {
+	sent := 0
+	err := w.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		now := w.now()
+		mails, err := rm.MailOutboxRepo().FindDue(ctx, now, outboxBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, mail := range mails {
+			w.deliver(ctx, mail, now)
+			if mail.Status == entity.OutboxMailSent {
+				sent++
+			}
+			if err := rm.MailOutboxRepo().Update(ctx, mail); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return sent, err
+}
+
+func (w *outboxWorker) deliver(ctx context.Context, mail *entity.OutboxMail, now time.Time) {
+	mail.Attempts++
+	if err := w.mailClient.SendRawEmail(ctx, mail.ToEmail, mail.Subject, mail.HTMLBody, mail.TextBody); err != nil {
+		log.Printf("Failed to send mail %d (attempt %d): %v", mail.ID, mail.Attempts, err)
+		mail.LastError = sql.NullString{String: err.Error(), Valid: true}
+		if mail.Attempts >= maxDeliveryAttempts {
+			mail.Status = entity.OutboxMailFailed
+			return
+		}
+		mail.NextAttemptAt = now.Add(retryDelay(mail.Attempts))
+		return
+	}
+	mail.Status = entity.OutboxMailSent
+	mail.SentAt = sql.NullTime{Time: now, Valid: true}
+	mail.LastError = sql.NullString{}
+}
+
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, retryMaxDelay)
+}
@@ -0,0 +1,62 @@
+package mail_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/mock"
+	mailService "github.com/icchon/matcha/api/internal/service/mail"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestOutboxWorker_DeliverPending(t *testing.T) {
+	testCases := []struct {
+		name           string
+		attempts       int
+		sendErr        error
+		expectStatus   entity.OutboxMailStatus
+		expectSent     int
+		expectRetryMin time.Duration
+	}{
+		{name: "Sent", expectStatus: entity.OutboxMailSent, expectSent: 1},
+		{name: "First failure retries after 30s", sendErr: errors.New("smtp error"), expectStatus: entity.OutboxMailPending, expectRetryMin: 30 * time.Second},
+		{name: "Backoff doubles", attempts: 2, sendErr: errors.New("smtp error"), expectStatus: entity.OutboxMailPending, expectRetryMin: 2 * time.Minute},
+		{name: "Gives up after the last attempt", attempts: 7, sendErr: errors.New("smtp error"), expectStatus: entity.OutboxMailFailed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			outboxRepo := mock.NewMockMailOutboxRepository(ctrl)
+			mailClient := mock.NewMockMailClient(ctrl)
+			worker := mailService.NewOutboxWorker(&mockUow{rm: &mockRepositoryManager{outboxRepo: outboxRepo}}, mailClient)
+
+			mail := &entity.OutboxMail{ID: 1, ToEmail: "test@example.com", Subject: "s", HTMLBody: "h", TextBody: "t", Status: entity.OutboxMailPending, Attempts: tc.attempts}
+			outboxRepo.EXPECT().FindDue(gomock.Any(), gomock.Any(), gomock.Any()).Return([]*entity.OutboxMail{mail}, nil)
+			mailClient.EXPECT().SendRawEmail(gomock.Any(), "test@example.com", "s", "h", "t").Return(tc.sendErr)
+			outboxRepo.EXPECT().Update(gomock.Any(), mail).Return(nil)
+
+			before := time.Now()
+			sent, err := worker.DeliverPending(context.Background())
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectSent, sent)
+			assert.Equal(t, tc.expectStatus, mail.Status)
+			assert.Equal(t, tc.attempts+1, mail.Attempts)
+			if tc.sendErr != nil {
+				assert.Equal(t, "smtp error", mail.LastError.String)
+			} else {
+				assert.True(t, mail.SentAt.Valid)
+			}
+			if tc.expectRetryMin > 0 {
+				assert.Equal(t, tc.expectRetryMin, mail.NextAttemptAt.Sub(before).Round(time.Second))
+			}
+		})
+	}
+}
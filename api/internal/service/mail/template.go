@@ -0,0 +1,78 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/service"
+	"github.com/icchon/matcha/api/internal/i18n"
+)
+
+// templates/<locale>/<name>.html と .txt の組で 1 通分
+// .txt は "subject" と "body"、.html は layout.html の "content" を定義する
+//
+//go:embed templates
+var templateFS embed.FS
+
+const (
+	templateVerification  = "verification"
+	templatePasswordReset = "password_reset"
+	templateDigest        = "digest"
+)
+
+var templateNames = []string{templateVerification, templatePasswordReset, templateDigest}
+
+type mailTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// 埋め込みファイルなので、足りないテンプレートは起動時に panic させて気づけるようにする
+var mailTemplates = mustLoadTemplates()
+
+func mustLoadTemplates() map[string]*mailTemplate {
+	templates := make(map[string]*mailTemplate)
+	for _, locale := range i18n.Supported {
+		for _, name := range templateNames {
+			base := fmt.Sprintf("templates/%s/%s", locale, name)
+			templates[templateKey(locale, name)] = &mailTemplate{
+				html: htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/layout.html", base+".html")),
+				text: texttemplate.Must(texttemplate.ParseFS(templateFS, base+".txt")),
+			}
+		}
+	}
+	return templates
+}
+
+func templateKey(locale i18n.Locale, name string) string {
+	return string(locale) + "/" + name
+}
+
+// render は ctx の言語でメールの件名・HTML・テキストを作る
+func render(ctx context.Context, name string, data any) (*service.RenderedEmail, error) {
+	t, ok := mailTemplates[templateKey(i18n.FromContext(ctx), name)]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	var subject, text, html bytes.Buffer
+	if err := t.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := t.text.ExecuteTemplate(&text, "body", data); err != nil {
+		return nil, err
+	}
+	if err := t.html.ExecuteTemplate(&html, "layout", data); err != nil {
+		return nil, err
+	}
+	return &service.RenderedEmail{
+		Subject: strings.TrimSpace(subject.String()),
+		HTML:    html.String(),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+	}, nil
+}
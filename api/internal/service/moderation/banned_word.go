@@ -0,0 +1,60 @@
+package moderation
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/service"
+)
+
+const ReasonBannedWord = "banned_word"
+
+type bannedWordFilter struct {
+	pattern *regexp.Regexp
+}
+
+var _ service.MessageFilter = (*bannedWordFilter)(nil)
+
+// NewBannedWordFilter は禁止語を同じ文字数の * に置き換える (大文字小文字は区別しない)
+// 英数字だけの語は単語単位、それ以外 (日本語など) は部分一致で探す
+func NewBannedWordFilter(words []string) *bannedWordFilter {
+	alternatives := make([]string, 0, len(words))
+	for _, word := range words {
+		word = strings.TrimSpace(word)
+		if word == "" {
+			continue
+		}
+		quoted := regexp.QuoteMeta(word)
+		if isASCIIWord(word) {
+			quoted = `\b` + quoted + `\b`
+		}
+		alternatives = append(alternatives, quoted)
+	}
+	if len(alternatives) == 0 {
+		return &bannedWordFilter{}
+	}
+	return &bannedWordFilter{pattern: regexp.MustCompile(`(?i)(?:` + strings.Join(alternatives, "|") + `)`)}
+}
+
+func (f *bannedWordFilter) Filter(ctx context.Context, msg *entity.Message) (*service.ModerationResult, error) {
+	if f.pattern == nil || !f.pattern.MatchString(msg.Content) {
+		return &service.ModerationResult{Action: service.ModerationAllow, Content: msg.Content}, nil
+	}
+	masked := f.pattern.ReplaceAllStringFunc(msg.Content, func(word string) string {
+		return strings.Repeat("*", utf8.RuneCountInString(word))
+	})
+	return &service.ModerationResult{Action: service.ModerationMask, Content: masked, Reason: ReasonBannedWord}, nil
+}
+
+func isASCIIWord(word string) bool {
+	for _, r := range word {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
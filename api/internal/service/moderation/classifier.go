@@ -0,0 +1,42 @@
+package moderation
+
+import (
+	"context"
+	"image"
+
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/service"
+)
+
+type classifierChain struct {
+	classifiers []service.ImageClassifier
+}
+
+var _ service.ImageClassifier = (*classifierChain)(nil)
+
+// NewClassifierChain は写真を順番に検査する ImageClassifier を作る
+// 何も渡さなければすべての写真を承認する
+func NewClassifierChain(classifiers ...service.ImageClassifier) *classifierChain {
+	return &classifierChain{classifiers: classifiers}
+}
+
+// Classify はすべての検査を行い、最初に疑わしいとした理由を返す
+// 一致の記録は途中で止めると残らないので、疑わしいとされた後も続ける
+func (c *classifierChain) Classify(ctx context.Context, pic *entity.Picture, img image.Image) (*service.ImageClassification, error) {
+	result := &service.ImageClassification{}
+	for _, classifier := range c.classifiers {
+		r, err := classifier.Classify(ctx, pic, img)
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			continue
+		}
+		if r.Flagged && !result.Flagged {
+			result.Flagged = true
+			result.Reason = r.Reason
+		}
+		result.Matches = append(result.Matches, r.Matches...)
+	}
+	return result, nil
+}
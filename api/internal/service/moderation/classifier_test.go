@@ -0,0 +1,189 @@
+package moderation
+
+import (
+	"context"
+	"database/sql"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
+	"github.com/icchon/matcha/api/internal/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	skinColor  = color.RGBA{R: 224, G: 172, B: 140, A: 255}
+	otherColor = color.RGBA{R: 40, G: 90, B: 200, A: 255}
+)
+
+// halfImage は上から ratio の割合を肌色、残りを青で塗った画像を作る
+func halfImage(w, h int, ratio float64) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		c := otherColor
+		if float64(y) < float64(h)*ratio {
+			c = skinColor
+		}
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// gradientImage は横方向に明るさが変わる縞の画像を作る。reverse なら向きが逆になる
+func gradientImage(w, h int, reverse bool) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := (x*255/w + y*64/h) % 256
+			if reverse {
+				v = 255 - v
+			}
+			img.SetGray(x, y, color.Gray{Y: uint8(v)})
+		}
+	}
+	return img
+}
+
+// staticClassifier は決まった判定を返すテスト用の ImageClassifier
+type staticClassifier struct {
+	result *service.ImageClassification
+	called bool
+}
+
+func (c *staticClassifier) Classify(ctx context.Context, pic *entity.Picture, img image.Image) (*service.ImageClassification, error) {
+	c.called = true
+	return c.result, nil
+}
+
+func TestClassifierChain(t *testing.T) {
+	t.Run("First flag wins", func(t *testing.T) {
+		match := &entity.PictureMatch{PictureID: 1, MatchedPictureID: 2}
+		clean := &staticClassifier{result: &service.ImageClassification{}}
+		flag := &staticClassifier{result: &service.ImageClassification{Flagged: true, Reason: ReasonSkinTone}}
+		next := &staticClassifier{result: &service.ImageClassification{Flagged: true, Reason: ReasonDuplicate, Matches: []*entity.PictureMatch{match}}}
+
+		result, err := NewClassifierChain(clean, flag, next).Classify(context.Background(), &entity.Picture{}, nil)
+
+		assert.NoError(t, err)
+		assert.True(t, result.Flagged)
+		assert.Equal(t, ReasonSkinTone, result.Reason)
+		assert.True(t, clean.called)
+		// 後の検査の一致も残す
+		assert.True(t, next.called)
+		assert.Equal(t, []*entity.PictureMatch{match}, result.Matches)
+	})
+
+	t.Run("Empty chain approves everything", func(t *testing.T) {
+		result, err := NewClassifierChain().Classify(context.Background(), &entity.Picture{}, nil)
+
+		assert.NoError(t, err)
+		assert.False(t, result.Flagged)
+	})
+}
+
+func TestSkinToneClassifier(t *testing.T) {
+	c := NewSkinToneClassifier(0.6)
+
+	testCases := []struct {
+		name    string
+		ratio   float64
+		flagged bool
+	}{
+		{name: "Mostly skin tone", ratio: 0.8, flagged: true},
+		{name: "Some skin tone", ratio: 0.4, flagged: false},
+		{name: "No skin tone", ratio: 0, flagged: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := c.Classify(context.Background(), &entity.Picture{}, halfImage(48, 60, tc.ratio))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.flagged, result.Flagged)
+			if tc.flagged {
+				assert.Equal(t, ReasonSkinTone, result.Reason)
+			}
+		})
+	}
+}
+
+func TestDifferenceHash(t *testing.T) {
+	original := DifferenceHash(gradientImage(480, 600, false))
+
+	// 縮小しても同じ画像とみなせる
+	assert.LessOrEqual(t, HammingDistance(original, DifferenceHash(gradientImage(160, 200, false))), 4)
+	// 違う画像は大きく離れる
+	assert.Greater(t, HammingDistance(original, DifferenceHash(gradientImage(480, 600, true))), 32)
+}
+
+func TestPerceptualHash(t *testing.T) {
+	original := PerceptualHash(gradientImage(480, 600, false))
+
+	// 縮小しても重複として見つけられる距離に収まる
+	assert.LessOrEqual(t, HammingDistance(original, PerceptualHash(gradientImage(160, 200, false))), repo.MaxPictureHashDistance)
+	// 違う画像は大きく離れる
+	assert.Greater(t, HammingDistance(original, PerceptualHash(halfImage(480, 600, 0.5))), 16)
+}
+
+func TestDuplicateClassifier(t *testing.T) {
+	userID := uuid.New()
+	dhash := sql.NullInt64{Int64: 0x0f0f0f0f0f0f0f0f, Valid: true}
+	phash := sql.NullInt64{Int64: 0x00ff00ff00ff00ff, Valid: true}
+	pic := func() *entity.Picture {
+		return &entity.Picture{ID: 1, UserID: userID, DHash: dhash, PHash: phash}
+	}
+
+	t.Run("Similar picture of another user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		otherID := uuid.New()
+		pictureRepo := mock.NewMockPictureQueryRepository(ctrl)
+		pictureRepo.EXPECT().FindSimilar(gomock.Any(), userID, dhash.Int64, phash.Int64, 7).Return([]*entity.Picture{
+			{ID: 1, UserID: userID, DHash: dhash, PHash: phash},
+			// pHash のない古い写真は dHash だけで見つかる
+			{ID: 2, UserID: otherID, DHash: sql.NullInt64{Int64: dhash.Int64 ^ 0b101, Valid: true}},
+		}, nil)
+
+		result, err := NewDuplicateClassifier(pictureRepo, 7).Classify(context.Background(), pic(), nil)
+
+		assert.NoError(t, err)
+		assert.True(t, result.Flagged)
+		assert.Equal(t, ReasonDuplicate, result.Reason)
+		assert.Equal(t, []*entity.PictureMatch{{
+			PictureID:        1,
+			UserID:           userID,
+			MatchedPictureID: 2,
+			MatchedUserID:    otherID,
+			DHashDistance:    2,
+			PHashDistance:    64,
+		}}, result.Matches)
+	})
+
+	t.Run("No similar picture", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureRepo := mock.NewMockPictureQueryRepository(ctrl)
+		pictureRepo.EXPECT().FindSimilar(gomock.Any(), userID, dhash.Int64, phash.Int64, 7).Return(nil, nil)
+
+		result, err := NewDuplicateClassifier(pictureRepo, 7).Classify(context.Background(), pic(), nil)
+
+		assert.NoError(t, err)
+		assert.False(t, result.Flagged)
+		assert.Empty(t, result.Matches)
+	})
+
+	t.Run("Picture without hash", func(t *testing.T) {
+		result, err := NewDuplicateClassifier(nil, 7).Classify(context.Background(), &entity.Picture{ID: 1, UserID: userID, DHash: dhash}, nil)
+
+		assert.NoError(t, err)
+		assert.False(t, result.Flagged)
+	})
+}
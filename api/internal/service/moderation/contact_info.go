@@ -0,0 +1,69 @@
+package moderation
+
+import (
+	"context"
+	"regexp"
+	"time"
+	"unicode"
+
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
+)
+
+const ReasonContactInfo = "contact_info_on_new_match"
+
+var (
+	urlPattern = regexp.MustCompile(`(?i)(?:https?://|www\.)\S+|\b[a-z0-9][a-z0-9-]*\.(?:com|net|org|io|me|app|jp|co|ly|gg)\b`)
+	// 全角数字や区切り文字も含めて拾い、数字の桁数で電話番号か判定する
+	phonePattern = regexp.MustCompile(`[+＋]?[0-9０-９][0-9０-９\s\-‐－ー−().（）]{7,}[0-9０-９]`)
+)
+
+const minPhoneDigits = 10
+
+type contactInfoFilter struct {
+	connRepo    repo.ConnectionQueryRepository
+	freshWindow time.Duration
+	now         func() time.Time
+}
+
+var _ service.MessageFilter = (*contactInfoFilter)(nil)
+
+// NewContactInfoFilter はマッチして freshWindow 以内の相手に URL や電話番号を送ろうとしたメッセージを保留にする
+// マッチ直後に外部サービスへ誘導する詐欺・業者対策
+func NewContactInfoFilter(connRepo repo.ConnectionQueryRepository, freshWindow time.Duration) *contactInfoFilter {
+	return &contactInfoFilter{connRepo: connRepo, freshWindow: freshWindow, now: time.Now}
+}
+
+func (f *contactInfoFilter) Filter(ctx context.Context, msg *entity.Message) (*service.ModerationResult, error) {
+	allow := &service.ModerationResult{Action: service.ModerationAllow, Content: msg.Content}
+	if !containsContactInfo(msg.Content) {
+		return allow, nil
+	}
+	conn, err := f.connRepo.Find(ctx, msg.SenderID, msg.RecipientID)
+	if err != nil {
+		return nil, err
+	}
+	if conn != nil && f.now().Sub(conn.CreatedAt) >= f.freshWindow {
+		return allow, nil
+	}
+	return &service.ModerationResult{Action: service.ModerationHold, Content: msg.Content, Reason: ReasonContactInfo}, nil
+}
+
+func containsContactInfo(content string) bool {
+	if urlPattern.MatchString(content) {
+		return true
+	}
+	for _, candidate := range phonePattern.FindAllString(content, -1) {
+		digits := 0
+		for _, r := range candidate {
+			if unicode.IsDigit(r) {
+				digits++
+			}
+		}
+		if digits >= minPhoneDigits {
+			return true
+		}
+	}
+	return false
+}
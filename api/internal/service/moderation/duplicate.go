@@ -0,0 +1,66 @@
+package moderation
+
+import (
+	"context"
+	"database/sql"
+	"image"
+
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
+)
+
+const ReasonDuplicate = "duplicate_of_other_user"
+
+// ハッシュのない写真との距離。どのビットも一致しないとみなす
+const missingHashDistance = 64
+
+type duplicateClassifier struct {
+	pictureRepo repo.PictureQueryRepository
+	maxDistance int
+}
+
+var _ service.ImageClassifier = (*duplicateClassifier)(nil)
+
+// NewDuplicateClassifier は他のユーザーの写真と dHash か pHash が maxDistance ビット以内の写真を疑わしいとする
+// 他人の写真を使い回す偽アカウント対策。同じユーザーが同じ写真を上げ直すのは問題にしない
+// maxDistance は repo.MaxPictureHashDistance までしか効かない
+func NewDuplicateClassifier(pictureRepo repo.PictureQueryRepository, maxDistance int) *duplicateClassifier {
+	return &duplicateClassifier{pictureRepo: pictureRepo, maxDistance: maxDistance}
+}
+
+func (c *duplicateClassifier) Classify(ctx context.Context, pic *entity.Picture, img image.Image) (*service.ImageClassification, error) {
+	// 2 つのハッシュは同時に計算される。片方しかない写真は検査前のもの
+	if !pic.DHash.Valid || !pic.PHash.Valid {
+		return &service.ImageClassification{}, nil
+	}
+	similar, err := c.pictureRepo.FindSimilar(ctx, pic.UserID, pic.DHash.Int64, pic.PHash.Int64, c.maxDistance)
+	if err != nil {
+		return nil, err
+	}
+	result := &service.ImageClassification{}
+	for _, other := range similar {
+		// 却下された写真と似ていても疑わしい
+		if other.ID == pic.ID {
+			continue
+		}
+		result.Flagged = true
+		result.Reason = ReasonDuplicate
+		result.Matches = append(result.Matches, &entity.PictureMatch{
+			PictureID:        pic.ID,
+			UserID:           pic.UserID,
+			MatchedPictureID: other.ID,
+			MatchedUserID:    other.UserID,
+			DHashDistance:    hashDistance(pic.DHash, other.DHash),
+			PHashDistance:    hashDistance(pic.PHash, other.PHash),
+		})
+	}
+	return result, nil
+}
+
+func hashDistance(a, b sql.NullInt64) int {
+	if !a.Valid || !b.Valid {
+		return missingHashDistance
+	}
+	return HammingDistance(uint64(a.Int64), uint64(b.Int64))
+}
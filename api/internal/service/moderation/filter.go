@@ -0,0 +1,54 @@
+package moderation
+
+import (
+	"context"
+
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/service"
+)
+
+var actionRank = map[service.ModerationAction]int{
+	service.ModerationAllow:  0,
+	service.ModerationMask:   1,
+	service.ModerationHold:   2,
+	service.ModerationReject: 3,
+}
+
+type filterChain struct {
+	filters []service.MessageFilter
+}
+
+var _ service.MessageFilter = (*filterChain)(nil)
+
+// NewFilterChain はフィルタを順番に通すフィルタを作る
+func NewFilterChain(filters ...service.MessageFilter) *filterChain {
+	return &filterChain{filters: filters}
+}
+
+// Filter は最も強い判定を返す。reject が出た時点で打ち切る
+// mask された本文は次のフィルタに渡され、結果の Content になる
+func (c *filterChain) Filter(ctx context.Context, msg *entity.Message) (*service.ModerationResult, error) {
+	current := *msg
+	result := &service.ModerationResult{Action: service.ModerationAllow}
+	for _, f := range c.filters {
+		r, err := f.Filter(ctx, &current)
+		if err != nil {
+			return nil, err
+		}
+		if r == nil || r.Action == service.ModerationAllow {
+			continue
+		}
+		if r.Action == service.ModerationMask {
+			current.Content = r.Content
+		}
+		if actionRank[r.Action] > actionRank[result.Action] {
+			result.Action = r.Action
+			result.Reason = r.Reason
+		}
+		if r.Action == service.ModerationReject {
+			break
+		}
+	}
+	result.Content = current.Content
+	return result, nil
+}
@@ -0,0 +1,171 @@
+package moderation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/service"
+	"github.com/icchon/matcha/api/internal/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// staticFilter は決まった判定を返すテスト用のフィルタ
+type staticFilter struct {
+	result *service.ModerationResult
+	called bool
+	seen   string
+}
+
+func (f *staticFilter) Filter(ctx context.Context, msg *entity.Message) (*service.ModerationResult, error) {
+	f.called = true
+	f.seen = msg.Content
+	return f.result, nil
+}
+
+func TestFilterChain(t *testing.T) {
+	t.Run("Masked content is passed to later filters and the strongest action wins", func(t *testing.T) {
+		mask := &staticFilter{result: &service.ModerationResult{Action: service.ModerationMask, Content: "masked", Reason: ReasonBannedWord}}
+		hold := &staticFilter{result: &service.ModerationResult{Action: service.ModerationHold, Reason: ReasonContactInfo}}
+		msg := &entity.Message{Content: "original"}
+
+		result, err := NewFilterChain(mask, hold).Filter(context.Background(), msg)
+
+		assert.NoError(t, err)
+		assert.Equal(t, service.ModerationHold, result.Action)
+		assert.Equal(t, ReasonContactInfo, result.Reason)
+		assert.Equal(t, "masked", result.Content)
+		assert.Equal(t, "masked", hold.seen)
+		assert.Equal(t, "original", msg.Content)
+	})
+
+	t.Run("Reject stops the chain", func(t *testing.T) {
+		reject := &staticFilter{result: &service.ModerationResult{Action: service.ModerationReject, Reason: ReasonRateLimited}}
+		next := &staticFilter{result: &service.ModerationResult{Action: service.ModerationAllow}}
+
+		result, err := NewFilterChain(reject, next).Filter(context.Background(), &entity.Message{Content: "hi"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, service.ModerationReject, result.Action)
+		assert.False(t, next.called)
+	})
+
+	t.Run("Empty chain allows everything", func(t *testing.T) {
+		result, err := NewFilterChain().Filter(context.Background(), &entity.Message{Content: "hi"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, service.ModerationAllow, result.Action)
+		assert.Equal(t, "hi", result.Content)
+	})
+}
+
+func TestBannedWordFilter(t *testing.T) {
+	f := NewBannedWordFilter([]string{"spam", "ばか", " "})
+
+	testCases := []struct {
+		name     string
+		content  string
+		action   service.ModerationAction
+		expected string
+	}{
+		{name: "Clean message", content: "hello there", action: service.ModerationAllow, expected: "hello there"},
+		{name: "Case-insensitive whole word", content: "no SPAM please", action: service.ModerationMask, expected: "no **** please"},
+		{name: "ASCII word inside another word is kept", content: "spammer", action: service.ModerationAllow, expected: "spammer"},
+		{name: "Japanese word is matched as a substring", content: "あなたはばかです", action: service.ModerationMask, expected: "あなたは**です"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := f.Filter(context.Background(), &entity.Message{Content: tc.content})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.action, result.Action)
+			assert.Equal(t, tc.expected, result.Content)
+		})
+	}
+
+	t.Run("No words configured", func(t *testing.T) {
+		result, err := NewBannedWordFilter(nil).Filter(context.Background(), &entity.Message{Content: "spam"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, service.ModerationAllow, result.Action)
+	})
+}
+
+func TestContactInfoFilter(t *testing.T) {
+	senderID := uuid.New()
+	recipientID := uuid.New()
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name        string
+		content     string
+		connectedAt *time.Time
+		lookup      bool
+		action      service.ModerationAction
+	}{
+		{name: "Plain message skips the connection lookup", content: "nice to meet you", action: service.ModerationAllow},
+		{name: "Short number is not a phone number", content: "I have 2 cats and 12345 steps", action: service.ModerationAllow},
+		{name: "URL on a fresh match is held", content: "see https://example.com/me", connectedAt: ptr(now.Add(-time.Hour)), lookup: true, action: service.ModerationHold},
+		{name: "Bare domain on a fresh match is held", content: "add me on example.jp", connectedAt: ptr(now.Add(-time.Hour)), lookup: true, action: service.ModerationHold},
+		{name: "Phone number on a fresh match is held", content: "call 090-1234-5678", connectedAt: ptr(now.Add(-time.Hour)), lookup: true, action: service.ModerationHold},
+		{name: "Full-width phone number is detected", content: "電話は０９０１２３４５６７８", connectedAt: ptr(now.Add(-time.Hour)), lookup: true, action: service.ModerationHold},
+		{name: "Contact info is allowed on an older match", content: "call +81 90 1234 5678", connectedAt: ptr(now.Add(-48 * time.Hour)), lookup: true, action: service.ModerationAllow},
+		{name: "Contact info without a connection is held", content: "www.example.com", lookup: true, action: service.ModerationHold},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			connRepo := mock.NewMockConnectionQueryRepository(ctrl)
+			if tc.lookup {
+				var conn *entity.Connection
+				if tc.connectedAt != nil {
+					conn = &entity.Connection{User1ID: senderID, User2ID: recipientID, CreatedAt: *tc.connectedAt}
+				}
+				connRepo.EXPECT().Find(gomock.Any(), senderID, recipientID).Return(conn, nil)
+			}
+			f := NewContactInfoFilter(connRepo, 24*time.Hour)
+			f.now = func() time.Time { return now }
+
+			result, err := f.Filter(context.Background(), &entity.Message{SenderID: senderID, RecipientID: recipientID, Content: tc.content})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.action, result.Action)
+			if tc.action == service.ModerationHold {
+				assert.Equal(t, ReasonContactInfo, result.Reason)
+			}
+		})
+	}
+}
+
+func TestRateLimitFilter(t *testing.T) {
+	senderID := uuid.New()
+	otherID := uuid.New()
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	f := NewRateLimitFilter(2, time.Minute)
+	f.now = func() time.Time { return now }
+	send := func(sender uuid.UUID) service.ModerationAction {
+		result, err := f.Filter(context.Background(), &entity.Message{SenderID: sender, Content: "hi"})
+		assert.NoError(t, err)
+		return result.Action
+	}
+
+	assert.Equal(t, service.ModerationAllow, send(senderID))
+	assert.Equal(t, service.ModerationAllow, send(senderID))
+	assert.Equal(t, service.ModerationReject, send(senderID))
+	// 上限は送信者ごと
+	assert.Equal(t, service.ModerationAllow, send(otherID))
+
+	now = now.Add(time.Minute + time.Second)
+	assert.Equal(t, service.ModerationAllow, send(senderID))
+	assert.NotContains(t, f.sent, otherID)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,146 @@
+package moderation
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// dHash の大きさ。横に 1 つ多く取って隣り合う画素を比べ、64 ビットにする
+const (
+	dHashWidth  = 9
+	dHashHeight = 8
+)
+
+// pHash の大きさ。pHashSize 四方に縮めて DCT し、低い周波数の pHashBits 四方を使う
+const (
+	pHashSize = 32
+	pHashBits = 8
+)
+
+// DifferenceHash は img の dHash を返す
+// 9x8 の灰色に縮め、横に隣り合う画素の明るさを比べる。縮小や再圧縮では値がほとんど変わらない
+func DifferenceHash(img image.Image) uint64 {
+	cells := grayscale(img, dHashWidth, dHashHeight)
+	if cells == nil {
+		return 0
+	}
+	var hash uint64
+	for y := 0; y < dHashHeight; y++ {
+		for x := 0; x < dHashWidth-1; x++ {
+			hash <<= 1
+			if cells[y][x] > cells[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// PerceptualHash は img の pHash を返す
+// 32x32 の灰色に縮めて DCT し、直流成分を除く低い周波数の係数が中央値より大きいかを並べる
+// dHash より色調の補正や軽い切り抜きに強い
+func PerceptualHash(img image.Image) uint64 {
+	cells := grayscale(img, pHashSize, pHashSize)
+	if cells == nil {
+		return 0
+	}
+	coeffs := dct2(cells)
+
+	low := make([]float64, 0, pHashBits*pHashBits)
+	for y := 0; y < pHashBits; y++ {
+		low = append(low, coeffs[y][:pHashBits]...)
+	}
+	// 直流成分は画像全体の明るさなので中央値に含めない
+	sorted := append([]float64(nil), low[1:]...)
+	sort.Float64s(sorted)
+	median := (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+
+	var hash uint64
+	for _, c := range low {
+		hash <<= 1
+		if c > median {
+			hash |= 1
+		}
+	}
+	return hash
+}
+
+// HammingDistance は 2 つのハッシュで異なるビットの数を返す
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// grayscale は img を w x h の灰色に縮め、各マスに入る画素の明るさの平均を返す。空の画像なら nil
+func grayscale(img image.Image, w, h int) [][]float64 {
+	b := img.Bounds()
+	iw, ih := b.Dx(), b.Dy()
+	if iw == 0 || ih == 0 {
+		return nil
+	}
+	sums := make([][]float64, h)
+	counts := make([][]int, h)
+	for y := range sums {
+		sums[y] = make([]float64, w)
+		counts[y] = make([]int, w)
+	}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		cy := (y - b.Min.Y) * h / ih
+		for x := b.Min.X; x < b.Max.X; x++ {
+			cx := (x - b.Min.X) * w / iw
+			sums[cy][cx] += float64(color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y)
+			counts[cy][cx]++
+		}
+	}
+	// 画像がマスより小さいときは空のマスが残る。dHash と同じく 0 のままにする
+	for y := range sums {
+		for x := range sums[y] {
+			if counts[y][x] > 0 {
+				sums[y][x] = math.Floor(sums[y][x] / float64(counts[y][x]))
+			}
+		}
+	}
+	return sums
+}
+
+// dct2 は正方行列の 2 次元 DCT-II を行と列に分けて計算する
+func dct2(m [][]float64) [][]float64 {
+	n := len(m)
+	cos := make([][]float64, n)
+	for k := range cos {
+		cos[k] = make([]float64, n)
+		for i := range cos[k] {
+			cos[k][i] = math.Cos(math.Pi / float64(n) * (float64(i) + 0.5) * float64(k))
+		}
+	}
+	dct := func(in []float64) []float64 {
+		out := make([]float64, n)
+		for k := range out {
+			for i, v := range in {
+				out[k] += v * cos[k][i]
+			}
+		}
+		return out
+	}
+
+	rows := make([][]float64, n)
+	for y := range m {
+		rows[y] = dct(m[y])
+	}
+	out := make([][]float64, n)
+	for y := range out {
+		out[y] = make([]float64, n)
+	}
+	col := make([]float64, n)
+	for x := 0; x < n; x++ {
+		for y := 0; y < n; y++ {
+			col[y] = rows[y][x]
+		}
+		for y, v := range dct(col) {
+			out[y][x] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,267 @@
+package moderation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
+)
+
+const (
+	defaultHeldMessageLimit = 50
+	maxHeldMessageLimit     = 100
+)
+
+type moderationService struct {
+	uow            repo.UnitOfWork
+	heldRepo       repo.HeldMessageQueryRepository
+	chatService    service.ChatService
+	notifService   service.NotificationService
+	chatPub        client.Publisher
+	messageEditPub client.Publisher
+	moderationPub  client.Publisher
+}
+
+var _ service.ModerationService = (*moderationService)(nil)
+
+func NewModerationService(
+	uow repo.UnitOfWork,
+	heldRepo repo.HeldMessageQueryRepository,
+	chatService service.ChatService,
+	notifService service.NotificationService,
+	chatPub client.Publisher,
+	messageEditPub client.Publisher,
+	moderationPub client.Publisher,
+) *moderationService {
+	return &moderationService{
+		uow:            uow,
+		heldRepo:       heldRepo,
+		chatService:    chatService,
+		notifService:   notifService,
+		chatPub:        chatPub,
+		messageEditPub: messageEditPub,
+		moderationPub:  moderationPub,
+	}
+}
+
+func (s *moderationService) ListHeldMessages(ctx context.Context, params *service.ListHeldMessagesParams) ([]*entity.HeldMessage, error) {
+	status := params.Status
+	if status == "" {
+		status = entity.HeldMessagePending
+	}
+	switch status {
+	case entity.HeldMessagePending, entity.HeldMessageApproved, entity.HeldMessageRejected:
+	default:
+		return nil, apperrors.ErrInvalidInput
+	}
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultHeldMessageLimit
+	}
+	limit = min(limit, maxHeldMessageLimit)
+	helds, err := s.heldRepo.Query(ctx, &repo.HeldMessageQuery{
+		Status: &status,
+		Limit:  limit,
+		Offset: max(params.Offset, 0),
+	})
+	if err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+	if helds == nil {
+		helds = []*entity.HeldMessage{}
+	}
+	return helds, nil
+}
+
+// findPending は審査待ちのメッセージを返す。審査済みなら ErrConflict
+func (s *moderationService) findPending(ctx context.Context, heldMessageID int64) (*entity.HeldMessage, error) {
+	held, err := s.heldRepo.Find(ctx, heldMessageID)
+	if err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+	if held == nil {
+		return nil, apperrors.ErrNotFound
+	}
+	if held.Status != entity.HeldMessagePending {
+		return nil, apperrors.ErrConflict
+	}
+	return held, nil
+}
+
+func (s *moderationService) ApproveHeldMessage(ctx context.Context, reviewerID uuid.UUID, heldMessageID int64) (*entity.Message, error) {
+	held, err := s.findPending(ctx, heldMessageID)
+	if err != nil {
+		return nil, err
+	}
+	if held.EditMessageID.Valid {
+		return s.approveEdit(ctx, reviewerID, held)
+	}
+	msg := &entity.Message{
+		SenderID:    held.SenderID,
+		RecipientID: held.RecipientID,
+		Content:     held.Content,
+		SentAt:      held.SentAt,
+	}
+	if len(held.AttachmentIDs) > 0 {
+		attachments, err := s.chatService.ResolveAttachments(ctx, held.SenderID, held.RecipientID, held.AttachmentIDs)
+		if err != nil {
+			return nil, err
+		}
+		msg.Attachments = attachments
+	}
+	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		if err := rm.MessageRepo().Create(ctx, msg); err != nil {
+			return err
+		}
+		for _, attachment := range msg.Attachments {
+			if err := rm.MessageAttachmentRepo().AttachToMessage(ctx, attachment.ID, msg.ID); err != nil {
+				return err
+			}
+		}
+		held.Status = entity.HeldMessageApproved
+		held.ReviewedBy = uuid.NullUUID{UUID: reviewerID, Valid: true}
+		held.ReviewedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		held.MessageID = sql.NullInt64{Int64: msg.ID, Valid: true}
+		ok, err := rm.HeldMessageRepo().Review(ctx, held)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			// 同時に審査された
+			return apperrors.ErrConflict
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := s.publish(ctx, s.chatPub, client.NewMessagePayload(msg)); err != nil {
+		return nil, err
+	}
+	if err := s.publish(ctx, s.moderationPub, &client.ModerationPayload{
+		UserID:        held.SenderID,
+		RecipientID:   held.RecipientID,
+		Action:        client.ModerationEventApprove,
+		HeldMessageID: held.ID,
+		MessageID:     msg.ID,
+		SentAt:        held.SentAt,
+	}); err != nil {
+		return nil, err
+	}
+	if _, err := s.notifService.CreateAndSendNotification(ctx, msg.SenderID, msg.RecipientID, entity.NotifMessage); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// approveEdit は保留した編集をメッセージに反映する
+// その間にメッセージが取り消されていたら反映できないので ErrConflict にする。管理者は却下すればよい
+func (s *moderationService) approveEdit(ctx context.Context, reviewerID uuid.UUID, held *entity.HeldMessage) (*entity.Message, error) {
+	now := time.Now()
+	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		ok, err := rm.MessageRepo().UpdateContent(ctx, held.EditMessageID.Int64, held.Content, now)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return apperrors.ErrConflict
+		}
+		held.Status = entity.HeldMessageApproved
+		held.ReviewedBy = uuid.NullUUID{UUID: reviewerID, Valid: true}
+		held.ReviewedAt = sql.NullTime{Time: now, Valid: true}
+		held.MessageID = held.EditMessageID
+		if ok, err = rm.HeldMessageRepo().Review(ctx, held); err != nil {
+			return err
+		}
+		if !ok {
+			// 同時に審査された
+			return apperrors.ErrConflict
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	msg := &entity.Message{
+		ID:          held.EditMessageID.Int64,
+		SenderID:    held.SenderID,
+		RecipientID: held.RecipientID,
+		Content:     held.Content,
+		SentAt:      held.SentAt,
+		EditedAt:    sql.NullTime{Time: now, Valid: true},
+	}
+	if err := s.publish(ctx, s.messageEditPub, &client.MessageEditPayload{
+		MessageID:   msg.ID,
+		UserID:      msg.SenderID,
+		RecipientID: msg.RecipientID,
+		Content:     msg.Content,
+		EditedAt:    now,
+	}); err != nil {
+		return nil, err
+	}
+	if err := s.publish(ctx, s.moderationPub, &client.ModerationPayload{
+		UserID:        held.SenderID,
+		RecipientID:   held.RecipientID,
+		Action:        client.ModerationEventApprove,
+		HeldMessageID: held.ID,
+		MessageID:     msg.ID,
+		SentAt:        held.SentAt,
+	}); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (s *moderationService) RejectHeldMessage(ctx context.Context, reviewerID uuid.UUID, heldMessageID int64, reason string) (*entity.HeldMessage, error) {
+	held, err := s.findPending(ctx, heldMessageID)
+	if err != nil {
+		return nil, err
+	}
+	if reason == "" {
+		reason = held.Reason
+	}
+	held.Status = entity.HeldMessageRejected
+	held.ReviewedBy = uuid.NullUUID{UUID: reviewerID, Valid: true}
+	held.ReviewedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		ok, err := rm.HeldMessageRepo().Review(ctx, held)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return apperrors.ErrConflict
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := s.publish(ctx, s.moderationPub, &client.ModerationPayload{
+		UserID:        held.SenderID,
+		RecipientID:   held.RecipientID,
+		Action:        client.ModerationEventReject,
+		Reason:        reason,
+		Content:       held.Content,
+		HeldMessageID: held.ID,
+		MessageID:     held.EditMessageID.Int64,
+		SentAt:        held.SentAt,
+	}); err != nil {
+		return nil, err
+	}
+	return held, nil
+}
+
+func (s *moderationService) publish(ctx context.Context, pub client.Publisher, payload interface{}) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return pub.Publish(ctx, payloadBytes)
+}
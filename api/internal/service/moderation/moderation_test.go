@@ -0,0 +1,243 @@
+package moderation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// mockRepositoryManager is a mock for repo.RepositoryManager.
+type mockRepositoryManager struct {
+	repo.RepositoryManager
+	messageRepo      repo.MessageRepository
+	heldMessageRepo  repo.HeldMessageRepository
+	pictureRepo      repo.PictureRepository
+	pictureMatchRepo repo.PictureMatchRepository
+}
+
+func (m *mockRepositoryManager) MessageRepo() repo.MessageRepository {
+	return m.messageRepo
+}
+func (m *mockRepositoryManager) HeldMessageRepo() repo.HeldMessageRepository {
+	return m.heldMessageRepo
+}
+func (m *mockRepositoryManager) PictureRepo() repo.PictureRepository {
+	return m.pictureRepo
+}
+func (m *mockRepositoryManager) PictureMatchRepo() repo.PictureMatchRepository {
+	return m.pictureMatchRepo
+}
+
+// mockUow is a mock for repo.UnitOfWork for testing services.
+type mockUow struct {
+	rm repo.RepositoryManager
+}
+
+func (u *mockUow) Do(ctx context.Context, fn func(rm repo.RepositoryManager) error) error {
+	return fn(u.rm)
+}
+
+func TestModerationService_ApproveHeldMessage(t *testing.T) {
+	reviewerID := uuid.New()
+	senderID := uuid.New()
+	recipientID := uuid.New()
+	pending := func() *entity.HeldMessage {
+		return &entity.HeldMessage{
+			ID: 3, SenderID: senderID, RecipientID: recipientID, Content: "see example.com",
+			Reason: ReasonContactInfo, Status: entity.HeldMessagePending, SentAt: time.Now(),
+		}
+	}
+
+	t.Run("Approved message is stored and delivered", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		heldRepo := mock.NewMockHeldMessageRepository(ctrl)
+		messageRepo := mock.NewMockMessageRepository(ctrl)
+		notifService := mock.NewMockNotificationService(ctrl)
+		chatPub := mock.NewMockPublisher(ctrl)
+		moderationPub := mock.NewMockPublisher(ctrl)
+		s := NewModerationService(
+			&mockUow{rm: &mockRepositoryManager{messageRepo: messageRepo, heldMessageRepo: heldRepo}},
+			heldRepo, nil, notifService, chatPub, nil, moderationPub,
+		)
+
+		heldRepo.EXPECT().Find(gomock.Any(), int64(3)).Return(pending(), nil)
+		messageRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, msg *entity.Message) error {
+			assert.Equal(t, "see example.com", msg.Content)
+			msg.ID = 10
+			return nil
+		})
+		heldRepo.EXPECT().Review(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, held *entity.HeldMessage) (bool, error) {
+			assert.Equal(t, entity.HeldMessageApproved, held.Status)
+			assert.Equal(t, reviewerID, held.ReviewedBy.UUID)
+			assert.Equal(t, int64(10), held.MessageID.Int64)
+			return true, nil
+		})
+		chatPub.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil)
+		moderationPub.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, data interface{}) error {
+			var payload client.ModerationPayload
+			assert.NoError(t, json.Unmarshal(data.([]byte), &payload))
+			assert.Equal(t, senderID, payload.UserID)
+			assert.Equal(t, client.ModerationEventApprove, payload.Action)
+			assert.Equal(t, int64(10), payload.MessageID)
+			return nil
+		})
+		notifService.EXPECT().CreateAndSendNotification(gomock.Any(), senderID, recipientID, entity.NotifMessage).Return(&entity.Notification{}, nil)
+
+		msg, err := s.ApproveHeldMessage(context.Background(), reviewerID, 3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10), msg.ID)
+	})
+
+	t.Run("Approved edit updates the message", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		heldRepo := mock.NewMockHeldMessageRepository(ctrl)
+		messageRepo := mock.NewMockMessageRepository(ctrl)
+		editPub := mock.NewMockPublisher(ctrl)
+		moderationPub := mock.NewMockPublisher(ctrl)
+		s := NewModerationService(
+			&mockUow{rm: &mockRepositoryManager{messageRepo: messageRepo, heldMessageRepo: heldRepo}},
+			heldRepo, nil, nil, nil, editPub, moderationPub,
+		)
+
+		held := pending()
+		held.EditMessageID = sql.NullInt64{Int64: 7, Valid: true}
+		heldRepo.EXPECT().Find(gomock.Any(), int64(3)).Return(held, nil)
+		// 新しいメッセージは作らず、通知もしない
+		messageRepo.EXPECT().UpdateContent(gomock.Any(), int64(7), "see example.com", gomock.Any()).Return(true, nil)
+		heldRepo.EXPECT().Review(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, held *entity.HeldMessage) (bool, error) {
+			assert.Equal(t, int64(7), held.MessageID.Int64)
+			return true, nil
+		})
+		editPub.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, data interface{}) error {
+			var payload client.MessageEditPayload
+			assert.NoError(t, json.Unmarshal(data.([]byte), &payload))
+			assert.Equal(t, int64(7), payload.MessageID)
+			assert.Equal(t, "see example.com", payload.Content)
+			return nil
+		})
+		moderationPub.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil)
+
+		msg, err := s.ApproveHeldMessage(context.Background(), reviewerID, 3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), msg.ID)
+		assert.True(t, msg.EditedAt.Valid)
+	})
+
+	t.Run("Edit of a deleted message cannot be approved", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		heldRepo := mock.NewMockHeldMessageRepository(ctrl)
+		messageRepo := mock.NewMockMessageRepository(ctrl)
+		s := NewModerationService(
+			&mockUow{rm: &mockRepositoryManager{messageRepo: messageRepo, heldMessageRepo: heldRepo}},
+			heldRepo, nil, nil, nil, nil, nil,
+		)
+
+		held := pending()
+		held.EditMessageID = sql.NullInt64{Int64: 7, Valid: true}
+		heldRepo.EXPECT().Find(gomock.Any(), int64(3)).Return(held, nil)
+		messageRepo.EXPECT().UpdateContent(gomock.Any(), int64(7), gomock.Any(), gomock.Any()).Return(false, nil)
+
+		_, err := s.ApproveHeldMessage(context.Background(), reviewerID, 3)
+
+		assert.ErrorIs(t, err, apperrors.ErrConflict)
+	})
+
+	t.Run("Concurrent review rolls back", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		heldRepo := mock.NewMockHeldMessageRepository(ctrl)
+		messageRepo := mock.NewMockMessageRepository(ctrl)
+		s := NewModerationService(
+			&mockUow{rm: &mockRepositoryManager{messageRepo: messageRepo, heldMessageRepo: heldRepo}},
+			heldRepo, nil, nil, nil, nil, nil,
+		)
+
+		heldRepo.EXPECT().Find(gomock.Any(), int64(3)).Return(pending(), nil)
+		messageRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+		heldRepo.EXPECT().Review(gomock.Any(), gomock.Any()).Return(false, nil)
+
+		_, err := s.ApproveHeldMessage(context.Background(), reviewerID, 3)
+
+		assert.ErrorIs(t, err, apperrors.ErrConflict)
+	})
+
+	t.Run("Unknown held message", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		heldRepo := mock.NewMockHeldMessageRepository(ctrl)
+		s := NewModerationService(&mockUow{}, heldRepo, nil, nil, nil, nil, nil)
+
+		heldRepo.EXPECT().Find(gomock.Any(), int64(3)).Return(nil, nil)
+
+		_, err := s.ApproveHeldMessage(context.Background(), reviewerID, 3)
+
+		assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	})
+}
+
+func TestModerationService_RejectHeldMessage(t *testing.T) {
+	reviewerID := uuid.New()
+	senderID := uuid.New()
+
+	t.Run("Rejected message is reported to the sender", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		heldRepo := mock.NewMockHeldMessageRepository(ctrl)
+		moderationPub := mock.NewMockPublisher(ctrl)
+		s := NewModerationService(&mockUow{rm: &mockRepositoryManager{heldMessageRepo: heldRepo}}, heldRepo, nil, nil, nil, nil, moderationPub)
+
+		heldRepo.EXPECT().Find(gomock.Any(), int64(3)).Return(&entity.HeldMessage{
+			ID: 3, SenderID: senderID, Content: "call 09012345678", Reason: ReasonContactInfo, Status: entity.HeldMessagePending,
+		}, nil)
+		heldRepo.EXPECT().Review(gomock.Any(), gomock.Any()).Return(true, nil)
+		moderationPub.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, data interface{}) error {
+			var payload client.ModerationPayload
+			assert.NoError(t, json.Unmarshal(data.([]byte), &payload))
+			assert.Equal(t, client.ModerationEventReject, payload.Action)
+			assert.Equal(t, "spam", payload.Reason)
+			assert.Equal(t, "call 09012345678", payload.Content)
+			return nil
+		})
+
+		held, err := s.RejectHeldMessage(context.Background(), reviewerID, 3, "spam")
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.HeldMessageRejected, held.Status)
+	})
+
+	t.Run("Already reviewed message", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		heldRepo := mock.NewMockHeldMessageRepository(ctrl)
+		s := NewModerationService(&mockUow{}, heldRepo, nil, nil, nil, nil, nil)
+
+		heldRepo.EXPECT().Find(gomock.Any(), int64(3)).Return(&entity.HeldMessage{ID: 3, Status: entity.HeldMessageApproved}, nil)
+
+		_, err := s.RejectHeldMessage(context.Background(), reviewerID, 3, "")
+
+		assert.ErrorIs(t, err, apperrors.ErrConflict)
+	})
+}
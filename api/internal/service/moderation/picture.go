@@ -0,0 +1,310 @@
+package moderation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"log"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
+)
+
+// ReasonUnreadable は検査のために写真を読めなかったことを表す。何度試しても読めないので管理者に回す
+// 読めないまま残すと、古い順に検査するため後の写真の検査が止まってしまう
+const ReasonUnreadable = "unreadable_image"
+
+const (
+	// 1 回の ClassifyPending で検査する枚数
+	classifyBatchSize   = 20
+	defaultPictureLimit = 50
+	maxPictureLimit     = 100
+	// 偽アカウントの兆候の一覧の件数と、1 人について返す一致の件数
+	defaultSignalLimit = 50
+	maxSignalLimit     = 100
+	maxSignalMatches   = 100
+	// 管理者に返す写真の署名付き URL の有効期間の単位
+	reviewPictureURLTTL = time.Hour
+	// pictures.flag_reason の長さ
+	maxFlagReasonLength = 255
+)
+
+type pictureModerationService struct {
+	uow              repo.UnitOfWork
+	pictureRepo      repo.PictureQueryRepository
+	pictureMatchRepo repo.PictureMatchQueryRepository
+	fileClient       client.FileClient
+	classifier       service.ImageClassifier
+	now              func() time.Time
+}
+
+var _ service.PictureModerationService = (*pictureModerationService)(nil)
+
+func NewPictureModerationService(uow repo.UnitOfWork, pictureRepo repo.PictureQueryRepository, pictureMatchRepo repo.PictureMatchQueryRepository, fileClient client.FileClient, classifier service.ImageClassifier) *pictureModerationService {
+	return &pictureModerationService{
+		uow:              uow,
+		pictureRepo:      pictureRepo,
+		pictureMatchRepo: pictureMatchRepo,
+		fileClient:       fileClient,
+		classifier:       classifier,
+		now:              time.Now,
+	}
+}
+
+// RunPictureModeration は ctx が終わるまで interval ごとに未検査の写真を検査する
+func RunPictureModeration(ctx context.Context, s service.PictureModerationService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			classified, err := s.ClassifyPending(ctx)
+			if err != nil {
+				log.Printf("Failed to classify pictures: %v", err)
+				continue
+			}
+			if classified > 0 {
+				log.Printf("Classified %d pictures", classified)
+			}
+		}
+	}
+}
+
+func (s *pictureModerationService) ClassifyPending(ctx context.Context) (int, error) {
+	classified := false
+	pictures, err := s.pictureRepo.QueryModeration(ctx, &repo.PictureModerationQuery{
+		Status:     entity.PicturePending,
+		Classified: &classified,
+		Limit:      classifyBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, pic := range pictures {
+		// 1 枚の失敗で他の写真の検査を止めない
+		if err := s.classify(ctx, pic); err != nil {
+			log.Printf("Failed to classify picture %d: %v", pic.ID, err)
+			continue
+		}
+		n++
+	}
+	return n, nil
+}
+
+// classify は写真を検査して結果と他のユーザーの写真との一致を保存する。変種のない古い写真は検査できないので承認する
+func (s *pictureModerationService) classify(ctx context.Context, pic *entity.Picture) error {
+	result := &service.ImageClassification{}
+	if pic.AssetID != "" {
+		img, err := s.openImage(pic.AssetID)
+		if err != nil {
+			return err
+		}
+		if img == nil {
+			result = &service.ImageClassification{Flagged: true, Reason: ReasonUnreadable}
+		} else {
+			pic.DHash = sql.NullInt64{Int64: int64(DifferenceHash(img)), Valid: true}
+			pic.PHash = sql.NullInt64{Int64: int64(PerceptualHash(img)), Valid: true}
+			if result, err = s.classifier.Classify(ctx, pic, img); err != nil {
+				return err
+			}
+		}
+	}
+
+	pic.ClassifiedAt = sql.NullTime{Time: s.now(), Valid: true}
+	if result.Flagged {
+		pic.FlagReason = result.Reason
+	} else {
+		pic.Status = entity.PictureApproved
+	}
+	return s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		// 消された・先に審査された写真はそのままにする
+		ok, err := rm.PictureRepo().Classify(ctx, pic)
+		if err != nil || !ok {
+			return err
+		}
+		for _, match := range result.Matches {
+			if err := rm.PictureMatchRepo().Create(ctx, match); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// openImage は card の変種を読む。filesrv に届かないときはエラー、変種がないときや画像として読めないときは nil を返す
+func (s *pictureModerationService) openImage(assetID string) (image.Image, error) {
+	rc, err := s.fileClient.OpenImage(assetID)
+	if errors.Is(err, client.ErrAssetNotFound) {
+		log.Printf("Picture asset %s not found", assetID)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open picture asset %s: %w", assetID, err)
+	}
+	defer rc.Close()
+	img, _, err := image.Decode(rc)
+	if err != nil {
+		log.Printf("Failed to decode picture asset %s: %v", assetID, err)
+		return nil, nil
+	}
+	return img, nil
+}
+
+func (s *pictureModerationService) ListPictures(ctx context.Context, reviewerID uuid.UUID, params *service.ListPicturesParams) ([]*entity.Picture, error) {
+	q := &repo.PictureModerationQuery{Status: params.Status}
+	switch q.Status {
+	case "", entity.PicturePending:
+		// 審査待ちは検査で疑わしいとされたものだけ
+		classified := true
+		q.Status = entity.PicturePending
+		q.Classified = &classified
+	case entity.PictureApproved, entity.PictureRejected:
+	default:
+		return nil, apperrors.ErrInvalidInput
+	}
+	q.Limit = params.Limit
+	if q.Limit <= 0 {
+		q.Limit = defaultPictureLimit
+	}
+	q.Limit = min(q.Limit, maxPictureLimit)
+	q.Offset = max(params.Offset, 0)
+
+	pictures, err := s.pictureRepo.QueryModeration(ctx, q)
+	if err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+	if pictures == nil {
+		pictures = []*entity.Picture{}
+	}
+	s.signPictures(reviewerID, pictures...)
+	return pictures, nil
+}
+
+// findPending は審査待ちの写真を返す。審査済みなら ErrConflict
+func (s *pictureModerationService) findPending(ctx context.Context, pictureID int32) (*entity.Picture, error) {
+	pic, err := s.pictureRepo.Find(ctx, pictureID)
+	if err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+	if pic == nil {
+		return nil, apperrors.ErrNotFound
+	}
+	if pic.Status != entity.PicturePending {
+		return nil, apperrors.ErrConflict
+	}
+	return pic, nil
+}
+
+func (s *pictureModerationService) ApprovePicture(ctx context.Context, reviewerID uuid.UUID, pictureID int32) (*entity.Picture, error) {
+	pic, err := s.findPending(ctx, pictureID)
+	if err != nil {
+		return nil, err
+	}
+	pic.Status = entity.PictureApproved
+	if err := s.review(ctx, reviewerID, pic); err != nil {
+		return nil, err
+	}
+	return pic, nil
+}
+
+func (s *pictureModerationService) RejectPicture(ctx context.Context, reviewerID uuid.UUID, pictureID int32, reason string) (*entity.Picture, error) {
+	if utf8.RuneCountInString(reason) > maxFlagReasonLength {
+		return nil, apperrors.ErrInvalidInput
+	}
+	pic, err := s.findPending(ctx, pictureID)
+	if err != nil {
+		return nil, err
+	}
+	pic.Status = entity.PictureRejected
+	if reason != "" {
+		pic.FlagReason = reason
+	}
+	if err := s.review(ctx, reviewerID, pic); err != nil {
+		return nil, err
+	}
+	return pic, nil
+}
+
+func (s *pictureModerationService) review(ctx context.Context, reviewerID uuid.UUID, pic *entity.Picture) error {
+	pic.ReviewedBy = uuid.NullUUID{UUID: reviewerID, Valid: true}
+	pic.ReviewedAt = sql.NullTime{Time: s.now(), Valid: true}
+	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		ok, err := rm.PictureRepo().Review(ctx, pic)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			// 同時に審査された
+			return apperrors.ErrConflict
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	s.signPictures(reviewerID, pic)
+	return nil
+}
+
+func (s *pictureModerationService) ListFakeAccountSignals(ctx context.Context, params *service.ListFakeAccountSignalsParams) ([]*entity.FakeAccountSignal, error) {
+	q := &repo.FakeAccountSignalQuery{Limit: params.Limit}
+	if q.Limit <= 0 {
+		q.Limit = defaultSignalLimit
+	}
+	q.Limit = min(q.Limit, maxSignalLimit)
+	q.Offset = max(params.Offset, 0)
+
+	signals, err := s.pictureMatchRepo.QueryFakeAccountSignals(ctx, q)
+	if err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+	if signals == nil {
+		signals = []*entity.FakeAccountSignal{}
+	}
+	return signals, nil
+}
+
+// FindFakeAccountSignal は写真のないユーザーや一致のないユーザーにも 0 の兆候を返す
+func (s *pictureModerationService) FindFakeAccountSignal(ctx context.Context, userID uuid.UUID) (*entity.FakeAccountSignal, []*entity.PictureMatch, error) {
+	signals, err := s.pictureMatchRepo.QueryFakeAccountSignals(ctx, &repo.FakeAccountSignalQuery{UserID: &userID})
+	if err != nil {
+		return nil, nil, apperrors.ErrInternalServer
+	}
+	signal := &entity.FakeAccountSignal{UserID: userID}
+	if len(signals) > 0 {
+		signal = signals[0]
+	}
+	matches, err := s.pictureMatchRepo.Query(ctx, &repo.PictureMatchQuery{UserID: &userID, Limit: maxSignalMatches})
+	if err != nil {
+		return nil, nil, apperrors.ErrInternalServer
+	}
+	if matches == nil {
+		matches = []*entity.PictureMatch{}
+	}
+	return signal, matches, nil
+}
+
+// signPictures は写真の URL を reviewerID だけが期限まで使える URL にする
+func (s *pictureModerationService) signPictures(reviewerID uuid.UUID, pictures ...*entity.Picture) {
+	expiresAt := client.ImageURLExpiry(s.now(), reviewPictureURLTTL)
+	for _, pic := range pictures {
+		pic.URL = s.fileClient.SignImageURL(pic.URL, reviewerID, expiresAt)
+		if pic.ThumbnailURL != "" {
+			pic.ThumbnailURL = s.fileClient.SignImageURL(pic.ThumbnailURL, reviewerID, expiresAt)
+		}
+		if pic.CardURL != "" {
+			pic.CardURL = s.fileClient.SignImageURL(pic.CardURL, reviewerID, expiresAt)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
+	"github.com/icchon/matcha/api/internal/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func expectSignImageURL(fileClient *mock.MockFileClient, viewerID uuid.UUID) {
+	fileClient.EXPECT().SignImageURL(gomock.Any(), viewerID, gomock.Any()).DoAndReturn(func(rawURL string, viewerID uuid.UUID, expiresAt time.Time) string {
+		return rawURL + "?v=" + viewerID.String()
+	}).AnyTimes()
+}
+
+func TestPictureModerationService_ClassifyPending(t *testing.T) {
+	userID := uuid.New()
+	pending := func() *entity.Picture {
+		return &entity.Picture{ID: 5, UserID: userID, AssetID: "asset", Status: entity.PicturePending}
+	}
+
+	matches := []*entity.PictureMatch{
+		{PictureID: 5, UserID: userID, MatchedPictureID: 2, MatchedUserID: uuid.New()},
+		{PictureID: 5, UserID: userID, MatchedPictureID: 3, MatchedUserID: uuid.New()},
+	}
+
+	testCases := []struct {
+		name           string
+		picture        *entity.Picture
+		image          []byte
+		classification *service.ImageClassification
+		// 検査の結果と一緒に保存される一致の数
+		expectedMatches int
+		expectedStatus  entity.PictureStatus
+		expectedReason  string
+	}{
+		{
+			name:           "Clean picture is approved",
+			picture:        pending(),
+			image:          encodeJPEG(t, halfImage(48, 60, 0)),
+			classification: &service.ImageClassification{},
+			expectedStatus: entity.PictureApproved,
+		},
+		{
+			name:            "Flagged picture waits for review",
+			picture:         pending(),
+			image:           encodeJPEG(t, halfImage(48, 60, 0)),
+			classification:  &service.ImageClassification{Flagged: true, Reason: ReasonDuplicate, Matches: matches},
+			expectedMatches: 2,
+			expectedStatus:  entity.PicturePending,
+			expectedReason:  ReasonDuplicate,
+		},
+		{
+			name:           "Unreadable picture waits for review",
+			picture:        pending(),
+			image:          []byte("not an image"),
+			expectedStatus: entity.PicturePending,
+			expectedReason: ReasonUnreadable,
+		},
+		{
+			name:           "Picture without variants is approved",
+			picture:        &entity.Picture{ID: 5, UserID: userID, Status: entity.PicturePending},
+			expectedStatus: entity.PictureApproved,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			pictureRepo := mock.NewMockPictureRepository(ctrl)
+			pictureMatchRepo := mock.NewMockPictureMatchRepository(ctrl)
+			fileClient := mock.NewMockFileClient(ctrl)
+			classifier := mock.NewMockImageClassifier(ctrl)
+			rm := &mockRepositoryManager{pictureRepo: pictureRepo, pictureMatchRepo: pictureMatchRepo}
+			s := NewPictureModerationService(&mockUow{rm: rm}, pictureRepo, pictureMatchRepo, fileClient, classifier)
+
+			pictureRepo.EXPECT().QueryModeration(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, q *repo.PictureModerationQuery) ([]*entity.Picture, error) {
+				assert.Equal(t, entity.PicturePending, q.Status)
+				assert.False(t, *q.Classified)
+				return []*entity.Picture{tc.picture}, nil
+			})
+			if tc.image != nil {
+				fileClient.EXPECT().OpenImage("asset").Return(io.NopCloser(bytes.NewReader(tc.image)), nil)
+			}
+			if tc.classification != nil {
+				classifier.EXPECT().Classify(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, pic *entity.Picture, img image.Image) (*service.ImageClassification, error) {
+					// 検査の前にハッシュを計算しておく
+					assert.True(t, pic.DHash.Valid)
+					assert.True(t, pic.PHash.Valid)
+					return tc.classification, nil
+				})
+			}
+			pictureRepo.EXPECT().Classify(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, pic *entity.Picture) (bool, error) {
+				assert.Equal(t, tc.expectedStatus, pic.Status)
+				assert.Equal(t, tc.expectedReason, pic.FlagReason)
+				assert.True(t, pic.ClassifiedAt.Valid)
+				return true, nil
+			})
+			pictureMatchRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(tc.expectedMatches)
+
+			n, err := s.ClassifyPending(context.Background())
+
+			assert.NoError(t, err)
+			assert.Equal(t, 1, n)
+		})
+	}
+
+	t.Run("Matches of an already reviewed picture are not saved", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureRepo := mock.NewMockPictureRepository(ctrl)
+		fileClient := mock.NewMockFileClient(ctrl)
+		classifier := mock.NewMockImageClassifier(ctrl)
+		// PictureMatchRepo は呼ばれない
+		s := NewPictureModerationService(&mockUow{rm: &mockRepositoryManager{pictureRepo: pictureRepo}}, pictureRepo, nil, fileClient, classifier)
+
+		pictureRepo.EXPECT().QueryModeration(gomock.Any(), gomock.Any()).Return([]*entity.Picture{pending()}, nil)
+		fileClient.EXPECT().OpenImage("asset").Return(io.NopCloser(bytes.NewReader(encodeJPEG(t, halfImage(48, 60, 0)))), nil)
+		classifier.EXPECT().Classify(gomock.Any(), gomock.Any(), gomock.Any()).Return(&service.ImageClassification{
+			Flagged: true,
+			Reason:  ReasonDuplicate,
+			Matches: []*entity.PictureMatch{{PictureID: 5, UserID: userID, MatchedPictureID: 2, MatchedUserID: uuid.New()}},
+		}, nil)
+		pictureRepo.EXPECT().Classify(gomock.Any(), gomock.Any()).Return(false, nil)
+
+		n, err := s.ClassifyPending(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("Missing asset does not block the next picture", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureRepo := mock.NewMockPictureRepository(ctrl)
+		fileClient := mock.NewMockFileClient(ctrl)
+		s := NewPictureModerationService(&mockUow{rm: &mockRepositoryManager{pictureRepo: pictureRepo}}, pictureRepo, nil, fileClient, NewClassifierChain())
+
+		missing := &entity.Picture{ID: 4, UserID: userID, AssetID: "missing", Status: entity.PicturePending}
+		pictureRepo.EXPECT().QueryModeration(gomock.Any(), gomock.Any()).Return([]*entity.Picture{missing, pending()}, nil)
+		fileClient.EXPECT().OpenImage("missing").Return(nil, fmt.Errorf("%w: missing", client.ErrAssetNotFound))
+		fileClient.EXPECT().OpenImage("asset").Return(io.NopCloser(bytes.NewReader(encodeJPEG(t, halfImage(48, 60, 0)))), nil)
+		pictureRepo.EXPECT().Classify(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, pic *entity.Picture) (bool, error) {
+			// 検査済みになるので次の回の QueryModeration には出てこない
+			assert.True(t, pic.ClassifiedAt.Valid)
+			if pic.ID == 4 {
+				assert.Equal(t, entity.PicturePending, pic.Status)
+				assert.Equal(t, ReasonUnreadable, pic.FlagReason)
+			} else {
+				assert.Equal(t, entity.PictureApproved, pic.Status)
+			}
+			return true, nil
+		}).Times(2)
+
+		n, err := s.ClassifyPending(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+	})
+
+	t.Run("File service failure is retried later", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureRepo := mock.NewMockPictureRepository(ctrl)
+		fileClient := mock.NewMockFileClient(ctrl)
+		s := NewPictureModerationService(&mockUow{rm: &mockRepositoryManager{pictureRepo: pictureRepo}}, pictureRepo, nil, fileClient, NewClassifierChain())
+
+		pictureRepo.EXPECT().QueryModeration(gomock.Any(), gomock.Any()).Return([]*entity.Picture{pending()}, nil)
+		fileClient.EXPECT().OpenImage("asset").Return(nil, errors.New("connection refused"))
+
+		n, err := s.ClassifyPending(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+	})
+}
+
+func TestPictureModerationService_ReviewPicture(t *testing.T) {
+	reviewerID := uuid.New()
+	flagged := func() *entity.Picture {
+		return &entity.Picture{ID: 5, UserID: uuid.New(), URL: "http://files/a/full.jpg", Status: entity.PicturePending, FlagReason: ReasonSkinTone}
+	}
+
+	t.Run("Approve", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureRepo := mock.NewMockPictureRepository(ctrl)
+		fileClient := mock.NewMockFileClient(ctrl)
+		s := NewPictureModerationService(&mockUow{rm: &mockRepositoryManager{pictureRepo: pictureRepo}}, pictureRepo, nil, fileClient, nil)
+
+		pictureRepo.EXPECT().Find(gomock.Any(), int32(5)).Return(flagged(), nil)
+		pictureRepo.EXPECT().Review(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, pic *entity.Picture) (bool, error) {
+			assert.Equal(t, entity.PictureApproved, pic.Status)
+			assert.Equal(t, reviewerID, pic.ReviewedBy.UUID)
+			return true, nil
+		})
+		expectSignImageURL(fileClient, reviewerID)
+
+		pic, err := s.ApprovePicture(context.Background(), reviewerID, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "http://files/a/full.jpg?v="+reviewerID.String(), pic.URL)
+	})
+
+	t.Run("Reject keeps the classifier reason", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureRepo := mock.NewMockPictureRepository(ctrl)
+		fileClient := mock.NewMockFileClient(ctrl)
+		s := NewPictureModerationService(&mockUow{rm: &mockRepositoryManager{pictureRepo: pictureRepo}}, pictureRepo, nil, fileClient, nil)
+
+		pictureRepo.EXPECT().Find(gomock.Any(), int32(5)).Return(flagged(), nil)
+		pictureRepo.EXPECT().Review(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, pic *entity.Picture) (bool, error) {
+			assert.Equal(t, entity.PictureRejected, pic.Status)
+			assert.Equal(t, ReasonSkinTone, pic.FlagReason)
+			return true, nil
+		})
+		expectSignImageURL(fileClient, reviewerID)
+
+		_, err := s.RejectPicture(context.Background(), reviewerID, 5, "")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Concurrent review", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureRepo := mock.NewMockPictureRepository(ctrl)
+		s := NewPictureModerationService(&mockUow{rm: &mockRepositoryManager{pictureRepo: pictureRepo}}, pictureRepo, nil, nil, nil)
+
+		pictureRepo.EXPECT().Find(gomock.Any(), int32(5)).Return(flagged(), nil)
+		pictureRepo.EXPECT().Review(gomock.Any(), gomock.Any()).Return(false, nil)
+
+		_, err := s.ApprovePicture(context.Background(), reviewerID, 5)
+
+		assert.Equal(t, apperrors.ErrConflict, err)
+	})
+
+	t.Run("Already reviewed picture", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureRepo := mock.NewMockPictureRepository(ctrl)
+		s := NewPictureModerationService(nil, pictureRepo, nil, nil, nil)
+
+		reviewed := flagged()
+		reviewed.Status = entity.PictureApproved
+		pictureRepo.EXPECT().Find(gomock.Any(), int32(5)).Return(reviewed, nil)
+
+		_, err := s.RejectPicture(context.Background(), reviewerID, 5, "fake")
+
+		assert.Equal(t, apperrors.ErrConflict, err)
+	})
+
+	t.Run("Unknown picture", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureRepo := mock.NewMockPictureRepository(ctrl)
+		s := NewPictureModerationService(nil, pictureRepo, nil, nil, nil)
+
+		pictureRepo.EXPECT().Find(gomock.Any(), int32(5)).Return(nil, nil)
+
+		_, err := s.ApprovePicture(context.Background(), reviewerID, 5)
+
+		assert.Equal(t, apperrors.ErrNotFound, err)
+	})
+}
+
+func TestPictureModerationService_FakeAccountSignals(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("List clamps the limit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureMatchRepo := mock.NewMockPictureMatchRepository(ctrl)
+		s := NewPictureModerationService(nil, nil, pictureMatchRepo, nil, nil)
+
+		pictureMatchRepo.EXPECT().QueryFakeAccountSignals(gomock.Any(), &repo.FakeAccountSignalQuery{Limit: maxSignalLimit, Offset: 0}).Return(nil, nil)
+
+		signals, err := s.ListFakeAccountSignals(context.Background(), &service.ListFakeAccountSignalsParams{Limit: 1000, Offset: -1})
+
+		assert.NoError(t, err)
+		assert.NotNil(t, signals)
+		assert.Empty(t, signals)
+	})
+
+	t.Run("User with matches", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureMatchRepo := mock.NewMockPictureMatchRepository(ctrl)
+		s := NewPictureModerationService(nil, nil, pictureMatchRepo, nil, nil)
+
+		signal := &entity.FakeAccountSignal{UserID: userID, MatchedPictures: 1, MatchedUsers: 1, TotalPictures: 2}
+		matches := []*entity.PictureMatch{{PictureID: 5, UserID: userID, MatchedPictureID: 2, MatchedUserID: uuid.New()}}
+		pictureMatchRepo.EXPECT().QueryFakeAccountSignals(gomock.Any(), &repo.FakeAccountSignalQuery{UserID: &userID}).Return([]*entity.FakeAccountSignal{signal}, nil)
+		pictureMatchRepo.EXPECT().Query(gomock.Any(), &repo.PictureMatchQuery{UserID: &userID, Limit: maxSignalMatches}).Return(matches, nil)
+
+		got, gotMatches, err := s.FindFakeAccountSignal(context.Background(), userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, signal, got)
+		assert.Equal(t, matches, gotMatches)
+	})
+
+	t.Run("User without pictures", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureMatchRepo := mock.NewMockPictureMatchRepository(ctrl)
+		s := NewPictureModerationService(nil, nil, pictureMatchRepo, nil, nil)
+
+		pictureMatchRepo.EXPECT().QueryFakeAccountSignals(gomock.Any(), gomock.Any()).Return(nil, nil)
+		pictureMatchRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		got, gotMatches, err := s.FindFakeAccountSignal(context.Background(), userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &entity.FakeAccountSignal{UserID: userID}, got)
+		assert.Equal(t, 0.0, got.Score())
+		assert.Empty(t, gotMatches)
+	})
+}
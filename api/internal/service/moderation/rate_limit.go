@@ -0,0 +1,75 @@
+package moderation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/service"
+)
+
+const ReasonRateLimited = "rate_limited"
+
+type rateLimitFilter struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	sent      map[uuid.UUID][]time.Time
+	lastSweep time.Time
+}
+
+var _ service.MessageFilter = (*rateLimitFilter)(nil)
+
+// NewRateLimitFilter は送信者ごとに window の間 limit 件を超えたメッセージを拒否する (スライディングウィンドウ)
+// 記録はプロセス内のメモリに持つため、api を複数台にした場合は台ごとの上限になる
+func NewRateLimitFilter(limit int, window time.Duration) *rateLimitFilter {
+	return &rateLimitFilter{
+		limit:  limit,
+		window: window,
+		now:    time.Now,
+		sent:   make(map[uuid.UUID][]time.Time),
+	}
+}
+
+func (f *rateLimitFilter) Filter(ctx context.Context, msg *entity.Message) (*service.ModerationResult, error) {
+	if f.limit <= 0 {
+		return &service.ModerationResult{Action: service.ModerationAllow, Content: msg.Content}, nil
+	}
+	now := f.now()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cutoff := now.Add(-f.window)
+	if now.Sub(f.lastSweep) >= f.window {
+		f.sweep(cutoff)
+		f.lastSweep = now
+	}
+	f.sent[msg.SenderID] = recent(f.sent[msg.SenderID], cutoff)
+	if len(f.sent[msg.SenderID]) >= f.limit {
+		return &service.ModerationResult{Action: service.ModerationReject, Content: msg.Content, Reason: ReasonRateLimited}, nil
+	}
+	f.sent[msg.SenderID] = append(f.sent[msg.SenderID], now)
+	return &service.ModerationResult{Action: service.ModerationAllow, Content: msg.Content}, nil
+}
+
+// sweep はしばらく送信していない送信者の記録を消す
+func (f *rateLimitFilter) sweep(cutoff time.Time) {
+	for senderID, times := range f.sent {
+		if len(recent(times, cutoff)) == 0 {
+			delete(f.sent, senderID)
+		}
+	}
+}
+
+// recent は cutoff より後の送信時刻だけを返す (times は古い順)
+func recent(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	return times[i:]
+}
@@ -0,0 +1,58 @@
+package moderation
+
+import (
+	"context"
+	"image"
+
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/service"
+)
+
+const ReasonSkinTone = "high_skin_tone_ratio"
+
+// 肌色の割合を数えるときに飛ばす画素の間隔。card (480x600) なら 1/4 の画素で十分
+const skinSampleStep = 2
+
+type skinToneClassifier struct {
+	threshold float64
+}
+
+var _ service.ImageClassifier = (*skinToneClassifier)(nil)
+
+// NewSkinToneClassifier は肌色の画素の割合が threshold を超える写真を疑わしいとする
+// 露出の多い写真を管理者に回すための目安で、顔のアップなども引っかかる
+func NewSkinToneClassifier(threshold float64) *skinToneClassifier {
+	return &skinToneClassifier{threshold: threshold}
+}
+
+func (c *skinToneClassifier) Classify(ctx context.Context, pic *entity.Picture, img image.Image) (*service.ImageClassification, error) {
+	if skinRatio(img) > c.threshold {
+		return &service.ImageClassification{Flagged: true, Reason: ReasonSkinTone}, nil
+	}
+	return &service.ImageClassification{}, nil
+}
+
+// skinRatio は肌色の画素の割合を返す。RGB の経験則 (Kovac らの昼光下の規則) で判定する
+func skinRatio(img image.Image) float64 {
+	b := img.Bounds()
+	total, skin := 0, 0
+	for y := b.Min.Y; y < b.Max.Y; y += skinSampleStep {
+		for x := b.Min.X; x < b.Max.X; x += skinSampleStep {
+			r16, g16, b16, _ := img.At(x, y).RGBA()
+			total++
+			if isSkin(int(r16>>8), int(g16>>8), int(b16>>8)) {
+				skin++
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(skin) / float64(total)
+}
+
+func isSkin(r, g, b int) bool {
+	return r > 95 && g > 40 && b > 20 &&
+		max(r, g, b)-min(r, g, b) > 15 &&
+		r-g > 15 && r > b
+}
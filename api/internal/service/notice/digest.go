@@ -0,0 +1,266 @@
+package notice
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
+	"github.com/icchon/matcha/api/internal/i18n"
+)
+
+// まとめ通知メールに載せる通知の種類
+var digestTypes = []entity.NotificationType{entity.NotifMatch, entity.NotifLike, entity.NotifMessage}
+
+var _ service.DigestService = (*digestService)(nil)
+
+type digestService struct {
+	uow              repo.UnitOfWork
+	notificationRepo repo.NotificationQueryRepository
+	prefRepo         repo.NotificationPreferenceQueryRepository
+	digestRepo       repo.EmailDigestQueryRepository
+	authRepo         repo.AuthQueryRepository
+	profileRepo      repo.UserProfileQueryRepository
+	presenceRepo     repo.PresenceQueryRepository
+	mailService      service.MailService
+	signingKey       []byte
+	// 最後の接続・通知からこの期間が過ぎたらメールを送る
+	quietPeriod time.Duration
+	now         func() time.Time
+}
+
+func NewDigestService(uow repo.UnitOfWork, notificationRepo repo.NotificationQueryRepository, prefRepo repo.NotificationPreferenceQueryRepository, digestRepo repo.EmailDigestQueryRepository, authRepo repo.AuthQueryRepository, profileRepo repo.UserProfileQueryRepository, presenceRepo repo.PresenceQueryRepository, mailService service.MailService, signingKey string, quietPeriod time.Duration) service.DigestService {
+	return &digestService{
+		uow:              uow,
+		notificationRepo: notificationRepo,
+		prefRepo:         prefRepo,
+		digestRepo:       digestRepo,
+		authRepo:         authRepo,
+		profileRepo:      profileRepo,
+		presenceRepo:     presenceRepo,
+		mailService:      mailService,
+		signingKey:       []byte(signingKey),
+		quietPeriod:      quietPeriod,
+		now:              time.Now,
+	}
+}
+
+// RunDigestJob は ctx が終わるまで interval ごとにまとめ通知メールを送る
+func RunDigestJob(ctx context.Context, s service.DigestService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sent, err := s.SendDigests(ctx)
+			if err != nil {
+				log.Printf("Failed to send email digests: %v", err)
+				continue
+			}
+			if sent > 0 {
+				log.Printf("Sent %d email digests", sent)
+			}
+		}
+	}
+}
+
+func (s *digestService) SendDigests(ctx context.Context) (int, error) {
+	now := s.now()
+	recipientIDs, err := s.notificationRepo.FindDigestRecipients(ctx, digestTypes, now.Add(-s.quietPeriod))
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, userID := range recipientIDs {
+		// 1 人の失敗で他のユーザーへの送信を止めない
+		ok, err := s.sendDigest(ctx, userID, now)
+		if err != nil {
+			log.Printf("Failed to send email digest to %s: %v", userID, err)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+func (s *digestService) sendDigest(ctx context.Context, userID uuid.UUID, now time.Time) (bool, error) {
+	online, err := s.presenceRepo.IsOnline(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if online {
+		return false, nil
+	}
+
+	types, err := s.digestTypesFor(ctx, userID)
+	if err != nil || len(types) == 0 {
+		return false, err
+	}
+	isRead := false
+	q := &repo.NotificationQuery{RecipientID: &userID, IsRead: &isRead, Types: types}
+	last, err := s.digestRepo.Find(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if last != nil {
+		q.UpdatedAfter = &last.LastSentAt
+	}
+	notifications, err := s.notificationRepo.Query(ctx, q)
+	if err != nil {
+		return false, err
+	}
+	summary := summarize(notifications)
+	if summary.Matches+summary.Likes+summary.Messages == 0 {
+		return false, nil
+	}
+
+	email, err := s.emailOf(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	mailCtx := ctx
+	var username string
+	if email != "" {
+		profile, err := s.profileRepo.Find(ctx, userID)
+		if err != nil {
+			return false, err
+		}
+		// バックグラウンドのジョブには Accept-Language がないので、設定がなければ既定の言語になる
+		if profile != nil {
+			mailCtx = i18n.WithPreferred(ctx, profile.Locale.String)
+		}
+		username = displayName(profile)
+	}
+
+	// メールの送信待ちと送った時刻の記録を同じトランザクションで行う
+	// メールアドレスのないユーザー (OAuth のみなど) も送ったことにして、次回以降の対象から外す
+	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		if email != "" {
+			if err := s.mailService.SendDigestEmail(mailCtx, rm, email, username, summary, s.unsubscribeToken(userID)); err != nil {
+				return err
+			}
+		}
+		return rm.EmailDigestRepo().Upsert(ctx, &entity.EmailDigest{UserID: userID, LastSentAt: now})
+	}); err != nil {
+		return false, err
+	}
+	return email != "", nil
+}
+
+// digestTypesFor はユーザーがメール通知を止めていない種類を返す
+func (s *digestService) digestTypesFor(ctx context.Context, userID uuid.UUID) ([]entity.NotificationType, error) {
+	prefs, err := s.prefRepo.Query(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	disabled := make(map[entity.NotificationType]bool, len(prefs))
+	for _, pref := range prefs {
+		disabled[pref.Type] = !pref.EmailDigest
+	}
+	var types []entity.NotificationType
+	for _, notifType := range digestTypes {
+		if !disabled[notifType] {
+			types = append(types, notifType)
+		}
+	}
+	return types, nil
+}
+
+func (s *digestService) emailOf(ctx context.Context, userID uuid.UUID) (string, error) {
+	auths, err := s.authRepo.Query(ctx, &repo.AuthQuery{UserID: &userID})
+	if err != nil {
+		return "", err
+	}
+	for _, auth := range auths {
+		if auth.Email.Valid && auth.Email.String != "" {
+			return auth.Email.String, nil
+		}
+	}
+	return "", nil
+}
+
+func summarize(notifications []*entity.Notification) *service.DigestSummary {
+	summary := &service.DigestSummary{}
+	for _, notification := range notifications {
+		count := max(notification.Count, 1)
+		switch notification.Type {
+		case entity.NotifMatch:
+			summary.Matches += count
+		case entity.NotifLike:
+			summary.Likes += count
+		case entity.NotifMessage:
+			summary.Messages += count
+		}
+	}
+	return summary
+}
+
+func (s *digestService) Unsubscribe(ctx context.Context, token string) error {
+	userID, ok := s.verifyUnsubscribeToken(token)
+	if !ok {
+		return apperrors.ErrInvalidInput
+	}
+	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		prefs, err := rm.NotificationPreferenceRepo().Query(ctx, userID)
+		if err != nil {
+			return err
+		}
+		existing := make(map[entity.NotificationType]*entity.NotificationPreference, len(prefs))
+		for _, pref := range prefs {
+			existing[pref.Type] = pref
+		}
+		// アプリ内通知の設定はそのまま残す
+		for _, notifType := range entity.NotificationTypes {
+			pref, ok := existing[notifType]
+			if !ok {
+				pref = &entity.NotificationPreference{UserID: userID, Type: notifType, InApp: defaultChannels.InApp}
+			}
+			pref.EmailDigest = false
+			if err := rm.NotificationPreferenceRepo().Upsert(ctx, pref); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return apperrors.ErrInternalServer
+	}
+	return nil
+}
+
+// 配信停止リンクは期限なしで使えるよう、ユーザー ID だけに署名する
+func (s *digestService) unsubscribeToken(userID uuid.UUID) string {
+	return userID.String() + "." + s.unsubscribeSignature(userID)
+}
+
+func (s *digestService) verifyUnsubscribeToken(token string) (uuid.UUID, bool) {
+	id, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, false
+	}
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	if !hmac.Equal([]byte(sig), []byte(s.unsubscribeSignature(userID))) {
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func (s *digestService) unsubscribeSignature(userID uuid.UUID) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte("email_digest_unsubscribe." + userID.String()))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
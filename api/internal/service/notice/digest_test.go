@@ -0,0 +1,148 @@
+package notice
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/mock"
+	"github.com/icchon/matcha/api/internal/service/mail"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDigestService_SendDigests(t *testing.T) {
+	userID := uuid.New()
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	lastSentAt := now.Add(-48 * time.Hour)
+
+	testCases := []struct {
+		name       string
+		online     bool
+		prefs      []*entity.NotificationPreference
+		email      string
+		expectSent int
+		expectMail bool
+	}{
+		{name: "Send a digest with an unsubscribe link", email: "taro@example.com", expectSent: 1, expectMail: true},
+		{name: "Online users are skipped", online: true},
+		{
+			name:  "Opted out of every digest type",
+			email: "taro@example.com",
+			prefs: []*entity.NotificationPreference{
+				{UserID: userID, Type: entity.NotifMatch, InApp: true, EmailDigest: false},
+				{UserID: userID, Type: entity.NotifLike, InApp: true, EmailDigest: false},
+				{UserID: userID, Type: entity.NotifMessage, InApp: true, EmailDigest: false},
+			},
+		},
+		{name: "No email address is marked as sent", expectSent: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			notificationRepo := mock.NewMockNotificationRepository(ctrl)
+			prefRepo := mock.NewMockNotificationPreferenceRepository(ctrl)
+			digestRepo := mock.NewMockEmailDigestRepository(ctrl)
+			authRepo := mock.NewMockAuthQueryRepository(ctrl)
+			profileRepo := mock.NewMockUserProfileQueryRepository(ctrl)
+			presenceRepo := mock.NewMockPresenceQueryRepository(ctrl)
+			outboxRepo := mock.NewMockMailOutboxRepository(ctrl)
+			mailService := mail.NewApplicationMailService("http://localhost:3100")
+			uow := &mockUow{rm: &mockRepositoryManager{digestRepo: digestRepo, outboxRepo: outboxRepo}}
+			s := NewDigestService(uow, notificationRepo, prefRepo, digestRepo, authRepo, profileRepo, presenceRepo, mailService, "secret", 24*time.Hour).(*digestService)
+			s.now = func() time.Time { return now }
+
+			notificationRepo.EXPECT().FindDigestRecipients(gomock.Any(), digestTypes, now.Add(-24*time.Hour)).Return([]uuid.UUID{userID}, nil)
+			presenceRepo.EXPECT().IsOnline(gomock.Any(), userID).Return(tc.online, nil)
+			if !tc.online {
+				prefRepo.EXPECT().Query(gomock.Any(), userID).Return(tc.prefs, nil)
+			}
+			if !tc.online && tc.prefs == nil {
+				digestRepo.EXPECT().Find(gomock.Any(), userID).Return(&entity.EmailDigest{UserID: userID, LastSentAt: lastSentAt}, nil)
+				notificationRepo.EXPECT().Query(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, q *repo.NotificationQuery) ([]*entity.Notification, error) {
+					assert.Equal(t, lastSentAt, *q.UpdatedAfter)
+					assert.False(t, *q.IsRead)
+					assert.ElementsMatch(t, digestTypes, q.Types)
+					return []*entity.Notification{
+						{ID: 1, RecipientID: userID, Type: entity.NotifMatch, Count: 1},
+						{ID: 2, RecipientID: userID, Type: entity.NotifLike, Count: 3},
+						{ID: 3, RecipientID: userID, Type: entity.NotifMessage, Count: 1},
+					}, nil
+				})
+				var auths []*entity.Auth
+				if tc.email != "" {
+					auths = append(auths, &entity.Auth{UserID: userID, Email: sql.NullString{String: tc.email, Valid: true}})
+				}
+				authRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(auths, nil)
+				digestRepo.EXPECT().Upsert(gomock.Any(), &entity.EmailDigest{UserID: userID, LastSentAt: now}).Return(nil)
+			}
+			if tc.expectMail {
+				profileRepo.EXPECT().Find(gomock.Any(), userID).Return(&entity.UserProfile{
+					UserID:   userID,
+					Username: sql.NullString{String: "taro", Valid: true},
+				}, nil)
+				outboxRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, m *entity.OutboxMail) error {
+					assert.Equal(t, tc.email, m.ToEmail)
+					assert.Contains(t, m.HTMLBody, "taro")
+					assert.Contains(t, m.HTMLBody, "いいね: 3件")
+					assert.Contains(t, m.HTMLBody, "http://localhost:3100/unsubscribe?token="+s.unsubscribeToken(userID))
+					return nil
+				})
+			}
+
+			sent, err := s.SendDigests(context.Background())
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectSent, sent)
+		})
+	}
+}
+
+func TestDigestService_Unsubscribe(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("Valid token disables every email digest", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		prefRepo := mock.NewMockNotificationPreferenceRepository(ctrl)
+		s := NewDigestService(&mockUow{rm: &mockRepositoryManager{prefRepo: prefRepo}}, nil, prefRepo, nil, nil, nil, nil, nil, "secret", time.Hour).(*digestService)
+
+		prefRepo.EXPECT().Query(gomock.Any(), userID).Return([]*entity.NotificationPreference{
+			{UserID: userID, Type: entity.NotifView, InApp: false, EmailDigest: true},
+		}, nil)
+		saved := map[entity.NotificationType]*entity.NotificationPreference{}
+		prefRepo.EXPECT().Upsert(gomock.Any(), gomock.Any()).Times(len(entity.NotificationTypes)).
+			DoAndReturn(func(ctx context.Context, pref *entity.NotificationPreference) error {
+				saved[pref.Type] = pref
+				return nil
+			})
+
+		err := s.Unsubscribe(context.Background(), s.unsubscribeToken(userID))
+
+		assert.NoError(t, err)
+		for _, pref := range saved {
+			assert.False(t, pref.EmailDigest)
+		}
+		assert.False(t, saved[entity.NotifView].InApp)
+		assert.True(t, saved[entity.NotifLike].InApp)
+	})
+
+	t.Run("Tampered token", func(t *testing.T) {
+		s := NewDigestService(&mockUow{}, nil, nil, nil, nil, nil, nil, nil, "secret", time.Hour).(*digestService)
+		other := NewDigestService(&mockUow{}, nil, nil, nil, nil, nil, nil, nil, "other", time.Hour).(*digestService)
+
+		for _, token := range []string{"", "garbage", uuid.New().String() + "." + s.unsubscribeSignature(userID), other.unsubscribeToken(userID)} {
+			err := s.Unsubscribe(context.Background(), token)
+			assert.ErrorIs(t, err, apperrors.ErrInvalidInput)
+		}
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/icchon/matcha/api/internal/domain/client"
 	"github.com/icchon/matcha/api/internal/domain/entity"
@@ -11,49 +13,100 @@ import (
 	"github.com/icchon/matcha/api/internal/domain/service"
 )
 
+const (
+	// 同じ相手からの like / view をこの期間内なら 1 件にまとめる
+	aggregationWindow = 24 * time.Hour
+	// 同じ相手がこの期間内にもう一度プロフィールを見ても通知しない (リロードなど)
+	viewDedupeWindow = 30 * time.Minute
+)
+
+// 件数をまとめる通知の種類
+var aggregatedTypes = map[entity.NotificationType]bool{
+	entity.NotifLike: true,
+	entity.NotifView: true,
+}
+
 var _ service.NotificationService = (*notificationService)(nil)
 
 type notificationService struct {
 	uow              repo.UnitOfWork
 	notificationRepo repo.NotificationQueryRepository
+	prefRepo         repo.NotificationPreferenceQueryRepository
+	muteRepo         repo.NotificationMuteQueryRepository
+	profileRepo      repo.UserProfileQueryRepository
+	pictureRepo      repo.PictureQueryRepository
 	notificationPub  client.Publisher
+	notifReadPub     client.Publisher
+	fileClient       client.FileClient
 }
 
-func NewNotificationService(uow repo.UnitOfWork, notificationRepo repo.NotificationQueryRepository, notificationPub client.Publisher) service.NotificationService {
+func NewNotificationService(uow repo.UnitOfWork, notificationRepo repo.NotificationQueryRepository, prefRepo repo.NotificationPreferenceQueryRepository, muteRepo repo.NotificationMuteQueryRepository, profileRepo repo.UserProfileQueryRepository, pictureRepo repo.PictureQueryRepository, notificationPub client.Publisher, notifReadPub client.Publisher, fileClient client.FileClient) service.NotificationService {
 	return &notificationService{
 		uow:              uow,
 		notificationRepo: notificationRepo,
+		prefRepo:         prefRepo,
+		muteRepo:         muteRepo,
+		profileRepo:      profileRepo,
+		pictureRepo:      pictureRepo,
 		notificationPub:  notificationPub,
+		notifReadPub:     notifReadPub,
+		fileClient:       fileClient,
 	}
 }
 
-func (s *notificationService) GetNotifications(ctx context.Context, recipientID uuid.UUID) ([]*entity.Notification, error) {
-	return s.notificationRepo.Query(ctx, &repo.NotificationQuery{
-		RecipientID: &recipientID,
-	})
-}
-
 func (s *notificationService) CreateAndSendNotification(ctx context.Context, senderID uuid.UUID, recipiendID uuid.UUID, notifType entity.NotificationType) (*entity.Notification, error) {
-	notification := &entity.Notification{
-		RecipientID: recipiendID,
-		SenderID:    sql.NullString{String: senderID.String(), Valid: true},
-		Type:        notifType,
+	channels, err := s.channelsFor(ctx, recipiendID, senderID, notifType)
+	if err != nil {
+		return nil, err
+	}
+	// メールのまとめ通知は保存された通知から作るため、どちらかのチャネルが有効なら保存する
+	if !channels.InApp && !channels.EmailDigest {
+		return nil, nil
 	}
+
+	var notification *entity.Notification
 	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
-		if err := rm.NotificationRepo().Create(ctx, notification); err != nil {
-			return err
+		if aggregatedTypes[notifType] {
+			if err := rm.NotificationRepo().LockAggregation(ctx, recipiendID, senderID, notifType); err != nil {
+				return err
+			}
+			now := time.Now()
+			latest, err := rm.NotificationRepo().FindLatest(ctx, recipiendID, senderID, notifType, now.Add(-aggregationWindow))
+			if err != nil {
+				return err
+			}
+			if latest != nil {
+				if notifType == entity.NotifView && now.Sub(latest.UpdatedAt) < viewDedupeWindow {
+					return nil
+				}
+				notification = latest
+				return rm.NotificationRepo().Increment(ctx, notification)
+			}
+		}
+		notification = &entity.Notification{
+			RecipientID: recipiendID,
+			SenderID:    sql.NullString{String: senderID.String(), Valid: true},
+			Type:        notifType,
 		}
-		return nil
+		return rm.NotificationRepo().Create(ctx, notification)
 	}); err != nil {
 		return nil, err
 	}
+	if notification == nil || !channels.InApp {
+		return notification, nil
+	}
 
+	sender := s.senderInfo(ctx, recipiendID, senderID)
 	payload := client.NotificationPayload{
-		ID:          notification.ID,
-		RecipientID: notification.RecipientID,
-		SenderID:    notification.SenderID.String,
-		Type:        string(notification.Type),
-		CreatedAt:   notification.CreatedAt,
+		ID:                 notification.ID,
+		RecipientID:        notification.RecipientID,
+		SenderID:           notification.SenderID.String,
+		SenderName:         sender.name,
+		SenderThumbnailURL: sender.thumbnailURL,
+		Type:               string(notification.Type),
+		Count:              notification.Count,
+		CreatedAt:          notification.CreatedAt,
+		UpdatedAt:          notification.UpdatedAt,
 	}
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
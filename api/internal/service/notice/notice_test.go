@@ -0,0 +1,289 @@
+package notice
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
+	"github.com/icchon/matcha/api/internal/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// mockRepositoryManager is a mock for repo.RepositoryManager.
+type mockRepositoryManager struct {
+	repo.RepositoryManager
+	notificationRepo repo.NotificationRepository
+	prefRepo         repo.NotificationPreferenceRepository
+	muteRepo         repo.NotificationMuteRepository
+	digestRepo       repo.EmailDigestRepository
+	outboxRepo       repo.MailOutboxRepository
+}
+
+func (m *mockRepositoryManager) NotificationRepo() repo.NotificationRepository {
+	return m.notificationRepo
+}
+func (m *mockRepositoryManager) NotificationPreferenceRepo() repo.NotificationPreferenceRepository {
+	return m.prefRepo
+}
+func (m *mockRepositoryManager) NotificationMuteRepo() repo.NotificationMuteRepository {
+	return m.muteRepo
+}
+func (m *mockRepositoryManager) EmailDigestRepo() repo.EmailDigestRepository {
+	return m.digestRepo
+}
+func (m *mockRepositoryManager) MailOutboxRepo() repo.MailOutboxRepository {
+	return m.outboxRepo
+}
+
+// mockUow is a mock for repo.UnitOfWork for testing services.
+type mockUow struct {
+	rm repo.RepositoryManager
+}
+
+func (u *mockUow) Do(ctx context.Context, fn func(rm repo.RepositoryManager) error) error {
+	return fn(u.rm)
+}
+
+func TestNotificationService_CreateAndSendNotification(t *testing.T) {
+	senderID := uuid.New()
+	recipientID := uuid.New()
+
+	testCases := []struct {
+		name       string
+		muted      bool
+		pref       *entity.NotificationPreference
+		expectSave bool
+		expectPush bool
+		expectNil  bool
+	}{
+		{name: "Default settings save and push", expectSave: true, expectPush: true},
+		{name: "Muted sender is dropped", muted: true, expectNil: true},
+		{
+			name:       "In-app disabled still saves for the digest",
+			pref:       &entity.NotificationPreference{InApp: false, EmailDigest: true},
+			expectSave: true,
+		},
+		{
+			name:      "All channels disabled",
+			pref:      &entity.NotificationPreference{InApp: false, EmailDigest: false},
+			expectNil: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			notificationRepo := mock.NewMockNotificationRepository(ctrl)
+			prefRepo := mock.NewMockNotificationPreferenceRepository(ctrl)
+			muteRepo := mock.NewMockNotificationMuteRepository(ctrl)
+			profileRepo := mock.NewMockUserProfileQueryRepository(ctrl)
+			pictureRepo := mock.NewMockPictureQueryRepository(ctrl)
+			pub := mock.NewMockPublisher(ctrl)
+			fileClient := mock.NewMockFileClient(ctrl)
+			s := NewNotificationService(&mockUow{rm: &mockRepositoryManager{notificationRepo: notificationRepo}}, notificationRepo, prefRepo, muteRepo, profileRepo, pictureRepo, pub, nil, fileClient)
+
+			var mutes []*entity.NotificationMute
+			if tc.muted {
+				mutes = append(mutes, &entity.NotificationMute{UserID: recipientID, MutedUserID: senderID, MutedUntil: time.Now().Add(time.Hour)})
+			}
+			muteRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(mutes, nil)
+			if !tc.muted {
+				prefRepo.EXPECT().Find(gomock.Any(), recipientID, entity.NotifLike).Return(tc.pref, nil)
+			}
+			if tc.expectSave {
+				notificationRepo.EXPECT().LockAggregation(gomock.Any(), recipientID, senderID, entity.NotifLike).Return(nil)
+				notificationRepo.EXPECT().FindLatest(gomock.Any(), recipientID, senderID, entity.NotifLike, gomock.Any()).Return(nil, nil)
+				notificationRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+			}
+			if tc.expectPush {
+				profileRepo.EXPECT().Find(gomock.Any(), senderID).Return(nil, nil)
+				pictureRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(nil, nil)
+				// 写真がない送信者は既定の画像
+				fileClient.EXPECT().AvatarURL(senderID).Return("http://files/avatars/sender.png")
+				pub.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, data interface{}) error {
+					var payload client.NotificationPayload
+					assert.NoError(t, json.Unmarshal(data.([]byte), &payload))
+					assert.Equal(t, "http://files/avatars/sender.png", payload.SenderThumbnailURL)
+					return nil
+				})
+			}
+
+			notification, err := s.CreateAndSendNotification(context.Background(), senderID, recipientID, entity.NotifLike)
+
+			assert.NoError(t, err)
+			if tc.expectNil {
+				assert.Nil(t, notification)
+			} else {
+				assert.NotNil(t, notification)
+			}
+		})
+	}
+}
+
+func TestNotificationService_CreateAndSendNotification_Aggregation(t *testing.T) {
+	senderID := uuid.New()
+	recipientID := uuid.New()
+
+	testCases := []struct {
+		name            string
+		notifType       entity.NotificationType
+		latestUpdatedAt time.Duration
+		expectIncrement bool
+		expectNil       bool
+	}{
+		{name: "Repeated like increments the count", notifType: entity.NotifLike, latestUpdatedAt: -time.Minute, expectIncrement: true},
+		{name: "Repeated view within the dedupe window is skipped", notifType: entity.NotifView, latestUpdatedAt: -time.Minute, expectNil: true},
+		{name: "Later view increments the count", notifType: entity.NotifView, latestUpdatedAt: -2 * time.Hour, expectIncrement: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			notificationRepo := mock.NewMockNotificationRepository(ctrl)
+			prefRepo := mock.NewMockNotificationPreferenceRepository(ctrl)
+			muteRepo := mock.NewMockNotificationMuteRepository(ctrl)
+			profileRepo := mock.NewMockUserProfileQueryRepository(ctrl)
+			pictureRepo := mock.NewMockPictureQueryRepository(ctrl)
+			pub := mock.NewMockPublisher(ctrl)
+			fileClient := mock.NewMockFileClient(ctrl)
+			s := NewNotificationService(&mockUow{rm: &mockRepositoryManager{notificationRepo: notificationRepo}}, notificationRepo, prefRepo, muteRepo, profileRepo, pictureRepo, pub, nil, fileClient)
+
+			latest := &entity.Notification{
+				ID:          5,
+				RecipientID: recipientID,
+				SenderID:    sql.NullString{String: senderID.String(), Valid: true},
+				Type:        tc.notifType,
+				Count:       1,
+				UpdatedAt:   time.Now().Add(tc.latestUpdatedAt),
+			}
+			muteRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(nil, nil)
+			prefRepo.EXPECT().Find(gomock.Any(), recipientID, tc.notifType).Return(nil, nil)
+			// ロックしてから探す
+			gomock.InOrder(
+				notificationRepo.EXPECT().LockAggregation(gomock.Any(), recipientID, senderID, tc.notifType).Return(nil),
+				notificationRepo.EXPECT().FindLatest(gomock.Any(), recipientID, senderID, tc.notifType, gomock.Any()).Return(latest, nil),
+			)
+			if tc.expectIncrement {
+				notificationRepo.EXPECT().Increment(gomock.Any(), latest).DoAndReturn(func(ctx context.Context, n *entity.Notification) error {
+					n.Count++
+					n.UpdatedAt = time.Now()
+					return nil
+				})
+				profileRepo.EXPECT().Find(gomock.Any(), senderID).Return(&entity.UserProfile{
+					UserID:   senderID,
+					Username: sql.NullString{String: "taro", Valid: true},
+				}, nil)
+				pictureRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(nil, nil)
+				fileClient.EXPECT().AvatarURL(senderID).Return("http://files/avatars/sender.png")
+				pub.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, data interface{}) error {
+					var payload client.NotificationPayload
+					assert.NoError(t, json.Unmarshal(data.([]byte), &payload))
+					assert.Equal(t, int64(5), payload.ID)
+					assert.Equal(t, 2, payload.Count)
+					assert.Equal(t, "taro", payload.SenderName)
+					return nil
+				})
+			}
+
+			notification, err := s.CreateAndSendNotification(context.Background(), senderID, recipientID, tc.notifType)
+
+			assert.NoError(t, err)
+			if tc.expectNil {
+				assert.Nil(t, notification)
+			} else {
+				assert.Equal(t, 2, notification.Count)
+			}
+		})
+	}
+}
+
+func TestNotificationService_UpdateSettings(t *testing.T) {
+	userID := uuid.New()
+	mutedID := uuid.New()
+
+	t.Run("Replace preferences and mutes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		prefRepo := mock.NewMockNotificationPreferenceRepository(ctrl)
+		muteRepo := mock.NewMockNotificationMuteRepository(ctrl)
+		s := NewNotificationService(&mockUow{rm: &mockRepositoryManager{prefRepo: prefRepo, muteRepo: muteRepo}}, nil, prefRepo, muteRepo, nil, nil, nil, nil, nil)
+
+		mutedUntil := time.Now().Add(24 * time.Hour)
+		saved := map[entity.NotificationType]*entity.NotificationPreference{}
+		prefRepo.EXPECT().Upsert(gomock.Any(), gomock.Any()).Times(len(entity.NotificationTypes)).
+			DoAndReturn(func(ctx context.Context, pref *entity.NotificationPreference) error {
+				saved[pref.Type] = pref
+				return nil
+			})
+		muteRepo.EXPECT().DeleteAll(gomock.Any(), userID).Return(nil)
+		muteRepo.EXPECT().Upsert(gomock.Any(), gomock.Any()).Return(nil)
+		prefRepo.EXPECT().Query(gomock.Any(), userID).DoAndReturn(func(ctx context.Context, userID uuid.UUID) ([]*entity.NotificationPreference, error) {
+			return []*entity.NotificationPreference{saved[entity.NotifView]}, nil
+		})
+		muteRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return([]*entity.NotificationMute{
+			{UserID: userID, MutedUserID: mutedID, MutedUntil: mutedUntil},
+		}, nil)
+
+		settings, err := s.UpdateSettings(context.Background(), userID, &service.NotificationSettings{
+			Types: map[entity.NotificationType]service.NotificationChannels{
+				entity.NotifView: {InApp: false, EmailDigest: false},
+			},
+			Mutes: []*service.NotificationMuteSetting{{UserID: mutedID, MutedUntil: mutedUntil}},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, service.NotificationChannels{}, settings.Types[entity.NotifView])
+		assert.Equal(t, defaultChannels, settings.Types[entity.NotifLike])
+		// 指定しなかった種類は既定値で保存し直す
+		assert.True(t, saved[entity.NotifLike].InApp)
+		assert.True(t, saved[entity.NotifLike].EmailDigest)
+		assert.Len(t, settings.Mutes, 1)
+	})
+
+	invalidCases := []struct {
+		name     string
+		settings *service.NotificationSettings
+	}{
+		{
+			name: "Unknown notification type",
+			settings: &service.NotificationSettings{
+				Types: map[entity.NotificationType]service.NotificationChannels{"poke": {}},
+			},
+		},
+		{
+			name: "Muting yourself",
+			settings: &service.NotificationSettings{
+				Mutes: []*service.NotificationMuteSetting{{UserID: userID, MutedUntil: time.Now().Add(time.Hour)}},
+			},
+		},
+		{
+			name: "Mute already expired",
+			settings: &service.NotificationSettings{
+				Mutes: []*service.NotificationMuteSetting{{UserID: mutedID, MutedUntil: time.Now().Add(-time.Hour)}},
+			},
+		},
+	}
+	for _, tc := range invalidCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewNotificationService(&mockUow{}, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			_, err := s.UpdateSettings(context.Background(), userID, tc.settings)
+
+			assert.ErrorIs(t, err, apperrors.ErrInvalidInput)
+		})
+	}
+}
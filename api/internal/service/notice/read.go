@@ -0,0 +1,196 @@
+package notice
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
+)
+
+const (
+	defaultNotificationLimit = 20
+	maxNotificationLimit     = 100
+)
+
+func (s *notificationService) GetNotifications(ctx context.Context, params *service.GetNotificationsParams) (*service.NotificationPage, error) {
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultNotificationLimit
+	}
+	limit = min(limit, maxNotificationLimit)
+
+	q := &repo.NotificationQuery{
+		RecipientID: &params.RecipientID,
+		// 次のページがあるかを知るため 1 件多く取る
+		Limit: limit + 1,
+	}
+	if params.UnreadOnly {
+		isRead := false
+		q.IsRead = &isRead
+	}
+	if params.Cursor != "" {
+		cursor, ok := parseNotificationCursor(params.Cursor)
+		if !ok {
+			return nil, apperrors.ErrInvalidInput
+		}
+		q.Before = cursor
+	}
+	notifications, err := s.notificationRepo.Query(ctx, q)
+	if err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+	unread, err := s.notificationRepo.CountUnread(ctx, params.RecipientID)
+	if err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+
+	page := &service.NotificationPage{
+		Notifications: make([]*service.NotificationItem, 0, min(len(notifications), limit)),
+		UnreadCount:   unread,
+	}
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+		page.NextCursor = formatNotificationCursor(notifications[len(notifications)-1])
+	}
+	for _, notification := range notifications {
+		page.Notifications = append(page.Notifications, toNotificationItem(notification))
+	}
+	senderIDs := make([]uuid.UUID, 0, len(page.Notifications))
+	for _, item := range page.Notifications {
+		if item.SenderID != nil {
+			senderIDs = append(senderIDs, *item.SenderID)
+		}
+	}
+	senders := s.senderInfos(ctx, params.RecipientID, senderIDs)
+	for _, item := range page.Notifications {
+		if item.SenderID != nil {
+			item.SenderName = senders[*item.SenderID].name
+			item.SenderThumbnailURL = senders[*item.SenderID].thumbnailURL
+		}
+	}
+	return page, nil
+}
+
+// 通知一覧のカーソルは "<updated_at の unix マイクロ秒>_<id>"。updated_at は DB と同じ精度で持つ
+func formatNotificationCursor(notification *entity.Notification) string {
+	return strconv.FormatInt(notification.UpdatedAt.UnixMicro(), 10) + "_" + strconv.FormatInt(notification.ID, 10)
+}
+
+func parseNotificationCursor(cursor string) (*repo.NotificationCursor, bool) {
+	updatedAt, id, ok := strings.Cut(cursor, "_")
+	if !ok {
+		return nil, false
+	}
+	micros, err := strconv.ParseInt(updatedAt, 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	notificationID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || notificationID <= 0 {
+		return nil, false
+	}
+	return &repo.NotificationCursor{UpdatedAt: time.UnixMicro(micros), ID: notificationID}, true
+}
+
+// findOwn は userID 宛ての通知を返す。他人の通知は存在しないものとして扱う
+func (s *notificationService) findOwn(ctx context.Context, userID uuid.UUID, notificationID int64) (*entity.Notification, error) {
+	notification, err := s.notificationRepo.Find(ctx, notificationID)
+	if err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+	if notification == nil || notification.RecipientID != userID {
+		return nil, apperrors.ErrNotFound
+	}
+	return notification, nil
+}
+
+func (s *notificationService) MarkNotificationRead(ctx context.Context, userID uuid.UUID, notificationID int64, isRead bool) (*service.NotificationItem, error) {
+	notification, err := s.findOwn(ctx, userID, notificationID)
+	if err != nil {
+		return nil, err
+	}
+	notification.IsRead.Bool = isRead
+	notification.IsRead.Valid = true
+	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		return rm.NotificationRepo().Update(ctx, notification)
+	}); err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+	action := client.NotificationReadActionRead
+	if !isRead {
+		action = client.NotificationReadActionUnread
+	}
+	if err := s.publishRead(ctx, userID, action, notificationID); err != nil {
+		return nil, err
+	}
+	return toNotificationItem(notification), nil
+}
+
+func (s *notificationService) MarkAllNotificationsRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var updated int64
+	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		var err error
+		updated, err = rm.NotificationRepo().MarkAllRead(ctx, userID)
+		return err
+	}); err != nil {
+		return 0, apperrors.ErrInternalServer
+	}
+	if err := s.publishRead(ctx, userID, client.NotificationReadActionReadAll, 0); err != nil {
+		return 0, err
+	}
+	return updated, nil
+}
+
+func (s *notificationService) DeleteNotification(ctx context.Context, userID uuid.UUID, notificationID int64) error {
+	if _, err := s.findOwn(ctx, userID, notificationID); err != nil {
+		return err
+	}
+	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		return rm.NotificationRepo().Delete(ctx, notificationID)
+	}); err != nil {
+		return apperrors.ErrInternalServer
+	}
+	return s.publishRead(ctx, userID, client.NotificationReadActionDelete, notificationID)
+}
+
+// publishRead は変更後の未読数を添えて、本人のすべてのタブに知らせる
+func (s *notificationService) publishRead(ctx context.Context, userID uuid.UUID, action string, notificationID int64) error {
+	unread, err := s.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return apperrors.ErrInternalServer
+	}
+	payloadBytes, err := json.Marshal(&client.NotificationReadPayload{
+		UserID:         userID,
+		Action:         action,
+		NotificationID: notificationID,
+		UnreadCount:    unread,
+		Timestamp:      time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return err
+	}
+	return s.notifReadPub.Publish(ctx, payloadBytes)
+}
+
+func toNotificationItem(notification *entity.Notification) *service.NotificationItem {
+	item := &service.NotificationItem{
+		ID:        notification.ID,
+		Type:      notification.Type,
+		IsRead:    notification.IsRead.Bool,
+		Count:     notification.Count,
+		CreatedAt: notification.CreatedAt,
+		UpdatedAt: notification.UpdatedAt,
+	}
+	if senderID, err := uuid.Parse(notification.SenderID.String); notification.SenderID.Valid && err == nil {
+		item.SenderID = &senderID
+	}
+	return item
+}
@@ -0,0 +1,153 @@
+package notice
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
+	"github.com/icchon/matcha/api/internal/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNotificationService_GetNotifications(t *testing.T) {
+	userID := uuid.New()
+	senderID := uuid.New()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 123456000, time.UTC)
+
+	t.Run("Unread page with next cursor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		notificationRepo := mock.NewMockNotificationRepository(ctrl)
+		profileRepo := mock.NewMockUserProfileQueryRepository(ctrl)
+		pictureRepo := mock.NewMockPictureQueryRepository(ctrl)
+		fileClient := mock.NewMockFileClient(ctrl)
+		s := NewNotificationService(&mockUow{}, notificationRepo, nil, nil, profileRepo, pictureRepo, nil, nil, fileClient)
+
+		notificationRepo.EXPECT().Query(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, q *repo.NotificationQuery) ([]*entity.Notification, error) {
+			assert.Equal(t, userID, *q.RecipientID)
+			assert.False(t, *q.IsRead)
+			assert.Equal(t, int64(10), q.Before.ID)
+			assert.True(t, now.Equal(q.Before.UpdatedAt), "cursor time = %s", q.Before.UpdatedAt)
+			assert.Equal(t, 3, q.Limit)
+			// まとめて更新された古い通知 (id 3) は、更新時刻の順で新しい通知より前に並ぶ
+			return []*entity.Notification{
+				{ID: 3, RecipientID: userID, SenderID: sql.NullString{String: senderID.String(), Valid: true}, Type: entity.NotifLike, UpdatedAt: now.Add(-time.Minute)},
+				{ID: 8, RecipientID: userID, Type: entity.NotifView, UpdatedAt: now.Add(-time.Hour)},
+				{ID: 7, RecipientID: userID, Type: entity.NotifView, UpdatedAt: now.Add(-2 * time.Hour)},
+			}, nil
+		})
+		notificationRepo.EXPECT().CountUnread(gomock.Any(), userID).Return(5, nil)
+		profileRepo.EXPECT().Find(gomock.Any(), senderID).Return(&entity.UserProfile{
+			UserID:    senderID,
+			FirstName: sql.NullString{String: "Taro", Valid: true},
+		}, nil)
+		pictureRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return([]*entity.Picture{
+			{UserID: senderID, URL: "http://files/first.jpg"},
+			{UserID: senderID, URL: "http://files/profile.jpg", IsProfilePic: sql.NullBool{Bool: true, Valid: true}},
+		}, nil)
+		// 受信者が見るための URL にする
+		fileClient.EXPECT().SignImageURL("http://files/profile.jpg", userID, gomock.Any()).Return("http://files/profile.jpg?sig=x")
+
+		page, err := s.GetNotifications(context.Background(), &service.GetNotificationsParams{
+			RecipientID: userID, UnreadOnly: true, Cursor: formatNotificationCursor(&entity.Notification{ID: 10, UpdatedAt: now}), Limit: 2,
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, page.Notifications, 2)
+		assert.Equal(t, formatNotificationCursor(&entity.Notification{ID: 8, UpdatedAt: now.Add(-time.Hour)}), page.NextCursor)
+		assert.Equal(t, int64(3), page.Notifications[0].ID)
+		assert.Equal(t, 5, page.UnreadCount)
+		assert.Equal(t, senderID, *page.Notifications[0].SenderID)
+		assert.Equal(t, "Taro", page.Notifications[0].SenderName)
+		assert.Equal(t, "http://files/profile.jpg?sig=x", page.Notifications[0].SenderThumbnailURL)
+		assert.Nil(t, page.Notifications[1].SenderID)
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		s := NewNotificationService(&mockUow{}, nil, nil, nil, nil, nil, nil, nil, nil)
+
+		// id だけの古い形式も受け付けない
+		for _, cursor := range []string{"abc", "10", "1700000000000000_", "_10", "1700000000000000_0", "x_10"} {
+			_, err := s.GetNotifications(context.Background(), &service.GetNotificationsParams{RecipientID: userID, Cursor: cursor})
+
+			assert.ErrorIs(t, err, apperrors.ErrInvalidInput, cursor)
+		}
+	})
+}
+
+func TestNotificationService_MarkNotificationRead(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("Mark read and notify other tabs", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		notificationRepo := mock.NewMockNotificationRepository(ctrl)
+		readPub := mock.NewMockPublisher(ctrl)
+		s := NewNotificationService(&mockUow{rm: &mockRepositoryManager{notificationRepo: notificationRepo}}, notificationRepo, nil, nil, nil, nil, nil, readPub, nil)
+
+		notificationRepo.EXPECT().Find(gomock.Any(), int64(3)).Return(&entity.Notification{ID: 3, RecipientID: userID}, nil)
+		notificationRepo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, n *entity.Notification) error {
+			assert.True(t, n.IsRead.Bool)
+			return nil
+		})
+		notificationRepo.EXPECT().CountUnread(gomock.Any(), userID).Return(1, nil)
+		readPub.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, data interface{}) error {
+			var payload client.NotificationReadPayload
+			assert.NoError(t, json.Unmarshal(data.([]byte), &payload))
+			assert.Equal(t, userID, payload.UserID)
+			assert.Equal(t, client.NotificationReadActionRead, payload.Action)
+			assert.Equal(t, int64(3), payload.NotificationID)
+			assert.Equal(t, 1, payload.UnreadCount)
+			return nil
+		})
+
+		item, err := s.MarkNotificationRead(context.Background(), userID, 3, true)
+
+		assert.NoError(t, err)
+		assert.True(t, item.IsRead)
+	})
+
+	t.Run("Someone else's notification is not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		notificationRepo := mock.NewMockNotificationRepository(ctrl)
+		s := NewNotificationService(&mockUow{}, notificationRepo, nil, nil, nil, nil, nil, nil, nil)
+
+		notificationRepo.EXPECT().Find(gomock.Any(), int64(3)).Return(&entity.Notification{ID: 3, RecipientID: uuid.New()}, nil)
+
+		_, err := s.MarkNotificationRead(context.Background(), userID, 3, true)
+
+		assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	})
+}
+
+func TestNotificationService_MarkAllNotificationsRead(t *testing.T) {
+	userID := uuid.New()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	notificationRepo := mock.NewMockNotificationRepository(ctrl)
+	readPub := mock.NewMockPublisher(ctrl)
+	s := NewNotificationService(&mockUow{rm: &mockRepositoryManager{notificationRepo: notificationRepo}}, notificationRepo, nil, nil, nil, nil, nil, readPub, nil)
+
+	notificationRepo.EXPECT().MarkAllRead(gomock.Any(), userID).Return(int64(4), nil)
+	notificationRepo.EXPECT().CountUnread(gomock.Any(), userID).Return(0, nil)
+	readPub.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil)
+
+	updated, err := s.MarkAllNotificationsRead(context.Background(), userID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), updated)
+}
@@ -0,0 +1,81 @@
+package notice
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+)
+
+// 通知に載せるサムネイルの署名付き URL の有効期間の単位 (client.ImageURLExpiry)
+const senderThumbnailURLTTL = time.Hour
+
+type senderInfo struct {
+	name         string
+	thumbnailURL string
+}
+
+// senderInfo は通知に載せる送信者の表示名と、受信者 recipientID 向けに署名したサムネイルを返す
+// 取得に失敗しても通知自体は送るため、エラーはログに残して空で返す
+func (s *notificationService) senderInfo(ctx context.Context, recipientID, senderID uuid.UUID) senderInfo {
+	var info senderInfo
+	profile, err := s.profileRepo.Find(ctx, senderID)
+	if err != nil {
+		log.Printf("Failed to load profile of notification sender %s: %v", senderID, err)
+	} else {
+		info.name = displayName(profile)
+	}
+
+	// 審査の済んでいない写真は受信者に見せない
+	approved := entity.PictureApproved
+	pictures, err := s.pictureRepo.Query(ctx, &repo.PictureQuery{UserID: &senderID, Status: &approved})
+	if err != nil {
+		log.Printf("Failed to load pictures of notification sender %s: %v", senderID, err)
+		return info
+	}
+	for _, picture := range pictures {
+		if picture.IsProfilePic.Valid && picture.IsProfilePic.Bool {
+			info.thumbnailURL = s.signThumbnail(recipientID, picture)
+			return info
+		}
+	}
+	if len(pictures) > 0 {
+		info.thumbnailURL = s.signThumbnail(recipientID, pictures[0])
+	} else {
+		info.thumbnailURL = s.fileClient.AvatarURL(senderID)
+	}
+	return info
+}
+
+func (s *notificationService) signThumbnail(recipientID uuid.UUID, picture *entity.Picture) string {
+	return s.fileClient.SignImageURL(picture.Thumbnail(), recipientID, client.ImageURLExpiry(time.Now(), senderThumbnailURLTTL))
+}
+
+// displayName はユーザー名、なければ名前を返す
+func displayName(profile *entity.UserProfile) string {
+	switch {
+	case profile == nil:
+		return ""
+	case profile.Username.Valid && profile.Username.String != "":
+		return profile.Username.String
+	case profile.FirstName.Valid:
+		return profile.FirstName.String
+	}
+	return ""
+}
+
+// senderInfos は通知一覧の送信者情報を送信者ごとに 1 回だけ読む
+func (s *notificationService) senderInfos(ctx context.Context, recipientID uuid.UUID, senderIDs []uuid.UUID) map[uuid.UUID]senderInfo {
+	infos := make(map[uuid.UUID]senderInfo, len(senderIDs))
+	for _, senderID := range senderIDs {
+		if _, ok := infos[senderID]; ok {
+			continue
+		}
+		infos[senderID] = s.senderInfo(ctx, recipientID, senderID)
+	}
+	return infos
+}
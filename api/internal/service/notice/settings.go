@@ -0,0 +1,118 @@
+package notice
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
+)
+
+// 設定がない種類はすべてのチャネルで受け取る
+var defaultChannels = service.NotificationChannels{InApp: true, EmailDigest: true}
+
+// channelsFor は recipientID が senderID からの notifType の通知をどのチャネルで受け取るかを返す
+// ミュート中の相手からは何も受け取らない
+func (s *notificationService) channelsFor(ctx context.Context, recipientID, senderID uuid.UUID, notifType entity.NotificationType) (service.NotificationChannels, error) {
+	now := time.Now()
+	mutes, err := s.muteRepo.Query(ctx, &repo.NotificationMuteQuery{
+		UserID:      &recipientID,
+		MutedUserID: &senderID,
+		ActiveAt:    &now,
+	})
+	if err != nil {
+		return service.NotificationChannels{}, err
+	}
+	if len(mutes) > 0 {
+		return service.NotificationChannels{}, nil
+	}
+	pref, err := s.prefRepo.Find(ctx, recipientID, notifType)
+	if err != nil {
+		return service.NotificationChannels{}, err
+	}
+	if pref == nil {
+		return defaultChannels, nil
+	}
+	return service.NotificationChannels{InApp: pref.InApp, EmailDigest: pref.EmailDigest}, nil
+}
+
+func (s *notificationService) GetSettings(ctx context.Context, userID uuid.UUID) (*service.NotificationSettings, error) {
+	prefs, err := s.prefRepo.Query(ctx, userID)
+	if err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+	now := time.Now()
+	mutes, err := s.muteRepo.Query(ctx, &repo.NotificationMuteQuery{UserID: &userID, ActiveAt: &now})
+	if err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+
+	settings := &service.NotificationSettings{
+		Types: make(map[entity.NotificationType]service.NotificationChannels, len(entity.NotificationTypes)),
+		Mutes: make([]*service.NotificationMuteSetting, 0, len(mutes)),
+	}
+	for _, notifType := range entity.NotificationTypes {
+		settings.Types[notifType] = defaultChannels
+	}
+	for _, pref := range prefs {
+		settings.Types[pref.Type] = service.NotificationChannels{InApp: pref.InApp, EmailDigest: pref.EmailDigest}
+	}
+	for _, mute := range mutes {
+		settings.Mutes = append(settings.Mutes, &service.NotificationMuteSetting{UserID: mute.MutedUserID, MutedUntil: mute.MutedUntil})
+	}
+	return settings, nil
+}
+
+func (s *notificationService) UpdateSettings(ctx context.Context, userID uuid.UUID, settings *service.NotificationSettings) (*service.NotificationSettings, error) {
+	known := make(map[entity.NotificationType]bool, len(entity.NotificationTypes))
+	for _, notifType := range entity.NotificationTypes {
+		known[notifType] = true
+	}
+	for notifType := range settings.Types {
+		if !known[notifType] {
+			return nil, apperrors.ErrInvalidInput
+		}
+	}
+	now := time.Now()
+	for _, mute := range settings.Mutes {
+		if mute.UserID == uuid.Nil || mute.UserID == userID || !mute.MutedUntil.After(now) {
+			return nil, apperrors.ErrInvalidInput
+		}
+	}
+
+	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		for _, notifType := range entity.NotificationTypes {
+			channels, ok := settings.Types[notifType]
+			if !ok {
+				channels = defaultChannels
+			}
+			if err := rm.NotificationPreferenceRepo().Upsert(ctx, &entity.NotificationPreference{
+				UserID:      userID,
+				Type:        notifType,
+				InApp:       channels.InApp,
+				EmailDigest: channels.EmailDigest,
+			}); err != nil {
+				return err
+			}
+		}
+		if err := rm.NotificationMuteRepo().DeleteAll(ctx, userID); err != nil {
+			return err
+		}
+		for _, mute := range settings.Mutes {
+			if err := rm.NotificationMuteRepo().Upsert(ctx, &entity.NotificationMute{
+				UserID:      userID,
+				MutedUserID: mute.UserID,
+				MutedUntil:  mute.MutedUntil,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, apperrors.ErrInternalServer
+	}
+	return s.GetSettings(ctx, userID)
+}
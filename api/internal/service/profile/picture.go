@@ -3,115 +3,292 @@ package profile
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"log"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/client"
 	"github.com/icchon/matcha/api/internal/domain/entity"
 	"github.com/icchon/matcha/api/internal/domain/repo"
 )
 
-func (s *profileService) DeletePicture(ctx context.Context, pictureID int32, userID uuid.UUID) error {
-	picture, err := s.pictureRepo.Find(ctx, pictureID)
-	if err != nil {
-		return apperrors.ErrInternalServer
-	}
-	if picture == nil || picture.UserID != userID {
-		return apperrors.ErrNotFound
+const (
+	// 1 ユーザーが持てる写真の枚数
+	maxPicturesPerUser = 5
+	// 写真の署名付き URL の有効期間の単位 (client.ImageURLExpiry)
+	pictureURLTTL = time.Hour
+)
+
+// lockPictures は同じユーザーの写真の変更を直列化してから、並び順どおりの写真を返す
+func lockPictures(ctx context.Context, rm repo.RepositoryManager, userID uuid.UUID) ([]*entity.Picture, error) {
+	if err := rm.PictureRepo().LockByUser(ctx, userID); err != nil {
+		return nil, err
 	}
-	return s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
-		return rm.PictureRepo().Delete(ctx, pictureID)
-	})
+	return rm.PictureRepo().Query(ctx, &repo.PictureQuery{UserID: &userID})
 }
 
-func (s *profileService) FindPicture(ctx context.Context, pictureID int32) (*entity.Picture, error) {
-	return s.pictureRepo.Find(ctx, pictureID)
+// savePictures は pictures の順に position を振り直して保存する
+// プロフィール写真の部分ユニークインデックスに引っかからないよう、プロフィール写真は最後に更新する
+func savePictures(ctx context.Context, rm repo.RepositoryManager, pictures []*entity.Picture) error {
+	var profilePic *entity.Picture
+	for i, pic := range pictures {
+		pic.Position = i
+		if pic.IsProfilePic.Bool {
+			profilePic = pic
+			continue
+		}
+		if err := rm.PictureRepo().Update(ctx, pic); err != nil {
+			return err
+		}
+	}
+	if profilePic == nil {
+		return nil
+	}
+	return rm.PictureRepo().Update(ctx, profilePic)
 }
 
-func (s *profileService) FindPictures(ctx context.Context, userID uuid.UUID) ([]*entity.Picture, error) {
-	return s.pictureRepo.Query(ctx, &repo.PictureQuery{UserID: &userID})
+// releaseImages は filesrv の参照を外す。失敗しても写真の操作は取り消さない
+func (s *profileService) releaseImages(assetIDs []string) {
+	for _, assetID := range assetIDs {
+		if assetID == "" {
+			continue
+		}
+		if err := s.fileClient.DeleteImage(assetID); err != nil {
+			log.Printf("Failed to release picture asset %s: %v", assetID, err)
+		}
+	}
 }
 
-func (s *profileService) UpdatePictureStatus(ctx context.Context, userID uuid.UUID, pictureID int32, isProfilePic bool) error {
-	pictures, err := s.pictureRepo.Query(ctx, &repo.PictureQuery{UserID: &userID})
-	if err != nil {
-		return apperrors.ErrInternalServer
-	}
-	if pictures == nil {
+// fileError は filesrv が受け付けなかった理由をクライアントに返せるエラーにする
+func fileError(err error) error {
+	switch {
+	case errors.Is(err, client.ErrFileRejected):
+		return apperrors.ErrInvalidInput
+	case errors.Is(err, client.ErrFileQuotaExceeded):
+		return apperrors.ErrTooManyRequests
+	case errors.Is(err, client.ErrUploadNotFound):
 		return apperrors.ErrNotFound
+	case errors.Is(err, client.ErrUploadIncomplete):
+		return apperrors.ErrConflict
 	}
-	return s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+	return err
+}
+
+// DeletePicture はプロフィール写真を消したら先頭の写真をプロフィール写真にし、並び順を詰める
+// コミットした後に filesrv の参照を外す
+func (s *profileService) DeletePicture(ctx context.Context, pictureID int32, userID uuid.UUID) error {
+	var deleted *entity.Picture
+	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		pictures, err := lockPictures(ctx, rm, userID)
+		if err != nil {
+			return err
+		}
+		remaining := make([]*entity.Picture, 0, len(pictures))
 		for _, pic := range pictures {
-			if isProfilePic {
-				if pic.ID == pictureID {
-					if isProfilePic == pic.IsProfilePic.Bool {
-						continue
-					}
-					pic.IsProfilePic = sql.NullBool{Bool: isProfilePic, Valid: true}
-				} else {
-					if !pic.IsProfilePic.Bool {
-						continue
-					}
-					pic.IsProfilePic = sql.NullBool{Bool: false, Valid: true}
-				}
-			} else {
-				if pic.ID == pictureID {
-					if isProfilePic == pic.IsProfilePic.Bool {
-						continue
-					}
-					pic.IsProfilePic = sql.NullBool{Bool: isProfilePic, Valid: true}
-				} else {
-					continue
-				}
-			}
-			if err := rm.PictureRepo().Update(ctx, pic); err != nil {
-				return err
+			if pic.ID == pictureID {
+				deleted = pic
+				continue
 			}
+			remaining = append(remaining, pic)
 		}
-		return nil
-	})
+		if deleted == nil {
+			return apperrors.ErrNotFound
+		}
+		if err := rm.PictureRepo().Delete(ctx, pictureID); err != nil {
+			return err
+		}
+		if deleted.IsProfilePic.Bool && len(remaining) > 0 {
+			remaining[0].IsProfilePic = sql.NullBool{Bool: true, Valid: true}
+		}
+		return savePictures(ctx, rm, remaining)
+	}); err != nil {
+		return err
+	}
+	s.releaseImages([]string{deleted.AssetID})
+	return nil
 }
 
-func (s *profileService) UploadPicture(ctx context.Context, userID uuid.UUID, image []byte) (*entity.Picture, error) {
-	if len(image) == 0 {
-		return nil, apperrors.ErrInvalidInput
+func (s *profileService) FindPicture(ctx context.Context, pictureID int32) (*entity.Picture, error) {
+	return s.pictureRepo.Find(ctx, pictureID)
+}
+
+// FindPictures はブロックしている・されている相手の写真を存在しないものとして扱う
+// 本人以外には承認された写真だけを返す
+func (s *profileService) FindPictures(ctx context.Context, viewerID, userID uuid.UUID) ([]*entity.Picture, error) {
+	q := &repo.PictureQuery{UserID: &userID}
+	if viewerID != userID {
+		blocked, err := s.isBlocked(ctx, viewerID, userID)
+		if err != nil {
+			return nil, apperrors.ErrInternalServer
+		}
+		if blocked {
+			return nil, apperrors.ErrNotFound
+		}
+		approved := entity.PictureApproved
+		q.Status = &approved
 	}
-	url, err := s.fileClient.SaveImage(image, uuid.NewString())
+	pictures, err := s.pictureRepo.Query(ctx, q)
 	if err != nil {
 		return nil, err
 	}
-	picture := &entity.Picture{
-		UserID:       userID,
-		URL:          url,
-		IsProfilePic: sql.NullBool{Bool: false, Valid: true},
+	s.signPictures(viewerID, pictures...)
+	return pictures, nil
+}
+
+func (s *profileService) isBlocked(ctx context.Context, a, b uuid.UUID) (bool, error) {
+	for _, pair := range [][2]uuid.UUID{{a, b}, {b, a}} {
+		block, err := s.blockRepo.Find(ctx, pair[0], pair[1])
+		if err != nil {
+			return false, err
+		}
+		if block != nil {
+			return true, nil
+		}
 	}
+	return false, nil
+}
+
+func (s *profileService) AvatarURL(userID uuid.UUID) string {
+	return s.fileClient.AvatarURL(userID)
+}
+
+// signPictures は返す写真の URL を viewerID だけが期限まで使える URL にする
+// 保存し直さない写真にだけ使う
+func (s *profileService) signPictures(viewerID uuid.UUID, pictures ...*entity.Picture) {
+	expiresAt := client.ImageURLExpiry(time.Now(), pictureURLTTL)
+	for _, pic := range pictures {
+		pic.URL = s.fileClient.SignImageURL(pic.URL, viewerID, expiresAt)
+		if pic.ThumbnailURL != "" {
+			pic.ThumbnailURL = s.fileClient.SignImageURL(pic.ThumbnailURL, viewerID, expiresAt)
+		}
+		if pic.CardURL != "" {
+			pic.CardURL = s.fileClient.SignImageURL(pic.CardURL, viewerID, expiresAt)
+		}
+	}
+}
+
+func (s *profileService) SetProfilePicture(ctx context.Context, userID uuid.UUID, pictureID int32) ([]*entity.Picture, error) {
+	var pictures []*entity.Picture
 	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
-		return rm.PictureRepo().Create(ctx, picture)
+		var err error
+		pictures, err = lockPictures(ctx, rm, userID)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, pic := range pictures {
+			found = found || pic.ID == pictureID
+		}
+		if !found {
+			return apperrors.ErrNotFound
+		}
+		for _, pic := range pictures {
+			pic.IsProfilePic = sql.NullBool{Bool: pic.ID == pictureID, Valid: true}
+		}
+		return savePictures(ctx, rm, pictures)
 	}); err != nil {
 		return nil, err
 	}
-	return picture, nil
+	s.signPictures(userID, pictures...)
+	return pictures, nil
 }
 
-func (s *profileService) UploadPicutures(ctx context.Context, userID uuid.UUID, images [][]byte) ([]*entity.Picture, error) {
+func (s *profileService) ReorderPictures(ctx context.Context, userID uuid.UUID, pictureIDs []int32) ([]*entity.Picture, error) {
+	var ordered []*entity.Picture
+	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		pictures, err := lockPictures(ctx, rm, userID)
+		if err != nil {
+			return err
+		}
+		if len(pictureIDs) != len(pictures) {
+			return apperrors.ErrInvalidInput
+		}
+		byID := make(map[int32]*entity.Picture, len(pictures))
+		for _, pic := range pictures {
+			byID[pic.ID] = pic
+		}
+		ordered = make([]*entity.Picture, 0, len(pictures))
+		for _, id := range pictureIDs {
+			pic, ok := byID[id]
+			if !ok {
+				return apperrors.ErrInvalidInput
+			}
+			// 同じ ID を 2 回指定させない
+			delete(byID, id)
+			ordered = append(ordered, pic)
+		}
+		return savePictures(ctx, rm, ordered)
+	}); err != nil {
+		return nil, err
+	}
+	s.signPictures(userID, ordered...)
+	return ordered, nil
+}
+
+func (s *profileService) UploadPicture(ctx context.Context, userID uuid.UUID, image *client.FileSource) (*entity.Picture, error) {
+	pictures, err := s.UploadPicutures(ctx, userID, []*client.FileSource{image})
+	if err != nil {
+		return nil, err
+	}
+	return pictures[0], nil
+}
+
+// UploadPicutures は写真を末尾に追加する。プロフィール写真がなければ 1 枚目をプロフィール写真にする
+func (s *profileService) UploadPicutures(ctx context.Context, userID uuid.UUID, images []*client.FileSource) ([]*entity.Picture, error) {
 	n := len(images)
-	if n == 0 {
+	if n == 0 || n > maxPicturesPerUser {
 		return nil, apperrors.ErrInvalidInput
 	}
-	var urls []string
 	for _, img := range images {
-		url, err := s.fileClient.SaveImage(img, uuid.NewString())
+		if img == nil || (img.Reader == nil && img.UploadID == "") {
+			return nil, apperrors.ErrInvalidInput
+		}
+	}
+	// 上限を超えるのが明らかなら filesrv に送らない。確定はトランザクションの中で行う
+	existing, err := s.pictureRepo.Query(ctx, &repo.PictureQuery{UserID: &userID})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing)+n > maxPicturesPerUser {
+		return nil, apperrors.ErrConflict
+	}
+
+	var saved []*client.Image
+	var assetIDs []string
+	for _, img := range images {
+		// 元のファイル名は filesrv に渡さない
+		image, err := s.fileClient.SaveImage(userID, &client.FileSource{Reader: img.Reader, UploadID: img.UploadID, Filename: uuid.NewString()})
 		if err != nil {
-			return nil, err
+			s.releaseImages(assetIDs)
+			return nil, fileError(err)
 		}
-		urls = append(urls, url)
+		saved = append(saved, image)
+		assetIDs = append(assetIDs, image.AssetID)
 	}
 	var pictures []*entity.Picture
 	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
-		for _, url := range urls {
+		current, err := lockPictures(ctx, rm, userID)
+		if err != nil {
+			return err
+		}
+		if len(current)+n > maxPicturesPerUser {
+			return apperrors.ErrConflict
+		}
+		hasProfilePic := false
+		for _, pic := range current {
+			hasProfilePic = hasProfilePic || pic.IsProfilePic.Bool
+		}
+		for i, image := range saved {
 			pic := &entity.Picture{
 				UserID:       userID,
-				URL:          url,
-				IsProfilePic: sql.NullBool{Bool: false, Valid: true},
+				URL:          image.FullURL,
+				AssetID:      image.AssetID,
+				ThumbnailURL: image.ThumbnailURL,
+				CardURL:      image.CardURL,
+				IsProfilePic: sql.NullBool{Bool: !hasProfilePic && i == 0, Valid: true},
+				Position:     len(current) + i,
 			}
 			if err := rm.PictureRepo().Create(ctx, pic); err != nil {
 				return err
@@ -120,7 +297,10 @@ func (s *profileService) UploadPicutures(ctx context.Context, userID uuid.UUID,
 		}
 		return nil
 	}); err != nil {
+		// 保存できなかった写真の参照は残さない
+		s.releaseImages(assetIDs)
 		return nil, err
 	}
+	s.signPictures(userID, pictures...)
 	return pictures, nil
 }
@@ -1,509 +1,428 @@
 package profile
 
-// import (
-// 	"context"
-// 	"database/sql"
-// 	"errors"
-// 	"testing"
-
-// 	"github.com/google/uuid"
-// 	"github.com/icchon/matcha/api/internal/apperrors"
-// 	"github.com/icchon/matcha/api/internal/domain/entity"
-// 	"github.com/icchon/matcha/api/internal/domain/repo"
-// 	"github.com/icchon/matcha/api/internal/mock"
-// 	"github.com/stretchr/testify/assert"
-// 	"go.uber.org/mock/gomock"
-// )
-
-// // Re-defining mock helpers as they are not in a shared file.
-
-// // mockPictureRepositoryManager is a mock for repo.RepositoryManager.
-// type mockPictureRepositoryManager struct {
-// 	repo.RepositoryManager
-// 	pictureRepo repo.PictureRepository
-// }
-
-// func (m *mockPictureRepositoryManager) PictureRepo() repo.PictureRepository {
-// 	return m.pictureRepo
-// }
-
-// // mockPictureUow is a mock for repo.UnitOfWork for testing services.
-// type mockPictureUow struct {
-// 	rm  repo.RepositoryManager
-// 	err error
-// }
-
-// func (u *mockPictureUow) Do(ctx context.Context, fn func(rm repo.RepositoryManager) error) error {
-// 	if u.err != nil {
-// 		return u.err
-// 	}
-// 	return fn(u.rm)
-// }
-
-// func TestProfileService_DeletePicture(t *testing.T) {
-// 	userID := uuid.New()
-// 	otherUserID := uuid.New()
-// 	pictureID := int32(1)
-// 	dbErr := errors.New("db error")
-
-// 	testCases := []struct {
-// 		name        string
-// 		setupMocks  func(picQueryRepo *mock.MockPictureQueryRepository, picRepo *mock.MockPictureRepository)
-// 		uowError    error
-// 		pictureID   int32
-// 		userID      uuid.UUID
-// 		expectedErr error
-// 	}{
-// 		{
-// 			name: "Success",
-// 			setupMocks: func(picQueryRepo *mock.MockPictureQueryRepository, picRepo *mock.MockPictureRepository) {
-// 				picQueryRepo.EXPECT().Find(gomock.Any(), pictureID).Return(&entity.Picture{ID: pictureID, UserID: userID}, nil)
-// 				picRepo.EXPECT().Delete(gomock.Any(), pictureID).Return(nil)
-// 			},
-// 			pictureID:   pictureID,
-// 			userID:      userID,
-// 			expectedErr: nil,
-// 		},
-// 		{
-// 			name: "Find fails",
-// 			setupMocks: func(picQueryRepo *mock.MockPictureQueryRepository, picRepo *mock.MockPictureRepository) {
-// 				picQueryRepo.EXPECT().Find(gomock.Any(), pictureID).Return(nil, dbErr)
-// 			},
-// 			pictureID:   pictureID,
-// 			userID:      userID,
-// 			expectedErr: apperrors.ErrInternalServer,
-// 		},
-// 		{
-// 			name: "Picture not found",
-// 			setupMocks: func(picQueryRepo *mock.MockPictureQueryRepository, picRepo *mock.MockPictureRepository) {
-// 				picQueryRepo.EXPECT().Find(gomock.Any(), pictureID).Return(nil, nil)
-// 			},
-// 			pictureID:   pictureID,
-// 			userID:      userID,
-// 			expectedErr: apperrors.ErrNotFound,
-// 		},
-// 		{
-// 			name: "User is not owner",
-// 			setupMocks: func(picQueryRepo *mock.MockPictureQueryRepository, picRepo *mock.MockPictureRepository) {
-// 				picQueryRepo.EXPECT().Find(gomock.Any(), pictureID).Return(&entity.Picture{ID: pictureID, UserID: otherUserID}, nil)
-// 			},
-// 			pictureID:   pictureID,
-// 			userID:      userID,
-// 			expectedErr: apperrors.ErrNotFound,
-// 		},
-// 		{
-// 			name: "Delete fails in UoW",
-// 			setupMocks: func(picQueryRepo *mock.MockPictureQueryRepository, picRepo *mock.MockPictureRepository) {
-// 				picQueryRepo.EXPECT().Find(gomock.Any(), pictureID).Return(&entity.Picture{ID: pictureID, UserID: userID}, nil)
-// 				picRepo.EXPECT().Delete(gomock.Any(), pictureID).Return(dbErr)
-// 			},
-// 			pictureID:   pictureID,
-// 			userID:      userID,
-// 			expectedErr: dbErr,
-// 		},
-// 	}
-
-// 	for _, tc := range testCases {
-// 		t.Run(tc.name, func(t *testing.T) {
-// 			ctrl := gomock.NewController(t)
-// 			defer ctrl.Finish()
-
-// 			picQueryRepo := mock.NewMockPictureQueryRepository(ctrl)
-// 			picRepo := mock.NewMockPictureRepository(ctrl)
-
-// 			if tc.setupMocks != nil {
-// 				tc.setupMocks(picQueryRepo, picRepo)
-// 			}
-
-// 			mockRM := &mockPictureRepositoryManager{pictureRepo: picRepo}
-// 			mockUOW := &mockPictureUow{rm: mockRM, err: tc.uowError}
-
-// 			// Dummy mocks for other dependencies of profileService
-// 			profileRepo := mock.NewMockUserProfileRepository(ctrl)
-// 			viewRepo := mock.NewMockViewQueryRepository(ctrl)
-// 			likeRepo := mock.NewMockLikeQueryRepository(ctrl)
-// 			fileClient := mock.NewMockFileClient(ctrl)
-
-// 			service := NewProfileService(mockUOW, profileRepo, fileClient, picQueryRepo, viewRepo, likeRepo)
-
-// 			err := service.DeletePicture(context.Background(), tc.pictureID, tc.userID)
-// 			assert.Equal(t, tc.expectedErr, err)
-// 		})
-// 	}
-// }
-
-// func TestProfileService_UploadPicture(t *testing.T) {
-// 	userID := uuid.New()
-// 	imageBytes := []byte("test-image")
-// 	imageURL := "http://example.com/image.jpg"
-// 	dbErr := errors.New("db error")
-// 	fileErr := errors.New("file client error")
-
-// 	testCases := []struct {
-// 		name        string
-// 		setupMocks  func(fileClient *mock.MockFileClient, picRepo *mock.MockPictureRepository)
-// 		image       []byte
-// 		expectedPic *entity.Picture
-// 		expectedErr error
-// 	}{
-// 		{
-// 			name: "Success",
-// 			setupMocks: func(fileClient *mock.MockFileClient, picRepo *mock.MockPictureRepository) {
-// 				fileClient.EXPECT().SaveImage(imageBytes, gomock.Any()).Return(imageURL, nil)
-// 				picRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, p *entity.Picture) error {
-// 					// Check if the picture being created has the correct data
-// 					assert.Equal(t, userID, p.UserID)
-// 					assert.Equal(t, imageURL, p.URL)
-// 					assert.False(t, p.IsProfilePic.Bool)
-// 					return nil
-// 				})
-// 			},
-// 			image: imageBytes,
-// 			expectedPic: &entity.Picture{
-// 				UserID:       userID,
-// 				URL:          imageURL,
-// 				IsProfilePic: sql.NullBool{Bool: false, Valid: true},
-// 			},
-// 			expectedErr: nil,
-// 		},
-// 		{
-// 			name:        "Empty Image",
-// 			setupMocks:  nil,
-// 			image:       []byte{},
-// 			expectedPic: nil,
-// 			expectedErr: apperrors.ErrInvalidInput,
-// 		},
-// 		{
-// 			name: "FileClient SaveImage fails",
-// 			setupMocks: func(fileClient *mock.MockFileClient, picRepo *mock.MockPictureRepository) {
-// 				fileClient.EXPECT().SaveImage(imageBytes, gomock.Any()).Return("", fileErr)
-// 			},
-// 			image:       imageBytes,
-// 			expectedPic: nil,
-// 			expectedErr: fileErr,
-// 		},
-// 		{
-// 			name: "DB Create fails",
-// 			setupMocks: func(fileClient *mock.MockFileClient, picRepo *mock.MockPictureRepository) {
-// 				fileClient.EXPECT().SaveImage(imageBytes, gomock.Any()).Return(imageURL, nil)
-// 				picRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(dbErr)
-// 			},
-// 			image:       imageBytes,
-// 			expectedPic: nil,
-// 			expectedErr: dbErr,
-// 		},
-// 	}
-
-// 	for _, tc := range testCases {
-// 		t.Run(tc.name, func(t *testing.T) {
-// 			ctrl := gomock.NewController(t)
-// 			defer ctrl.Finish()
-
-// 			fileClient := mock.NewMockFileClient(ctrl)
-// 			picRepo := mock.NewMockPictureRepository(ctrl)
-
-// 			if tc.setupMocks != nil {
-// 				tc.setupMocks(fileClient, picRepo)
-// 			}
-
-// 			mockRM := &mockPictureRepositoryManager{pictureRepo: picRepo}
-// 			mockUOW := &mockPictureUow{rm: mockRM}
-
-// 			// Dummy mocks for other dependencies
-// 			profileRepo := mock.NewMockUserProfileRepository(ctrl)
-// 			picQueryRepo := mock.NewMockPictureQueryRepository(ctrl)
-// 			viewRepo := mock.NewMockViewQueryRepository(ctrl)
-// 			likeRepo := mock.NewMockLikeQueryRepository(ctrl)
-
-// 			service := NewProfileService(mockUOW, profileRepo, fileClient, picQueryRepo, viewRepo, likeRepo)
-
-// 			pic, err := service.UploadPicture(context.Background(), userID, tc.image)
-
-// 			assert.Equal(t, tc.expectedErr, err)
-// 			// Can't do a simple assert.Equal on the picture object because of the random uuid in the url
-// 			if tc.expectedPic != nil {
-// 				assert.NotNil(t, pic)
-// 				assert.Equal(t, tc.expectedPic.UserID, pic.UserID)
-// 				assert.Equal(t, tc.expectedPic.URL, pic.URL)
-// 				assert.Equal(t, tc.expectedPic.IsProfilePic, pic.IsProfilePic)
-// 			} else {
-// 				assert.Nil(t, pic)
-// 			}
-// 		})
-// 	}
-// }
-
-// func TestProfileService_FindPicture(t *testing.T) {
-// 	pictureID := int32(1)
-// 	expectedPicture := &entity.Picture{ID: pictureID, UserID: uuid.New()}
-// 	dbErr := errors.New("db error")
-
-// 	testCases := []struct {
-// 		name        string
-// 		setupMocks  func(picQueryRepo *mock.MockPictureQueryRepository)
-// 		expected    *entity.Picture
-// 		expectedErr error
-// 	}{
-// 		{
-// 			name: "Success",
-// 			setupMocks: func(picQueryRepo *mock.MockPictureQueryRepository) {
-// 				picQueryRepo.EXPECT().Find(gomock.Any(), pictureID).Return(expectedPicture, nil)
-// 			},
-// 			expected:    expectedPicture,
-// 			expectedErr: nil,
-// 		},
-// 		{
-// 			name: "DB Error",
-// 			setupMocks: func(picQueryRepo *mock.MockPictureQueryRepository) {
-// 				picQueryRepo.EXPECT().Find(gomock.Any(), pictureID).Return(nil, dbErr)
-// 			},
-// 			expected:    nil,
-// 			expectedErr: dbErr,
-// 		},
-// 	}
-
-// 	for _, tc := range testCases {
-// 		t.Run(tc.name, func(t *testing.T) {
-// 			ctrl := gomock.NewController(t)
-// 			defer ctrl.Finish()
-
-// 			picQueryRepo := mock.NewMockPictureQueryRepository(ctrl)
-// 			if tc.setupMocks != nil {
-// 				tc.setupMocks(picQueryRepo)
-// 			}
-
-// 			service := NewProfileService(nil, nil, nil, picQueryRepo, nil, nil)
-// 			pic, err := service.FindPicture(context.Background(), pictureID)
-
-// 			assert.Equal(t, tc.expected, pic)
-// 			assert.Equal(t, tc.expectedErr, err)
-// 		})
-// 	}
-// }
-
-// func TestProfileService_FindPictures(t *testing.T) {
-// 	userID := uuid.New()
-// 	expectedPictures := []*entity.Picture{{ID: 1, UserID: userID}}
-// 	dbErr := errors.New("db error")
-
-// 	testCases := []struct {
-// 		name        string
-// 		setupMocks  func(picQueryRepo *mock.MockPictureQueryRepository)
-// 		expected    []*entity.Picture
-// 		expectedErr error
-// 	}{
-// 		{
-// 			name: "Success",
-// 			setupMocks: func(picQueryRepo *mock.MockPictureQueryRepository) {
-// 				picQueryRepo.EXPECT().Query(gomock.Any(), &repo.PictureQuery{UserID: &userID}).Return(expectedPictures, nil)
-// 			},
-// 			expected:    expectedPictures,
-// 			expectedErr: nil,
-// 		},
-// 		{
-// 			name: "DB Error",
-// 			setupMocks: func(picQueryRepo *mock.MockPictureQueryRepository) {
-// 				picQueryRepo.EXPECT().Query(gomock.Any(), &repo.PictureQuery{UserID: &userID}).Return(nil, dbErr)
-// 			},
-// 			expected:    nil,
-// 			expectedErr: dbErr,
-// 		},
-// 	}
-
-// 	for _, tc := range testCases {
-// 		t.Run(tc.name, func(t *testing.T) {
-// 			ctrl := gomock.NewController(t)
-// 			defer ctrl.Finish()
-
-// 			picQueryRepo := mock.NewMockPictureQueryRepository(ctrl)
-// 			if tc.setupMocks != nil {
-// 				tc.setupMocks(picQueryRepo)
-// 			}
-
-// 			service := NewProfileService(nil, nil, nil, picQueryRepo, nil, nil)
-// 			pics, err := service.FindPictures(context.Background(), userID)
-
-// 			assert.Equal(t, tc.expected, pics)
-// 			assert.Equal(t, tc.expectedErr, err)
-// 		})
-// 	}
-// }
-
-// func TestProfileService_UpdatePictureStatus(t *testing.T) {
-// 	userID := uuid.New()
-// 	picToSetAsProfile := int32(1)
-// 	dbErr := errors.New("db error")
-
-// 	baseUserPictures := []*entity.Picture{
-// 		{ID: 1, UserID: userID, IsProfilePic: sql.NullBool{Bool: false, Valid: true}},
-// 		{ID: 2, UserID: userID, IsProfilePic: sql.NullBool{Bool: true, Valid: true}},
-// 		{ID: 3, UserID: userID, IsProfilePic: sql.NullBool{Bool: false, Valid: true}},
-// 	}
-
-// 	testCases := []struct {
-// 		name        string
-// 		setupMocks  func(picQueryRepo *mock.MockPictureQueryRepository, picRepo *mock.MockPictureRepository, pictures []*entity.Picture)
-// 		pictureID   int32
-// 		isProfile   bool
-// 		expectedErr error
-// 	}{
-// 		{
-// 			name: "Success - Set new profile picture",
-// 			setupMocks: func(picQueryRepo *mock.MockPictureQueryRepository, picRepo *mock.MockPictureRepository, pictures []*entity.Picture) {
-// 				picQueryRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(pictures, nil)
-// 				// Expect pic 1 to be updated to true
-// 				picRepo.EXPECT().Update(gomock.Any(), gomock.Cond(func(p interface{}) bool {
-// 					pic, ok := p.(*entity.Picture)
-// 					if !ok {
-// 						return false
-// 					}
-// 					return pic.ID == 1 && pic.IsProfilePic.Bool == true
-// 				})).Return(nil)
-// 				// Expect pic 2 to be updated to false
-// 				picRepo.EXPECT().Update(gomock.Any(), gomock.Cond(func(p interface{}) bool {
-// 					pic, ok := p.(*entity.Picture)
-// 					if !ok {
-// 						return false
-// 					}
-// 					return pic.ID == 2 && pic.IsProfilePic.Bool == false
-// 				})).Return(nil)
-// 			},
-// 			pictureID:   picToSetAsProfile,
-// 			isProfile:   true,
-// 			expectedErr: nil,
-// 		},
-// 		{
-// 			name: "Query Fails",
-// 			setupMocks: func(picQueryRepo *mock.MockPictureQueryRepository, picRepo *mock.MockPictureRepository, pictures []*entity.Picture) {
-// 				picQueryRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(nil, dbErr)
-// 			},
-// 			pictureID:   picToSetAsProfile,
-// 			isProfile:   true,
-// 			expectedErr: apperrors.ErrInternalServer,
-// 		},
-// 		{
-// 			name: "No pictures found",
-// 			setupMocks: func(picQueryRepo *mock.MockPictureQueryRepository, picRepo *mock.MockPictureRepository, pictures []*entity.Picture) {
-// 				picQueryRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(nil, nil)
-// 			},
-// 			pictureID:   picToSetAsProfile,
-// 			isProfile:   true,
-// 			expectedErr: apperrors.ErrNotFound,
-// 		},
-// 		{
-// 			name: "Update Fails",
-// 			setupMocks: func(picQueryRepo *mock.MockPictureQueryRepository, picRepo *mock.MockPictureRepository, pictures []*entity.Picture) {
-// 				picQueryRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(pictures, nil)
-// 				picRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(dbErr)
-// 			},
-// 			pictureID:   picToSetAsProfile,
-// 			isProfile:   true,
-// 			expectedErr: dbErr,
-// 		},
-// 	}
-
-// 	for _, tc := range testCases {
-// 		t.Run(tc.name, func(t *testing.T) {
-// 			ctrl := gomock.NewController(t)
-// 			defer ctrl.Finish()
-
-// 			// Create a deep copy of the pictures slice for each test run
-// 			userPicturesCopy := make([]*entity.Picture, len(baseUserPictures))
-// 			for i, p := range baseUserPictures {
-// 				picCopy := *p
-// 				userPicturesCopy[i] = &picCopy
-// 			}
-
-// 			picQueryRepo := mock.NewMockPictureQueryRepository(ctrl)
-// 			picRepo := mock.NewMockPictureRepository(ctrl)
-// 			if tc.setupMocks != nil {
-// 				tc.setupMocks(picQueryRepo, picRepo, userPicturesCopy)
-// 			}
-
-// 			mockRM := &mockPictureRepositoryManager{pictureRepo: picRepo}
-// 			mockUOW := &mockPictureUow{rm: mockRM}
-
-// 			service := NewProfileService(mockUOW, nil, nil, picQueryRepo, nil, nil)
-// 			err := service.UpdatePictureStatus(context.Background(), userID, tc.pictureID, tc.isProfile)
-
-// 			assert.Equal(t, tc.expectedErr, err)
-// 		})
-// 	}
-// }
-
-// func TestProfileService_UploadPicutures(t *testing.T) {
-// 	userID := uuid.New()
-// 	images := [][]byte{[]byte("img1"), []byte("img2")}
-// 	urls := []string{"url1", "url2"}
-// 	dbErr := errors.New("db error")
-// 	fileErr := errors.New("file error")
-
-// 	testCases := []struct {
-// 		name          string
-// 		setupMocks    func(fileClient *mock.MockFileClient, picRepo *mock.MockPictureRepository)
-// 		images        [][]byte
-// 		expectedCount int
-// 		expectedErr   error
-// 	}{
-// 		{
-// 			name: "Success",
-// 			setupMocks: func(fileClient *mock.MockFileClient, picRepo *mock.MockPictureRepository) {
-// 				fileClient.EXPECT().SaveImage(images[0], gomock.Any()).Return(urls[0], nil)
-// 				fileClient.EXPECT().SaveImage(images[1], gomock.Any()).Return(urls[1], nil)
-// 				picRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(2).Return(nil)
-// 			},
-// 			images:        images,
-// 			expectedCount: 2,
-// 			expectedErr:   nil,
-// 		},
-// 		{
-// 			name:          "No images",
-// 			images:        [][]byte{},
-// 			expectedCount: 0,
-// 			expectedErr:   apperrors.ErrInvalidInput,
-// 		},
-// 		{
-// 			name: "SaveImage fails",
-// 			setupMocks: func(fileClient *mock.MockFileClient, picRepo *mock.MockPictureRepository) {
-// 				fileClient.EXPECT().SaveImage(images[0], gomock.Any()).Return("", fileErr)
-// 			},
-// 			images:        images,
-// 			expectedCount: 0,
-// 			expectedErr:   fileErr,
-// 		},
-// 		{
-// 			name: "Create fails",
-// 			setupMocks: func(fileClient *mock.MockFileClient, picRepo *mock.MockPictureRepository) {
-// 				fileClient.EXPECT().SaveImage(images[0], gomock.Any()).Return(urls[0], nil)
-// 				fileClient.EXPECT().SaveImage(images[1], gomock.Any()).Return(urls[1], nil)
-// 				picRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(dbErr)
-// 			},
-// 			images:        images,
-// 			expectedCount: 0,
-// 			expectedErr:   dbErr,
-// 		},
-// 	}
-
-// 	for _, tc := range testCases {
-// 		t.Run(tc.name, func(t *testing.T) {
-// 			ctrl := gomock.NewController(t)
-// 			defer ctrl.Finish()
-
-// 			fileClient := mock.NewMockFileClient(ctrl)
-// 			picRepo := mock.NewMockPictureRepository(ctrl)
-
-// 			if tc.setupMocks != nil {
-// 				tc.setupMocks(fileClient, picRepo)
-// 			}
-
-// 			mockRM := &mockPictureRepositoryManager{pictureRepo: picRepo}
-// 			mockUOW := &mockPictureUow{rm: mockRM}
-
-// 			service := NewProfileService(mockUOW, nil, fileClient, nil, nil, nil)
-// 			pics, err := service.UploadPicutures(context.Background(), userID, tc.images)
-
-// 			assert.Equal(t, tc.expectedErr, err)
-// 			assert.Len(t, pics, tc.expectedCount)
-// 		})
-// 	}
-// }
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// mockPictureRepositoryManager is a mock for repo.RepositoryManager.
+type mockPictureRepositoryManager struct {
+	repo.RepositoryManager
+	pictureRepo repo.PictureRepository
+}
+
+func (m *mockPictureRepositoryManager) PictureRepo() repo.PictureRepository {
+	return m.pictureRepo
+}
+
+// mockPictureUow is a mock for repo.UnitOfWork for testing services.
+type mockPictureUow struct {
+	rm repo.RepositoryManager
+}
+
+func (u *mockPictureUow) Do(ctx context.Context, fn func(rm repo.RepositoryManager) error) error {
+	return fn(u.rm)
+}
+
+func newPictures(userID uuid.UUID, n int, profileIndex int) []*entity.Picture {
+	pictures := make([]*entity.Picture, 0, n)
+	for i := 0; i < n; i++ {
+		pictures = append(pictures, &entity.Picture{
+			ID:           int32(i + 1),
+			UserID:       userID,
+			IsProfilePic: sql.NullBool{Bool: i == profileIndex, Valid: true},
+			Position:     i,
+		})
+	}
+	return pictures
+}
+
+// expectSignImageURL は URL の後ろに閲覧者を付けた「署名付き」URL を返させる
+func expectSignImageURL(fileClient *mock.MockFileClient, viewerID uuid.UUID) {
+	fileClient.EXPECT().SignImageURL(gomock.Any(), viewerID, gomock.Any()).DoAndReturn(func(rawURL string, viewerID uuid.UUID, expiresAt time.Time) string {
+		return rawURL + "?v=" + viewerID.String()
+	}).AnyTimes()
+}
+
+func imageSource(data string) *client.FileSource {
+	return &client.FileSource{Reader: strings.NewReader(data), Filename: "photo.jpg"}
+}
+
+func TestProfileService_UploadPicutures(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("First picture becomes the profile picture", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureRepo := mock.NewMockPictureRepository(ctrl)
+		fileClient := mock.NewMockFileClient(ctrl)
+		uow := &mockPictureUow{rm: &mockPictureRepositoryManager{pictureRepo: pictureRepo}}
+		s := NewProfileService(uow, nil, fileClient, pictureRepo, nil, nil, nil, nil, nil, nil)
+
+		pictureRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+		fileClient.EXPECT().SaveImage(userID, gomock.Any()).Return(&client.Image{AssetID: "a", ThumbnailURL: "http://files/a/thumbnail.jpg", CardURL: "http://files/a/card.jpg", FullURL: "http://files/a/full.jpg"}, nil)
+		fileClient.EXPECT().SaveImage(userID, gomock.Any()).Return(&client.Image{AssetID: "b", FullURL: "http://files/b/full.jpg"}, nil)
+		pictureRepo.EXPECT().LockByUser(gomock.Any(), userID).Return(nil)
+		pictureRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		expectSignImageURL(fileClient, userID)
+
+		pictures, err := s.UploadPicutures(context.Background(), userID, []*client.FileSource{imageSource("a"), imageSource("b")})
+
+		assert.NoError(t, err)
+		assert.Len(t, pictures, 2)
+		assert.True(t, pictures[0].IsProfilePic.Bool)
+		assert.False(t, pictures[1].IsProfilePic.Bool)
+		assert.Equal(t, 0, pictures[0].Position)
+		assert.Equal(t, 1, pictures[1].Position)
+		assert.Equal(t, "a", pictures[0].AssetID)
+		assert.Equal(t, "http://files/a/full.jpg?v="+userID.String(), pictures[0].URL)
+		assert.Equal(t, "http://files/a/thumbnail.jpg?v="+userID.String(), pictures[0].ThumbnailURL)
+		assert.Equal(t, "http://files/a/card.jpg?v="+userID.String(), pictures[0].CardURL)
+		// 変種がない写真の URL は空のまま
+		assert.Equal(t, "", pictures[1].ThumbnailURL)
+	})
+
+	t.Run("Appended after existing pictures", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureRepo := mock.NewMockPictureRepository(ctrl)
+		fileClient := mock.NewMockFileClient(ctrl)
+		uow := &mockPictureUow{rm: &mockPictureRepositoryManager{pictureRepo: pictureRepo}}
+		s := NewProfileService(uow, nil, fileClient, pictureRepo, nil, nil, nil, nil, nil, nil)
+
+		pictureRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(newPictures(userID, 3, 0), nil).Times(2)
+		fileClient.EXPECT().SaveImage(userID, gomock.Any()).Return(&client.Image{AssetID: "a", ThumbnailURL: "http://files/a/thumbnail.jpg", CardURL: "http://files/a/card.jpg", FullURL: "http://files/a/full.jpg"}, nil)
+		pictureRepo.EXPECT().LockByUser(gomock.Any(), userID).Return(nil)
+		pictureRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+		expectSignImageURL(fileClient, userID)
+
+		picture, err := s.UploadPicture(context.Background(), userID, imageSource("a"))
+
+		assert.NoError(t, err)
+		assert.False(t, picture.IsProfilePic.Bool)
+		assert.Equal(t, 3, picture.Position)
+	})
+
+	t.Run("Limit reached before upload", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureRepo := mock.NewMockPictureRepository(ctrl)
+		s := NewProfileService(&mockPictureUow{}, nil, nil, pictureRepo, nil, nil, nil, nil, nil, nil)
+
+		pictureRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(newPictures(userID, 4, 0), nil)
+
+		_, err := s.UploadPicutures(context.Background(), userID, []*client.FileSource{imageSource("a"), imageSource("b")})
+
+		assert.ErrorIs(t, err, apperrors.ErrConflict)
+	})
+
+	t.Run("Limit reached by a concurrent upload", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureRepo := mock.NewMockPictureRepository(ctrl)
+		fileClient := mock.NewMockFileClient(ctrl)
+		uow := &mockPictureUow{rm: &mockPictureRepositoryManager{pictureRepo: pictureRepo}}
+		s := NewProfileService(uow, nil, fileClient, pictureRepo, nil, nil, nil, nil, nil, nil)
+
+		pictureRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(newPictures(userID, 4, 0), nil)
+		fileClient.EXPECT().SaveImage(userID, gomock.Any()).Return(&client.Image{AssetID: "a", ThumbnailURL: "http://files/a/thumbnail.jpg", CardURL: "http://files/a/card.jpg", FullURL: "http://files/a/full.jpg"}, nil)
+		pictureRepo.EXPECT().LockByUser(gomock.Any(), userID).Return(nil)
+		pictureRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(newPictures(userID, 5, 0), nil)
+		// 保存できなかった画像の参照を外す
+		fileClient.EXPECT().DeleteImage("a").Return(nil)
+
+		_, err := s.UploadPicture(context.Background(), userID, imageSource("a"))
+
+		assert.ErrorIs(t, err, apperrors.ErrConflict)
+	})
+
+	t.Run("Upload quota exceeded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureRepo := mock.NewMockPictureRepository(ctrl)
+		fileClient := mock.NewMockFileClient(ctrl)
+		s := NewProfileService(&mockPictureUow{}, nil, fileClient, pictureRepo, nil, nil, nil, nil, nil, nil)
+
+		pictureRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(nil, nil)
+		fileClient.EXPECT().SaveImage(userID, gomock.Any()).Return(&client.Image{AssetID: "a", FullURL: "http://files/a/full.jpg"}, nil)
+		fileClient.EXPECT().SaveImage(userID, gomock.Any()).Return(nil, fmt.Errorf("%w: quota_exceeded", client.ErrFileQuotaExceeded))
+		fileClient.EXPECT().DeleteImage("a").Return(nil)
+
+		_, err := s.UploadPicutures(context.Background(), userID, []*client.FileSource{imageSource("a"), imageSource("b")})
+
+		assert.ErrorIs(t, err, apperrors.ErrTooManyRequests)
+	})
+
+	t.Run("Rejected by the file service", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureRepo := mock.NewMockPictureRepository(ctrl)
+		fileClient := mock.NewMockFileClient(ctrl)
+		s := NewProfileService(&mockPictureUow{}, nil, fileClient, pictureRepo, nil, nil, nil, nil, nil, nil)
+
+		pictureRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(nil, nil)
+		fileClient.EXPECT().SaveImage(userID, gomock.Any()).Return(nil, fmt.Errorf("%w: too_many_pixels", client.ErrFileRejected))
+
+		_, err := s.UploadPicture(context.Background(), userID, imageSource("a"))
+
+		assert.ErrorIs(t, err, apperrors.ErrInvalidInput)
+	})
+
+	t.Run("Empty image", func(t *testing.T) {
+		s := NewProfileService(&mockPictureUow{}, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+		_, err := s.UploadPicture(context.Background(), userID, nil)
+		assert.ErrorIs(t, err, apperrors.ErrInvalidInput)
+
+		_, err = s.UploadPicture(context.Background(), userID, &client.FileSource{Filename: "photo.jpg"})
+		assert.ErrorIs(t, err, apperrors.ErrInvalidInput)
+	})
+
+	t.Run("Resumable upload is passed through without the original filename", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureRepo := mock.NewMockPictureRepository(ctrl)
+		fileClient := mock.NewMockFileClient(ctrl)
+		uow := &mockPictureUow{rm: &mockPictureRepositoryManager{pictureRepo: pictureRepo}}
+		s := NewProfileService(uow, nil, fileClient, pictureRepo, nil, nil, nil, nil, nil, nil)
+
+		pictureRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+		fileClient.EXPECT().SaveImage(userID, gomock.Any()).DoAndReturn(func(userID uuid.UUID, src *client.FileSource) (*client.Image, error) {
+			assert.Equal(t, "u1", src.UploadID)
+			assert.NotEqual(t, "photo.jpg", src.Filename)
+			return &client.Image{AssetID: "a", FullURL: "http://files/a/full.jpg"}, nil
+		})
+		pictureRepo.EXPECT().LockByUser(gomock.Any(), userID).Return(nil)
+		pictureRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+		expectSignImageURL(fileClient, userID)
+
+		_, err := s.UploadPicture(context.Background(), userID, &client.FileSource{UploadID: "u1", Filename: "photo.jpg"})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unfinished resumable upload", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureRepo := mock.NewMockPictureRepository(ctrl)
+		fileClient := mock.NewMockFileClient(ctrl)
+		s := NewProfileService(&mockPictureUow{}, nil, fileClient, pictureRepo, nil, nil, nil, nil, nil, nil)
+
+		pictureRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(nil, nil)
+		fileClient.EXPECT().SaveImage(userID, gomock.Any()).Return(nil, fmt.Errorf("%w: upload is not complete", client.ErrUploadIncomplete))
+
+		_, err := s.UploadPicture(context.Background(), userID, &client.FileSource{UploadID: "u1"})
+
+		assert.ErrorIs(t, err, apperrors.ErrConflict)
+	})
+}
+
+func TestProfileService_DeletePicture(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("Deleting the profile picture promotes the first remaining one", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureRepo := mock.NewMockPictureRepository(ctrl)
+		fileClient := mock.NewMockFileClient(ctrl)
+		uow := &mockPictureUow{rm: &mockPictureRepositoryManager{pictureRepo: pictureRepo}}
+		s := NewProfileService(uow, nil, fileClient, pictureRepo, nil, nil, nil, nil, nil, nil)
+
+		pictures := newPictures(userID, 3, 0)
+		pictures[0].AssetID = "asset1"
+		pictureRepo.EXPECT().LockByUser(gomock.Any(), userID).Return(nil)
+		pictureRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(pictures, nil)
+		pictureRepo.EXPECT().Delete(gomock.Any(), int32(1)).Return(nil)
+		fileClient.EXPECT().DeleteImage("asset1").Return(nil)
+		var updated []*entity.Picture
+		pictureRepo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, pic *entity.Picture) error {
+			updated = append(updated, pic)
+			return nil
+		}).Times(2)
+
+		err := s.DeletePicture(context.Background(), 1, userID)
+
+		assert.NoError(t, err)
+		// プロフィール写真は最後に更新される
+		assert.Equal(t, int32(3), updated[0].ID)
+		assert.Equal(t, 1, updated[0].Position)
+		assert.Equal(t, int32(2), updated[1].ID)
+		assert.Equal(t, 0, updated[1].Position)
+		assert.True(t, updated[1].IsProfilePic.Bool)
+	})
+
+	t.Run("Someone else's picture is not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureRepo := mock.NewMockPictureRepository(ctrl)
+		uow := &mockPictureUow{rm: &mockPictureRepositoryManager{pictureRepo: pictureRepo}}
+		s := NewProfileService(uow, nil, nil, pictureRepo, nil, nil, nil, nil, nil, nil)
+
+		pictureRepo.EXPECT().LockByUser(gomock.Any(), userID).Return(nil)
+		pictureRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(newPictures(userID, 2, 0), nil)
+
+		err := s.DeletePicture(context.Background(), 9, userID)
+
+		assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	})
+}
+
+func TestProfileService_SetProfilePicture(t *testing.T) {
+	userID := uuid.New()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pictureRepo := mock.NewMockPictureRepository(ctrl)
+	fileClient := mock.NewMockFileClient(ctrl)
+	uow := &mockPictureUow{rm: &mockPictureRepositoryManager{pictureRepo: pictureRepo}}
+	s := NewProfileService(uow, nil, fileClient, pictureRepo, nil, nil, nil, nil, nil, nil)
+	expectSignImageURL(fileClient, userID)
+
+	pictureRepo.EXPECT().LockByUser(gomock.Any(), userID).Return(nil)
+	pictureRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(newPictures(userID, 3, 0), nil)
+	var updated []*entity.Picture
+	pictureRepo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, pic *entity.Picture) error {
+		updated = append(updated, pic)
+		return nil
+	}).Times(3)
+
+	pictures, err := s.SetProfilePicture(context.Background(), userID, 2)
+
+	assert.NoError(t, err)
+	assert.False(t, pictures[0].IsProfilePic.Bool)
+	assert.True(t, pictures[1].IsProfilePic.Bool)
+	// 古いプロフィール写真を外してから新しい写真を設定する
+	assert.Equal(t, int32(2), updated[2].ID)
+}
+
+func TestProfileService_ReorderPictures(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pictureRepo := mock.NewMockPictureRepository(ctrl)
+		fileClient := mock.NewMockFileClient(ctrl)
+		uow := &mockPictureUow{rm: &mockPictureRepositoryManager{pictureRepo: pictureRepo}}
+		s := NewProfileService(uow, nil, fileClient, pictureRepo, nil, nil, nil, nil, nil, nil)
+
+		pictureRepo.EXPECT().LockByUser(gomock.Any(), userID).Return(nil)
+		pictureRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(newPictures(userID, 3, 0), nil)
+		pictureRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+		expectSignImageURL(fileClient, userID)
+
+		pictures, err := s.ReorderPictures(context.Background(), userID, []int32{3, 1, 2})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int32{3, 1, 2}, []int32{pictures[0].ID, pictures[1].ID, pictures[2].ID})
+		assert.Equal(t, []int{0, 1, 2}, []int{pictures[0].Position, pictures[1].Position, pictures[2].Position})
+	})
+
+	testCases := []struct {
+		name       string
+		pictureIDs []int32
+	}{
+		{name: "Missing picture", pictureIDs: []int32{3, 1}},
+		{name: "Duplicate picture", pictureIDs: []int32{3, 1, 1}},
+		{name: "Unknown picture", pictureIDs: []int32{3, 1, 9}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			pictureRepo := mock.NewMockPictureRepository(ctrl)
+			uow := &mockPictureUow{rm: &mockPictureRepositoryManager{pictureRepo: pictureRepo}}
+			s := NewProfileService(uow, nil, nil, pictureRepo, nil, nil, nil, nil, nil, nil)
+
+			pictureRepo.EXPECT().LockByUser(gomock.Any(), userID).Return(nil)
+			pictureRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return(newPictures(userID, 3, 0), nil)
+
+			_, err := s.ReorderPictures(context.Background(), userID, tc.pictureIDs)
+
+			assert.ErrorIs(t, err, apperrors.ErrInvalidInput)
+		})
+	}
+}
+
+func TestProfileService_FindPictures(t *testing.T) {
+	ownerID := uuid.New()
+	viewerID := uuid.New()
+
+	testCases := []struct {
+		name        string
+		viewerID    uuid.UUID
+		blocks      map[[2]uuid.UUID]bool
+		expectedErr error
+	}{
+		{name: "Owner", viewerID: ownerID},
+		{name: "Other user", viewerID: viewerID},
+		{name: "Blocked by the owner", viewerID: viewerID, blocks: map[[2]uuid.UUID]bool{{ownerID, viewerID}: true}, expectedErr: apperrors.ErrNotFound},
+		{name: "Viewer blocked the owner", viewerID: viewerID, blocks: map[[2]uuid.UUID]bool{{viewerID, ownerID}: true}, expectedErr: apperrors.ErrNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			pictureRepo := mock.NewMockPictureRepository(ctrl)
+			blockRepo := mock.NewMockBlockQueryRepository(ctrl)
+			fileClient := mock.NewMockFileClient(ctrl)
+			s := NewProfileService(&mockPictureUow{}, nil, fileClient, pictureRepo, nil, nil, nil, nil, nil, blockRepo)
+
+			blockRepo.EXPECT().Find(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, blockerID, blockedID uuid.UUID) (*entity.Block, error) {
+				if tc.blocks[[2]uuid.UUID{blockerID, blockedID}] {
+					return &entity.Block{BlockerID: blockerID, BlockedID: blockedID}, nil
+				}
+				return nil, nil
+			}).AnyTimes()
+			if tc.expectedErr == nil {
+				pictures := newPictures(ownerID, 1, 0)
+				pictures[0].URL = "http://files/a/full.jpg"
+				pictureRepo.EXPECT().Query(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, q *repo.PictureQuery) ([]*entity.Picture, error) {
+					// 本人以外には承認された写真だけを見せる
+					if tc.viewerID == ownerID {
+						assert.Nil(t, q.Status)
+					} else {
+						assert.Equal(t, entity.PictureApproved, *q.Status)
+					}
+					return pictures, nil
+				})
+				expectSignImageURL(fileClient, tc.viewerID)
+			}
+
+			pictures, err := s.FindPictures(context.Background(), tc.viewerID, ownerID)
+
+			assert.Equal(t, tc.expectedErr, err)
+			if tc.expectedErr == nil {
+				// 閲覧者ごとに署名する
+				assert.Equal(t, "http://files/a/full.jpg?v="+tc.viewerID.String(), pictures[0].URL)
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/icchon/matcha/api/internal/domain/entity"
 	"github.com/icchon/matcha/api/internal/domain/repo"
 	"github.com/icchon/matcha/api/internal/domain/service"
+	"github.com/icchon/matcha/api/internal/i18n"
 )
 
 type profileService struct {
@@ -23,15 +24,19 @@ type profileService struct {
 	fileClient   client.FileClient
 	userTagRepo  repo.UserTagRepository
 	userDataRepo repo.UserDataRepository
+	blockRepo    repo.BlockQueryRepository
 }
 
 var _ service.ProfileService = (*profileService)(nil)
 
-func NewProfileService(uow repo.UnitOfWork, profileRepo repo.UserProfileRepository, fileClient client.FileClient, pictureRepo repo.PictureQueryRepository, viewRepo repo.ViewQueryRepository, likeRepo repo.LikeQueryRepository, notifSvc service.NotificationService, userTagRepo repo.UserTagRepository, userDataRepo repo.UserDataRepository) *profileService {
-	return &profileService{uow: uow, profileRepo: profileRepo, fileClient: fileClient, pictureRepo: pictureRepo, viewRepo: viewRepo, likeRepo: likeRepo, notifSvc: notifSvc, userTagRepo: userTagRepo, userDataRepo: userDataRepo}
+func NewProfileService(uow repo.UnitOfWork, profileRepo repo.UserProfileRepository, fileClient client.FileClient, pictureRepo repo.PictureQueryRepository, viewRepo repo.ViewQueryRepository, likeRepo repo.LikeQueryRepository, notifSvc service.NotificationService, userTagRepo repo.UserTagRepository, userDataRepo repo.UserDataRepository, blockRepo repo.BlockQueryRepository) *profileService {
+	return &profileService{uow: uow, profileRepo: profileRepo, fileClient: fileClient, pictureRepo: pictureRepo, viewRepo: viewRepo, likeRepo: likeRepo, notifSvc: notifSvc, userTagRepo: userTagRepo, userDataRepo: userDataRepo, blockRepo: blockRepo}
 }
 
 func (s *profileService) CreateProfile(ctx context.Context, profile *entity.UserProfile) (*entity.UserProfile, error) {
+	if !normalizeLocale(&profile.Locale) {
+		return nil, apperrors.ErrInvalidInput
+	}
 	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
 		return rm.ProfileRepo().Create(ctx, profile)
 	}); err != nil {
@@ -77,6 +82,12 @@ func (s *profileService) UpdateProfile(ctx context.Context, userID uuid.UUID, pr
 		if profile.LocationName.Valid {
 			target.LocationName = profile.LocationName
 		}
+		if profile.Locale.Valid {
+			if !normalizeLocale(&profile.Locale) {
+				return nil, apperrors.ErrInvalidInput
+			}
+			target.Locale = profile.Locale
+		}
 	}
 
 	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
@@ -87,6 +98,23 @@ func (s *profileService) UpdateProfile(ctx context.Context, userID uuid.UUID, pr
 	return target, nil
 }
 
+// normalizeLocale は "en-US" などを対応している言語にそろえる。空文字は未設定に戻す
+func normalizeLocale(locale *sql.NullString) bool {
+	if !locale.Valid {
+		return true
+	}
+	if locale.String == "" {
+		*locale = sql.NullString{}
+		return true
+	}
+	l, ok := i18n.Parse(locale.String)
+	if !ok {
+		return false
+	}
+	locale.String = string(l)
+	return true
+}
+
 func (s *profileService) FindProfile(ctx context.Context, userID uuid.UUID) (*entity.UserProfile, error) {
 	profile, err := s.profileRepo.Find(ctx, userID)
 	if err != nil {
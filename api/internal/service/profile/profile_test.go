@@ -22,7 +22,7 @@ func TestProfileService_RecommendProfiles(t *testing.T) {
 	userTagRepo := mock.NewMockUserTagRepository(ctrl)
 	// other repos and services can be mocked as needed
 
-	profileSvc := NewProfileService(nil, profileRepo, nil, nil, nil, nil, nil, userTagRepo, userDataRepo)
+	profileSvc := NewProfileService(nil, profileRepo, nil, nil, nil, nil, nil, userTagRepo, userDataRepo, nil)
 
 	selfUserID := uuid.New()
 	candidateUserID1 := uuid.New()
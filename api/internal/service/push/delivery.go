@@ -0,0 +1,167 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
+)
+
+const (
+	// 端末がオフラインの間、プッシュサービスに保持してもらう時間
+	pushTTL = 24 * time.Hour
+	// ペイロードの上限 (約 4KB) に収めるため、チャットの本文はここで切る
+	maxPushContentLen = 200
+	// 全レプリカに同じイベントが届くので、最初に確保したレプリカだけが送る。確保はこの時間だけ残す
+	deliveryClaimTTL = 10 * time.Minute
+
+	notificationEvent = "notification_event"
+	chatEvent         = "chat_event"
+)
+
+// pushEnvelope は WebSocket のイベントと同じ形。Service Worker で同じ処理を使える
+type pushEnvelope struct {
+	Type    string      `json:"type"`
+	Payload interface{} `json:"payload"`
+}
+
+// sendJob は 1 つの端末への送信
+type sendJob struct {
+	userID uuid.UUID
+	event  string
+	sub    *entity.PushSubscription
+	msg    *client.PushMessage
+}
+
+// SubscribeDelivery は notification_channel と chat_outgoing を購読し、接続していない宛先に Web Push を送る
+func SubscribeDelivery(ctx context.Context, delivery service.PushDeliveryService, notificationSub client.Subscriber, chatSub client.Subscriber) error {
+	if err := notificationSub.SubscribeChannel(ctx, func(ctx context.Context, data interface{}) error {
+		return delivery.PushNotification(ctx, data.(*client.NotificationPayload))
+	}); err != nil {
+		return err
+	}
+	return chatSub.SubscribeChannel(ctx, func(ctx context.Context, data interface{}) error {
+		return delivery.PushChatMessage(ctx, data.(*client.MessagePayload))
+	})
+}
+
+func (s *pushService) PushNotification(ctx context.Context, payload *client.NotificationPayload) error {
+	// メッセージは chat_outgoing の方で本文付きで届けるので、通知では送らない
+	if payload.Type == string(entity.NotifMessage) {
+		return nil
+	}
+	// 集約された通知は updated_at を更新して配信し直されるので、その度に送る
+	key := fmt.Sprintf("%s:%d:%d", notificationEvent, payload.ID, payload.UpdatedAt.UnixMicro())
+	return s.pushToOfflineUser(ctx, key, payload.RecipientID, notificationEvent, payload, client.PushUrgencyNormal)
+}
+
+func (s *pushService) PushChatMessage(ctx context.Context, payload *client.MessagePayload) error {
+	// 添付ファイルの署名付き URL は大きく期限もあるので、本文の抜粋だけを送る
+	preview := &client.MessagePayload{
+		ID:          payload.ID,
+		SenderID:    payload.SenderID,
+		RecipientID: payload.RecipientID,
+		Content:     truncate(payload.Content, maxPushContentLen),
+		SentAt:      payload.SentAt,
+	}
+	for _, attachment := range payload.Attachments {
+		preview.AttachmentIDs = append(preview.AttachmentIDs, attachment.ID)
+	}
+	key := fmt.Sprintf("%s:%d", chatEvent, payload.ID)
+	return s.pushToOfflineUser(ctx, key, payload.RecipientID, chatEvent, preview, client.PushUrgencyHigh)
+}
+
+// pushToOfflineUser はどのゲートウェイにも接続していないユーザーの全端末への送信を積む
+// 送信は RunSenders のワーカーが行う。遅いプッシュサービスで購読のループを止めない
+// key は配信するイベントごとに一意にする。他のレプリカが確保済みなら何もしない
+func (s *pushService) pushToOfflineUser(ctx context.Context, key string, userID uuid.UUID, event string, payload interface{}, urgency client.PushUrgency) error {
+	claimed, err := s.deliveryRepo.Claim(ctx, key, deliveryClaimTTL)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+	online, err := s.presenceRepo.IsOnline(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if online {
+		return nil
+	}
+	subs, err := s.subscriptionRepo.Query(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(pushEnvelope{Type: event, Payload: payload})
+	if err != nil {
+		return err
+	}
+	msg := &client.PushMessage{Payload: data, TTL: pushTTL, Urgency: urgency}
+	for _, sub := range subs {
+		select {
+		case s.jobs <- &sendJob{userID: userID, event: event, sub: sub, msg: msg}:
+		default:
+			log.Printf("Push queue is full, dropping %s to user %s (subscription %d).", event, userID, sub.ID)
+		}
+	}
+	return nil
+}
+
+// RunSenders は ctx が終わるまで workers 個のワーカーで積まれた送信を行う
+func (s *pushService) RunSenders(ctx context.Context, workers int) {
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-s.jobs:
+					s.send(ctx, job)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// send は失効した購読を削除し、それ以外の送信エラーはログに残す
+func (s *pushService) send(ctx context.Context, job *sendJob) {
+	err := s.pushClient.Send(ctx, job.sub, job.msg)
+	if errors.Is(err, client.ErrPushSubscriptionGone) {
+		log.Printf("Push subscription %d of user %s is gone, deleting it.", job.sub.ID, job.userID)
+		if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+			return rm.PushSubscriptionRepo().Delete(ctx, job.sub.Endpoint)
+		}); err != nil {
+			log.Printf("Failed to delete push subscription %d: %v", job.sub.ID, err)
+		}
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to push %s to user %s (subscription %d): %v", job.event, job.userID, job.sub.ID, err)
+	}
+}
+
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max]) + "…"
+}
@@ -0,0 +1,127 @@
+package push
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
+)
+
+const (
+	// 非圧縮の P-256 公開鍵と認証シークレットの長さ
+	p256dhLen     = 65
+	authSecretLen = 16
+
+	// 送信待ちの上限。あふれた分は捨てる
+	sendQueueSize = 1024
+)
+
+var _ service.PushService = (*pushService)(nil)
+var _ service.PushDeliveryService = (*pushService)(nil)
+
+type pushService struct {
+	uow              repo.UnitOfWork
+	subscriptionRepo repo.PushSubscriptionQueryRepository
+	presenceRepo     repo.PresenceQueryRepository
+	deliveryRepo     repo.PushDeliveryRepository
+	pushClient       client.PushClient
+	// 開発用。プライベート・ループバックアドレスと http の endpoint を受け付ける
+	allowPrivateEndpoints bool
+	jobs                  chan *sendJob
+}
+
+// NewPushService の送信は RunSenders を起動するまで溜まるだけ
+func NewPushService(uow repo.UnitOfWork, subscriptionRepo repo.PushSubscriptionQueryRepository, presenceRepo repo.PresenceQueryRepository, deliveryRepo repo.PushDeliveryRepository, pushClient client.PushClient, allowPrivateEndpoints bool) *pushService {
+	return &pushService{
+		uow:                   uow,
+		subscriptionRepo:      subscriptionRepo,
+		presenceRepo:          presenceRepo,
+		deliveryRepo:          deliveryRepo,
+		pushClient:            pushClient,
+		allowPrivateEndpoints: allowPrivateEndpoints,
+		jobs:                  make(chan *sendJob, sendQueueSize),
+	}
+}
+
+func (s *pushService) GetPublicKey(ctx context.Context) (string, error) {
+	publicKey := s.pushClient.PublicKey()
+	if publicKey == "" {
+		return "", apperrors.ErrNotFound
+	}
+	return publicKey, nil
+}
+
+func (s *pushService) Subscribe(ctx context.Context, userID uuid.UUID, params *service.PushSubscriptionParams) (*entity.PushSubscription, error) {
+	if !validEndpoint(params.Endpoint, s.allowPrivateEndpoints) ||
+		!validKey(params.Keys.P256dh, p256dhLen) ||
+		!validKey(params.Keys.Auth, authSecretLen) {
+		return nil, apperrors.ErrInvalidInput
+	}
+	sub := &entity.PushSubscription{
+		UserID:    userID,
+		Endpoint:  params.Endpoint,
+		P256dh:    params.Keys.P256dh,
+		Auth:      params.Keys.Auth,
+		UserAgent: sql.NullString{String: params.UserAgent, Valid: params.UserAgent != ""},
+	}
+	if err := s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		return rm.PushSubscriptionRepo().Upsert(ctx, sub)
+	}); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *pushService) Unsubscribe(ctx context.Context, userID uuid.UUID, endpoint string) error {
+	subs, err := s.subscriptionRepo.Query(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		if sub.Endpoint != endpoint {
+			continue
+		}
+		return s.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+			return rm.PushSubscriptionRepo().Delete(ctx, endpoint)
+		})
+	}
+	return apperrors.ErrNotFound
+}
+
+// validEndpoint はプッシュサービスの URL として公開アドレスの https だけを受け付ける
+// endpoint はサーバーから POST するので、内部のアドレスを指させない。名前解決後の宛先は送信時に確かめる
+// allowPrivate なら、ローカルの偽のプッシュサービスで試せるよう http と内部のアドレスも許す
+func validEndpoint(endpoint string, allowPrivate bool) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if allowPrivate {
+		return u.Scheme == "https" || u.Scheme == "http"
+	}
+	if u.Scheme != "https" {
+		return false
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return client.IsPublicIP(ip)
+	}
+	return true
+}
+
+func validKey(key string, size int) bool {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(key, "="))
+	return err == nil && len(data) == size
+}
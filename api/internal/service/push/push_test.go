@@ -0,0 +1,349 @@
+package push
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
+	"github.com/icchon/matcha/api/internal/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// mockRepositoryManager is a mock for repo.RepositoryManager.
+type mockRepositoryManager struct {
+	repo.RepositoryManager
+	subscriptionRepo repo.PushSubscriptionRepository
+}
+
+func (m *mockRepositoryManager) PushSubscriptionRepo() repo.PushSubscriptionRepository {
+	return m.subscriptionRepo
+}
+
+// mockUow is a mock for repo.UnitOfWork for testing services.
+type mockUow struct {
+	rm repo.RepositoryManager
+}
+
+func (u *mockUow) Do(ctx context.Context, fn func(rm repo.RepositoryManager) error) error {
+	return fn(u.rm)
+}
+
+func subscriptionParams(endpoint string) *service.PushSubscriptionParams {
+	params := &service.PushSubscriptionParams{Endpoint: endpoint}
+	params.Keys.P256dh = base64.RawURLEncoding.EncodeToString(append([]byte{0x04}, make([]byte, 64)...))
+	params.Keys.Auth = base64.RawURLEncoding.EncodeToString(make([]byte, 16))
+	return params
+}
+
+func TestPushService_Subscribe(t *testing.T) {
+	userID := uuid.New()
+
+	testCases := []struct {
+		name         string
+		params       *service.PushSubscriptionParams
+		allowPrivate bool
+		expectError  error
+	}{
+		{name: "Push service endpoint", params: subscriptionParams("https://fcm.googleapis.com/fcm/send/abc")},
+		{name: "Local fake push service in development", params: subscriptionParams("http://127.0.0.1:9000/push/abc"), allowPrivate: true},
+		{name: "Local fake push service is rejected", params: subscriptionParams("http://127.0.0.1:9000/push/abc"), expectError: apperrors.ErrInvalidInput},
+		{name: "Plain http is rejected", params: subscriptionParams("http://push.example.com/abc"), expectError: apperrors.ErrInvalidInput},
+		{name: "Loopback is rejected", params: subscriptionParams("https://localhost/push/abc"), expectError: apperrors.ErrInvalidInput},
+		{name: "Private address is rejected", params: subscriptionParams("https://10.0.0.5/push/abc"), expectError: apperrors.ErrInvalidInput},
+		{name: "Link-local address is rejected", params: subscriptionParams("https://169.254.169.254/latest/meta-data"), expectError: apperrors.ErrInvalidInput},
+		{name: "IPv6 loopback is rejected", params: subscriptionParams("https://[::1]/push/abc"), expectError: apperrors.ErrInvalidInput},
+		{name: "Relative endpoint is rejected", params: subscriptionParams("/push/abc"), expectError: apperrors.ErrInvalidInput},
+		{name: "Broken key is rejected", params: func() *service.PushSubscriptionParams {
+			params := subscriptionParams("https://push.example.com/abc")
+			params.Keys.P256dh = "short"
+			return params
+		}(), expectError: apperrors.ErrInvalidInput},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			subscriptionRepo := mock.NewMockPushSubscriptionRepository(ctrl)
+			s := NewPushService(&mockUow{rm: &mockRepositoryManager{subscriptionRepo: subscriptionRepo}}, subscriptionRepo, nil, nil, nil, tc.allowPrivate)
+			if tc.expectError == nil {
+				subscriptionRepo.EXPECT().Upsert(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, sub *entity.PushSubscription) error {
+					assert.Equal(t, userID, sub.UserID)
+					assert.Equal(t, tc.params.Endpoint, sub.Endpoint)
+					return nil
+				})
+			}
+
+			_, err := s.Subscribe(context.Background(), userID, tc.params)
+
+			assert.ErrorIs(t, err, tc.expectError)
+		})
+	}
+}
+
+func TestPushService_Unsubscribe(t *testing.T) {
+	userID := uuid.New()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	subscriptionRepo := mock.NewMockPushSubscriptionRepository(ctrl)
+	s := NewPushService(&mockUow{rm: &mockRepositoryManager{subscriptionRepo: subscriptionRepo}}, subscriptionRepo, nil, nil, nil, false)
+
+	subscriptionRepo.EXPECT().Query(gomock.Any(), userID).Return([]*entity.PushSubscription{{ID: 1, UserID: userID, Endpoint: "https://push.example.com/a"}}, nil).Times(2)
+	subscriptionRepo.EXPECT().Delete(gomock.Any(), "https://push.example.com/a").Return(nil)
+
+	assert.NoError(t, s.Unsubscribe(context.Background(), userID, "https://push.example.com/a"))
+	// 他人の端末や登録していない endpoint は消せない
+	assert.ErrorIs(t, s.Unsubscribe(context.Background(), userID, "https://push.example.com/b"), apperrors.ErrNotFound)
+}
+
+// drain は積まれた送信をその場で行う
+func drain(s *pushService) {
+	for len(s.jobs) > 0 {
+		s.send(context.Background(), <-s.jobs)
+	}
+}
+
+func TestPushService_PushChatMessage(t *testing.T) {
+	senderID := uuid.New()
+	recipientID := uuid.New()
+	payload := &client.MessagePayload{
+		ID:          10,
+		SenderID:    senderID,
+		RecipientID: recipientID,
+		Content:     strings.Repeat("あ", 300),
+		Attachments: []client.AttachmentPayload{{ID: "att-1", URL: "http://files/att-1?sig=..."}},
+	}
+
+	t.Run("Offline recipient gets a push on every device", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		subscriptionRepo := mock.NewMockPushSubscriptionRepository(ctrl)
+		presenceRepo := mock.NewMockPresenceQueryRepository(ctrl)
+		deliveryRepo := mock.NewMockPushDeliveryRepository(ctrl)
+		pushClient := mock.NewMockPushClient(ctrl)
+		s := NewPushService(&mockUow{rm: &mockRepositoryManager{subscriptionRepo: subscriptionRepo}}, subscriptionRepo, presenceRepo, deliveryRepo, pushClient, false)
+
+		deliveryRepo.EXPECT().Claim(gomock.Any(), "chat_event:10", deliveryClaimTTL).Return(true, nil)
+		presenceRepo.EXPECT().IsOnline(gomock.Any(), recipientID).Return(false, nil)
+		subscriptionRepo.EXPECT().Query(gomock.Any(), recipientID).Return([]*entity.PushSubscription{
+			{ID: 1, Endpoint: "https://push.example.com/a"},
+			{ID: 2, Endpoint: "https://push.example.com/b"},
+		}, nil)
+		pushClient.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, sub *entity.PushSubscription, msg *client.PushMessage) error {
+			assert.Equal(t, client.PushUrgencyHigh, msg.Urgency)
+			var envelope struct {
+				Type    string                `json:"type"`
+				Payload client.MessagePayload `json:"payload"`
+			}
+			assert.NoError(t, json.Unmarshal(msg.Payload, &envelope))
+			assert.Equal(t, "chat_event", envelope.Type)
+			assert.Equal(t, int64(10), envelope.Payload.ID)
+			assert.Equal(t, strings.Repeat("あ", maxPushContentLen)+"…", envelope.Payload.Content)
+			assert.Equal(t, []string{"att-1"}, envelope.Payload.AttachmentIDs)
+			assert.Empty(t, envelope.Payload.Attachments)
+			return nil
+		}).Times(2)
+
+		assert.NoError(t, s.PushChatMessage(context.Background(), payload))
+		drain(s)
+	})
+
+	t.Run("Push claimed by another replica is not sent again", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		presenceRepo := mock.NewMockPresenceQueryRepository(ctrl)
+		deliveryRepo := mock.NewMockPushDeliveryRepository(ctrl)
+		s := NewPushService(&mockUow{}, nil, presenceRepo, deliveryRepo, nil, false)
+
+		deliveryRepo.EXPECT().Claim(gomock.Any(), "chat_event:10", deliveryClaimTTL).Return(false, nil)
+		presenceRepo.EXPECT().IsOnline(gomock.Any(), gomock.Any()).Times(0)
+
+		assert.NoError(t, s.PushChatMessage(context.Background(), payload))
+		assert.Empty(t, s.jobs)
+	})
+
+	t.Run("Online recipient is left to the WebSocket", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		presenceRepo := mock.NewMockPresenceQueryRepository(ctrl)
+		deliveryRepo := mock.NewMockPushDeliveryRepository(ctrl)
+		s := NewPushService(&mockUow{}, nil, presenceRepo, deliveryRepo, nil, false)
+
+		deliveryRepo.EXPECT().Claim(gomock.Any(), gomock.Any(), deliveryClaimTTL).Return(true, nil)
+		presenceRepo.EXPECT().IsOnline(gomock.Any(), recipientID).Return(true, nil)
+
+		assert.NoError(t, s.PushChatMessage(context.Background(), payload))
+	})
+
+	t.Run("Gone subscriptions are deleted and others still get the push", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		subscriptionRepo := mock.NewMockPushSubscriptionRepository(ctrl)
+		presenceRepo := mock.NewMockPresenceQueryRepository(ctrl)
+		deliveryRepo := mock.NewMockPushDeliveryRepository(ctrl)
+		pushClient := mock.NewMockPushClient(ctrl)
+		s := NewPushService(&mockUow{rm: &mockRepositoryManager{subscriptionRepo: subscriptionRepo}}, subscriptionRepo, presenceRepo, deliveryRepo, pushClient, false)
+
+		gone := &entity.PushSubscription{ID: 1, Endpoint: "https://push.example.com/gone"}
+		failing := &entity.PushSubscription{ID: 2, Endpoint: "https://push.example.com/failing"}
+		ok := &entity.PushSubscription{ID: 3, Endpoint: "https://push.example.com/ok"}
+		deliveryRepo.EXPECT().Claim(gomock.Any(), gomock.Any(), deliveryClaimTTL).Return(true, nil)
+		presenceRepo.EXPECT().IsOnline(gomock.Any(), recipientID).Return(false, nil)
+		subscriptionRepo.EXPECT().Query(gomock.Any(), recipientID).Return([]*entity.PushSubscription{gone, failing, ok}, nil)
+		pushClient.EXPECT().Send(gomock.Any(), gone, gomock.Any()).Return(client.ErrPushSubscriptionGone)
+		pushClient.EXPECT().Send(gomock.Any(), failing, gomock.Any()).Return(errors.New("timeout"))
+		pushClient.EXPECT().Send(gomock.Any(), ok, gomock.Any()).Return(nil)
+		subscriptionRepo.EXPECT().Delete(gomock.Any(), gone.Endpoint).Return(nil)
+
+		assert.NoError(t, s.PushChatMessage(context.Background(), payload))
+		drain(s)
+	})
+
+	t.Run("Full queue drops the push instead of blocking", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		subscriptionRepo := mock.NewMockPushSubscriptionRepository(ctrl)
+		presenceRepo := mock.NewMockPresenceQueryRepository(ctrl)
+		deliveryRepo := mock.NewMockPushDeliveryRepository(ctrl)
+		s := NewPushService(&mockUow{}, subscriptionRepo, presenceRepo, deliveryRepo, nil, false)
+		for i := 0; i < sendQueueSize; i++ {
+			s.jobs <- &sendJob{}
+		}
+
+		deliveryRepo.EXPECT().Claim(gomock.Any(), gomock.Any(), deliveryClaimTTL).Return(true, nil)
+		presenceRepo.EXPECT().IsOnline(gomock.Any(), recipientID).Return(false, nil)
+		subscriptionRepo.EXPECT().Query(gomock.Any(), recipientID).Return([]*entity.PushSubscription{{ID: 1}}, nil)
+
+		assert.NoError(t, s.PushChatMessage(context.Background(), payload))
+		assert.Len(t, s.jobs, sendQueueSize)
+	})
+}
+
+func TestPushService_PushNotification(t *testing.T) {
+	recipientID := uuid.New()
+	updatedAt := time.UnixMicro(1700000000000000)
+
+	t.Run("Like is pushed as a notification event", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		subscriptionRepo := mock.NewMockPushSubscriptionRepository(ctrl)
+		presenceRepo := mock.NewMockPresenceQueryRepository(ctrl)
+		deliveryRepo := mock.NewMockPushDeliveryRepository(ctrl)
+		pushClient := mock.NewMockPushClient(ctrl)
+		s := NewPushService(&mockUow{}, subscriptionRepo, presenceRepo, deliveryRepo, pushClient, false)
+
+		deliveryRepo.EXPECT().Claim(gomock.Any(), "notification_event:1:1700000000000000", deliveryClaimTTL).Return(true, nil)
+		presenceRepo.EXPECT().IsOnline(gomock.Any(), recipientID).Return(false, nil)
+		subscriptionRepo.EXPECT().Query(gomock.Any(), recipientID).Return([]*entity.PushSubscription{{ID: 1}}, nil)
+		pushClient.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, sub *entity.PushSubscription, msg *client.PushMessage) error {
+			assert.Equal(t, client.PushUrgencyNormal, msg.Urgency)
+			assert.Contains(t, string(msg.Payload), `"type":"notification_event"`)
+			assert.Contains(t, string(msg.Payload), `"sender_name":"taro"`)
+			return nil
+		})
+
+		err := s.PushNotification(context.Background(), &client.NotificationPayload{ID: 1, RecipientID: recipientID, Type: "like", SenderName: "taro", Count: 2, UpdatedAt: updatedAt})
+
+		assert.NoError(t, err)
+		drain(s)
+	})
+
+	t.Run("Re-aggregated notification is claimed under a new key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		presenceRepo := mock.NewMockPresenceQueryRepository(ctrl)
+		deliveryRepo := mock.NewMockPushDeliveryRepository(ctrl)
+		s := NewPushService(&mockUow{}, nil, presenceRepo, deliveryRepo, nil, false)
+
+		gomock.InOrder(
+			deliveryRepo.EXPECT().Claim(gomock.Any(), "notification_event:1:1700000000000000", deliveryClaimTTL).Return(true, nil),
+			deliveryRepo.EXPECT().Claim(gomock.Any(), "notification_event:1:1700000001000000", deliveryClaimTTL).Return(true, nil),
+		)
+		presenceRepo.EXPECT().IsOnline(gomock.Any(), recipientID).Return(true, nil).Times(2)
+
+		assert.NoError(t, s.PushNotification(context.Background(), &client.NotificationPayload{ID: 1, RecipientID: recipientID, Type: "like", Count: 1, UpdatedAt: updatedAt}))
+		assert.NoError(t, s.PushNotification(context.Background(), &client.NotificationPayload{ID: 1, RecipientID: recipientID, Type: "like", Count: 2, UpdatedAt: updatedAt.Add(time.Second)}))
+	})
+
+	t.Run("Message notifications are covered by the chat push", func(t *testing.T) {
+		s := NewPushService(&mockUow{}, nil, nil, nil, nil, false)
+
+		err := s.PushNotification(context.Background(), &client.NotificationPayload{ID: 1, RecipientID: recipientID, Type: "message"})
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestPushService_RunSenders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pushClient := mock.NewMockPushClient(ctrl)
+	s := NewPushService(&mockUow{}, nil, nil, nil, pushClient, false)
+
+	// 遅い送信があっても他のワーカーが次を送る
+	const workers = 2
+	release := make(chan struct{})
+	sent := make(chan int64, 2)
+	pushClient.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, sub *entity.PushSubscription, msg *client.PushMessage) error {
+		if sub.ID == 1 {
+			<-release
+		}
+		sent <- sub.ID
+		return nil
+	}).Times(2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.RunSenders(ctx, workers)
+		close(done)
+	}()
+	s.jobs <- &sendJob{sub: &entity.PushSubscription{ID: 1}, msg: &client.PushMessage{}}
+	s.jobs <- &sendJob{sub: &entity.PushSubscription{ID: 2}, msg: &client.PushMessage{}}
+
+	assert.Equal(t, int64(2), <-sent)
+	close(release)
+	assert.Equal(t, int64(1), <-sent)
+
+	cancel()
+	<-done
+}
+
+func TestPushService_GetPublicKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pushClient := mock.NewMockPushClient(ctrl)
+	s := NewPushService(&mockUow{}, nil, nil, nil, pushClient, false)
+
+	pushClient.EXPECT().PublicKey().Return("BPublicKey")
+	key, err := s.GetPublicKey(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "BPublicKey", key)
+
+	// VAPID 鍵が設定されていない
+	pushClient.EXPECT().PublicKey().Return("")
+	_, err = s.GetPublicKey(context.Background())
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+}
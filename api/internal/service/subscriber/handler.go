@@ -2,72 +2,103 @@ package subscriber
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"github.com/google/uuid"
 	"github.com/icchon/matcha/api/internal/domain/client"
 	"github.com/icchon/matcha/api/internal/domain/entity"
 	"github.com/icchon/matcha/api/internal/domain/repo"
 	"github.com/icchon/matcha/api/internal/domain/service"
+	"github.com/lib/pq"
 	"log"
+	"math"
 	"time"
 )
 
+// presence_query で一度に問い合わせられるユーザー数の上限
+const maxPresenceQueryUsers = 100
+
 type subscriberHandler struct {
-	uow         repo.UnitOfWork
-	messageRepo repo.MessageRepository
-	readPub     client.Publisher
-	ackPub      client.Publisher
-	chatPub     client.Publisher
-	presencePub client.Publisher
+	uow              repo.UnitOfWork
+	messageRepo      repo.MessageRepository
+	connRepo         repo.ConnectionQueryRepository
+	userRepo         repo.UserQueryRepository
+	presenceRepo     repo.PresenceQueryRepository
+	readPub          client.Publisher
+	ackPub           client.Publisher
+	chatPub          client.Publisher
+	presencePub      client.Publisher
+	typingPub        client.Publisher
+	presenceQueryPub client.Publisher
+	moderationPub    client.Publisher
+
+	messageFilter service.MessageFilter
 
 	userService  service.UserService
 	notifService service.NotificationService
+	chatService  service.ChatService
 }
 
 func NewSubscriberHandler(
 	uow repo.UnitOfWork,
 	messageRepo repo.MessageRepository,
+	connRepo repo.ConnectionQueryRepository,
+	userRepo repo.UserQueryRepository,
+	presenceRepo repo.PresenceQueryRepository,
 	readPub client.Publisher,
 	ackPub client.Publisher,
 	chatPub client.Publisher,
 	presencePub client.Publisher,
+	typingPub client.Publisher,
+	presenceQueryPub client.Publisher,
+	moderationPub client.Publisher,
+	messageFilter service.MessageFilter,
 	userService service.UserService,
 	notifService service.NotificationService,
+	chatService service.ChatService,
 ) *subscriberHandler {
 	return &subscriberHandler{
-		uow:          uow,
-		messageRepo:  messageRepo,
-		readPub:      readPub,
-		ackPub:       ackPub,
-		chatPub:      chatPub,
-		presencePub:  presencePub,
-		userService:  userService,
-		notifService: notifService,
+		uow:              uow,
+		messageRepo:      messageRepo,
+		connRepo:         connRepo,
+		userRepo:         userRepo,
+		presenceRepo:     presenceRepo,
+		readPub:          readPub,
+		ackPub:           ackPub,
+		chatPub:          chatPub,
+		presencePub:      presencePub,
+		typingPub:        typingPub,
+		presenceQueryPub: presenceQueryPub,
+		moderationPub:    moderationPub,
+		messageFilter:    messageFilter,
+		userService:      userService,
+		notifService:     notifService,
+		chatService:      chatService,
 	}
 }
 
+// ReadSubscHandler は会話の既読位置 (watermark) を進め、未読フラグを 1 回の UPDATE でまとめて落とす
 func (h *subscriberHandler) ReadSubscHandler(ctx context.Context, payload *client.ReadPayload) error {
-	unreadMsgs, err := h.messageRepo.Query(ctx, &repo.MessageQuery{
-		SenderID:    &payload.RecipientID,
-		RecipientID: &payload.UserID,
-		IsRead:      func(b bool) *bool { return &b }(false),
-	})
-	if err != nil {
-		return err
+	upTo := payload.LastReadMessageID
+	if upTo <= 0 {
+		upTo = math.MaxInt64
 	}
+	var read *entity.ConversationRead
 	if err := h.uow.Do(ctx, func(rm repo.RepositoryManager) error {
-		for _, msg := range unreadMsgs {
-			if err := rm.MessageRepo().MarkAsRead(ctx, msg.ID); err != nil {
-				return err
-			}
+		if _, err := rm.MessageRepo().MarkConversationRead(ctx, payload.UserID, payload.RecipientID, upTo); err != nil {
+			return err
 		}
-		return nil
+		var err error
+		read, err = rm.ConversationReadRepo().Advance(ctx, payload.UserID, payload.RecipientID, upTo)
+		return err
 	}); err != nil {
 		return err
 	}
 	readPayload := &client.ReadPayload{
-		UserID:      payload.UserID,
-		RecipientID: payload.RecipientID,
-		Timestamp:   payload.Timestamp,
+		UserID:            payload.UserID,
+		RecipientID:       payload.RecipientID,
+		LastReadMessageID: read.LastReadMessageID,
+		Timestamp:         payload.Timestamp,
 	}
 	payloadBytes, err := json.Marshal(readPayload)
 	if err != nil {
@@ -79,6 +110,8 @@ func (h *subscriberHandler) ReadSubscHandler(ctx context.Context, payload *clien
 	return nil
 }
 
+// ChatSubscHandler はモデレーションを通してからメッセージを保存・配信する
+// reject と hold は送信者にだけ moderation イベントで結果を返す
 func (h *subscriberHandler) ChatSubscHandler(ctx context.Context, payload *client.MessagePayload) error {
 	log.Printf("Received message payload: %+v", payload)
 	msg := &entity.Message{
@@ -87,11 +120,44 @@ func (h *subscriberHandler) ChatSubscHandler(ctx context.Context, payload *clien
 		Content:     payload.Content,
 		SentAt:      payload.SentAt,
 	}
+	if len(payload.AttachmentIDs) > 0 {
+		attachments, err := h.chatService.ResolveAttachments(ctx, msg.SenderID, msg.RecipientID, payload.AttachmentIDs)
+		if err != nil {
+			return err
+		}
+		msg.Attachments = attachments
+	}
+
+	result, err := h.messageFilter.Filter(ctx, msg)
+	if err != nil {
+		return err
+	}
+	switch result.Action {
+	case service.ModerationReject:
+		log.Printf("Rejected message from %s to %s: %s", msg.SenderID, msg.RecipientID, result.Reason)
+		return h.publishModeration(ctx, &client.ModerationPayload{
+			UserID:      msg.SenderID,
+			RecipientID: msg.RecipientID,
+			Action:      client.ModerationEventReject,
+			Reason:      result.Reason,
+			Content:     payload.Content,
+			SentAt:      msg.SentAt,
+		})
+	case service.ModerationHold:
+		return h.holdMessage(ctx, msg, result)
+	}
+	msg.Content = result.Content
+
 	if err := h.uow.Do(ctx, func(rm repo.RepositoryManager) error {
 		log.Printf("Creating message from %s to %s: %s", msg.SenderID, msg.RecipientID, msg.Content)
 		if err := rm.MessageRepo().Create(ctx, msg); err != nil {
 			return err
 		}
+		for _, attachment := range msg.Attachments {
+			if err := rm.MessageAttachmentRepo().AttachToMessage(ctx, attachment.ID, msg.ID); err != nil {
+				return err
+			}
+		}
 		log.Printf("Created message with ID %d", msg.ID)
 		return nil
 	}); err != nil {
@@ -110,14 +176,7 @@ func (h *subscriberHandler) ChatSubscHandler(ctx context.Context, payload *clien
 		return err
 	}
 
-	chatPayload := &client.MessagePayload{
-		ID:          msg.ID,
-		SenderID:    msg.SenderID,
-		RecipientID: msg.RecipientID,
-		Content:     msg.Content,
-		SentAt:      msg.SentAt,
-	}
-	chatBytes, err := json.Marshal(chatPayload)
+	chatBytes, err := json.Marshal(client.NewMessagePayload(msg))
 	if err != nil {
 		return err
 	}
@@ -130,7 +189,56 @@ func (h *subscriberHandler) ChatSubscHandler(ctx context.Context, payload *clien
 	return nil
 }
 
+// holdMessage は管理者の審査に回す。添付ファイルは承認時にメッセージへ紐付ける
+func (h *subscriberHandler) holdMessage(ctx context.Context, msg *entity.Message, result *service.ModerationResult) error {
+	held := &entity.HeldMessage{
+		SenderID:      msg.SenderID,
+		RecipientID:   msg.RecipientID,
+		Content:       result.Content,
+		AttachmentIDs: pq.StringArray{},
+		Reason:        result.Reason,
+		SentAt:        msg.SentAt,
+	}
+	for _, attachment := range msg.Attachments {
+		held.AttachmentIDs = append(held.AttachmentIDs, attachment.ID)
+	}
+	if err := h.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		return rm.HeldMessageRepo().Create(ctx, held)
+	}); err != nil {
+		return err
+	}
+	log.Printf("Held message %d from %s to %s: %s", held.ID, msg.SenderID, msg.RecipientID, result.Reason)
+	return h.publishModeration(ctx, &client.ModerationPayload{
+		UserID:        held.SenderID,
+		RecipientID:   held.RecipientID,
+		Action:        client.ModerationEventHold,
+		Reason:        held.Reason,
+		HeldMessageID: held.ID,
+		SentAt:        held.SentAt,
+	})
+}
+
+func (h *subscriberHandler) publishModeration(ctx context.Context, payload *client.ModerationPayload) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return h.moderationPub.Publish(ctx, payloadBytes)
+}
+
 func (h *subscriberHandler) PresenceSubscHandler(ctx context.Context, payload *client.PresencePayload) error {
+	if payload.Status == "offline" {
+		if err := h.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+			user, err := rm.UserRepo().Find(ctx, payload.UserID)
+			if err != nil || user == nil {
+				return err
+			}
+			user.LastConnection = sql.NullTime{Time: time.Now(), Valid: true}
+			return rm.UserRepo().Update(ctx, user)
+		}); err != nil {
+			log.Printf("Error updating last connection of %s: %v", payload.UserID, err)
+		}
+	}
 	connections, err := h.userService.FindConnections(ctx, payload.UserID)
 	if err != nil {
 		return err
@@ -158,3 +266,123 @@ func (h *subscriberHandler) PresenceSubscHandler(ctx context.Context, payload *c
 	}
 	return nil
 }
+
+func (h *subscriberHandler) TypingSubscHandler(ctx context.Context, payload *client.TypingPayload) error {
+	conn, err := h.connRepo.Find(ctx, payload.UserID, payload.RecipientID)
+	if err != nil {
+		return err
+	}
+	if conn == nil {
+		log.Printf("Dropping typing event from %s to %s: users are not connected", payload.UserID, payload.RecipientID)
+		return nil
+	}
+	typingPayload := &client.TypingPayload{
+		UserID:      payload.UserID,
+		RecipientID: payload.RecipientID,
+		IsTyping:    payload.IsTyping,
+		Timestamp:   payload.Timestamp,
+	}
+	payloadBytes, err := json.Marshal(typingPayload)
+	if err != nil {
+		return err
+	}
+	return h.typingPub.Publish(ctx, payloadBytes)
+}
+
+// PresenceQuerySubscHandler は問い合わせたユーザーのうち、つながっている相手の状態だけを返す
+func (h *subscriberHandler) PresenceQuerySubscHandler(ctx context.Context, payload *client.PresenceQueryPayload) error {
+	connections, err := h.userService.FindConnections(ctx, payload.UserID)
+	if err != nil {
+		return err
+	}
+	connected := make(map[uuid.UUID]bool, len(connections))
+	for _, conn := range connections {
+		otherID, err := conn.GetOtherUserID(payload.UserID)
+		if err != nil {
+			continue
+		}
+		connected[otherID] = true
+	}
+
+	targets := make([]uuid.UUID, 0, len(payload.UserIDs))
+	seen := make(map[uuid.UUID]bool, len(payload.UserIDs))
+	for _, userID := range payload.UserIDs {
+		if !connected[userID] || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		targets = append(targets, userID)
+		if len(targets) == maxPresenceQueryUsers {
+			break
+		}
+	}
+
+	online, err := h.presenceRepo.FindOnline(ctx, targets)
+	if err != nil {
+		return err
+	}
+	// 最終接続時刻はまとめて 1 回のクエリで読む
+	lastConnections := make(map[uuid.UUID]time.Time, len(targets))
+	if len(targets) > 0 {
+		users, err := h.userRepo.Query(ctx, &repo.UserQuery{IDs: targets})
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			if user.LastConnection.Valid {
+				lastConnections[user.ID] = user.LastConnection.Time
+			}
+		}
+	}
+	presences := make([]*client.PresenceStatus, 0, len(targets))
+	for _, userID := range targets {
+		presence := &client.PresenceStatus{
+			UserID: userID,
+			Status: "offline",
+		}
+		if online[userID] {
+			presence.Status = "online"
+		}
+		if lastConnection, ok := lastConnections[userID]; ok {
+			presence.LastConnection = &lastConnection
+		}
+		presences = append(presences, presence)
+	}
+
+	resultPayload := &client.PresenceQueryResultPayload{
+		RequestID:   payload.RequestID,
+		ConnID:      payload.ConnID,
+		RecipientID: payload.UserID,
+		Presences:   presences,
+	}
+	payloadBytes, err := json.Marshal(resultPayload)
+	if err != nil {
+		return err
+	}
+	return h.presenceQueryPub.Publish(ctx, payloadBytes)
+}
+
+// 編集・送信取り消し・リアクションは REST と同じ検証を通すため ChatService に任せる
+// 配信も ChatService が行う
+func (h *subscriberHandler) MessageEditSubscHandler(ctx context.Context, payload *client.MessageEditPayload) error {
+	_, _, err := h.chatService.EditMessage(ctx, payload.UserID, payload.RecipientID, payload.MessageID, payload.Content)
+	return err
+}
+
+func (h *subscriberHandler) MessageDeleteSubscHandler(ctx context.Context, payload *client.MessageDeletePayload) error {
+	_, err := h.chatService.DeleteMessage(ctx, payload.UserID, payload.RecipientID, payload.MessageID)
+	return err
+}
+
+func (h *subscriberHandler) ReactionSubscHandler(ctx context.Context, payload *client.ReactionPayload) error {
+	switch payload.Action {
+	case client.ReactionActionAdd:
+		_, err := h.chatService.AddReaction(ctx, payload.UserID, payload.RecipientID, payload.MessageID, payload.Emoji)
+		return err
+	case client.ReactionActionRemove:
+		return h.chatService.RemoveReaction(ctx, payload.UserID, payload.RecipientID, payload.MessageID, payload.Emoji)
+	default:
+		log.Printf("Dropping reaction event from %s: unknown action %q", payload.UserID, payload.Action)
+		return nil
+	}
+}
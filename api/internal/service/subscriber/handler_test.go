@@ -0,0 +1,310 @@
+package subscriber
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/entity"
+	"github.com/icchon/matcha/api/internal/domain/repo"
+	"github.com/icchon/matcha/api/internal/domain/service"
+	"github.com/icchon/matcha/api/internal/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// mockRepositoryManager is a mock for repo.RepositoryManager.
+type mockRepositoryManager struct {
+	repo.RepositoryManager
+	messageRepo          repo.MessageRepository
+	conversationReadRepo repo.ConversationReadRepository
+	heldMessageRepo      repo.HeldMessageRepository
+}
+
+func (m *mockRepositoryManager) MessageRepo() repo.MessageRepository {
+	return m.messageRepo
+}
+func (m *mockRepositoryManager) ConversationReadRepo() repo.ConversationReadRepository {
+	return m.conversationReadRepo
+}
+
+func (m *mockRepositoryManager) HeldMessageRepo() repo.HeldMessageRepository {
+	return m.heldMessageRepo
+}
+
+// mockUow is a mock for repo.UnitOfWork for testing services.
+type mockUow struct {
+	rm repo.RepositoryManager
+}
+
+func (u *mockUow) Do(ctx context.Context, fn func(rm repo.RepositoryManager) error) error {
+	return fn(u.rm)
+}
+
+func TestSubscriberHandler_ReadSubscHandler(t *testing.T) {
+	userID := uuid.New()
+	peerID := uuid.New()
+
+	testCases := []struct {
+		name         string
+		requested    int64
+		expectedUpTo int64
+	}{
+		{name: "Read up to the given message", requested: 10, expectedUpTo: 10},
+		{name: "Read everything when no watermark is given", requested: 0, expectedUpTo: math.MaxInt64},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			messageRepo := mock.NewMockMessageRepository(ctrl)
+			readRepo := mock.NewMockConversationReadRepository(ctrl)
+			readPub := mock.NewMockPublisher(ctrl)
+			h := &subscriberHandler{
+				uow:     &mockUow{rm: &mockRepositoryManager{messageRepo: messageRepo, conversationReadRepo: readRepo}},
+				readPub: readPub,
+			}
+
+			messageRepo.EXPECT().MarkConversationRead(gomock.Any(), userID, peerID, tc.expectedUpTo).Return(int64(2), nil)
+			readRepo.EXPECT().Advance(gomock.Any(), userID, peerID, tc.expectedUpTo).Return(&entity.ConversationRead{
+				UserID: userID, PeerID: peerID, LastReadMessageID: 9,
+			}, nil)
+			readPub.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, data interface{}) error {
+				var payload client.ReadPayload
+				assert.NoError(t, json.Unmarshal(data.([]byte), &payload))
+				assert.Equal(t, userID, payload.UserID)
+				assert.Equal(t, peerID, payload.RecipientID)
+				assert.Equal(t, int64(9), payload.LastReadMessageID)
+				return nil
+			})
+
+			err := h.ReadSubscHandler(context.Background(), &client.ReadPayload{
+				UserID:            userID,
+				RecipientID:       peerID,
+				LastReadMessageID: tc.requested,
+			})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSubscriberHandler_TypingSubscHandler(t *testing.T) {
+	userID := uuid.New()
+	recipientID := uuid.New()
+
+	testCases := []struct {
+		name       string
+		connection *entity.Connection
+		expectPub  bool
+	}{
+		{
+			name:       "Relayed between connected users",
+			connection: &entity.Connection{User1ID: userID, User2ID: recipientID},
+			expectPub:  true,
+		},
+		{
+			name:       "Dropped when users are not connected",
+			connection: nil,
+			expectPub:  false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			connRepo := mock.NewMockConnectionQueryRepository(ctrl)
+			typingPub := mock.NewMockPublisher(ctrl)
+			h := &subscriberHandler{connRepo: connRepo, typingPub: typingPub}
+
+			connRepo.EXPECT().Find(gomock.Any(), userID, recipientID).Return(tc.connection, nil)
+			if tc.expectPub {
+				typingPub.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, data interface{}) error {
+					var payload client.TypingPayload
+					assert.NoError(t, json.Unmarshal(data.([]byte), &payload))
+					assert.Equal(t, recipientID, payload.RecipientID)
+					assert.True(t, payload.IsTyping)
+					return nil
+				})
+			}
+
+			err := h.TypingSubscHandler(context.Background(), &client.TypingPayload{
+				UserID:      userID,
+				RecipientID: recipientID,
+				IsTyping:    true,
+			})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSubscriberHandler_PresenceQuerySubscHandler(t *testing.T) {
+	userID := uuid.New()
+	onlineID := uuid.New()
+	offlineID := uuid.New()
+	strangerID := uuid.New()
+	lastConnection := time.Now().Add(-time.Hour)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userService := mock.NewMockUserService(ctrl)
+	userRepo := mock.NewMockUserQueryRepository(ctrl)
+	presenceRepo := mock.NewMockPresenceQueryRepository(ctrl)
+	presenceQueryPub := mock.NewMockPublisher(ctrl)
+	h := &subscriberHandler{
+		userService:      userService,
+		userRepo:         userRepo,
+		presenceRepo:     presenceRepo,
+		presenceQueryPub: presenceQueryPub,
+	}
+
+	userService.EXPECT().FindConnections(gomock.Any(), userID).Return([]*entity.Connection{
+		{User1ID: userID, User2ID: onlineID},
+		{User1ID: offlineID, User2ID: userID},
+	}, nil)
+	presenceRepo.EXPECT().FindOnline(gomock.Any(), []uuid.UUID{onlineID, offlineID}).Return(map[uuid.UUID]bool{onlineID: true}, nil)
+	// ユーザーごとではなく 1 回のクエリで読む
+	userRepo.EXPECT().Query(gomock.Any(), &repo.UserQuery{IDs: []uuid.UUID{onlineID, offlineID}}).Return([]*entity.User{
+		{ID: offlineID, LastConnection: sql.NullTime{Time: lastConnection, Valid: true}},
+		{ID: onlineID},
+	}, nil)
+	presenceQueryPub.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, data interface{}) error {
+		var result client.PresenceQueryResultPayload
+		assert.NoError(t, json.Unmarshal(data.([]byte), &result))
+		assert.Equal(t, "req-1", result.RequestID)
+		assert.Equal(t, "conn-1", result.ConnID)
+		assert.Equal(t, userID, result.RecipientID)
+		if assert.Len(t, result.Presences, 2) {
+			assert.Equal(t, "online", result.Presences[0].Status)
+			assert.Nil(t, result.Presences[0].LastConnection)
+			assert.Equal(t, "offline", result.Presences[1].Status)
+			assert.True(t, lastConnection.Equal(*result.Presences[1].LastConnection))
+		}
+		return nil
+	})
+
+	err := h.PresenceQuerySubscHandler(context.Background(), &client.PresenceQueryPayload{
+		RequestID: "req-1",
+		ConnID:    "conn-1",
+		UserID:    userID,
+		UserIDs:   []uuid.UUID{onlineID, strangerID, offlineID, onlineID},
+	})
+	assert.NoError(t, err)
+}
+
+func TestSubscriberHandler_ChatSubscHandler_Moderation(t *testing.T) {
+	senderID := uuid.New()
+	recipientID := uuid.New()
+	sentAt := time.Now()
+
+	newPayload := func() *client.MessagePayload {
+		return &client.MessagePayload{SenderID: senderID, RecipientID: recipientID, Content: "original", SentAt: sentAt}
+	}
+
+	t.Run("Rejected message is not stored and the reason goes back to the sender", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		messageFilter := mock.NewMockMessageFilter(ctrl)
+		moderationPub := mock.NewMockPublisher(ctrl)
+		h := &subscriberHandler{
+			uow:           &mockUow{rm: &mockRepositoryManager{}},
+			messageFilter: messageFilter,
+			moderationPub: moderationPub,
+		}
+
+		messageFilter.EXPECT().Filter(gomock.Any(), gomock.Any()).Return(&service.ModerationResult{
+			Action: service.ModerationReject, Content: "original", Reason: "rate_limited",
+		}, nil)
+		moderationPub.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, data interface{}) error {
+			var payload client.ModerationPayload
+			assert.NoError(t, json.Unmarshal(data.([]byte), &payload))
+			assert.Equal(t, senderID, payload.UserID)
+			assert.Equal(t, client.ModerationEventReject, payload.Action)
+			assert.Equal(t, "rate_limited", payload.Reason)
+			assert.Equal(t, "original", payload.Content)
+			return nil
+		})
+
+		assert.NoError(t, h.ChatSubscHandler(context.Background(), newPayload()))
+	})
+
+	t.Run("Held message is stored for review instead of being delivered", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		messageFilter := mock.NewMockMessageFilter(ctrl)
+		heldRepo := mock.NewMockHeldMessageRepository(ctrl)
+		moderationPub := mock.NewMockPublisher(ctrl)
+		h := &subscriberHandler{
+			uow:           &mockUow{rm: &mockRepositoryManager{heldMessageRepo: heldRepo}},
+			messageFilter: messageFilter,
+			moderationPub: moderationPub,
+		}
+
+		messageFilter.EXPECT().Filter(gomock.Any(), gomock.Any()).Return(&service.ModerationResult{
+			Action: service.ModerationHold, Content: "original", Reason: "contact_info_on_new_match",
+		}, nil)
+		heldRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, held *entity.HeldMessage) error {
+			assert.Equal(t, senderID, held.SenderID)
+			assert.Equal(t, "contact_info_on_new_match", held.Reason)
+			held.ID = 5
+			return nil
+		})
+		moderationPub.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, data interface{}) error {
+			var payload client.ModerationPayload
+			assert.NoError(t, json.Unmarshal(data.([]byte), &payload))
+			assert.Equal(t, client.ModerationEventHold, payload.Action)
+			assert.Equal(t, int64(5), payload.HeldMessageID)
+			return nil
+		})
+
+		assert.NoError(t, h.ChatSubscHandler(context.Background(), newPayload()))
+	})
+
+	t.Run("Masked content is stored and delivered", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		messageFilter := mock.NewMockMessageFilter(ctrl)
+		messageRepo := mock.NewMockMessageRepository(ctrl)
+		ackPub := mock.NewMockPublisher(ctrl)
+		chatPub := mock.NewMockPublisher(ctrl)
+		notifService := mock.NewMockNotificationService(ctrl)
+		h := &subscriberHandler{
+			uow:           &mockUow{rm: &mockRepositoryManager{messageRepo: messageRepo}},
+			messageFilter: messageFilter,
+			ackPub:        ackPub,
+			chatPub:       chatPub,
+			notifService:  notifService,
+		}
+
+		messageFilter.EXPECT().Filter(gomock.Any(), gomock.Any()).Return(&service.ModerationResult{
+			Action: service.ModerationMask, Content: "********", Reason: "banned_word",
+		}, nil)
+		messageRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, msg *entity.Message) error {
+			assert.Equal(t, "********", msg.Content)
+			msg.ID = 1
+			return nil
+		})
+		ackPub.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil)
+		chatPub.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, data interface{}) error {
+			var payload client.MessagePayload
+			assert.NoError(t, json.Unmarshal(data.([]byte), &payload))
+			assert.Equal(t, "********", payload.Content)
+			return nil
+		})
+		notifService.EXPECT().CreateAndSendNotification(gomock.Any(), senderID, recipientID, entity.NotifMessage).Return(&entity.Notification{}, nil)
+
+		assert.NoError(t, h.ChatSubscHandler(context.Background(), newPayload()))
+	})
+}
@@ -12,12 +12,22 @@ type SubscriberHandler interface {
 	ChatSubscHandler(ctx context.Context, payload *client.MessagePayload) error
 	PresenceSubscHandler(ctx context.Context, payload *client.PresencePayload) error
 	ReadSubscHandler(ctx context.Context, payload *client.ReadPayload) error
+	TypingSubscHandler(ctx context.Context, payload *client.TypingPayload) error
+	PresenceQuerySubscHandler(ctx context.Context, payload *client.PresenceQueryPayload) error
+	MessageEditSubscHandler(ctx context.Context, payload *client.MessageEditPayload) error
+	MessageDeleteSubscHandler(ctx context.Context, payload *client.MessageDeletePayload) error
+	ReactionSubscHandler(ctx context.Context, payload *client.ReactionPayload) error
 }
 
 type subscriberService struct {
-	chatSub     client.Subscriber
-	presenseSub client.Subscriber
-	readSub     client.Subscriber
+	chatSub          client.Subscriber
+	presenseSub      client.Subscriber
+	readSub          client.Subscriber
+	typingSub        client.Subscriber
+	presenceQuerySub client.Subscriber
+	messageEditSub   client.Subscriber
+	messageDeleteSub client.Subscriber
+	reactionSub      client.Subscriber
 
 	subscHandler SubscriberHandler
 }
@@ -28,13 +38,23 @@ func NewSubscriberService(
 	chatSub client.Subscriber,
 	presenseSub client.Subscriber,
 	readSub client.Subscriber,
+	typingSub client.Subscriber,
+	presenceQuerySub client.Subscriber,
+	messageEditSub client.Subscriber,
+	messageDeleteSub client.Subscriber,
+	reactionSub client.Subscriber,
 	subscHandler SubscriberHandler,
 ) *subscriberService {
 	return &subscriberService{
-		chatSub:      chatSub,
-		presenseSub:  presenseSub,
-		readSub:      readSub,
-		subscHandler: subscHandler,
+		chatSub:          chatSub,
+		presenseSub:      presenseSub,
+		readSub:          readSub,
+		typingSub:        typingSub,
+		presenceQuerySub: presenceQuerySub,
+		messageEditSub:   messageEditSub,
+		messageDeleteSub: messageDeleteSub,
+		reactionSub:      reactionSub,
+		subscHandler:     subscHandler,
 	}
 }
 
@@ -60,5 +80,50 @@ func (s *subscriberService) Initialize(ctx context.Context) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.typingSub.SubscribeChannel(ctx, func(ctx context.Context, data interface{}) error {
+		if err := s.subscHandler.TypingSubscHandler(ctx, data.(*client.TypingPayload)); err != nil {
+			log.Printf("Error handling typing event: %v", err)
+			return err
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := s.presenceQuerySub.SubscribeChannel(ctx, func(ctx context.Context, data interface{}) error {
+		if err := s.subscHandler.PresenceQuerySubscHandler(ctx, data.(*client.PresenceQueryPayload)); err != nil {
+			log.Printf("Error handling presence query: %v", err)
+			return err
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := s.messageEditSub.SubscribeChannel(ctx, func(ctx context.Context, data interface{}) error {
+		if err := s.subscHandler.MessageEditSubscHandler(ctx, data.(*client.MessageEditPayload)); err != nil {
+			log.Printf("Error handling message edit event: %v", err)
+			return err
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := s.messageDeleteSub.SubscribeChannel(ctx, func(ctx context.Context, data interface{}) error {
+		if err := s.subscHandler.MessageDeleteSubscHandler(ctx, data.(*client.MessageDeletePayload)); err != nil {
+			log.Printf("Error handling message delete event: %v", err)
+			return err
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := s.reactionSub.SubscribeChannel(ctx, func(ctx context.Context, data interface{}) error {
+		if err := s.subscHandler.ReactionSubscHandler(ctx, data.(*client.ReactionPayload)); err != nil {
+			log.Printf("Error handling reaction event: %v", err)
+			return err
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
 	return nil
 }
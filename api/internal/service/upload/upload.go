@@ -0,0 +1,41 @@
+package upload
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/domain/service"
+)
+
+// 1 ファイルの最大サイズ。filesrv の上限と同じ
+const maxUploadLength = 10 << 20
+
+var _ service.UploadService = (*uploadService)(nil)
+
+type uploadService struct {
+	fileClient client.FileClient
+}
+
+func NewUploadService(fileClient client.FileClient) *uploadService {
+	return &uploadService{fileClient: fileClient}
+}
+
+func (s *uploadService) CreateUpload(ctx context.Context, userID uuid.UUID, length int64) (*client.Upload, error) {
+	if length <= 0 || length > maxUploadLength {
+		return nil, apperrors.ErrInvalidInput
+	}
+	upload, err := s.fileClient.CreateUpload(userID, length)
+	if err != nil {
+		switch {
+		case errors.Is(err, client.ErrFileRejected):
+			return nil, apperrors.ErrInvalidInput
+		case errors.Is(err, client.ErrFileQuotaExceeded):
+			return nil, apperrors.ErrTooManyRequests
+		}
+		return nil, err
+	}
+	return upload, nil
+}
@@ -0,0 +1,59 @@
+package upload
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/apperrors"
+	"github.com/icchon/matcha/api/internal/domain/client"
+	"github.com/icchon/matcha/api/internal/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUploadService_CreateUpload(t *testing.T) {
+	userID := uuid.New()
+	upload := &client.Upload{ID: "u1", URL: "http://files/uploads/u1", Length: 1024, ExpiresAt: time.Now().Add(time.Hour)}
+
+	testCases := []struct {
+		name        string
+		length      int64
+		clientErr   error
+		callsClient bool
+		expectError error
+	}{
+		{name: "Success", length: 1024, callsClient: true},
+		{name: "Empty", length: 0, expectError: apperrors.ErrInvalidInput},
+		{name: "Too large", length: maxUploadLength + 1, expectError: apperrors.ErrInvalidInput},
+		{name: "Rejected by the file service", length: 1024, callsClient: true, clientErr: fmt.Errorf("%w: too_large", client.ErrFileRejected), expectError: apperrors.ErrInvalidInput},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			fileClient := mock.NewMockFileClient(ctrl)
+			if tc.callsClient {
+				if tc.clientErr != nil {
+					fileClient.EXPECT().CreateUpload(userID, tc.length).Return(nil, tc.clientErr)
+				} else {
+					fileClient.EXPECT().CreateUpload(userID, tc.length).Return(upload, nil)
+				}
+			}
+
+			s := NewUploadService(fileClient)
+			got, err := s.CreateUpload(context.Background(), userID, tc.length)
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, upload, got)
+		})
+	}
+}
@@ -2,8 +2,10 @@ package user
 
 import (
 	"context"
+	"log"
 
 	"github.com/google/uuid"
+	"github.com/icchon/matcha/api/internal/domain/client"
 	"github.com/icchon/matcha/api/internal/domain/entity"
 	"github.com/icchon/matcha/api/internal/domain/repo"
 	"github.com/icchon/matcha/api/internal/domain/service"
@@ -18,11 +20,12 @@ type userService struct {
 	userDataRepo   repo.UserDataRepository
 	userTagRepo    repo.UserTagRepository
 	tagRepo        repo.TagRepository
+	fileClient     client.FileClient
 }
 
 var _ service.UserService = (*userService)(nil)
 
-func NewUserService(uow repo.UnitOfWork, likeRepo repo.LikeQueryRepository, viewRepo repo.ViewQueryRepository, connectionRepo repo.ConnectionQueryRepository, notifSvc service.NotificationService, userDataRepo repo.UserDataRepository, userTagRepo repo.UserTagRepository, tagRepo repo.TagRepository) service.UserService {
+func NewUserService(uow repo.UnitOfWork, likeRepo repo.LikeQueryRepository, viewRepo repo.ViewQueryRepository, connectionRepo repo.ConnectionQueryRepository, notifSvc service.NotificationService, userDataRepo repo.UserDataRepository, userTagRepo repo.UserTagRepository, tagRepo repo.TagRepository, fileClient client.FileClient) service.UserService {
 	return &userService{
 		uow:            uow,
 		likeRepo:       likeRepo,
@@ -32,16 +35,32 @@ func NewUserService(uow repo.UnitOfWork, likeRepo repo.LikeQueryRepository, view
 		userDataRepo:   userDataRepo,
 		userTagRepo:    userTagRepo,
 		tagRepo:        tagRepo,
+		fileClient:     fileClient,
 	}
 }
 
+// DeleteUser は写真の行も消えるので、コミットした後に filesrv の参照を外す
 func (u *userService) DeleteUser(ctx context.Context, userID uuid.UUID) error {
-	return u.uow.Do(ctx, func(rm repo.RepositoryManager) error {
-		if err := rm.UserRepo().Delete(ctx, userID); err != nil {
+	var pictures []*entity.Picture
+	if err := u.uow.Do(ctx, func(rm repo.RepositoryManager) error {
+		var err error
+		pictures, err = rm.PictureRepo().Query(ctx, &repo.PictureQuery{UserID: &userID})
+		if err != nil {
 			return err
 		}
-		return nil
-	})
+		return rm.UserRepo().Delete(ctx, userID)
+	}); err != nil {
+		return err
+	}
+	for _, pic := range pictures {
+		if pic.AssetID == "" {
+			continue
+		}
+		if err := u.fileClient.DeleteImage(pic.AssetID); err != nil {
+			log.Printf("Failed to release picture asset %s of deleted user %s: %v", pic.AssetID, userID, err)
+		}
+	}
+	return nil
 }
 
 // conection, like, view delete
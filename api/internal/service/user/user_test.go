@@ -21,6 +21,7 @@ type mockRepositoryManager struct {
 	connectionRepo         repo.ConnectionRepository
 	likeRepo               repo.LikeRepository
 	viewRepo               repo.ViewRepository
+	pictureRepo            repo.PictureRepository
 }
 
 func (m *mockRepositoryManager) UserRepo() repo.UserRepository {
@@ -38,6 +39,9 @@ func (m *mockRepositoryManager) LikeRepo() repo.LikeRepository {
 func (m *mockRepositoryManager) ViewRepo() repo.ViewRepository {
 	return m.viewRepo
 }
+func (m *mockRepositoryManager) PictureRepo() repo.PictureRepository {
+	return m.pictureRepo
+}
 
 // mockUow is a mock for repo.UnitOfWork for testing services.
 type mockUow struct {
@@ -58,19 +62,25 @@ func TestUserService_DeleteUser(t *testing.T) {
 
 	testCases := []struct {
 		name        string
-		setupMocks  func(userRepo *mock.MockUserRepository)
+		setupMocks  func(userRepo *mock.MockUserRepository, pictureRepo *mock.MockPictureRepository, fileClient *mock.MockFileClient)
 		expectedErr error
 	}{
 		{
-			name: "Success",
-			setupMocks: func(userRepo *mock.MockUserRepository) {
+			name: "Success releases picture assets",
+			setupMocks: func(userRepo *mock.MockUserRepository, pictureRepo *mock.MockPictureRepository, fileClient *mock.MockFileClient) {
+				pictureRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return([]*entity.Picture{
+					{ID: 1, UserID: userID, AssetID: "asset1"},
+					{ID: 2, UserID: userID},
+				}, nil)
 				userRepo.EXPECT().Delete(gomock.Any(), userID).Return(nil)
+				fileClient.EXPECT().DeleteImage("asset1").Return(nil)
 			},
 			expectedErr: nil,
 		},
 		{
-			name: "DB Error",
-			setupMocks: func(userRepo *mock.MockUserRepository) {
+			name: "DB Error keeps assets",
+			setupMocks: func(userRepo *mock.MockUserRepository, pictureRepo *mock.MockPictureRepository, fileClient *mock.MockFileClient) {
+				pictureRepo.EXPECT().Query(gomock.Any(), gomock.Any()).Return([]*entity.Picture{{ID: 1, UserID: userID, AssetID: "asset1"}}, nil)
 				userRepo.EXPECT().Delete(gomock.Any(), userID).Return(dbErr)
 			},
 			expectedErr: dbErr,
@@ -83,14 +93,16 @@ func TestUserService_DeleteUser(t *testing.T) {
 			defer ctrl.Finish()
 
 			userRepo := mock.NewMockUserRepository(ctrl)
+			pictureRepo := mock.NewMockPictureRepository(ctrl)
+			fileClient := mock.NewMockFileClient(ctrl)
 			if tc.setupMocks != nil {
-				tc.setupMocks(userRepo)
+				tc.setupMocks(userRepo, pictureRepo, fileClient)
 			}
 
-			mockRM := &mockRepositoryManager{userRepo: userRepo}
+			mockRM := &mockRepositoryManager{userRepo: userRepo, pictureRepo: pictureRepo}
 			mockUOW := &mockUow{rm: mockRM}
 
-			service := &userService{uow: mockUOW}
+			service := &userService{uow: mockUOW, fileClient: fileClient}
 			err := service.DeleteUser(context.Background(), userID)
 
 			assert.Equal(t, tc.expectedErr, err)
@@ -428,7 +440,7 @@ func TestUserService_UnlikeUser(t *testing.T) {
 
 			mockRM := &mockRepositoryManager{likeRepo: likeRepo, connectionRepo: connRepo}
 			mockUOW := &mockUow{rm: mockRM}
-			service := NewUserService(mockUOW, nil, nil, nil, notifService, nil, nil, nil) // Pass notifService
+			service := NewUserService(mockUOW, nil, nil, nil, notifService, nil, nil, nil, nil) // Pass notifService
 			err := service.UnlikeUser(context.Background(), likerID, likedID)
 			assert.Equal(t, tc.expectedErr, err)
 		})
@@ -74,7 +74,9 @@ CREATE TABLE user_profiles (
     birthday DATE NOT NULL,
     occupation VARCHAR(255),
     biography TEXT,
-    fame_rating INT,    location_name VARCHAR(255)
+    fame_rating INT,    location_name VARCHAR(255),
+    -- メールなどの言語 (ja / en)。NULL ならリクエストの Accept-Language で決める
+    locale VARCHAR(8)
 );
 
 -- (5. 関係性テーブル, 6. チャットテーブルも同様に users テーブルを参照)
@@ -94,14 +96,68 @@ CREATE TABLE user_tags (
     PRIMARY KEY (user_id, tag_id)
 );
 
+CREATE TYPE picture_status_enum AS ENUM ('pending', 'approved', 'rejected');
+
 CREATE TABLE pictures (
     id SERIAL PRIMARY KEY,
     user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    -- full の URL
     url VARCHAR(255) NOT NULL,
+    -- filesrv の asset ID と小さい変種の URL。変種がない古い写真は空
+    asset_id VARCHAR(64) NOT NULL DEFAULT '',
+    thumbnail_url VARCHAR(255) NOT NULL DEFAULT '',
+    card_url VARCHAR(255) NOT NULL DEFAULT '',
     is_profile_pic BOOLEAN DEFAULT FALSE,
+    -- 表示順 (0 から)。1 ユーザー 5 枚まで
+    position SMALLINT NOT NULL DEFAULT 0,
+    -- 審査の状態。approved になるまで本人以外には見えない
+    status picture_status_enum NOT NULL DEFAULT 'pending',
+    -- ImageClassifier が疑わしいとした理由。空でなければ管理者の審査を待つ
+    flag_reason VARCHAR(255) NOT NULL DEFAULT '',
+    -- card の変種の知覚ハッシュ (dHash と pHash)。他のユーザーの写真との重複を探す
+    dhash BIGINT,
+    phash BIGINT,
+    classified_at TIMESTAMP WITH TIME ZONE,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+-- プロフィール写真は 1 ユーザー 1 枚
+CREATE UNIQUE INDEX idx_pictures_one_profile_pic ON pictures (user_id) WHERE is_profile_pic;
+CREATE INDEX idx_pictures_user_position ON pictures (user_id, position);
+-- 検査待ちと審査待ちの写真を探す
+CREATE INDEX idx_pictures_moderation ON pictures (status, created_at) WHERE status = 'pending';
+
+-- 64 ビットのハッシュを 8 ビットずつの 8 つの帯に分け、(帯の位置 * 256 + 値) の配列にする
+-- ハミング距離が 7 以下なら少なくとも 1 つの帯が一致するので、GIN インデックスで候補を絞れる
+CREATE FUNCTION hash_bands(h BIGINT) RETURNS INT[] AS $$
+    SELECT ARRAY(SELECT (i * 256 + ((h >> (i * 8)) & 255))::INT FROM generate_series(0, 7) AS i)
+$$ LANGUAGE SQL IMMUTABLE STRICT PARALLEL SAFE;
+
+-- 異なるビットの数。PostgreSQL 13 には bit_count がない
+CREATE FUNCTION hamming_distance(a BIGINT, b BIGINT) RETURNS INT AS $$
+    SELECT length(replace((a # b)::bit(64)::text, '0', ''))
+$$ LANGUAGE SQL IMMUTABLE STRICT PARALLEL SAFE;
+
+CREATE INDEX idx_pictures_dhash_bands ON pictures USING GIN (hash_bands(dhash)) WHERE dhash IS NOT NULL;
+CREATE INDEX idx_pictures_phash_bands ON pictures USING GIN (hash_bands(phash)) WHERE phash IS NOT NULL;
+
+-- 後からアップロードされた写真 (picture_id) と、それに似ていた他のユーザーの写真
+CREATE TABLE picture_matches (
+    picture_id INT NOT NULL REFERENCES pictures(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    matched_picture_id INT NOT NULL REFERENCES pictures(id) ON DELETE CASCADE,
+    matched_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    dhash_distance SMALLINT NOT NULL,
+    phash_distance SMALLINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (picture_id, matched_picture_id),
+    CHECK (user_id <> matched_user_id)
+);
+
+CREATE INDEX idx_picture_matches_user ON picture_matches (user_id, created_at);
+
 ---------------------------------------------------
 
 -- 5. 関係性、履歴、通知 (Relationships, History, & Notifications)
@@ -144,25 +200,131 @@ CREATE TABLE notifications (
     sender_id UUID REFERENCES users(id) ON DELETE SET NULL,
     type notification_type_enum NOT NULL,
     is_read BOOLEAN DEFAULT FALSE,
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+    -- 同じ相手からの like / view は一定時間内なら 1 件にまとめ、count を増やす
+    count INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 通知の種類ごとの受け取り設定。行がない種類はすべてのチャネルで受け取る
+-- in_app: WebSocket でのリアルタイム通知 / email_digest: メールのまとめ通知
+CREATE TABLE notification_preferences (
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    type notification_type_enum NOT NULL,
+    in_app BOOLEAN NOT NULL DEFAULT TRUE,
+    email_digest BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, type)
+);
+
+-- user_id は muted_until まで muted_user_id からの通知を受け取らない
+CREATE TABLE notification_mutes (
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    muted_user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    muted_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, muted_user_id)
+);
+
+-- メールのまとめ通知を最後に送った時刻。これより後に更新された未読通知だけを次のメールに載せる
+CREATE TABLE email_digests (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    last_sent_at TIMESTAMP WITH TIME ZONE NOT NULL
 );
 
 ---------------------------------------------------
 
 -- 6. チャット機能 (Chat)
 
+-- 全文検索用: 日本語 (ひらがな・カタカナ・漢字) の連続部分を 1-gram と 2-gram の配列にする
+-- 日本語は形態素解析器がないため、n-gram をそのまま lexeme として tsvector に入れる
+-- 1-gram は 1 文字だけの検索 (単語の途中の漢字 1 文字など) に使う
+CREATE FUNCTION cjk_ngrams(t TEXT) RETURNS TEXT[] AS $$
+    SELECT COALESCE(array_agg(DISTINCT g.lexeme), '{}')
+    FROM regexp_matches(t, '[\u3040-\u30ff\u3400-\u9fff\uf900-\ufaff\uff66-\uff9f]+', 'g') AS m,
+         generate_series(1, char_length(m[1])) AS i,
+         LATERAL (VALUES (substr(m[1], i, 1)), (substr(m[1], i, 2))) AS g(lexeme)
+$$ LANGUAGE SQL IMMUTABLE STRICT;
+
 CREATE TABLE messages (
     id BIGSERIAL PRIMARY KEY,
     sender_id UUID REFERENCES users(id) ON DELETE CASCADE,
     recipient_id UUID REFERENCES users(id) ON DELETE CASCADE,
     content TEXT NOT NULL,
     sent_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    is_read BOOLEAN DEFAULT FALSE
+    is_read BOOLEAN DEFAULT FALSE,
+    edited_at TIMESTAMP WITH TIME ZONE,
+    -- 送信取り消し (全員から削除) は行を消さず content を空にして deleted_at を立てる
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    -- 英語は english 設定で語幹化、日本語は cjk_ngrams の 1-gram と 2-gram
+    content_tsv TSVECTOR GENERATED ALWAYS AS (
+        to_tsvector('english', content) || array_to_tsvector(cjk_ngrams(content))
+    ) STORED
+);
+
+CREATE TABLE message_reactions (
+    message_id BIGINT REFERENCES messages(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    emoji VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (message_id, user_id, emoji)
+);
+
+-- 会話ごとの既読位置 (user_id が peer_id から受け取ったメッセージをどこまで読んだか)
+-- last_read_message_id 以下の peer_id からのメッセージは既読
+CREATE TABLE conversation_reads (
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    peer_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    last_read_message_id BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, peer_id)
+);
+
+-- チャットの添付ファイル (実体は filesrv。id は filesrv が払い出す)
+-- アップロード直後は message_id が NULL で、送信したメッセージに紐付ける
+CREATE TABLE message_attachments (
+    id VARCHAR(64) PRIMARY KEY,
+    message_id BIGINT REFERENCES messages(id) ON DELETE CASCADE,
+    uploader_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipient_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    mime VARCHAR(64) NOT NULL,
+    size BIGINT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- モデレーションで保留されたメッセージ (管理者が承認すると messages に移す)
+-- messages とは別テーブルにして、審査前の本文が履歴・検索・チャット一覧に出ないようにする
+CREATE TYPE held_message_status_enum AS ENUM ('pending', 'approved', 'rejected');
+
+CREATE TABLE held_messages (
+    id BIGSERIAL PRIMARY KEY,
+    sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipient_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    attachment_ids TEXT[] NOT NULL DEFAULT '{}',
+    reason VARCHAR(255) NOT NULL,
+    status held_message_status_enum NOT NULL DEFAULT 'pending',
+    sent_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    -- 承認後に作成された messages の id。編集の保留なら編集するメッセージの id
+    message_id BIGINT REFERENCES messages(id) ON DELETE SET NULL,
+    -- 編集を保留したときの編集するメッセージ。消されたら保留も要らない
+    edit_message_id BIGINT REFERENCES messages(id) ON DELETE CASCADE
 );
 
 -- インデックスの最適化
 CREATE INDEX idx_user_data_location ON user_data USING btree (latitude, longitude);
 CREATE INDEX idx_messages_chat_history ON messages (sender_id, recipient_id, sent_at);
+CREATE INDEX idx_messages_content_tsv ON messages USING GIN (content_tsv);
+CREATE INDEX idx_message_attachments_message_id ON message_attachments (message_id);
+CREATE INDEX idx_held_messages_status ON held_messages (status, created_at);
+-- 一覧は最後にまとめた時刻の順に並べてページングする
+CREATE INDEX idx_notifications_recipient ON notifications (recipient_id, updated_at DESC, id DESC);
+CREATE INDEX idx_notifications_aggregation ON notifications (recipient_id, sender_id, type, updated_at);
 
 ---------------------------------------------------
 
@@ -176,3 +338,44 @@ CREATE TABLE refresh_tokens (
     FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
 );
 
+
+---------------------------------------------------
+
+-- 8. メール送信 (Mail Outbox)
+-- 送信するメールを業務データと同じトランザクションで保存し、ワーカーが送る
+CREATE TYPE mail_outbox_status_enum AS ENUM ('pending', 'sent', 'failed');
+
+CREATE TABLE mail_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    to_email VARCHAR(255) NOT NULL,
+    subject TEXT NOT NULL,
+    html_body TEXT NOT NULL,
+    text_body TEXT NOT NULL,
+    status mail_outbox_status_enum NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    -- 失敗したらバックオフしてこの時刻以降に送り直す
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_mail_outbox_due ON mail_outbox (next_attempt_at) WHERE status = 'pending';
+
+---------------------------------------------------
+
+-- 9. Web Push 購読 (Push Subscriptions)
+-- ブラウザ (端末) ごとの購読。endpoint はプッシュサービスが発行する URL で、端末を一意に表す
+CREATE TABLE push_subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    endpoint TEXT NOT NULL UNIQUE,
+    -- ペイロード暗号化に使うブラウザの公開鍵 (P-256) と認証シークレット。どちらも base64url
+    p256dh VARCHAR(255) NOT NULL,
+    auth VARCHAR(255) NOT NULL,
+    user_agent TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_push_subscriptions_user_id ON push_subscriptions (user_id);
@@ -6,12 +6,39 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/icchon/matcha/filesrv/internal/server"
+	"github.com/icchon/matcha/filesrv/internal/storage"
 )
 
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", key, err)
+	}
+	return d
+}
+
+func getEnvInt64(key string, defaultValue int64) int64 {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return defaultValue
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", key, err)
+	}
+	return n
+}
+
 func main() {
 	var config server.ServerConfig
 	var ok bool
@@ -19,15 +46,57 @@ func main() {
 	if !ok {
 		log.Fatalf("SERVER_ADDR not set")
 	}
-	config.UploadDir, ok = os.LookupEnv("UPLOAD_DIR")
-	if !ok {
-		log.Fatalf("UPLOAD_DIR not set")
-	}
 	config.BaseUrl, ok = os.LookupEnv("BASE_URL")
 	if !ok {
 		log.Fatalf("BASE_URL not set")
 	}
+	config.StorageBackend = os.Getenv("STORAGE_BACKEND")
+	if config.StorageBackend == "s3" {
+		config.S3 = storage.S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			PublicEndpoint:  os.Getenv("S3_PUBLIC_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PathStyle:       os.Getenv("S3_PATH_STYLE") == "true",
+		}
+	} else {
+		config.UploadDir, ok = os.LookupEnv("UPLOAD_DIR")
+		if !ok {
+			log.Fatalf("UPLOAD_DIR not set")
+		}
+		config.AttachmentDir, ok = os.LookupEnv("ATTACHMENT_DIR")
+		if !ok {
+			log.Fatalf("ATTACHMENT_DIR not set")
+		}
+	}
+	config.PresignTTL = getEnvDuration("PRESIGN_TTL", 5*time.Minute)
+	config.PublicThumbnails = os.Getenv("PUBLIC_THUMBNAILS") == "true"
+	config.URLSigningKey, ok = os.LookupEnv("URL_SIGNING_KEY")
+	if !ok {
+		log.Fatalf("URL_SIGNING_KEY not set")
+	}
+	config.ServiceAuthKey, ok = os.LookupEnv("SERVICE_AUTH_KEY")
+	if !ok {
+		log.Fatalf("SERVICE_AUTH_KEY not set")
+	}
+	config.MaxImagePixels = int(getEnvInt64("MAX_IMAGE_PIXELS", 40_000_000))
+	config.MaxConcurrentImageBuilds = int(getEnvInt64("MAX_CONCURRENT_IMAGE_BUILDS", 2))
+	config.UploadQuotaCount = int(getEnvInt64("UPLOAD_QUOTA_COUNT", 30))
+	config.UploadQuotaBytes = getEnvInt64("UPLOAD_QUOTA_BYTES", 100<<20)
+	config.UploadQuotaWindow = getEnvDuration("UPLOAD_QUOTA_WINDOW", time.Hour)
+	config.UploadStagingDir = os.Getenv("UPLOAD_STAGING_DIR")
+	if config.UploadStagingDir == "" {
+		config.UploadStagingDir = filepath.Join(os.TempDir(), "matcha-uploads")
+	}
+	config.ResumableUploadExpiry = getEnvDuration("RESUMABLE_UPLOAD_EXPIRY", 24*time.Hour)
+	config.AssetGCInterval = getEnvDuration("ASSET_GC_INTERVAL", time.Hour)
+	config.AssetGCGracePeriod = getEnvDuration("ASSET_GC_GRACE_PERIOD", 24*time.Hour)
 	srv := server.NewServer(&config)
+	if srv == nil {
+		log.Fatalf("Failed to setup server")
+	}
 	go func() {
 		if err := srv.Start(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Server failed to start: %v", err)
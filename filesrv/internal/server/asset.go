@@ -0,0 +1,201 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/icchon/matcha/filesrv/internal/storage"
+)
+
+// プロフィール写真は元画像の SHA-256 を asset ID にして {先頭 2 文字}/{assetID}/ に置く
+// 同じ画像がアップロードされたら変種を作り直さず参照数を増やす
+// 参照数が 0 になっても猶予期間の間は残し、GC が消す (その間に同じ画像が来たら復活させる)
+// refs.json は変種をすべて書いた後に書くので、refs.json があれば asset は揃っている
+
+var assetIDPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+var ErrAssetNotFound = errors.New("asset not found")
+
+const assetRefsFile = "refs.json"
+
+type assetRefs struct {
+	Refs      int       `json:"refs"`
+	CreatedAt time.Time `json:"created_at"`
+	// 参照数が 0 になった時刻。参照があるときは nil
+	UnreferencedSince *time.Time `json:"unreferenced_since,omitempty"`
+}
+
+type AssetStore struct {
+	Storage storage.Storage
+	// 参照数の読み書きと GC を直列化する (filesrv は 1 プロセスで動かす前提)
+	mu sync.Mutex
+}
+
+func NewAssetStore(st storage.Storage) *AssetStore {
+	return &AssetStore{Storage: st}
+}
+
+func assetPrefix(id string) string {
+	return id[:2] + "/" + id + "/"
+}
+
+// VariantKey は変種のキーを返す
+func VariantKey(id string, variant ImageVariant) string {
+	return assetPrefix(id) + string(variant) + ".jpg"
+}
+
+// Put は id (元画像の SHA-256) の asset の参照を 1 つ増やす。まだなければ build で変種を作って保存する
+func (s *AssetStore) Put(ctx context.Context, id string, build func() (map[ImageVariant][]byte, error)) (string, error) {
+	if !assetIDPattern.MatchString(id) {
+		return "", fmt.Errorf("invalid asset id %q", id)
+	}
+
+	s.mu.Lock()
+	ok, err := s.addRef(ctx, id)
+	s.mu.Unlock()
+	if err != nil || ok {
+		return id, err
+	}
+
+	// 変種の生成と書き込みは重いのでロックの外で行う
+	variants, err := build()
+	if err != nil {
+		return "", err
+	}
+	for name, b := range variants {
+		if err := s.Storage.Put(ctx, VariantKey(id, name), bytes.NewReader(b), int64(len(b)), "image/jpeg"); err != nil {
+			return "", err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 同じ画像が並行してアップロードされていたら、先に保存された方に参照を足す
+	if ok, err := s.addRef(ctx, id); err != nil || ok {
+		return id, err
+	}
+	if err := s.writeRefs(ctx, id, &assetRefs{Refs: 1, CreatedAt: time.Now()}); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// addRef は asset があれば参照数を増やして true を返す
+func (s *AssetStore) addRef(ctx context.Context, id string) (bool, error) {
+	refs, err := s.readRefs(ctx, id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	refs.Refs++
+	refs.UnreferencedSince = nil
+	return true, s.writeRefs(ctx, id, refs)
+}
+
+// Release は参照を 1 つ減らす。0 になったら GC の対象になる
+func (s *AssetStore) Release(ctx context.Context, id string) error {
+	if !assetIDPattern.MatchString(id) {
+		return ErrAssetNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	refs, err := s.readRefs(ctx, id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return ErrAssetNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if refs.Refs > 0 {
+		refs.Refs--
+	}
+	if refs.Refs == 0 && refs.UnreferencedSince == nil {
+		now := time.Now()
+		refs.UnreferencedSince = &now
+	}
+	return s.writeRefs(ctx, id, refs)
+}
+
+// Sweep は参照数が 0 のまま grace 以上経った asset を消し、消した数を返す
+func (s *AssetStore) Sweep(ctx context.Context, grace time.Duration) (int, error) {
+	objects, err := s.Storage.List(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for _, obj := range objects {
+		if path.Base(obj.Key) != assetRefsFile {
+			continue
+		}
+		id := path.Base(path.Dir(obj.Key))
+		if !assetIDPattern.MatchString(id) || obj.Key != assetPrefix(id)+assetRefsFile {
+			continue
+		}
+		refs, err := s.readRefs(ctx, id)
+		if err != nil {
+			log.Printf("Failed to read refs of asset %s: %v", id, err)
+			continue
+		}
+		if refs.Refs > 0 || refs.UnreferencedSince == nil || time.Since(*refs.UnreferencedSince) < grace {
+			continue
+		}
+		if err := s.remove(ctx, id); err != nil {
+			return removed, fmt.Errorf("failed to remove asset %s: %w", id, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// remove は refs.json を最後に消す。途中で失敗しても次の GC でやり直せる
+func (s *AssetStore) remove(ctx context.Context, id string) error {
+	objects, err := s.Storage.List(ctx, assetPrefix(id))
+	if err != nil {
+		return err
+	}
+	for _, obj := range objects {
+		if strings.HasSuffix(obj.Key, "/"+assetRefsFile) {
+			continue
+		}
+		if err := s.Storage.Delete(ctx, obj.Key); err != nil {
+			return err
+		}
+	}
+	return s.Storage.Delete(ctx, assetPrefix(id)+assetRefsFile)
+}
+
+func (s *AssetStore) readRefs(ctx context.Context, id string) (*assetRefs, error) {
+	rc, _, err := s.Storage.Get(ctx, assetPrefix(id)+assetRefsFile)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	var refs assetRefs
+	if err := json.NewDecoder(rc).Decode(&refs); err != nil {
+		return nil, err
+	}
+	return &refs, nil
+}
+
+func (s *AssetStore) writeRefs(ctx context.Context, id string, refs *assetRefs) error {
+	b, err := json.Marshal(refs)
+	if err != nil {
+		return err
+	}
+	return s.Storage.Put(ctx, assetPrefix(id)+assetRefsFile, bytes.NewReader(b), int64(len(b)), "application/json")
+}
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/icchon/matcha/filesrv/internal/storage"
+)
+
+// チャットの添付ファイルは /images とは別のディレクトリに置き、署名付き URL でのみ配信する
+// 誰が見てよいか (会話の参加者か) は api が判断し、参加者にだけ期限付きの URL を発行する
+
+var attachmentIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+type AttachmentMeta struct {
+	ID     string `json:"id"`
+	Mime   string `json:"mime"`
+	Size   int64  `json:"size"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+type AttachmentHandler struct {
+	Storage    storage.Storage
+	SigningKey []byte
+	Limits     *UploadLimits
+}
+
+func NewAttachmentHandler(st storage.Storage, signingKey string, limits *UploadLimits) *AttachmentHandler {
+	return &AttachmentHandler{
+		Storage:    st,
+		SigningKey: []byte(signingKey),
+		Limits:     limits,
+	}
+}
+
+// SignAttachment は id と有効期限 (unix 秒) に対する署名を返す。api 側と同じ計算をする
+func SignAttachment(key []byte, id string, expires int64) string {
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "%s.%d", id, expires)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (h *AttachmentHandler) UploadAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	upload, uerr := h.Limits.readUpload(w, r, "file")
+	if uerr != nil {
+		writeError(w, uerr.Status, uerr.Code, uerr.Message)
+		return
+	}
+	defer upload.Close()
+	h.saveAttachment(w, r, upload)
+}
+
+// saveAttachment は検証済みのファイルをランダムな ID で保存し、メタデータを返す
+func (h *AttachmentHandler) saveAttachment(w http.ResponseWriter, r *http.Request, upload *validUpload) {
+
+	id, err := newRandomID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Error generating attachment id")
+		return
+	}
+	meta := AttachmentMeta{
+		ID:     id,
+		Mime:   upload.Mime,
+		Size:   upload.Size,
+		Width:  upload.Config.Width,
+		Height: upload.Config.Height,
+	}
+	if err := h.save(r.Context(), meta, upload.Reader()); err != nil {
+		log.Printf("Failed to save attachment: %v", err)
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Error saving attachment")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(meta)
+}
+
+// GET /attachments/{attachmentID}?exp=<unix>&sig=<signature>
+func (h *AttachmentHandler) GetAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "attachmentID")
+	if !attachmentIDPattern.MatchString(id) {
+		writeNotFound(w)
+		return
+	}
+	if !h.verify(id, r.URL.Query().Get("exp"), r.URL.Query().Get("sig")) {
+		writeError(w, http.StatusForbidden, ErrCodeForbidden, "Invalid or expired signature")
+		return
+	}
+
+	meta, err := h.loadMeta(r.Context(), id)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+	// 添付ファイルは署名付き URL にリダイレクトせず中継し、ヘッダーをこちらで決める
+	rc, info, err := h.Storage.Get(r.Context(), id)
+	if err != nil {
+		writeNotFound(w)
+		return
+	}
+	defer rc.Close()
+
+	w.Header().Set("Content-Type", meta.Mime)
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if rs, ok := rc.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, "", info.ModTime, rs)
+		return
+	}
+	w.Header().Set("Content-Length", strconv.FormatInt(meta.Size, 10))
+	io.Copy(w, rc)
+}
+
+func (h *AttachmentHandler) verify(id, expStr, sig string) bool {
+	if len(h.SigningKey) == 0 || sig == "" {
+		return false
+	}
+	expires, err := strconv.ParseInt(expStr, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+	expected := SignAttachment(h.SigningKey, id, expires)
+	return hmac.Equal([]byte(expected), []byte(sig))
+}
+
+func metaKey(id string) string {
+	return id + ".json"
+}
+
+func (h *AttachmentHandler) save(ctx context.Context, meta AttachmentMeta, data io.Reader) error {
+	metaBytes, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	if err := h.Storage.Put(ctx, meta.ID, data, meta.Size, meta.Mime); err != nil {
+		return err
+	}
+	if err := h.Storage.Put(ctx, metaKey(meta.ID), bytes.NewReader(metaBytes), int64(len(metaBytes)), "application/json"); err != nil {
+		h.Storage.Delete(ctx, meta.ID)
+		return err
+	}
+	return nil
+}
+
+func (h *AttachmentHandler) loadMeta(ctx context.Context, id string) (*AttachmentMeta, error) {
+	rc, _, err := h.Storage.Get(ctx, metaKey(id))
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	var meta AttachmentMeta
+	if err := json.NewDecoder(rc).Decode(&meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// newRandomID は添付ファイルと画像の asset に使う 32 桁の hex の ID を返す
+func newRandomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
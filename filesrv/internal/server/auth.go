@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 書き込み系のエンドポイントは api からの呼び出しだけを受け付ける
+// api は ServiceAuthKey で method, path, 時刻, ユーザー ID に署名してヘッダーに付ける
+
+const (
+	ServiceTimestampHeader = "X-Service-Timestamp"
+	ServiceUserHeader      = "X-Service-User"
+	ServiceSignatureHeader = "X-Service-Signature"
+
+	// 時計のずれと再送を許す範囲
+	serviceAuthMaxSkew = 5 * time.Minute
+)
+
+type serviceUserKey struct{}
+
+// SignServiceRequest は api 側と同じ計算で署名を返す
+func SignServiceRequest(key []byte, method, path string, timestamp int64, userID string) string {
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "%s\n%s\n%d\n%s", method, path, timestamp, userID)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ServiceAuthMiddleware は署名を検証し、X-Service-User をコンテキストに入れる
+func ServiceAuthMiddleware(key []byte) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(key) == 0 {
+				writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "Service authentication is not configured")
+				return
+			}
+			timestamp, err := strconv.ParseInt(r.Header.Get(ServiceTimestampHeader), 10, 64)
+			if err != nil {
+				writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "Missing service credentials")
+				return
+			}
+			if skew := time.Since(time.Unix(timestamp, 0)); skew > serviceAuthMaxSkew || skew < -serviceAuthMaxSkew {
+				writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "Service credentials expired")
+				return
+			}
+			userID := r.Header.Get(ServiceUserHeader)
+			expected := SignServiceRequest(key, r.Method, r.URL.Path, timestamp, userID)
+			if !hmac.Equal([]byte(expected), []byte(r.Header.Get(ServiceSignatureHeader))) {
+				writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "Invalid service signature")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), serviceUserKey{}, userID)))
+		})
+	}
+}
+
+// serviceUser は署名されたユーザー ID を返す。なければ空
+func serviceUser(r *http.Request) string {
+	userID, _ := r.Context().Value(serviceUserKey{}).(string)
+	return userID
+}
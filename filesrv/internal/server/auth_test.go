@@ -0,0 +1,106 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestServiceAuthMiddleware(t *testing.T) {
+	key := []byte(testServiceAuthKey)
+	now := time.Now().Unix()
+
+	testCases := []struct {
+		name string
+		key  []byte
+		// 署名に使う値。送るヘッダーとは別に変えられる
+		signMethod, signPath string
+		signedAt             int64
+		sentAt               string
+		sentUser             string
+		signature            string
+		wantStatus           int
+	}{
+		{name: "Valid signature", wantStatus: http.StatusOK},
+		{name: "Clock skew within the limit", signedAt: now - 4*60, wantStatus: http.StatusOK},
+		{name: "Expired signature", signedAt: now - 6*60, wantStatus: http.StatusUnauthorized},
+		{name: "Signature from the future", signedAt: now + 6*60, wantStatus: http.StatusUnauthorized},
+		{name: "Missing timestamp", sentAt: "-", wantStatus: http.StatusUnauthorized},
+		{name: "Timestamp changed after signing", sentAt: strconv.FormatInt(now+1, 10), wantStatus: http.StatusUnauthorized},
+		{name: "Tampered user", sentUser: "user-2", wantStatus: http.StatusUnauthorized},
+		{name: "Signed for another path", signPath: "/attachments", wantStatus: http.StatusUnauthorized},
+		{name: "Signed for another method", signMethod: http.MethodDelete, wantStatus: http.StatusUnauthorized},
+		{name: "Bad signature", signature: "AAAA", wantStatus: http.StatusUnauthorized},
+		{name: "Signed with another key", key: []byte("other-key"), wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotUser string
+			handler := ServiceAuthMiddleware(key)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUser = serviceUser(r)
+			}))
+
+			signKey, method, path, user, signedAt := key, http.MethodPost, "/upload", "user-1", now
+			if tc.key != nil {
+				signKey = tc.key
+			}
+			if tc.signMethod != "" {
+				method = tc.signMethod
+			}
+			if tc.signPath != "" {
+				path = tc.signPath
+			}
+			if tc.signedAt != 0 {
+				signedAt = tc.signedAt
+			}
+			r := httptest.NewRequest(http.MethodPost, "/upload", nil)
+			r.Header.Set(ServiceTimestampHeader, strconv.FormatInt(signedAt, 10))
+			if tc.sentAt == "-" {
+				r.Header.Del(ServiceTimestampHeader)
+			} else if tc.sentAt != "" {
+				r.Header.Set(ServiceTimestampHeader, tc.sentAt)
+			}
+			r.Header.Set(ServiceUserHeader, user)
+			if tc.sentUser != "" {
+				r.Header.Set(ServiceUserHeader, tc.sentUser)
+			}
+			signature := SignServiceRequest(signKey, method, path, signedAt, user)
+			if tc.signature != "" {
+				signature = tc.signature
+			}
+			r.Header.Set(ServiceSignatureHeader, signature)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("status = %d %s, want %d", w.Code, w.Body, tc.wantStatus)
+			}
+			if tc.wantStatus == http.StatusOK && gotUser != "user-1" {
+				t.Errorf("service user = %q, want user-1", gotUser)
+			}
+		})
+	}
+}
+
+func TestServiceAuthMiddleware_NoKey(t *testing.T) {
+	handler := ServiceAuthMiddleware(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request without a configured key was accepted")
+	}))
+	now := time.Now().Unix()
+	r := httptest.NewRequest(http.MethodPost, "/upload", nil)
+	r.Header.Set(ServiceTimestampHeader, strconv.FormatInt(now, 10))
+	r.Header.Set(ServiceUserHeader, "user-1")
+	// 空の鍵で署名しても通さない
+	r.Header.Set(ServiceSignatureHeader, SignServiceRequest(nil, http.MethodPost, "/upload", now, "user-1"))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", w.Code)
+	}
+}
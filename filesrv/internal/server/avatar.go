@@ -0,0 +1,121 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// 写真のないユーザーの既定の画像 (identicon)。外部のサービスには頼らず、ユーザー ID からその場で作る
+// 同じ seed からはいつも同じ画像になるので、長くキャッシュしてよい
+
+const (
+	// 左右対称の avatarGrid x avatarGrid のマス
+	avatarGrid        = 5
+	defaultAvatarSize = 256
+	minAvatarSize     = 32
+	maxAvatarSize     = 512
+	avatarMaxAge      = 30 * 24 * time.Hour
+)
+
+// seed はユーザー ID (UUID) を想定する。seeder は 2 枚目以降の写真に "{ユーザー ID}-{番号}" を使う
+var avatarSeedPattern = regexp.MustCompile(`^[0-9A-Za-z-]{1,64}$`)
+
+// RenderAvatar は seed から決まる identicon を size x size で描く
+func RenderAvatar(seed string, size int) image.Image {
+	sum := sha256.Sum256([]byte(seed))
+	hue := float64(int(sum[0])<<8|int(sum[1])) / 65536 * 360
+	background := hslColor(hue, 0.35, 0.92)
+	foreground := hslColor(hue, 0.55, 0.5)
+
+	// 左半分 (中央の列を含む) を決めて右に写す
+	var cells [avatarGrid][avatarGrid]bool
+	half := (avatarGrid + 1) / 2
+	for y := 0; y < avatarGrid; y++ {
+		for x := 0; x < half; x++ {
+			bit := y*half + x
+			on := sum[2+bit/8]&(1<<(bit%8)) != 0
+			cells[y][x] = on
+			cells[y][avatarGrid-1-x] = on
+		}
+	}
+
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{background, foreground})
+	margin := size / 10
+	cell := float64(size-2*margin) / avatarGrid
+	for py := margin; py < size-margin; py++ {
+		y := min(int(float64(py-margin)/cell), avatarGrid-1)
+		for px := margin; px < size-margin; px++ {
+			x := min(int(float64(px-margin)/cell), avatarGrid-1)
+			if cells[y][x] {
+				img.SetColorIndex(px, py, 1)
+			}
+		}
+	}
+	return img
+}
+
+// hslColor は色相 (0-360)、彩度、明度から色を作る
+func hslColor(h, s, l float64) color.RGBA {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return color.RGBA{R: uint8((r + m) * 255), G: uint8((g + m) * 255), B: uint8((b + m) * 255), A: 255}
+}
+
+// GET /avatars/{seed}.png?size=N
+// size は minAvatarSize から maxAvatarSize の間に丸める。省略時は defaultAvatarSize
+func (h *Handler) AvatarHandler(w http.ResponseWriter, r *http.Request) {
+	seed, ok := strings.CutSuffix(chi.URLParam(r, "seed"), ".png")
+	if !ok || !avatarSeedPattern.MatchString(seed) {
+		writeNotFound(w)
+		return
+	}
+	size := defaultAvatarSize
+	if n, err := strconv.Atoi(r.URL.Query().Get("size")); err == nil {
+		size = max(minAvatarSize, min(n, maxAvatarSize))
+	}
+
+	etag := fmt.Sprintf(`"avatar-%s-%d"`, seed, size)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", int(avatarMaxAge.Seconds())))
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, RenderAvatar(seed, size)); err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Error rendering avatar")
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// エラーは {"code": "...", "message": "..."} で返す。api は code を見て扱いを決める
+
+const (
+	ErrCodeInvalidRequest       = "invalid_request"
+	ErrCodeUnauthorized         = "unauthorized"
+	ErrCodeForbidden            = "forbidden"
+	ErrCodeNotFound             = "not_found"
+	ErrCodeTooLarge             = "too_large"
+	ErrCodeUnsupportedMediaType = "unsupported_media_type"
+	ErrCodeTooManyPixels        = "too_many_pixels"
+	ErrCodeQuotaExceeded        = "quota_exceeded"
+	ErrCodeInternal             = "internal_error"
+)
+
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Code: code, Message: message})
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, ErrCodeNotFound, "Not found")
+}
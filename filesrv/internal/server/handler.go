@@ -1,115 +1,212 @@
 package server
 
 import (
-	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"image"
 	_ "image/gif"
 	_ "image/jpeg"
-	"image/png"
+	_ "image/png"
 	"io"
+	"log"
 	"net/http"
-	"os"
-	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/icchon/matcha/filesrv/internal/storage"
 )
 
 type Handler struct {
-	UploadDir string
-	BaseUrl   string
+	BaseUrl string
+	Assets  *AssetStore
+	Limits  *UploadLimits
+	// ストレージが署名付き URL を発行できるとき、その有効期間
+	PresignTTL time.Duration
+	// api と共有する、/images の URL 署名用の鍵
+	SigningKey []byte
+	// true なら thumbnail は署名なしで返す
+	PublicThumbnails bool
+	// 変種を同時に作る数の上限
+	Builds *ImageBuildLimiter
 }
 
-func NewHandler(uploadDir string, baseUrl string) *Handler {
+func NewHandler(baseUrl string, assets *AssetStore, limits *UploadLimits, presignTTL time.Duration, signingKey string, publicThumbnails bool, builds *ImageBuildLimiter) *Handler {
 	return &Handler{
-		UploadDir: uploadDir,
-		BaseUrl:   baseUrl,
+		BaseUrl:          baseUrl,
+		Assets:           assets,
+		Limits:           limits,
+		PresignTTL:       presignTTL,
+		SigningKey:       []byte(signingKey),
+		PublicThumbnails: publicThumbnails,
+		Builds:           builds,
 	}
 }
 
-type DogApiResponse struct {
+type ImageUploadResponse struct {
 	Message string `json:"message"`
-	Status  string `json:"status"`
+	AssetID string `json:"asset_id"`
+	// 互換のため full と同じ URL
+	URL      string                  `json:"url"`
+	Variants map[ImageVariant]string `json:"variants"`
 }
 
-func (h *Handler) RandomDogHandler(w http.ResponseWriter, r *http.Request) {
-	const dogApiUrl = "https://dog.ceo/api/breeds/image/random"
-
-	var resp DogApiResponse
-	res, err := http.Get(dogApiUrl)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Error fetching dog image: %v", err), http.StatusInternalServerError)
+// UploadImageHandler は検証してから変種を作って asset として保存する。同じ画像なら同じ asset ID を返す
+func (h *Handler) UploadImageHandler(w http.ResponseWriter, r *http.Request) {
+	upload, uerr := h.Limits.readUpload(w, r, "image")
+	if uerr != nil {
+		writeError(w, uerr.Status, uerr.Code, uerr.Message)
 		return
 	}
-	defer res.Body.Close()
-	json.NewDecoder(res.Body).Decode(&resp)
-
-	w.Header().Set("Content-Type", "plain/text")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(resp.Message))
+	defer upload.Close()
+	h.saveImage(w, r, upload)
 }
 
-func (h *Handler) UploadImageHandler(w http.ResponseWriter, r *http.Request) {
-	if err := r.ParseMultipartForm(10 << 20); err != nil {
-		http.Error(w, fmt.Sprintf("Error parsing form data: %v", err), http.StatusBadRequest)
+// saveImage は検証済みの画像を asset として保存し、変種の URL を返す
+func (h *Handler) saveImage(w http.ResponseWriter, r *http.Request, upload *validUpload) {
+	var buildErr error
+	assetID, err := h.Assets.Put(r.Context(), upload.Sum, func() (map[ImageVariant][]byte, error) {
+		if err := h.Builds.acquire(r.Context()); err != nil {
+			return nil, err
+		}
+		defer h.Builds.release()
+		variants, err := BuildImageVariants(upload.Reader())
+		buildErr = err
+		return variants, err
+	})
+	if buildErr != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Image could not be converted")
 		return
 	}
-
-	file, header, err := r.FormFile("image")
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error retrieving file: %v", err), http.StatusBadRequest)
+		log.Printf("Failed to save image: %v", err)
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Error saving image")
 		return
 	}
-	defer file.Close()
 
-	fileBytes, err := io.ReadAll(file)
-	if err != nil {
-		http.Error(w, "Error reading file content into memory", http.StatusInternalServerError)
-		return
+	res := ImageUploadResponse{
+		Message:  "File uploaded successfully",
+		AssetID:  assetID,
+		Variants: make(map[ImageVariant]string, len(imageVariants)),
 	}
-
-	pngBytes, err := ConvertToPNG(fileBytes)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Error converting image format: %v", err), http.StatusBadRequest)
-		return
+	for _, spec := range imageVariants {
+		res.Variants[spec.Name] = fmt.Sprintf("%s/images/%s/%s/%s.jpg", h.BaseUrl, assetID[:2], assetID, spec.Name)
 	}
+	res.URL = res.Variants[VariantFull]
 
-	baseName := filepath.Base(header.Filename)
-	ext := filepath.Ext(baseName)
-	uniqueName := fmt.Sprintf("%d_%s.png", time.Now().UnixNano(), baseName[:len(baseName)-len(ext)])
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
 
-	filePath := filepath.Join(h.UploadDir, uniqueName)
-	dst, err := os.Create(filePath)
+// DELETE /assets/{assetID}
+// api が写真を消したときに参照を 1 つ減らす。ファイルは GC が猶予期間の後に消す
+func (h *Handler) DeleteAssetHandler(w http.ResponseWriter, r *http.Request) {
+	err := h.Assets.Release(r.Context(), chi.URLParam(r, "assetID"))
+	if errors.Is(err, ErrAssetNotFound) {
+		writeNotFound(w)
+		return
+	}
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error creating file on server: %v", err), http.StatusInternalServerError)
+		log.Printf("Failed to release asset: %v", err)
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Error releasing asset")
 		return
 	}
-	defer dst.Close()
+	w.WriteHeader(http.StatusNoContent)
+}
 
-	if _, err = dst.Write(pngBytes); err != nil {
-		http.Error(w, fmt.Sprintf("Error writing PNG data to file: %v", err), http.StatusInternalServerError)
+// GET /assets/{assetID}/{variant}
+// api が写真を検査するために変種を読む。署名付き URL を介さず、リダイレクトもしない
+func (h *Handler) GetAssetVariantHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "assetID")
+	if !assetIDPattern.MatchString(id) {
+		writeNotFound(w)
 		return
 	}
+	serveObject(w, r, h.Assets.Storage, VariantKey(id, ImageVariant(chi.URLParam(r, "variant"))), 0, "private, no-store", "")
+}
 
-	fileURL := h.BaseUrl + fmt.Sprintf("/images/%s", uniqueName)
+var (
+	assetPathPattern  = regexp.MustCompile(`^[0-9a-f]{2}/([0-9a-f]{64})/(thumbnail|card|full)\.jpg$`)
+	legacyPathPattern = regexp.MustCompile(`^[^/.][^/]*\.png$`)
+)
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(fmt.Sprintf(`{"message": "File uploaded successfully", "url": "%s"}`, fileURL)))
+// GET /images/*?exp=<unix>&sig=<signature>[&v=<issued to>]
+// asset の変種と、content-addressed になる前の {unixnano}_{name}.png だけを返す
+func (h *Handler) ServeImageHandler(w http.ResponseWriter, r *http.Request) {
+	path := chi.URLParam(r, "*")
+	var key, etag string
+	var variant ImageVariant
+	if m := assetPathPattern.FindStringSubmatch(path); m != nil && path[:2] == m[1][:2] {
+		variant = ImageVariant(m[2])
+		key = VariantKey(m[1], variant)
+		// asset の中身は変わらないので ID と変種で決まる
+		etag = fmt.Sprintf(`"%s-%s"`, m[1], variant)
+	} else if legacyPathPattern.MatchString(path) {
+		key = path
+	} else {
+		writeNotFound(w)
+		return
+	}
+	cacheControl := h.imageAccess(r, path, variant)
+	if cacheControl == "" {
+		writeError(w, http.StatusForbidden, ErrCodeForbidden, "Invalid or expired signature")
+		return
+	}
+	serveObject(w, r, h.Assets.Storage, key, h.PresignTTL, cacheControl, etag)
 }
 
-func ConvertToPNG(inputData []byte) ([]byte, error) {
-	img, _, err := image.Decode(bytes.NewReader(inputData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode input image data: %w", err)
+// serveObject はストレージが署名付き URL を発行できればリダイレクトし、できなければ中継する
+// 中継するときは cacheControl と etag (空ならサイズと更新時刻から作る) を付け、If-None-Match に 304 で応える
+func serveObject(w http.ResponseWriter, r *http.Request, st storage.Storage, key string, presignTTL time.Duration, cacheControl string, etag string) {
+	if presigner, ok := st.(storage.Presigner); ok && presignTTL > 0 {
+		if _, err := st.Stat(r.Context(), key); errors.Is(err, storage.ErrNotFound) {
+			writeNotFound(w)
+			return
+		} else if err != nil {
+			writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Error reading file")
+			return
+		}
+		url, err := presigner.PresignGet(r.Context(), key, presignTTL)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Error signing file URL")
+			return
+		}
+		// リダイレクト先は期限付きなので覚えさせない
+		w.Header().Set("Cache-Control", "no-store")
+		http.Redirect(w, r, url, http.StatusFound)
+		return
 	}
 
-	output := new(bytes.Buffer)
-
-	err = png.Encode(output, img)
+	rc, info, err := st.Get(r.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		writeNotFound(w)
+		return
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to encode image to PNG: %w", err)
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Error reading file")
+		return
 	}
-
-	return output.Bytes(), nil
+	defer rc.Close()
+	if etag == "" {
+		etag = fmt.Sprintf(`"%x-%x"`, info.ModTime.UnixNano(), info.Size)
+	}
+	if info.ContentType != "" {
+		w.Header().Set("Content-Type", info.ContentType)
+	}
+	w.Header().Set("Cache-Control", cacheControl)
+	w.Header().Set("ETag", etag)
+	// ローカルのファイルなら Range や If-Modified-Since にも応える
+	if rs, ok := rc.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, "", info.ModTime, rs)
+		return
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" && (inm == "*" || strings.Contains(inm, etag)) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	io.Copy(w, rc)
 }
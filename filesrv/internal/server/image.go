@@ -0,0 +1,285 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+)
+
+// プロフィール写真はアップロード時に決まった大きさの変種を作り、同じ asset ID の下にまとめて置く
+// 一覧画面は thumbnail、カードは card、詳細画面は full を使う
+
+type ImageVariant string
+
+const (
+	VariantThumbnail ImageVariant = "thumbnail"
+	VariantCard      ImageVariant = "card"
+	VariantFull      ImageVariant = "full"
+)
+
+type variantSpec struct {
+	Name   ImageVariant
+	Width  int
+	Height int
+	// true なら Width x Height ちょうどに中央で切り抜く。false なら収まるように縮小するだけ
+	Crop bool
+}
+
+var imageVariants = []variantSpec{
+	{Name: VariantThumbnail, Width: 160, Height: 160, Crop: true},
+	{Name: VariantCard, Width: 480, Height: 600, Crop: true},
+	{Name: VariantFull, Width: 1600, Height: 1600},
+}
+
+const (
+	variantJPEGQuality = 85
+	// EXIF を探す先頭の長さ。APP セグメントは 1 つ 64KB まで
+	exifSearchLimit = 256 << 10
+)
+
+// BuildImageVariants は EXIF の向きを反映してから各変種を JPEG で作る
+// 再エンコードするので EXIF (位置情報など) は残らない
+func BuildImageVariants(r io.ReadSeeker) (map[ImageVariant][]byte, error) {
+	head := make([]byte, exifSearchLimit)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, fmt.Errorf("failed to read input image data: %w", err)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read input image data: %w", err)
+	}
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode input image data: %w", err)
+	}
+	// RGBA にするのは 1 回だけにして、全ての変種で使い回す
+	flat := applyOrientation(flatten(img), exifOrientation(head[:n]))
+
+	variants := make(map[ImageVariant][]byte, len(imageVariants))
+	for _, spec := range imageVariants {
+		var resized *image.RGBA
+		if spec.Crop {
+			resized = resizeToFill(flat, spec.Width, spec.Height)
+		} else {
+			resized = resizeToFit(flat, spec.Width, spec.Height)
+		}
+		out := new(bytes.Buffer)
+		if err := jpeg.Encode(out, resized, &jpeg.Options{Quality: variantJPEGQuality}); err != nil {
+			return nil, fmt.Errorf("failed to encode %s variant: %w", spec.Name, err)
+		}
+		variants[spec.Name] = out.Bytes()
+	}
+	return variants, nil
+}
+
+// ImageBuildLimiter は同時に作る変種の数を制限する
+// デコードした画像は 1 枚で 画素数 x 4 バイト以上のメモリを使うため、アップロードが重なっても膨らまないようにする
+type ImageBuildLimiter struct {
+	slots chan struct{}
+}
+
+func NewImageBuildLimiter(n int) *ImageBuildLimiter {
+	return &ImageBuildLimiter{slots: make(chan struct{}, max(1, n))}
+}
+
+// acquire は空きを待つ。先に ctx が終われば ctx のエラーを返す
+func (l *ImageBuildLimiter) acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *ImageBuildLimiter) release() {
+	<-l.slots
+}
+
+// exifOrientation は JPEG の APP1 (Exif) から Orientation タグを読む。見つからなければ 1
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		// SOS 以降は画像データ
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if size < 2 || pos+2+size > len(data) {
+			return 1
+		}
+		segment := data[pos+4 : pos+2+size]
+		if marker == 0xE1 && len(segment) >= 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(segment[6:])
+		}
+		pos += 2 + size
+	}
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		// 0x0112: Orientation (SHORT)
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			v := int(order.Uint16(tiff[entry+8:]))
+			if v < 1 || v > 8 {
+				return 1
+			}
+			return v
+		}
+	}
+	return 1
+}
+
+// flatten は src を (0, 0) 始まりの RGBA にする
+// 透過部分は白で埋める (JPEG は透過を持てないため)
+func flatten(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	if o, ok := src.(interface{ Opaque() bool }); ok && o.Opaque() {
+		draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+		return dst
+	}
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Over)
+	return dst
+}
+
+// applyOrientation は EXIF の Orientation (1-8) どおりに回転・反転した画像を返す
+// 1 なら src をそのまま返す
+func applyOrientation(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	// 5-8 は縦横が入れ替わる
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		row := src.Pix[src.PixOffset(b.Min.X, b.Min.Y+y):]
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			i := dy*dst.Stride + dx*4
+			copy(dst.Pix[i:i+4], row[x*4:x*4+4])
+		}
+	}
+	return dst
+}
+
+// resizeToFit は maxW x maxH に収まるように縮小する。拡大はしない
+func resizeToFit(src *image.RGBA, maxW, maxH int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > maxW || h > maxH {
+		if w*maxH > h*maxW {
+			w, h = maxW, max(1, h*maxW/w)
+		} else {
+			w, h = max(1, w*maxH/h), maxH
+		}
+	}
+	return scale(src, b, w, h)
+}
+
+// resizeToFill は w x h を覆うように縮小し、はみ出した部分を中央で切り落とす
+func resizeToFill(src *image.RGBA, w, h int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	crop := b
+	if sw*h > sh*w {
+		cw := max(1, sh*w/h)
+		crop.Min.X = b.Min.X + (sw-cw)/2
+		crop.Max.X = crop.Min.X + cw
+	} else {
+		ch := max(1, sw*h/w)
+		crop.Min.Y = b.Min.Y + (sh-ch)/2
+		crop.Max.Y = crop.Min.Y + ch
+	}
+	// 元画像が小さいときは拡大しない
+	if crop.Dx() < w {
+		w, h = crop.Dx(), crop.Dy()
+	}
+	return scale(src, crop, w, h)
+}
+
+// scale は src の r を w x h に縮小する。各画素は対応する範囲の平均を取る
+// src は flatten 済みで不透明なものとして alpha は見ない
+func scale(src *image.RGBA, r image.Rectangle, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	sw, sh := r.Dx(), r.Dy()
+	for y := 0; y < h; y++ {
+		y0, y1 := y*sh/h, max((y+1)*sh/h, y*sh/h+1)
+		for x := 0; x < w; x++ {
+			x0, x1 := x*sw/w, max((x+1)*sw/w, x*sw/w+1)
+			var sr, sg, sb, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[src.PixOffset(r.Min.X, r.Min.Y+sy):]
+				for sx := x0; sx < x1; sx++ {
+					sr += int(row[sx*4])
+					sg += int(row[sx*4+1])
+					sb += int(row[sx*4+2])
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(sr / n)
+			dst.Pix[i+1] = uint8(sg / n)
+			dst.Pix[i+2] = uint8(sb / n)
+			dst.Pix[i+3] = 0xFF
+		}
+	}
+	return dst
+}
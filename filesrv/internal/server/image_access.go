@@ -0,0 +1,56 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// /images の URL は api が閲覧のたびに発行する。?exp=<unix>&sig=<署名> に加えて &v=<発行先のユーザー ID>
+// filesrv はリクエストした人を認証しないので、URL を知っていれば誰でも失効まで取得できる (閲覧の制限ではない)
+// v は署名に含めて書き換えられないようにした、発行先の記録とキャッシュを分けるための値
+// PublicThumbnails のときだけ thumbnail は署名なしで返す
+
+const (
+	// 公開サムネイルをキャッシュしてよい期間
+	publicImageMaxAge = 24 * time.Hour
+	// 署名付き URL の応答をキャッシュしてよい上限。失効までの残りがこれより短ければそちらに合わせる
+	signedImageMaxAge = time.Hour
+)
+
+// SignImage は /images/ 以下のパス、有効期限 (unix 秒)、v に対する署名を返す。api 側と同じ計算をする
+func SignImage(key []byte, path string, expires int64, viewerID string) string {
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "image\n%s\n%d\n%s", path, expires, viewerID)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// imageAccess は path を返してよいか確かめ、返すときの Cache-Control を決める
+// 返せないときは Cache-Control を空にする
+func (h *Handler) imageAccess(r *http.Request, path string, variant ImageVariant) string {
+	if h.PublicThumbnails && variant == VariantThumbnail {
+		return fmt.Sprintf("public, max-age=%d", int(publicImageMaxAge.Seconds()))
+	}
+	q := r.URL.Query()
+	sig := q.Get("sig")
+	if len(h.SigningKey) == 0 || sig == "" {
+		return ""
+	}
+	expires, err := strconv.ParseInt(q.Get("exp"), 10, 64)
+	if err != nil {
+		return ""
+	}
+	remaining := time.Until(time.Unix(expires, 0))
+	if remaining <= 0 {
+		return ""
+	}
+	expected := SignImage(h.SigningKey, path, expires, q.Get("v"))
+	if !hmac.Equal([]byte(expected), []byte(sig)) {
+		return ""
+	}
+	return fmt.Sprintf("private, max-age=%d", int(min(remaining, signedImageMaxAge).Seconds()))
+}
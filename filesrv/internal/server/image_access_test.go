@@ -0,0 +1,179 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testAssetID = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	testViewer  = "3f1c2b6e-8a4d-4e2f-9b7a-1c2d3e4f5a6b"
+)
+
+func testImagePath(variant ImageVariant) string {
+	return VariantKey(testAssetID, variant)
+}
+
+// signedImageRequest は api と同じ形の /images の URL へのリクエストを作る
+func signedImageRequest(path string, expires time.Time, viewer string, sig string) *http.Request {
+	q := url.Values{}
+	q.Set("exp", strconv.FormatInt(expires.Unix(), 10))
+	q.Set("v", viewer)
+	q.Set("sig", sig)
+	return httptest.NewRequest(http.MethodGet, "/images/"+path+"?"+q.Encode(), nil)
+}
+
+func TestHandler_ImageAccess(t *testing.T) {
+	key := []byte(testURLSigningKey)
+	card := testImagePath(VariantCard)
+	expires := time.Now().Add(2 * time.Hour)
+	sig := SignImage(key, card, expires.Unix(), testViewer)
+
+	testCases := []struct {
+		name    string
+		request *http.Request
+		path    string
+		want    string
+	}{
+		{name: "Signed URL", request: signedImageRequest(card, expires, testViewer, sig), path: card, want: "private, max-age=3600"},
+		{name: "Expired", request: func() *http.Request {
+			expired := time.Now().Add(-time.Second)
+			return signedImageRequest(card, expired, testViewer, SignImage(key, card, expired.Unix(), testViewer))
+		}(), path: card, want: ""},
+		{name: "Expiry changed", request: signedImageRequest(card, expires.Add(time.Hour), testViewer, sig), path: card, want: ""},
+		{name: "Viewer changed", request: signedImageRequest(card, expires, "00000000-0000-0000-0000-000000000000", sig), path: card, want: ""},
+		{name: "Viewer removed", request: signedImageRequest(card, expires, "", sig), path: card, want: ""},
+		{name: "Signature reused on another path", request: signedImageRequest(testImagePath(VariantFull), expires, testViewer, sig), path: testImagePath(VariantFull), want: ""},
+		{name: "Missing signature", request: signedImageRequest(card, expires, testViewer, ""), path: card, want: ""},
+		{name: "Broken expiry", request: httptest.NewRequest(http.MethodGet, "/images/"+card+"?exp=soon&v="+testViewer+"&sig="+sig, nil), path: card, want: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := &Handler{SigningKey: key}
+
+			if got := h.imageAccess(tc.request, tc.path, VariantCard); got != tc.want {
+				t.Errorf("imageAccess = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHandler_ImageAccess_MaxAge(t *testing.T) {
+	key := []byte(testURLSigningKey)
+	card := testImagePath(VariantCard)
+	h := &Handler{SigningKey: key}
+
+	// 失効までが上限より短ければ、失効までしかキャッシュさせない
+	expires := time.Now().Add(10 * time.Minute)
+	got := h.imageAccess(signedImageRequest(card, expires, testViewer, SignImage(key, card, expires.Unix(), testViewer)), card, VariantCard)
+	maxAge, err := strconv.Atoi(strings.TrimPrefix(got, "private, max-age="))
+	if err != nil || maxAge > 600 || maxAge < 598 {
+		t.Errorf("Cache-Control = %q, want about 10 minutes", got)
+	}
+
+	// 長い期限でも上限で切る
+	expires = time.Now().Add(30 * 24 * time.Hour)
+	got = h.imageAccess(signedImageRequest(card, expires, testViewer, SignImage(key, card, expires.Unix(), testViewer)), card, VariantCard)
+	if want := "private, max-age=3600"; got != want {
+		t.Errorf("Cache-Control = %q, want %q", got, want)
+	}
+}
+
+func TestHandler_ImageAccess_PublicThumbnails(t *testing.T) {
+	unsigned := func(path string) *http.Request {
+		return httptest.NewRequest(http.MethodGet, "/images/"+path, nil)
+	}
+
+	testCases := []struct {
+		name             string
+		publicThumbnails bool
+		variant          ImageVariant
+		want             string
+	}{
+		{name: "Public thumbnail", publicThumbnails: true, variant: VariantThumbnail, want: "public, max-age=86400"},
+		{name: "Card is still signed", publicThumbnails: true, variant: VariantCard, want: ""},
+		{name: "Full is still signed", publicThumbnails: true, variant: VariantFull, want: ""},
+		{name: "Thumbnail is signed by default", publicThumbnails: false, variant: VariantThumbnail, want: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := &Handler{SigningKey: []byte(testURLSigningKey), PublicThumbnails: tc.publicThumbnails}
+			path := testImagePath(tc.variant)
+
+			if got := h.imageAccess(unsigned(path), path, tc.variant); got != tc.want {
+				t.Errorf("imageAccess = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHandler_ImageAccess_NoKey(t *testing.T) {
+	card := testImagePath(VariantCard)
+	expires := time.Now().Add(time.Hour)
+	h := &Handler{}
+
+	// 鍵がなければ空の鍵の署名も通さない
+	got := h.imageAccess(signedImageRequest(card, expires, testViewer, SignImage(nil, card, expires.Unix(), testViewer)), card, VariantCard)
+
+	if got != "" {
+		t.Errorf("imageAccess without a key = %q", got)
+	}
+}
+
+// api の SignImageURL (api/internal/infrastructure/file/filesrv_test.go) と同じ値。どちらかの計算を変えたら両方を直す
+const (
+	sharedImageSigningKey = "url-key"
+	sharedImageURL        = "http://files.example.com/images/9f/9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08/card.jpg" +
+		"?exp=4102444800&sig=P8JT6pT_l7TA0RA_xgooJma0qtLllhgDVynjYncJy6g&v=3f1c2b6e-8a4d-4e2f-9b7a-1c2d3e4f5a6b"
+)
+
+func TestSignImage_SharedVector(t *testing.T) {
+	u, err := url.Parse(sharedImageURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	path := strings.TrimPrefix(u.Path, "/images/")
+
+	if got := SignImage([]byte(sharedImageSigningKey), path, 4102444800, q.Get("v")); got != q.Get("sig") {
+		t.Errorf("SignImage = %s, want %s", got, q.Get("sig"))
+	}
+
+	// api が返す URL をそのまま受け付ける
+	var uploadDir string
+	s := newTestServer(t, func(config *ServerConfig) {
+		config.URLSigningKey = sharedImageSigningKey
+		uploadDir = config.UploadDir
+	})
+	file := filepath.Join(uploadDir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte("jpeg"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := serve(s, httptest.NewRequest(http.MethodGet, u.RequestURI(), nil))
+
+	if w.Code != http.StatusOK || w.Body.String() != "jpeg" {
+		t.Fatalf("GET = %d %s", w.Code, w.Body)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "private, max-age=3600" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+
+	// 署名が合わなければ 403
+	tampered := strings.Replace(u.RequestURI(), "v=3f1c", "v=0f1c", 1)
+	if w := serve(s, httptest.NewRequest(http.MethodGet, tampered, nil)); w.Code != http.StatusForbidden {
+		t.Errorf("GET with another viewer = %d, want 403", w.Code)
+	}
+}
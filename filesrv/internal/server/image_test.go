@@ -0,0 +1,252 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+	"time"
+)
+
+type ifdEntry struct {
+	tag   uint16
+	value uint16
+}
+
+// testTIFF は IFD0 に SHORT の entries を並べた TIFF ヘッダーを作る
+func testTIFF(order binary.ByteOrder, entries ...ifdEntry) []byte {
+	var buf bytes.Buffer
+	if order == binary.LittleEndian {
+		buf.WriteString("II")
+	} else {
+		buf.WriteString("MM")
+	}
+	binary.Write(&buf, order, uint16(42))
+	binary.Write(&buf, order, uint32(8))
+	binary.Write(&buf, order, uint16(len(entries)))
+	for _, e := range entries {
+		binary.Write(&buf, order, e.tag)
+		binary.Write(&buf, order, uint16(3)) // SHORT
+		binary.Write(&buf, order, uint32(1))
+		binary.Write(&buf, order, e.value)
+		binary.Write(&buf, order, uint16(0))
+	}
+	binary.Write(&buf, order, uint32(0))
+	return buf.Bytes()
+}
+
+// withEXIF は JPEG の SOI の直後に tiff を入れた APP1 (Exif) を差し込む
+func withEXIF(jpegData []byte, tiff []byte) []byte {
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	out := []byte{0xFF, 0xD8, 0xFF, 0xE1}
+	out = binary.BigEndian.AppendUint16(out, uint16(len(segment)+2))
+	out = append(out, segment...)
+	return append(out, jpegData[2:]...)
+}
+
+func TestExifOrientation(t *testing.T) {
+	plain := testJPEG(t, 4, 4)
+	orientation := func(v uint16) ifdEntry { return ifdEntry{tag: 0x0112, value: v} }
+	// JFIF の APP0 の後ろに Exif がある
+	jfif := append([]byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x07}, "JFIF\x00"...)
+	truncated := testTIFF(binary.BigEndian, ifdEntry{tag: 0x010F, value: 1}, orientation(6))
+
+	testCases := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{name: "Little endian (II)", data: withEXIF(plain, testTIFF(binary.LittleEndian, orientation(6))), want: 6},
+		{name: "Big endian (MM)", data: withEXIF(plain, testTIFF(binary.BigEndian, orientation(8))), want: 8},
+		{name: "Not the first entry", data: withEXIF(plain, testTIFF(binary.LittleEndian, ifdEntry{tag: 0x010F, value: 1}, orientation(3))), want: 3},
+		{name: "After APP0", data: append(jfif, withEXIF(plain, testTIFF(binary.BigEndian, orientation(5)))[2:]...), want: 5},
+		{name: "No EXIF", data: plain, want: 1},
+		{name: "No orientation tag", data: withEXIF(plain, testTIFF(binary.LittleEndian, ifdEntry{tag: 0x010F, value: 6})), want: 1},
+		{name: "Out of range", data: withEXIF(plain, testTIFF(binary.LittleEndian, orientation(9))), want: 1},
+		{name: "Unknown byte order", data: withEXIF(plain, append([]byte("XX"), testTIFF(binary.LittleEndian, orientation(6))[2:]...)), want: 1},
+		// 2 つ目のエントリーの途中で切れている
+		{name: "Truncated IFD", data: withEXIF(plain, truncated[:10+12+6]), want: 1},
+		{name: "IFD offset past the end", data: withEXIF(plain, append([]byte("MM\x00\x2A\x00\x00\xFF\xFF"), 0, 0)), want: 1},
+		{name: "Truncated segment", data: withEXIF(plain, testTIFF(binary.LittleEndian, orientation(6)))[:20], want: 1},
+		{name: "Not a JPEG", data: testPNG(t, 4, 4), want: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := exifOrientation(tc.data); got != tc.want {
+				t.Errorf("exifOrientation = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// 3x2 の画像の左上だけ赤
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	src.Set(0, 0, color.RGBA{R: 0xFF, A: 0xFF})
+
+	testCases := []struct {
+		orientation int
+		wantW       int
+		wantH       int
+		// 赤い画素の移り先
+		wantX, wantY int
+	}{
+		{orientation: 1, wantW: 3, wantH: 2, wantX: 0, wantY: 0},
+		{orientation: 2, wantW: 3, wantH: 2, wantX: 2, wantY: 0},
+		{orientation: 3, wantW: 3, wantH: 2, wantX: 2, wantY: 1},
+		{orientation: 4, wantW: 3, wantH: 2, wantX: 0, wantY: 1},
+		{orientation: 5, wantW: 2, wantH: 3, wantX: 0, wantY: 0},
+		{orientation: 6, wantW: 2, wantH: 3, wantX: 1, wantY: 0},
+		{orientation: 7, wantW: 2, wantH: 3, wantX: 1, wantY: 2},
+		{orientation: 8, wantW: 2, wantH: 3, wantX: 0, wantY: 2},
+	}
+
+	for _, tc := range testCases {
+		got := applyOrientation(src, tc.orientation)
+
+		if b := got.Bounds(); b.Dx() != tc.wantW || b.Dy() != tc.wantH {
+			t.Errorf("orientation %d: size = %dx%d, want %dx%d", tc.orientation, b.Dx(), b.Dy(), tc.wantW, tc.wantH)
+			continue
+		}
+		if c := got.RGBAAt(tc.wantX, tc.wantY); c.R != 0xFF {
+			t.Errorf("orientation %d: pixel at (%d, %d) = %v, want red", tc.orientation, tc.wantX, tc.wantY, c)
+		}
+	}
+}
+
+func TestResizeToFit(t *testing.T) {
+	testCases := []struct {
+		srcW, srcH   int
+		wantW, wantH int
+	}{
+		{srcW: 3200, srcH: 1600, wantW: 1600, wantH: 800},
+		{srcW: 1000, srcH: 3000, wantW: 533, wantH: 1600},
+		{srcW: 2000, srcH: 2000, wantW: 1600, wantH: 1600},
+		// 小さい画像は拡大しない
+		{srcW: 100, srcH: 50, wantW: 100, wantH: 50},
+		{srcW: 5000, srcH: 1, wantW: 1600, wantH: 1},
+	}
+
+	for _, tc := range testCases {
+		got := resizeToFit(image.NewRGBA(image.Rect(0, 0, tc.srcW, tc.srcH)), 1600, 1600).Bounds()
+
+		if got.Dx() != tc.wantW || got.Dy() != tc.wantH {
+			t.Errorf("resizeToFit(%dx%d) = %dx%d, want %dx%d", tc.srcW, tc.srcH, got.Dx(), got.Dy(), tc.wantW, tc.wantH)
+		}
+	}
+}
+
+func TestResizeToFill(t *testing.T) {
+	testCases := []struct {
+		srcW, srcH   int
+		w, h         int
+		wantW, wantH int
+	}{
+		{srcW: 1000, srcH: 500, w: 160, h: 160, wantW: 160, wantH: 160},
+		{srcW: 500, srcH: 1000, w: 160, h: 160, wantW: 160, wantH: 160},
+		{srcW: 1200, srcH: 1200, w: 480, h: 600, wantW: 480, wantH: 600},
+		// 切り抜いた範囲が小さいときは拡大せず、縦横比だけ合わせる
+		{srcW: 300, srcH: 1000, w: 480, h: 600, wantW: 300, wantH: 375},
+		{srcW: 100, srcH: 50, w: 160, h: 160, wantW: 50, wantH: 50},
+	}
+
+	for _, tc := range testCases {
+		got := resizeToFill(image.NewRGBA(image.Rect(0, 0, tc.srcW, tc.srcH)), tc.w, tc.h).Bounds()
+
+		if got.Dx() != tc.wantW || got.Dy() != tc.wantH {
+			t.Errorf("resizeToFill(%dx%d, %dx%d) = %dx%d, want %dx%d", tc.srcW, tc.srcH, tc.w, tc.h, got.Dx(), got.Dy(), tc.wantW, tc.wantH)
+		}
+	}
+}
+
+func TestResizeToFill_CropsCenter(t *testing.T) {
+	// 左右の帯を赤、中央を青にした 3:1 の画像
+	src := image.NewRGBA(image.Rect(0, 0, 300, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 300; x++ {
+			c := color.RGBA{R: 0xFF, A: 0xFF}
+			if x >= 100 && x < 200 {
+				c = color.RGBA{B: 0xFF, A: 0xFF}
+			}
+			src.SetRGBA(x, y, c)
+		}
+	}
+
+	got := resizeToFill(src, 10, 10)
+
+	for _, p := range []image.Point{{0, 0}, {9, 9}} {
+		if c := got.RGBAAt(p.X, p.Y); c.B != 0xFF || c.R != 0 {
+			t.Errorf("pixel at %v = %v, want the blue center", p, c)
+		}
+	}
+}
+
+func TestFlatten_Transparent(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(10, 10, 12, 12))
+	src.SetNRGBA(11, 11, color.NRGBA{R: 0xFF, A: 0xFF})
+
+	got := flatten(src)
+
+	if b := got.Bounds(); b.Min != (image.Point{}) || b.Dx() != 2 || b.Dy() != 2 {
+		t.Fatalf("bounds = %v", b)
+	}
+	// 透過部分は白になる
+	if c := got.RGBAAt(0, 0); c != (color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}) {
+		t.Errorf("transparent pixel = %v, want white", c)
+	}
+	if c := got.RGBAAt(1, 1); c != (color.RGBA{R: 0xFF, A: 0xFF}) {
+		t.Errorf("opaque pixel = %v, want red", c)
+	}
+}
+
+func TestBuildImageVariants_Orientation(t *testing.T) {
+	// 横長に保存して、EXIF で 90 度回すように指定した写真
+	data := withEXIF(testJPEG(t, 400, 200), testTIFF(binary.BigEndian, ifdEntry{tag: 0x0112, value: 6}))
+
+	variants, err := BuildImageVariants(bytes.NewReader(data))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSizes := map[ImageVariant]image.Point{
+		VariantThumbnail: {160, 160},
+		VariantCard:      {200, 250},
+		VariantFull:      {200, 400},
+	}
+	for name, want := range wantSizes {
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(variants[name]))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if cfg.Width != want.X || cfg.Height != want.Y {
+			t.Errorf("%s = %dx%d, want %dx%d", name, cfg.Width, cfg.Height, want.X, want.Y)
+		}
+	}
+	// 再エンコードで EXIF は落ちる
+	if exifOrientation(variants[VariantFull]) != 1 {
+		t.Error("EXIF orientation survived in the variant")
+	}
+}
+
+func TestImageBuildLimiter(t *testing.T) {
+	l := NewImageBuildLimiter(1)
+	if err := l.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 空きがなければ ctx が終わるまで待つ
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.acquire(ctx); err != context.DeadlineExceeded {
+		t.Errorf("acquire while full = %v, want DeadlineExceeded", err)
+	}
+
+	l.release()
+	if err := l.acquire(context.Background()); err != nil {
+		t.Errorf("acquire after release = %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// UploadQuota はユーザーごとに一定期間のアップロード回数とバイト数を制限する
+// 数えるのはプロセスのメモリ上なので、再起動すると数え直しになる
+
+type UploadQuota struct {
+	MaxUploads int
+	MaxBytes   int64
+	Window     time.Duration
+
+	mu    sync.Mutex
+	usage map[string]*quotaUsage
+	now   func() time.Time
+}
+
+type quotaUsage struct {
+	start   time.Time
+	uploads int
+	bytes   int64
+}
+
+func NewUploadQuota(maxUploads int, maxBytes int64, window time.Duration) *UploadQuota {
+	return &UploadQuota{
+		MaxUploads: maxUploads,
+		MaxBytes:   maxBytes,
+		Window:     window,
+		usage:      make(map[string]*quotaUsage),
+		now:        time.Now,
+	}
+}
+
+// Allow は size バイトのアップロードを許すなら数えて true を返す
+func (q *UploadQuota) Allow(userID string, size int64) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	for id, u := range q.usage {
+		if now.Sub(u.start) >= q.Window {
+			delete(q.usage, id)
+		}
+	}
+	u, ok := q.usage[userID]
+	if !ok {
+		u = &quotaUsage{start: now}
+		q.usage[userID] = u
+	}
+	if (q.MaxUploads > 0 && u.uploads+1 > q.MaxUploads) || (q.MaxBytes > 0 && u.bytes+size > q.MaxBytes) {
+		return false
+	}
+	u.uploads++
+	u.bytes += size
+	return true
+}
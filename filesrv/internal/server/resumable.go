@@ -0,0 +1,306 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 再開可能アップロード (tus 1.0.0 の creation, expiration, checksum, termination)
+// api が POST /uploads でユーザーの枠を作り、クライアントは返した URL に PATCH で少しずつ送る
+// 途切れたら HEAD で受け取り済みの位置を聞いて続きから送り直す
+// 送り終えたら api が POST /uploads/{id}/image (または attachment) で画像・添付ファイルとして保存する
+// ID は推測できないランダムな値で、URL を知っていることが送信の権限になる
+// 受けている途中のデータはストレージの種類によらずローカルの StagingDir に置く
+
+var uploadIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+var (
+	ErrUploadNotFound = errors.New("upload not found")
+	// 送られた位置が受け取り済みの位置と違う
+	ErrUploadOffsetMismatch = errors.New("upload offset mismatch")
+	// チャンクのチェックサムが合わない。そのチャンクは捨てる
+	ErrUploadChecksumMismatch = errors.New("upload checksum mismatch")
+	// 宣言した長さを超えて送られた
+	ErrUploadTooLong = errors.New("upload exceeds declared length")
+	// 同じアップロードに並行して書き込もうとした
+	ErrUploadBusy = errors.New("upload is busy")
+	// まだ全部届いていない
+	ErrUploadIncomplete = errors.New("upload is incomplete")
+)
+
+// uploadChecksumAlgorithms は Upload-Checksum で受け付けるアルゴリズム
+var uploadChecksumAlgorithms = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+}
+
+type ResumableUpload struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	Length int64  `json:"length"`
+	Offset int64  `json:"offset"`
+	// 最後に受け取ってから Expiry 経ったら捨てる
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UploadChecksum はチャンクに付いた Upload-Checksum
+type UploadChecksum struct {
+	Algorithm string
+	Sum       []byte
+}
+
+// ParseUploadChecksum は "<algorithm> <base64>" を読む
+func ParseUploadChecksum(header string) (*UploadChecksum, error) {
+	algorithm, encoded, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok {
+		return nil, errors.New("malformed Upload-Checksum")
+	}
+	if _, ok := uploadChecksumAlgorithms[algorithm]; !ok {
+		return nil, errors.New("unsupported checksum algorithm")
+	}
+	sum, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("malformed Upload-Checksum")
+	}
+	return &UploadChecksum{Algorithm: algorithm, Sum: sum}, nil
+}
+
+type ResumableStore struct {
+	Dir    string
+	Expiry time.Duration
+
+	mu sync.Mutex
+	// 書き込み中のアップロード
+	busy map[string]bool
+}
+
+func NewResumableStore(dir string, expiry time.Duration) (*ResumableStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &ResumableStore{Dir: dir, Expiry: expiry, busy: make(map[string]bool)}, nil
+}
+
+func (s *ResumableStore) statePath(id string) string {
+	return filepath.Join(s.Dir, id+".json")
+}
+
+func (s *ResumableStore) dataPath(id string) string {
+	return filepath.Join(s.Dir, id+".bin")
+}
+
+// Create は userID が length バイトを送る枠を作る
+func (s *ResumableStore) Create(userID string, length int64) (*ResumableUpload, error) {
+	id, err := newRandomID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	upload := &ResumableUpload{ID: id, UserID: userID, Length: length, CreatedAt: now, ExpiresAt: now.Add(s.Expiry)}
+	f, err := os.OpenFile(s.dataPath(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	if err := s.writeState(upload); err != nil {
+		os.Remove(s.dataPath(id))
+		return nil, err
+	}
+	return upload, nil
+}
+
+// Get は期限内のアップロードを返す
+func (s *ResumableStore) Get(id string) (*ResumableUpload, error) {
+	if !uploadIDPattern.MatchString(id) {
+		return nil, ErrUploadNotFound
+	}
+	b, err := os.ReadFile(s.statePath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrUploadNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var upload ResumableUpload
+	if err := json.Unmarshal(b, &upload); err != nil {
+		return nil, err
+	}
+	if time.Now().After(upload.ExpiresAt) {
+		return nil, ErrUploadNotFound
+	}
+	return &upload, nil
+}
+
+// Append は offset から r を書き足し、新しい状態を返す
+// checksum があれば合わないときにそのチャンクを捨てる。なければ途中で切れても受け取った分は残す
+func (s *ResumableStore) Append(id string, offset int64, r io.Reader, checksum *UploadChecksum) (*ResumableUpload, error) {
+	if err := s.acquire(id); err != nil {
+		return nil, err
+	}
+	defer s.release(id)
+
+	upload, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if offset != upload.Offset {
+		return upload, ErrUploadOffsetMismatch
+	}
+	f, err := os.OpenFile(s.dataPath(id), os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var h hash.Hash
+	w := io.Writer(f)
+	if checksum != nil {
+		h = uploadChecksumAlgorithms[checksum.Algorithm]()
+		w = io.MultiWriter(f, h)
+	}
+	remaining := upload.Length - offset
+	n, copyErr := io.Copy(w, io.LimitReader(r, remaining+1))
+
+	// 受け取った分を捨てるときは元の長さに戻す
+	rollback := func(cause error) (*ResumableUpload, error) {
+		if err := f.Truncate(offset); err != nil {
+			return nil, err
+		}
+		return upload, cause
+	}
+	if n > remaining {
+		return rollback(ErrUploadTooLong)
+	}
+	if checksum != nil {
+		if copyErr != nil {
+			return rollback(copyErr)
+		}
+		if !bytes.Equal(h.Sum(nil), checksum.Sum) {
+			return rollback(ErrUploadChecksumMismatch)
+		}
+	}
+	upload.Offset += n
+	upload.ExpiresAt = time.Now().Add(s.Expiry)
+	if err := s.writeState(upload); err != nil {
+		return rollback(err)
+	}
+	return upload, copyErr
+}
+
+// Take は送り終えたアップロードを userID の stagedFile として取り出す。取り出したら枠は消える
+func (s *ResumableStore) Take(id, userID string) (*stagedFile, error) {
+	if err := s.acquire(id); err != nil {
+		return nil, err
+	}
+	defer s.release(id)
+
+	upload, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	// 他人の枠は存在しないものとして扱う
+	if upload.UserID != userID {
+		return nil, ErrUploadNotFound
+	}
+	if upload.Offset != upload.Length {
+		return nil, ErrUploadIncomplete
+	}
+	staged, err := stageFile(s.dataPath(id))
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(s.statePath(id))
+	return staged, nil
+}
+
+// Remove は枠を消す (termination)
+func (s *ResumableStore) Remove(id string) error {
+	if err := s.acquire(id); err != nil {
+		return err
+	}
+	defer s.release(id)
+	if _, err := s.Get(id); err != nil {
+		return err
+	}
+	s.remove(id)
+	return nil
+}
+
+func (s *ResumableStore) remove(id string) {
+	os.Remove(s.statePath(id))
+	os.Remove(s.dataPath(id))
+}
+
+// Sweep は期限を過ぎたアップロードと、状態のないデータを消して数を返す
+func (s *ResumableStore) Sweep() int {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		log.Printf("Failed to list resumable uploads: %v", err)
+		return 0
+	}
+	removed := 0
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".bin")
+		if !ok || !uploadIDPattern.MatchString(id) {
+			continue
+		}
+		if s.acquire(id) != nil {
+			continue
+		}
+		if _, err := s.Get(id); errors.Is(err, ErrUploadNotFound) {
+			// Create の途中 (状態を書く前) のものは残す
+			if info, err := entry.Info(); err == nil && time.Since(info.ModTime()) > time.Minute {
+				s.remove(id)
+				removed++
+			}
+		}
+		s.release(id)
+	}
+	return removed
+}
+
+func (s *ResumableStore) acquire(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.busy[id] {
+		return ErrUploadBusy
+	}
+	s.busy[id] = true
+	return nil
+}
+
+func (s *ResumableStore) release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.busy, id)
+}
+
+// writeState は一時ファイルに書いてから置き換える
+func (s *ResumableStore) writeState(upload *ResumableUpload) error {
+	b, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+	tmp := s.statePath(upload.ID) + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.statePath(upload.ID))
+}
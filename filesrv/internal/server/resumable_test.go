@@ -0,0 +1,254 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestResumableStore(t *testing.T) *ResumableStore {
+	t.Helper()
+	store, err := NewResumableStore(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func sha256Checksum(data string) *UploadChecksum {
+	sum := sha256.Sum256([]byte(data))
+	return &UploadChecksum{Algorithm: "sha256", Sum: sum[:]}
+}
+
+func dataSize(t *testing.T, store *ResumableStore, id string) int64 {
+	t.Helper()
+	info, err := os.Stat(store.dataPath(id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Size()
+}
+
+func TestResumableStore_Append(t *testing.T) {
+	store := newTestResumableStore(t)
+	upload, err := store.Create("user-1", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	upload, err = store.Append(upload.ID, 0, strings.NewReader("hello"), sha256Checksum("hello"))
+	if err != nil || upload.Offset != 5 {
+		t.Fatalf("Append = %+v, %v", upload, err)
+	}
+	upload, err = store.Append(upload.ID, 5, strings.NewReader("world"), nil)
+	if err != nil || upload.Offset != 10 {
+		t.Fatalf("Append = %+v, %v", upload, err)
+	}
+
+	got, err := store.Get(upload.ID)
+	if err != nil || got.Offset != 10 {
+		t.Fatalf("Get = %+v, %v", got, err)
+	}
+	data, _ := os.ReadFile(store.dataPath(upload.ID))
+	if string(data) != "helloworld" {
+		t.Errorf("data = %q", data)
+	}
+}
+
+func TestResumableStore_Append_OffsetMismatch(t *testing.T) {
+	store := newTestResumableStore(t)
+	upload, _ := store.Create("user-1", 10)
+	if _, err := store.Append(upload.ID, 0, strings.NewReader("hello"), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// 受け取り済みの位置より前からも後からも書けない
+	for _, offset := range []int64{0, 3, 7} {
+		got, err := store.Append(upload.ID, offset, strings.NewReader("x"), nil)
+		if !errors.Is(err, ErrUploadOffsetMismatch) {
+			t.Errorf("Append at %d = %v, want ErrUploadOffsetMismatch", offset, err)
+		}
+		if got == nil || got.Offset != 5 {
+			t.Errorf("Append at %d returned %+v, want the current offset", offset, got)
+		}
+	}
+	if size := dataSize(t, store, upload.ID); size != 5 {
+		t.Errorf("data size = %d, want 5", size)
+	}
+}
+
+func TestResumableStore_Append_ChecksumMismatch(t *testing.T) {
+	store := newTestResumableStore(t)
+	upload, _ := store.Create("user-1", 10)
+	if _, err := store.Append(upload.ID, 0, strings.NewReader("hello"), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.Append(upload.ID, 5, strings.NewReader("world"), sha256Checksum("WORLD"))
+
+	if !errors.Is(err, ErrUploadChecksumMismatch) {
+		t.Fatalf("Append = %v, want ErrUploadChecksumMismatch", err)
+	}
+	if got.Offset != 5 {
+		t.Errorf("offset = %d, want 5", got.Offset)
+	}
+	// 書いたチャンクは元の長さまで切り詰める
+	if size := dataSize(t, store, upload.ID); size != 5 {
+		t.Errorf("data size = %d, want 5", size)
+	}
+	if stored, _ := store.Get(upload.ID); stored.Offset != 5 {
+		t.Errorf("stored offset = %d, want 5", stored.Offset)
+	}
+
+	// 同じ位置から送り直せる
+	if got, err := store.Append(upload.ID, 5, strings.NewReader("world"), sha256Checksum("world")); err != nil || got.Offset != 10 {
+		t.Errorf("retry = %+v, %v", got, err)
+	}
+}
+
+func TestResumableStore_Append_TooLong(t *testing.T) {
+	store := newTestResumableStore(t)
+	upload, _ := store.Create("user-1", 10)
+	if _, err := store.Append(upload.ID, 0, strings.NewReader("hello"), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.Append(upload.ID, 5, strings.NewReader("world!"), nil)
+
+	if !errors.Is(err, ErrUploadTooLong) {
+		t.Fatalf("Append = %v, want ErrUploadTooLong", err)
+	}
+	if got.Offset != 5 {
+		t.Errorf("offset = %d, want 5", got.Offset)
+	}
+	if size := dataSize(t, store, upload.ID); size != 5 {
+		t.Errorf("data size = %d, want 5", size)
+	}
+}
+
+func TestResumableStore_Append_Busy(t *testing.T) {
+	store := newTestResumableStore(t)
+	upload, _ := store.Create("user-1", 10)
+
+	pr, pw := io.Pipe()
+	done := make(chan *ResumableUpload)
+	go func() {
+		got, _ := store.Append(upload.ID, 0, pr, nil)
+		done <- got
+	}()
+	// 読まれるまで戻らないので、ここでは最初の PATCH が書き込み中
+	pw.Write([]byte("he"))
+
+	if _, err := store.Append(upload.ID, 0, strings.NewReader("hello"), nil); !errors.Is(err, ErrUploadBusy) {
+		t.Errorf("concurrent Append = %v, want ErrUploadBusy", err)
+	}
+	if _, err := store.Take(upload.ID, "user-1"); !errors.Is(err, ErrUploadBusy) {
+		t.Errorf("Take while writing = %v, want ErrUploadBusy", err)
+	}
+
+	pw.Close()
+	if got := <-done; got == nil || got.Offset != 2 {
+		t.Fatalf("first Append = %+v", got)
+	}
+	// 終われば続きを書ける
+	if got, err := store.Append(upload.ID, 2, strings.NewReader("llo"), nil); err != nil || got.Offset != 5 {
+		t.Errorf("Append after release = %+v, %v", got, err)
+	}
+}
+
+func TestResumableStore_Take(t *testing.T) {
+	store := newTestResumableStore(t)
+	upload, _ := store.Create("user-1", 5)
+
+	if _, err := store.Take(upload.ID, "user-1"); !errors.Is(err, ErrUploadIncomplete) {
+		t.Errorf("Take before complete = %v, want ErrUploadIncomplete", err)
+	}
+	if _, err := store.Append(upload.ID, 0, strings.NewReader("hello"), nil); err != nil {
+		t.Fatal(err)
+	}
+	// 他人の枠は存在しないものとして扱う
+	if _, err := store.Take(upload.ID, "user-2"); !errors.Is(err, ErrUploadNotFound) {
+		t.Errorf("Take by another user = %v, want ErrUploadNotFound", err)
+	}
+
+	staged, err := store.Take(upload.ID, "user-1")
+	if err != nil {
+		t.Fatalf("Take = %v", err)
+	}
+	defer staged.Close()
+	data, _ := io.ReadAll(staged.Reader())
+	if string(data) != "hello" || staged.Size != 5 {
+		t.Errorf("staged = %q (%d bytes)", data, staged.Size)
+	}
+	// 取り出したら枠は消える
+	if _, err := store.Get(upload.ID); !errors.Is(err, ErrUploadNotFound) {
+		t.Errorf("Get after Take = %v, want ErrUploadNotFound", err)
+	}
+}
+
+func TestResumableStore_Sweep(t *testing.T) {
+	store := newTestResumableStore(t)
+	old := time.Now().Add(-time.Hour)
+
+	live, _ := store.Create("user-1", 5)
+
+	expired, _ := store.Create("user-1", 5)
+	expired.ExpiresAt = time.Now().Add(-time.Second)
+	if err := store.writeState(expired); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(store.dataPath(expired.ID), old, old)
+
+	// 状態を書く前の Create の途中
+	creating := strings.Repeat("a", 32)
+	if err := os.WriteFile(store.dataPath(creating), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// 状態が書かれないまま残ったデータ
+	orphan := strings.Repeat("b", 32)
+	if err := os.WriteFile(store.dataPath(orphan), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(store.dataPath(orphan), old, old)
+
+	removed := store.Sweep()
+
+	if removed != 2 {
+		t.Errorf("removed = %d, want 2", removed)
+	}
+	for _, id := range []string{expired.ID, orphan} {
+		if _, err := os.Stat(store.dataPath(id)); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s was not removed", id)
+		}
+	}
+	if _, err := os.Stat(store.statePath(expired.ID)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("state of the expired upload was not removed")
+	}
+	if _, err := store.Get(live.ID); err != nil {
+		t.Errorf("live upload was removed: %v", err)
+	}
+	if _, err := os.Stat(store.dataPath(creating)); err != nil {
+		t.Errorf("upload being created was removed: %v", err)
+	}
+}
+
+func TestParseUploadChecksum(t *testing.T) {
+	sum := sha256.Sum256([]byte("hello"))
+	encoded := base64.StdEncoding.EncodeToString(sum[:])
+
+	checksum, err := ParseUploadChecksum("sha256 " + encoded)
+	if err != nil || checksum.Algorithm != "sha256" || !bytes.Equal(checksum.Sum, sum[:]) {
+		t.Errorf("ParseUploadChecksum = %+v, %v", checksum, err)
+	}
+	for _, header := range []string{"sha256", "md5 " + encoded, "sha256 !!!"} {
+		if _, err := ParseUploadChecksum(header); err == nil {
+			t.Errorf("ParseUploadChecksum(%q) was accepted", header)
+		}
+	}
+}
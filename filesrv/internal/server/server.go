@@ -3,50 +3,156 @@ package server
 import (
 	"context"
 	"fmt"
+	"log"
 	"net/http"
-	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"log"
+	"github.com/icchon/matcha/filesrv/internal/storage"
 )
 
 type ServerConfig struct {
 	ServerAddress string
 	UploadDir     string
 	BaseUrl       string
+	AttachmentDir string
+	// api と共有する、添付ファイルと画像の URL 署名用の鍵
+	URLSigningKey string
+	// 参照されなくなった画像を GC する間隔と、消すまでの猶予期間
+	AssetGCInterval    time.Duration
+	AssetGCGracePeriod time.Duration
+
+	// "local" (UploadDir / AttachmentDir) か "s3"。空なら local
+	StorageBackend string
+	// s3 のとき。画像は images/、添付ファイルは attachments/ の下に置く
+	S3 storage.S3Config
+	// ストレージが署名付き URL を発行できるとき、/images をその URL にリダイレクトする。0 なら中継する
+	PresignTTL time.Duration
+
+	// true なら /images の thumbnail は署名なしで返す。card と full はいつも署名が要る
+	PublicThumbnails bool
+
+	// api と共有する、書き込み系エンドポイントのサービス間認証の鍵
+	ServiceAuthKey string
+	// 受け付ける画像の最大画素数 (幅 x 高さ)
+	MaxImagePixels int
+	// 画像の変種を同時に作る数。1 つで最大 MaxImagePixels x 10 バイト程度のメモリを使う
+	MaxConcurrentImageBuilds int
+	// ユーザーごとに UploadQuotaWindow の間に受け付けるアップロードの回数とバイト数。0 なら制限しない
+	UploadQuotaCount  int
+	UploadQuotaBytes  int64
+	UploadQuotaWindow time.Duration
+
+	// 受けている途中のアップロードを置くローカルのディレクトリ
+	UploadStagingDir string
+	// 再開可能アップロードを最後に受け取ってから捨てるまでの時間
+	ResumableUploadExpiry time.Duration
 }
 
+// 1 ファイルの最大サイズ
+const maxUploadSize = 10 << 20
+
 type Server struct {
 	router     *chi.Mux
 	config     *ServerConfig
 	httpServer *http.Server
+	assets     *AssetStore
+	uploads    *ResumableStore
+	stopGC     chan struct{}
+}
+
+// newStorages は設定どおりに画像用と添付ファイル用のストレージを作る
+func newStorages(config *ServerConfig) (images storage.Storage, attachments storage.Storage, err error) {
+	switch config.StorageBackend {
+	case "", "local":
+		if images, err = storage.NewLocalStorage(config.UploadDir); err != nil {
+			return nil, nil, err
+		}
+		if attachments, err = storage.NewLocalStorage(config.AttachmentDir); err != nil {
+			return nil, nil, err
+		}
+		return images, attachments, nil
+	case "s3":
+		imagesConfig, attachmentsConfig := config.S3, config.S3
+		imagesConfig.Prefix = "images/"
+		attachmentsConfig.Prefix = "attachments/"
+		if images, err = storage.NewS3Storage(imagesConfig); err != nil {
+			return nil, nil, err
+		}
+		if attachments, err = storage.NewS3Storage(attachmentsConfig); err != nil {
+			return nil, nil, err
+		}
+		return images, attachments, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown storage backend: %q", config.StorageBackend)
+	}
 }
 
 func NewServer(config *ServerConfig) *Server {
+	imageStorage, attachmentStorage, err := newStorages(config)
+	if err != nil {
+		log.Printf("Failed to set up storage: %v", err)
+		return nil
+	}
 
-	if _, err := os.Stat(config.UploadDir); os.IsNotExist(err) {
-		if err := os.Mkdir(config.UploadDir, 0755); err != nil {
-			fmt.Printf("Failed to create uploads directory: %v\n", err)
-			return nil
-		}
+	uploads, err := NewResumableStore(filepath.Join(config.UploadStagingDir, "resumable"), config.ResumableUploadExpiry)
+	if err != nil {
+		log.Printf("Failed to set up upload staging: %v", err)
+		return nil
 	}
 
 	r := chi.NewRouter()
 
 	server := &Server{
-		router: r,
-		config: config,
+		router:  r,
+		config:  config,
+		assets:  NewAssetStore(imageStorage),
+		uploads: uploads,
+		stopGC:  make(chan struct{}),
 	}
 
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		writeNotFound(w)
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusMethodNotAllowed, ErrCodeInvalidRequest, "Method not allowed")
+	})
 
-	h := NewHandler(config.UploadDir, config.BaseUrl)
-	r.Post("/upload", h.UploadImageHandler)
-	r.Get("/dog", h.RandomDogHandler)
-	fileServer(r, "/images", http.Dir(config.UploadDir))
+	limits := &UploadLimits{
+		MaxBytes:   maxUploadSize,
+		MaxPixels:  config.MaxImagePixels,
+		Quota:      NewUploadQuota(config.UploadQuotaCount, config.UploadQuotaBytes, config.UploadQuotaWindow),
+		StagingDir: config.UploadStagingDir,
+	}
+	serviceAuth := ServiceAuthMiddleware([]byte(config.ServiceAuthKey))
+
+	h := NewHandler(config.BaseUrl, server.assets, limits, config.PresignTTL, config.URLSigningKey, config.PublicThumbnails, NewImageBuildLimiter(config.MaxConcurrentImageBuilds))
+	ah := NewAttachmentHandler(attachmentStorage, config.URLSigningKey, limits)
+	rh := NewResumableHandler(config.BaseUrl, uploads, limits, h, ah)
+
+	// 書き込みは api からだけ受け付ける
+	r.Group(func(r chi.Router) {
+		r.Use(serviceAuth)
+		r.Post("/upload", h.UploadImageHandler)
+		r.Get("/assets/{assetID}/{variant:thumbnail|card|full}", h.GetAssetVariantHandler)
+		r.Delete("/assets/{assetID}", h.DeleteAssetHandler)
+		r.Post("/attachments", ah.UploadAttachmentHandler)
+		r.Post("/uploads", rh.CreateHandler)
+		r.Post("/uploads/{uploadID}/image", rh.CompleteImageHandler)
+		r.Post("/uploads/{uploadID}/attachment", rh.CompleteAttachmentHandler)
+	})
+	// 送信自体はクライアントから直接受ける。ID を知っていることが権限になる
+	r.Options("/uploads", rh.OptionsHandler)
+	r.Head("/uploads/{uploadID}", rh.HeadHandler)
+	r.Patch("/uploads/{uploadID}", rh.PatchHandler)
+	r.Delete("/uploads/{uploadID}", rh.DeleteHandler)
+	r.Get("/images/*", h.ServeImageHandler)
+	r.Get("/avatars/{seed}", h.AvatarHandler)
+	r.Get("/attachments/{attachmentID}", ah.GetAttachmentHandler)
 	return server
 }
 
@@ -59,6 +165,8 @@ func (s *Server) Start() error {
 		IdleTimeout:  120 * time.Second,
 	}
 
+	go s.runAssetGC()
+
 	log.Printf("Starting HTTP server on %s", s.config.ServerAddress)
 	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("could not listen on %s: %v", s.config.ServerAddress, err)
@@ -68,13 +176,29 @@ func (s *Server) Start() error {
 
 func (s *Server) Shutdown(ctx context.Context) error {
 	log.Println("Shutting down server gracefully...")
+	close(s.stopGC)
 	return s.httpServer.Shutdown(ctx)
 }
 
-func fileServer(r chi.Router, path string, root http.FileSystem) {
-	if path != "/" && path[len(path)-1] != '/' {
-		r.Get(path, http.RedirectHandler(path+"/", http.StatusMovedPermanently).ServeHTTP)
-		path += "/"
+// runAssetGC は参照数 0 のまま猶予期間を過ぎた画像と、期限を過ぎた再開可能アップロードを定期的に消す
+func (s *Server) runAssetGC() {
+	ticker := time.NewTicker(s.config.AssetGCInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopGC:
+			return
+		case <-ticker.C:
+			removed, err := s.assets.Sweep(context.Background(), s.config.AssetGCGracePeriod)
+			if err != nil {
+				log.Printf("Asset GC failed: %v", err)
+			}
+			if removed > 0 {
+				log.Printf("Asset GC removed %d assets", removed)
+			}
+			if removed := s.uploads.Sweep(); removed > 0 {
+				log.Printf("Removed %d abandoned uploads", removed)
+			}
+		}
 	}
-	r.Handle(path+"*", http.StripPrefix(path, http.FileServer(root)))
 }
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const (
+	testServiceAuthKey = "service-key"
+	testURLSigningKey  = "url-key"
+)
+
+// newTestServer はローカルのストレージで組み立てたサーバーを返す
+func newTestServer(t *testing.T, configure func(*ServerConfig)) *Server {
+	t.Helper()
+	config := &ServerConfig{
+		UploadDir:             t.TempDir(),
+		AttachmentDir:         t.TempDir(),
+		UploadStagingDir:      t.TempDir(),
+		BaseUrl:               "http://files.example.com",
+		URLSigningKey:         testURLSigningKey,
+		ServiceAuthKey:        testServiceAuthKey,
+		ResumableUploadExpiry: time.Hour,
+	}
+	if configure != nil {
+		configure(config)
+	}
+	s := NewServer(config)
+	if s == nil {
+		t.Fatal("NewServer failed")
+	}
+	return s
+}
+
+func serve(s *Server, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, r)
+	return w
+}
+
+// serviceRequest は api と同じ署名を付けたリクエストを作る
+func serviceRequest(method, path, userID string, body io.Reader) *http.Request {
+	r := httptest.NewRequest(method, path, body)
+	timestamp := time.Now().Unix()
+	r.Header.Set(ServiceTimestampHeader, strconv.FormatInt(timestamp, 10))
+	r.Header.Set(ServiceUserHeader, userID)
+	r.Header.Set(ServiceSignatureHeader, SignServiceRequest([]byte(testServiceAuthKey), method, r.URL.Path, timestamp, userID))
+	return r
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
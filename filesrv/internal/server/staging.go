@@ -0,0 +1,67 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+)
+
+// アップロードはメモリに溜めず、受けながら一時ファイルに書いて SHA-256 を計算する
+// 検証・変種の生成・ストレージへの保存は一時ファイルから読む
+
+var errUploadTooLarge = errors.New("upload too large")
+
+// stagedFile は一時ファイルに受けたアップロード。使い終わったら Close で消す
+type stagedFile struct {
+	file *os.File
+	Size int64
+	// 中身の SHA-256 (hex)。画像の asset ID になる
+	Sum string
+}
+
+// stageUpload は r を maxBytes まで dir の一時ファイルに書く。超えたら errUploadTooLarge
+func stageUpload(dir string, r io.Reader, maxBytes int64) (*stagedFile, error) {
+	f, err := os.CreateTemp(dir, "upload-*")
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(r, maxBytes+1))
+	if err == nil && n > maxBytes {
+		err = errUploadTooLarge
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return &stagedFile{file: f, Size: n, Sum: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// stageFile は受け終わったファイルをそのまま stagedFile にする。path は Close で消える
+func stageFile(path string) (*stagedFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &stagedFile{file: f, Size: n, Sum: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// Reader は先頭から読む Reader を返す。呼ぶたびに先頭からになる
+func (s *stagedFile) Reader() io.ReadSeeker {
+	return io.NewSectionReader(s.file, 0, s.Size)
+}
+
+func (s *stagedFile) Close() error {
+	err := s.file.Close()
+	os.Remove(s.file.Name())
+	return err
+}
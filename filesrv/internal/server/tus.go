@@ -0,0 +1,214 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// tus 1.0.0 のヘッダー
+const (
+	tusVersion          = "1.0.0"
+	tusExtensions       = "creation,expiration,checksum,termination"
+	tusChecksumAlgos    = "sha1,sha256"
+	tusOffsetStreamType = "application/offset+octet-stream"
+	// tus の checksum 拡張で決められたステータス
+	statusChecksumMismatch = 460
+)
+
+type ResumableHandler struct {
+	BaseUrl     string
+	Store       *ResumableStore
+	Limits      *UploadLimits
+	Images      *Handler
+	Attachments *AttachmentHandler
+}
+
+func NewResumableHandler(baseUrl string, store *ResumableStore, limits *UploadLimits, images *Handler, attachments *AttachmentHandler) *ResumableHandler {
+	return &ResumableHandler{
+		BaseUrl:     baseUrl,
+		Store:       store,
+		Limits:      limits,
+		Images:      images,
+		Attachments: attachments,
+	}
+}
+
+type ResumableUploadResponse struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Length    int64     `json:"length"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (h *ResumableHandler) uploadURL(id string) string {
+	return fmt.Sprintf("%s/uploads/%s", h.BaseUrl, id)
+}
+
+func setTusHeaders(w http.ResponseWriter, upload *ResumableUpload) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.Header().Set("Cache-Control", "no-store")
+	if upload != nil {
+		w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+		w.Header().Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+		w.Header().Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+// OPTIONS /uploads
+func (h *ResumableHandler) OptionsHandler(w http.ResponseWriter, r *http.Request) {
+	setTusHeaders(w, nil)
+	w.Header().Set("Tus-Version", tusVersion)
+	w.Header().Set("Tus-Extension", tusExtensions)
+	w.Header().Set("Tus-Max-Size", strconv.FormatInt(h.Limits.MaxBytes, 10))
+	w.Header().Set("Tus-Checksum-Algorithm", tusChecksumAlgos)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /uploads (api から。Upload-Length で長さを宣言する)
+func (h *ResumableHandler) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	userID := serviceUser(r)
+	if userID == "" {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Missing uploading user")
+		return
+	}
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length <= 0 {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid Upload-Length")
+		return
+	}
+	if length > h.Limits.MaxBytes {
+		writeError(w, http.StatusRequestEntityTooLarge, ErrCodeTooLarge, fmt.Sprintf("File must be at most %d bytes", h.Limits.MaxBytes))
+		return
+	}
+	upload, err := h.Store.Create(userID, length)
+	if err != nil {
+		log.Printf("Failed to create resumable upload: %v", err)
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Error creating upload")
+		return
+	}
+	setTusHeaders(w, upload)
+	w.Header().Set("Location", h.uploadURL(upload.ID))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ResumableUploadResponse{ID: upload.ID, URL: h.uploadURL(upload.ID), Length: upload.Length, ExpiresAt: upload.ExpiresAt})
+}
+
+// HEAD /uploads/{uploadID}
+func (h *ResumableHandler) HeadHandler(w http.ResponseWriter, r *http.Request) {
+	upload, err := h.Store.Get(chi.URLParam(r, "uploadID"))
+	if err != nil {
+		h.writeStoreError(w, err)
+		return
+	}
+	setTusHeaders(w, upload)
+	w.WriteHeader(http.StatusOK)
+}
+
+// PATCH /uploads/{uploadID}
+// Upload-Offset から本文を書き足す。Upload-Checksum があればチャンクごとに確かめる
+func (h *ResumableHandler) PatchHandler(w http.ResponseWriter, r *http.Request) {
+	// tus ではエラーの応答にも Tus-Resumable が要る
+	setTusHeaders(w, nil)
+	if r.Header.Get("Tus-Resumable") != tusVersion {
+		w.Header().Set("Tus-Version", tusVersion)
+		writeError(w, http.StatusPreconditionFailed, ErrCodeInvalidRequest, "Unsupported Tus-Resumable version")
+		return
+	}
+	if r.Header.Get("Content-Type") != tusOffsetStreamType {
+		writeError(w, http.StatusUnsupportedMediaType, ErrCodeUnsupportedMediaType, "Content-Type must be "+tusOffsetStreamType)
+		return
+	}
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid Upload-Offset")
+		return
+	}
+	var checksum *UploadChecksum
+	if header := r.Header.Get("Upload-Checksum"); header != "" {
+		if checksum, err = ParseUploadChecksum(header); err != nil {
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+			return
+		}
+	}
+
+	upload, err := h.Store.Append(chi.URLParam(r, "uploadID"), offset, r.Body, checksum)
+	if err != nil {
+		h.writeStoreError(w, err)
+		return
+	}
+	setTusHeaders(w, upload)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DELETE /uploads/{uploadID}
+func (h *ResumableHandler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.Store.Remove(chi.URLParam(r, "uploadID")); err != nil {
+		h.writeStoreError(w, err)
+		return
+	}
+	setTusHeaders(w, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /uploads/{uploadID}/image (api から)
+// 送り終えたアップロードを検証して、POST /upload と同じように画像として保存する
+func (h *ResumableHandler) CompleteImageHandler(w http.ResponseWriter, r *http.Request) {
+	upload, ok := h.take(w, r)
+	if !ok {
+		return
+	}
+	defer upload.Close()
+	h.Images.saveImage(w, r, upload)
+}
+
+// POST /uploads/{uploadID}/attachment (api から)
+func (h *ResumableHandler) CompleteAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	upload, ok := h.take(w, r)
+	if !ok {
+		return
+	}
+	defer upload.Close()
+	h.Attachments.saveAttachment(w, r, upload)
+}
+
+func (h *ResumableHandler) take(w http.ResponseWriter, r *http.Request) (*validUpload, bool) {
+	staged, err := h.Store.Take(chi.URLParam(r, "uploadID"), serviceUser(r))
+	if err != nil {
+		h.writeStoreError(w, err)
+		return nil, false
+	}
+	upload, uerr := h.Limits.accept(r, staged)
+	if uerr != nil {
+		writeError(w, uerr.Status, uerr.Code, uerr.Message)
+		return nil, false
+	}
+	return upload, true
+}
+
+func (h *ResumableHandler) writeStoreError(w http.ResponseWriter, err error) {
+	setTusHeaders(w, nil)
+	switch {
+	case errors.Is(err, ErrUploadNotFound):
+		writeNotFound(w)
+	case errors.Is(err, ErrUploadOffsetMismatch):
+		writeError(w, http.StatusConflict, ErrCodeInvalidRequest, "Upload-Offset does not match the received length")
+	case errors.Is(err, ErrUploadBusy):
+		writeError(w, http.StatusConflict, ErrCodeInvalidRequest, "Upload is being written by another request")
+	case errors.Is(err, ErrUploadIncomplete):
+		writeError(w, http.StatusConflict, ErrCodeInvalidRequest, "Upload is not complete")
+	case errors.Is(err, ErrUploadChecksumMismatch):
+		writeError(w, statusChecksumMismatch, ErrCodeInvalidRequest, "Checksum mismatch")
+	case errors.Is(err, ErrUploadTooLong):
+		writeError(w, http.StatusRequestEntityTooLarge, ErrCodeTooLarge, "Upload exceeds Upload-Length")
+	default:
+		log.Printf("Resumable upload failed: %v", err)
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Error writing upload")
+	}
+}
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// createUpload は api と同じように POST /uploads で枠を作る
+func createUpload(t *testing.T, s *Server, userID string, length int) string {
+	t.Helper()
+	r := serviceRequest(http.MethodPost, "/uploads", userID, nil)
+	r.Header.Set("Upload-Length", strconv.Itoa(length))
+	w := serve(s, r)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST /uploads = %d %s", w.Code, w.Body)
+	}
+	var res ResumableUploadResponse
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	if w.Header().Get("Location") != res.URL || res.URL != "http://files.example.com/uploads/"+res.ID {
+		t.Errorf("upload URL = %s, Location = %s", res.URL, w.Header().Get("Location"))
+	}
+	return res.ID
+}
+
+func patchRequest(id string, offset int, body io.Reader) *http.Request {
+	r := httptest.NewRequest(http.MethodPatch, "/uploads/"+id, body)
+	r.Header.Set("Tus-Resumable", tusVersion)
+	r.Header.Set("Content-Type", tusOffsetStreamType)
+	r.Header.Set("Upload-Offset", strconv.Itoa(offset))
+	return r
+}
+
+func headOffset(t *testing.T, s *Server, id string) string {
+	t.Helper()
+	w := serve(s, httptest.NewRequest(http.MethodHead, "/uploads/"+id, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("HEAD = %d", w.Code)
+	}
+	return w.Header().Get("Upload-Offset")
+}
+
+func TestResumableHandler_UploadImage(t *testing.T) {
+	s := newTestServer(t, nil)
+	data := testPNG(t, 40, 30)
+	id := createUpload(t, s, "user-1", len(data))
+
+	// 途中で切れたら HEAD で位置を聞いて続きから送る
+	half := len(data) / 2
+	if w := serve(s, patchRequest(id, 0, bytes.NewReader(data[:half]))); w.Code != http.StatusNoContent {
+		t.Fatalf("PATCH = %d %s", w.Code, w.Body)
+	}
+	if got := headOffset(t, s, id); got != strconv.Itoa(half) {
+		t.Fatalf("Upload-Offset = %s, want %d", got, half)
+	}
+	w := serve(s, patchRequest(id, half, bytes.NewReader(data[half:])))
+	if w.Code != http.StatusNoContent || w.Header().Get("Upload-Offset") != strconv.Itoa(len(data)) {
+		t.Fatalf("PATCH = %d, Upload-Offset %s", w.Code, w.Header().Get("Upload-Offset"))
+	}
+
+	w = serve(s, serviceRequest(http.MethodPost, "/uploads/"+id+"/image", "user-1", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("complete = %d %s", w.Code, w.Body)
+	}
+	var res ImageUploadResponse
+	json.NewDecoder(w.Body).Decode(&res)
+	sum := sha256.Sum256(data)
+	if res.AssetID != hex.EncodeToString(sum[:]) {
+		t.Errorf("asset ID = %s, want the SHA-256 of the image", res.AssetID)
+	}
+	// 取り出したら枠は消える
+	if w := serve(s, httptest.NewRequest(http.MethodHead, "/uploads/"+id, nil)); w.Code != http.StatusNotFound {
+		t.Errorf("HEAD after complete = %d, want 404", w.Code)
+	}
+}
+
+func TestResumableHandler_Patch(t *testing.T) {
+	checksum := func(data string) string {
+		sum := sha256.Sum256([]byte(data))
+		return "sha256 " + base64.StdEncoding.EncodeToString(sum[:])
+	}
+
+	testCases := []struct {
+		name       string
+		offset     int
+		body       string
+		checksum   string
+		wantStatus int
+	}{
+		{name: "Next chunk", offset: 5, body: "world", checksum: checksum("world"), wantStatus: http.StatusNoContent},
+		{name: "Offset mismatch", offset: 3, body: "world", wantStatus: http.StatusConflict},
+		{name: "Checksum mismatch", offset: 5, body: "world", checksum: checksum("WORLD"), wantStatus: statusChecksumMismatch},
+		{name: "Past Upload-Length", offset: 5, body: "world!", wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "Unsupported checksum", offset: 5, body: "world", checksum: "md5 AAAA", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestServer(t, nil)
+			id := createUpload(t, s, "user-1", 10)
+			if w := serve(s, patchRequest(id, 0, bytes.NewReader([]byte("hello")))); w.Code != http.StatusNoContent {
+				t.Fatalf("first PATCH = %d", w.Code)
+			}
+
+			r := patchRequest(id, tc.offset, bytes.NewReader([]byte(tc.body)))
+			if tc.checksum != "" {
+				r.Header.Set("Upload-Checksum", tc.checksum)
+			}
+			w := serve(s, r)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("PATCH = %d %s, want %d", w.Code, w.Body, tc.wantStatus)
+			}
+			if w.Header().Get("Tus-Resumable") != tusVersion {
+				t.Errorf("Tus-Resumable = %q", w.Header().Get("Tus-Resumable"))
+			}
+			// 失敗したチャンクは捨て、受け取り済みの位置は変わらない
+			want := "5"
+			if tc.wantStatus == http.StatusNoContent {
+				want = "10"
+			}
+			if got := headOffset(t, s, id); got != want {
+				t.Errorf("Upload-Offset = %s, want %s", got, want)
+			}
+			if size := dataSize(t, s.uploads, id); strconv.FormatInt(size, 10) != want {
+				t.Errorf("data size = %d, want %s", size, want)
+			}
+		})
+	}
+}
+
+func TestResumableHandler_Patch_Busy(t *testing.T) {
+	s := newTestServer(t, nil)
+	id := createUpload(t, s, "user-1", 10)
+
+	pr, pw := io.Pipe()
+	done := make(chan int)
+	go func() {
+		done <- serve(s, patchRequest(id, 0, pr)).Code
+	}()
+	// 読まれるまで戻らないので、ここでは最初の PATCH が書き込み中
+	pw.Write([]byte("he"))
+
+	w := serve(s, patchRequest(id, 0, bytes.NewReader([]byte("hello"))))
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("concurrent PATCH = %d, want 409", w.Code)
+	}
+	var res ErrorResponse
+	json.NewDecoder(w.Body).Decode(&res)
+	if res.Message != "Upload is being written by another request" {
+		t.Errorf("message = %q", res.Message)
+	}
+	pw.Close()
+	if code := <-done; code != http.StatusNoContent {
+		t.Errorf("first PATCH = %d", code)
+	}
+	if got := headOffset(t, s, id); got != "2" {
+		t.Errorf("Upload-Offset = %s, want 2", got)
+	}
+}
+
+func TestResumableHandler_Complete(t *testing.T) {
+	data := testPNG(t, 10, 10)
+
+	testCases := []struct {
+		name       string
+		userID     string
+		sent       int
+		wantStatus int
+	}{
+		{name: "Another user's upload", userID: "user-2", sent: len(data), wantStatus: http.StatusNotFound},
+		{name: "Incomplete upload", userID: "user-1", sent: len(data) - 1, wantStatus: http.StatusConflict},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestServer(t, nil)
+			id := createUpload(t, s, "user-1", len(data))
+			if w := serve(s, patchRequest(id, 0, bytes.NewReader(data[:tc.sent]))); w.Code != http.StatusNoContent {
+				t.Fatalf("PATCH = %d", w.Code)
+			}
+
+			w := serve(s, serviceRequest(http.MethodPost, "/uploads/"+id+"/image", tc.userID, nil))
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("complete = %d %s, want %d", w.Code, w.Body, tc.wantStatus)
+			}
+			// 持ち主はまだ使える
+			if got := headOffset(t, s, id); got != strconv.Itoa(tc.sent) {
+				t.Errorf("Upload-Offset = %s, want %d", got, tc.sent)
+			}
+		})
+	}
+}
+
+func TestResumableHandler_Create(t *testing.T) {
+	s := newTestServer(t, nil)
+
+	testCases := []struct {
+		name       string
+		length     string
+		wantStatus int
+	}{
+		{name: "Missing length", length: "", wantStatus: http.StatusBadRequest},
+		{name: "Zero length", length: "0", wantStatus: http.StatusBadRequest},
+		{name: "Too large", length: strconv.Itoa(maxUploadSize + 1), wantStatus: http.StatusRequestEntityTooLarge},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := serviceRequest(http.MethodPost, "/uploads", "user-1", nil)
+			r.Header.Set("Upload-Length", tc.length)
+
+			if w := serve(s, r); w.Code != tc.wantStatus {
+				t.Errorf("POST /uploads = %d, want %d", w.Code, tc.wantStatus)
+			}
+		})
+	}
+
+	// 枠はクライアントからは作れない
+	r := httptest.NewRequest(http.MethodPost, "/uploads", nil)
+	r.Header.Set("Upload-Length", "10")
+	if w := serve(s, r); w.Code != http.StatusUnauthorized {
+		t.Errorf("unsigned POST /uploads = %d, want 401", w.Code)
+	}
+}
+
+func TestResumableHandler_Delete(t *testing.T) {
+	s := newTestServer(t, nil)
+	id := createUpload(t, s, "user-1", 10)
+
+	if w := serve(s, httptest.NewRequest(http.MethodDelete, "/uploads/"+id, nil)); w.Code != http.StatusNoContent {
+		t.Fatalf("DELETE = %d", w.Code)
+	}
+	if w := serve(s, patchRequest(id, 0, bytes.NewReader([]byte("hello")))); w.Code != http.StatusNotFound {
+		t.Errorf("PATCH after DELETE = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+)
+
+// アップロードされた画像は中身の先頭バイトで形式を判定し、許可した形式だけ受け付ける
+// 画素数は DecodeConfig (ヘッダーだけ読む) で確かめてから全体をデコードする
+
+type imageSignature struct {
+	Mime   string
+	Format string
+	Magic  []byte
+}
+
+var allowedImageSignatures = []imageSignature{
+	{Mime: "image/jpeg", Format: "jpeg", Magic: []byte{0xFF, 0xD8, 0xFF}},
+	{Mime: "image/png", Format: "png", Magic: []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'}},
+	{Mime: "image/gif", Format: "gif", Magic: []byte("GIF87a")},
+	{Mime: "image/gif", Format: "gif", Magic: []byte("GIF89a")},
+}
+
+// uploadError はクライアントに返す理由を持つ
+type uploadError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *uploadError) Error() string {
+	return e.Message
+}
+
+func sniffImage(data []byte) (imageSignature, bool) {
+	for _, sig := range allowedImageSignatures {
+		if bytes.HasPrefix(data, sig.Magic) {
+			return sig, true
+		}
+	}
+	return imageSignature{}, false
+}
+
+// validateImage は形式と画素数を確かめ、MIME タイプと大きさを返す
+func validateImage(r io.ReadSeeker, maxPixels int) (string, image.Config, *uploadError) {
+	head := make([]byte, 8)
+	n, _ := io.ReadFull(r, head)
+	sig, ok := sniffImage(head[:n])
+	if !ok {
+		return "", image.Config{}, &uploadError{http.StatusUnsupportedMediaType, ErrCodeUnsupportedMediaType, "Only JPEG, PNG and GIF images are accepted"}
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", image.Config{}, &uploadError{http.StatusInternalServerError, ErrCodeInternal, "Error reading file"}
+	}
+	cfg, format, err := image.DecodeConfig(r)
+	if err != nil || format != sig.Format {
+		return "", image.Config{}, &uploadError{http.StatusBadRequest, ErrCodeInvalidRequest, "Image could not be read"}
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return "", image.Config{}, &uploadError{http.StatusBadRequest, ErrCodeInvalidRequest, "Image could not be read"}
+	}
+	// maxPixels が 0 なら画素数は制限しない
+	if maxPixels > 0 && cfg.Width > maxPixels/cfg.Height {
+		return "", image.Config{}, &uploadError{http.StatusUnprocessableEntity, ErrCodeTooManyPixels, fmt.Sprintf("Image must be at most %d pixels", maxPixels)}
+	}
+	return sig.Mime, cfg, nil
+}
+
+// UploadLimits は画像と添付ファイルのアップロードに共通の制限
+type UploadLimits struct {
+	MaxBytes  int64
+	MaxPixels int
+	Quota     *UploadQuota
+	// 受けている途中のアップロードを置くディレクトリ
+	StagingDir string
+}
+
+// validUpload は検証を通ったアップロード。使い終わったら Close する
+type validUpload struct {
+	*stagedFile
+	Mime   string
+	Config image.Config
+}
+
+// readUpload は multipart の field を一時ファイルに流し込み、形式・画素数・ユーザーの上限を確かめる
+// field より前のパートは読み捨てる
+func (l *UploadLimits) readUpload(w http.ResponseWriter, r *http.Request, field string) (*validUpload, *uploadError) {
+	r.Body = http.MaxBytesReader(w, r.Body, l.MaxBytes+1<<20)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, &uploadError{http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid multipart form"}
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, &uploadError{http.StatusBadRequest, ErrCodeInvalidRequest, fmt.Sprintf("Missing %q file", field)}
+		}
+		if err != nil {
+			return nil, l.readError(err)
+		}
+		if part.FormName() != field || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+		staged, err := stageUpload(l.StagingDir, part, l.MaxBytes)
+		part.Close()
+		if err != nil {
+			return nil, l.readError(err)
+		}
+		return l.accept(r, staged)
+	}
+}
+
+func (l *UploadLimits) readError(err error) *uploadError {
+	var maxErr *http.MaxBytesError
+	if errors.Is(err, errUploadTooLarge) || errors.As(err, &maxErr) {
+		return &uploadError{http.StatusRequestEntityTooLarge, ErrCodeTooLarge, fmt.Sprintf("File must be at most %d bytes", l.MaxBytes)}
+	}
+	return &uploadError{http.StatusBadRequest, ErrCodeInvalidRequest, "Error reading upload"}
+}
+
+// accept は受け終わったファイルを検証し、上限に数える。通らなければ staged を消す
+func (l *UploadLimits) accept(r *http.Request, staged *stagedFile) (*validUpload, *uploadError) {
+	if staged.Size > l.MaxBytes {
+		staged.Close()
+		return nil, &uploadError{http.StatusRequestEntityTooLarge, ErrCodeTooLarge, fmt.Sprintf("File must be at most %d bytes", l.MaxBytes)}
+	}
+	mime, cfg, uerr := validateImage(staged.Reader(), l.MaxPixels)
+	if uerr != nil {
+		staged.Close()
+		return nil, uerr
+	}
+	// 検証を通ったものだけ数える
+	userID := serviceUser(r)
+	if userID == "" {
+		staged.Close()
+		return nil, &uploadError{http.StatusBadRequest, ErrCodeInvalidRequest, "Missing uploading user"}
+	}
+	if l.Quota != nil && !l.Quota.Allow(userID, staged.Size) {
+		staged.Close()
+		return nil, &uploadError{http.StatusTooManyRequests, ErrCodeQuotaExceeded, "Upload quota exceeded"}
+	}
+	return &validUpload{stagedFile: staged, Mime: mime, Config: cfg}, nil
+}
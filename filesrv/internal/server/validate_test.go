@@ -0,0 +1,204 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testGIF(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewPaletted(image.Rect(0, 0, w, h), color.Palette{color.Black, color.White})
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func testJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withPNGSize は IHDR の幅と高さを書き換える。ヘッダーだけ見る DecodeConfig はこの大きさを返す
+func withPNGSize(data []byte, w, h uint32) []byte {
+	out := append([]byte(nil), data...)
+	// シグネチャ 8 バイト、長さ 4 バイト、"IHDR" 4 バイトの後に幅と高さ
+	binary.BigEndian.PutUint32(out[16:], w)
+	binary.BigEndian.PutUint32(out[20:], h)
+	binary.BigEndian.PutUint32(out[29:], crc32.ChecksumIEEE(out[12:29]))
+	return out
+}
+
+func TestValidateImage(t *testing.T) {
+	png := testPNG(t, 100, 50)
+	gifImage := testGIF(t, 10, 10)
+
+	testCases := []struct {
+		name      string
+		data      []byte
+		maxPixels int
+		wantMime  string
+		wantCode  string
+	}{
+		{name: "PNG", data: png, wantMime: "image/png"},
+		{name: "JPEG", data: testJPEG(t, 10, 10), wantMime: "image/jpeg"},
+		{name: "GIF", data: gifImage, wantMime: "image/gif"},
+		{name: "Not an image", data: []byte("<svg xmlns=\"http://www.w3.org/2000/svg\"/>"), wantCode: ErrCodeUnsupportedMediaType},
+		{name: "Empty", data: nil, wantCode: ErrCodeUnsupportedMediaType},
+		{name: "PNG header with a GIF body", data: append(append([]byte(nil), png[:8]...), gifImage...), wantCode: ErrCodeInvalidRequest},
+		{name: "Truncated PNG", data: png[:20], wantCode: ErrCodeInvalidRequest},
+		{name: "Exactly MAX_IMAGE_PIXELS", data: png, maxPixels: 5000, wantMime: "image/png"},
+		{name: "Over MAX_IMAGE_PIXELS", data: png, maxPixels: 4999, wantCode: ErrCodeTooManyPixels},
+		// 全体をデコードせずにヘッダーの大きさで断る
+		{name: "Decompression bomb", data: withPNGSize(png, 100000, 100000), maxPixels: 40000000, wantCode: ErrCodeTooManyPixels},
+		{name: "Zero width", data: withPNGSize(png, 0, 50), wantCode: ErrCodeInvalidRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mime, cfg, uerr := validateImage(bytes.NewReader(tc.data), tc.maxPixels)
+
+			if tc.wantCode != "" {
+				if uerr == nil || uerr.Code != tc.wantCode {
+					t.Fatalf("validateImage = %v, want %s", uerr, tc.wantCode)
+				}
+				return
+			}
+			if uerr != nil {
+				t.Fatalf("validateImage = %v", uerr)
+			}
+			if mime != tc.wantMime || cfg.Width == 0 || cfg.Height == 0 {
+				t.Errorf("validateImage = %s %dx%d", mime, cfg.Width, cfg.Height)
+			}
+		})
+	}
+}
+
+func TestUploadLimits_Accept(t *testing.T) {
+	png := testPNG(t, 10, 10)
+
+	stage := func(t *testing.T, data []byte) *stagedFile {
+		staged, err := stageUpload(t.TempDir(), bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return staged
+	}
+	requestFrom := func(userID string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/upload", nil)
+		return r.WithContext(context.WithValue(r.Context(), serviceUserKey{}, userID))
+	}
+
+	testCases := []struct {
+		name       string
+		limits     *UploadLimits
+		data       []byte
+		userID     string
+		wantStatus int
+	}{
+		{name: "Accepted", limits: &UploadLimits{MaxBytes: 1 << 20}, data: png, userID: "user-1"},
+		{name: "Too large", limits: &UploadLimits{MaxBytes: int64(len(png)) - 1}, data: png, userID: "user-1", wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "Too many pixels", limits: &UploadLimits{MaxBytes: 1 << 20, MaxPixels: 99}, data: png, userID: "user-1", wantStatus: http.StatusUnprocessableEntity},
+		{name: "Not an image", limits: &UploadLimits{MaxBytes: 1 << 20}, data: []byte("hello"), userID: "user-1", wantStatus: http.StatusUnsupportedMediaType},
+		{name: "Missing user", limits: &UploadLimits{MaxBytes: 1 << 20}, data: png, wantStatus: http.StatusBadRequest},
+		{name: "Quota exceeded", limits: &UploadLimits{MaxBytes: 1 << 20, Quota: NewUploadQuota(0, int64(len(png))-1, time.Hour)}, data: png, userID: "user-1", wantStatus: http.StatusTooManyRequests},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			staged := stage(t, tc.data)
+
+			upload, uerr := tc.limits.accept(requestFrom(tc.userID), staged)
+
+			if tc.wantStatus != 0 {
+				if uerr == nil || uerr.Status != tc.wantStatus {
+					t.Fatalf("accept = %v, want %d", uerr, tc.wantStatus)
+				}
+				// 通らなければ一時ファイルは消える
+				if _, err := staged.file.Stat(); err == nil {
+					t.Errorf("staged file was not closed")
+				}
+				return
+			}
+			if uerr != nil {
+				t.Fatalf("accept = %v", uerr)
+			}
+			defer upload.Close()
+			if upload.Mime != "image/png" || upload.Config.Width != 10 {
+				t.Errorf("upload = %s %dx%d", upload.Mime, upload.Config.Width, upload.Config.Height)
+			}
+		})
+	}
+}
+
+func TestUploadQuota_Allow(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	q := NewUploadQuota(2, 100, time.Hour)
+	q.now = func() time.Time { return now }
+
+	if !q.Allow("user-1", 40) || !q.Allow("user-1", 40) {
+		t.Fatal("uploads within the quota were rejected")
+	}
+	if q.Allow("user-1", 1) {
+		t.Error("third upload was allowed")
+	}
+	// ユーザーごとに数える
+	if !q.Allow("user-2", 100) {
+		t.Error("another user's upload was rejected")
+	}
+	if q.Allow("user-2", 1) {
+		t.Error("upload over the byte limit was allowed")
+	}
+
+	// 断った分は数えない
+	now = now.Add(59 * time.Minute)
+	if q.Allow("user-1", 1) {
+		t.Error("quota was reset before the window ended")
+	}
+
+	now = now.Add(time.Minute)
+	if !q.Allow("user-1", 100) {
+		t.Error("quota was not reset after the window")
+	}
+	if !q.Allow("user-2", 100) {
+		t.Error("quota of another user was not reset after the window")
+	}
+}
+
+func TestUploadQuota_Unlimited(t *testing.T) {
+	q := NewUploadQuota(0, 0, time.Hour)
+	for i := 0; i < 100; i++ {
+		if !q.Allow("user-1", 1<<30) {
+			t.Fatal("upload was rejected without limits")
+		}
+	}
+}
+
+func TestSniffImage(t *testing.T) {
+	for _, data := range []string{"GIF87a...", "GIF89a...", "\xFF\xD8\xFF\xE0", "\x89PNG\r\n\x1A\n"} {
+		if _, ok := sniffImage([]byte(data)); !ok {
+			t.Errorf("sniffImage(%q) was rejected", data)
+		}
+	}
+	for _, data := range []string{"GIF8", "\x89PNG", "RIFF....WEBP", strings.Repeat("A", 8)} {
+		if _, ok := sniffImage([]byte(data)); ok {
+			t.Errorf("sniffImage(%q) was accepted", data)
+		}
+	}
+}
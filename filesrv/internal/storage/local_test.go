@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStorage_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewLocalStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	key := "ab/cd/photo.jpg"
+
+	if err := s.Put(ctx, key, strings.NewReader("hello"), 5, "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	info, err := s.Stat(ctx, key)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Key != key || info.Size != 5 || info.ContentType != "image/jpeg" {
+		t.Errorf("Stat = %+v", info)
+	}
+
+	body, _, err := s.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "hello" {
+		t.Errorf("Get = %q", data)
+	}
+
+	// 置き換えても書きかけの一時ファイルは残らない
+	if err := s.Put(ctx, key, strings.NewReader("bye"), 3, "image/jpeg"); err != nil {
+		t.Fatalf("Put again: %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, "ab", "cd"))
+	if len(entries) != 1 {
+		t.Errorf("files after replacing = %d, want 1", len(entries))
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Stat(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat after Delete = %v, want ErrNotFound", err)
+	}
+	if _, _, err := s.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	// 空になったディレクトリは片付けるが、Dir は残す
+	if _, err := os.Stat(filepath.Join(dir, "ab")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("empty directory was left: %v", err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("Dir was removed: %v", err)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of a missing key = %v", err)
+	}
+}
+
+func TestLocalStorage_DirectoryIsNotAnObject(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := s.Put(ctx, "ab/photo.jpg", strings.NewReader("x"), 1, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Stat(ctx, "ab"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat of a directory = %v, want ErrNotFound", err)
+	}
+	if _, _, err := s.Get(ctx, "ab"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of a directory = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLocalStorage_List(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewLocalStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, key := range []string{"ab/1.jpg", "ab/2.jpg", "cd/3.jpg"} {
+		if err := s.Put(ctx, key, strings.NewReader("x"), 1, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 書きかけの一時ファイルは返さない
+	if err := os.WriteFile(filepath.Join(dir, "ab", ".tmp-123"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	objects, err := s.List(ctx, "ab/")
+
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	var keys []string
+	for _, o := range objects {
+		keys = append(keys, o.Key)
+	}
+	if got, want := strings.Join(keys, ","), "ab/1.jpg,ab/2.jpg"; got != want {
+		t.Errorf("List = %s, want %s", got, want)
+	}
+}
+
+func TestLocalStorage_InvalidKey(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "files")
+	s, err := NewLocalStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(parent, "secret"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for _, key := range []string{"", "../secret", "ab/../../secret", "/etc/passwd", "ab//photo.jpg", "./photo.jpg", "ab/"} {
+		if _, err := s.path(key); err == nil {
+			t.Errorf("path(%q) was accepted", key)
+		}
+		if _, _, err := s.Get(ctx, key); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q) = %v, want an invalid key error", key, err)
+		}
+		if err := s.Put(ctx, key, strings.NewReader("x"), 1, ""); err == nil {
+			t.Errorf("Put(%q) was accepted", key)
+		}
+		if err := s.Delete(ctx, key); err == nil {
+			t.Errorf("Delete(%q) was accepted", key)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(parent, "secret"))
+	if err != nil || string(data) != "secret" {
+		t.Errorf("file outside Dir was changed: %q %v", data, err)
+	}
+}
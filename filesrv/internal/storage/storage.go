@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ファイルの置き場所を差し替えられるようにする。キーは "/" 区切りの相対パス
+
+var ErrNotFound = errors.New("object not found")
+
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ModTime     time.Time
+	ContentType string
+}
+
+type Storage interface {
+	// Put は key に書き込む。既にあれば置き換える。途中で失敗しても中途半端なものは見えない
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get は中身を返す。閉じるのは呼び出し側。なければ ErrNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	// Delete はなくてもエラーにしない
+	Delete(ctx context.Context, key string) error
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// List は prefix で始まるキーをすべて返す
+	List(ctx context.Context, prefix string) ([]*ObjectInfo, error)
+}
+
+// Presigner は期限付きで直接取得できる URL を発行できるストレージ
+// 発行できるなら filesrv は中身を中継せずにリダイレクトする
+type Presigner interface {
+	PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// 1 メッセージの書き込みに許す時間
+	writeWait = 10 * time.Second
+	// pong を待つ時間。これを過ぎると接続は死んだとみなす
+	pongWait = 60 * time.Second
+	// ping の送信間隔 (pongWait より短くする)
+	pingPeriod = (pongWait * 9) / 10
+	// クライアントから受け付ける 1 メッセージの最大サイズ
+	maxMessageSize = 64 * 1024
+	// 接続ごとの送信キューの長さ。溢れたクライアントは slow consumer として切断する
+	sendBufferSize = 256
+	// 同じ相手への typing_event (入力中) を中継する最短間隔
+	typingMinInterval = 2 * time.Second
+)
+
+// Client は 1 本の WebSocket 接続を表す
+// gorilla/websocket は並行な書き込みを許さないため、書き込みは writePump だけが行い
+// 他の goroutine は Send で送信キューに積む
+type Client struct {
+	id     string
+	userID uuid.UUID
+	conn   *websocket.Conn
+	send   chan []byte
+
+	// readPump の goroutine だけが触る
+	lastTyping map[uuid.UUID]typingState
+
+	done        chan struct{}
+	closeOnce   sync.Once
+	closeCode   int
+	closeReason string
+}
+
+func newClient(userID uuid.UUID, conn *websocket.Conn) *Client {
+	return &Client{
+		id:     uuid.NewString(),
+		userID: userID,
+		conn:   conn,
+		send:   make(chan []byte, sendBufferSize),
+		done:   make(chan struct{}),
+
+		lastTyping: make(map[uuid.UUID]typingState),
+	}
+}
+
+type typingState struct {
+	isTyping bool
+	sentAt   time.Time
+}
+
+// allowTyping は typing_event の連打を間引く
+// 入力中/停止の切り替えは常に通し、同じ状態の繰り返しは typingMinInterval ごとに 1 回だけ通す
+func (c *Client) allowTyping(recipientID uuid.UUID, isTyping bool) bool {
+	now := time.Now()
+	last, ok := c.lastTyping[recipientID]
+	if ok && last.isTyping == isTyping && now.Sub(last.sentAt) < typingMinInterval {
+		return false
+	}
+	c.lastTyping[recipientID] = typingState{isTyping: isTyping, sentAt: now}
+	return true
+}
+
+// Send はメッセージを送信キューに積む。キューが一杯なら積まずに false を返す
+func (c *Client) Send(message []byte) bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+	select {
+	case c.send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close は writePump を止めて接続を閉じる。何度呼んでもよい
+func (c *Client) Close() {
+	c.CloseWithReason(websocket.CloseNormalClosure, "")
+}
+
+// CloseWithReason は指定した close code をクライアントに送って接続を閉じる。最初の呼び出しだけが有効
+func (c *Client) CloseWithReason(code int, reason string) {
+	c.closeOnce.Do(func() {
+		c.closeCode = code
+		c.closeReason = reason
+		close(c.done)
+	})
+}
+
+// readPump は接続が切れるまでクライアントからのメッセージを読み、handle に渡す
+func (c *Client) readPump(handle func(message []byte)) {
+	defer c.Close()
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("Read error for user %s (conn %s): %v", c.userID, c.id, err)
+			}
+			return
+		}
+		handle(message)
+	}
+}
+
+// writePump は送信キューの内容と keepalive の ping を書き込む唯一の goroutine
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+	for {
+		// 閉じる指示は送信キューより優先する
+		select {
+		case <-c.done:
+			c.writeClose()
+			return
+		default:
+		}
+		select {
+		case message := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				log.Printf("Write error for user %s (conn %s): %v", c.userID, c.id, err)
+				c.Close()
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.Close()
+				return
+			}
+		case <-c.done:
+			c.writeClose()
+			return
+		}
+	}
+}
+
+func (c *Client) writeClose() {
+	c.conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(c.closeCode, c.closeReason),
+		time.Now().Add(writeWait),
+	)
+}
@@ -11,13 +11,15 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"sync"
+	"time"
 )
 
 type Gateway struct {
 	rdb         *redis.Client
-	connections map[uuid.UUID]*websocket.Conn
+	connections map[uuid.UUID]map[string]*Client // user_id -> conn_id -> client
 	mutex       sync.RWMutex
 	wsUpgrader  websocket.Upgrader
+	presence    presenceStore
 }
 
 type Channel string
@@ -31,6 +33,19 @@ const (
 	AckChannel              Channel = "ack_channel"
 	PresenceIncomingChannel Channel = "presence_incoming"
 	PresenceOutgoingChannel Channel = "presence_outgoing"
+	TypingIncomingChannel   Channel = "typing_incoming"
+	TypingOutgoingChannel   Channel = "typing_outgoing"
+
+	PresenceQueryIncomingChannel Channel = "presence_query_incoming"
+	PresenceQueryOutgoingChannel Channel = "presence_query_outgoing"
+	MessageEditIncomingChannel   Channel = "message_edit_incoming"
+	MessageEditOutgoingChannel   Channel = "message_edit_outgoing"
+	MessageDeleteIncomingChannel Channel = "message_delete_incoming"
+	MessageDeleteOutgoingChannel Channel = "message_delete_outgoing"
+	ReactionIncomingChannel      Channel = "reaction_incoming"
+	ReactionOutgoingChannel      Channel = "reaction_outgoing"
+	ModerationOutgoingChannel    Channel = "moderation_outgoing"
+	NotificationReadChannel      Channel = "notification_read_outgoing"
 )
 
 type Event string
@@ -41,15 +56,26 @@ const (
 	AckEvent          Event = "ack_event"
 	PresenceEvent     Event = "presence_event"
 	NotificationEvent Event = "notification_event"
+	TypingEvent       Event = "typing_event"
+	// presence_query はクライアントの問い合わせと、その接続だけに返す応答の両方に使う
+	PresenceQueryEvent Event = "presence_query"
+	MessageEditEvent   Event = "message_edit_event"
+	MessageDeleteEvent Event = "message_delete_event"
+	ReactionEvent      Event = "reaction_event"
+	// 送ったメッセージのモデレーション結果 (保留・拒否・承認)。送信者にだけ届く
+	ModerationEvent Event = "moderation_event"
+	// 通知の既読・削除。同じユーザーの他のタブのバッジを更新する
+	NotificationReadEvent Event = "notification_read_event"
 )
 
 func NewGateway(rdb *redis.Client) *Gateway {
 	return &Gateway{
 		rdb:         rdb,
-		connections: make(map[uuid.UUID]*websocket.Conn),
+		connections: make(map[uuid.UUID]map[string]*Client),
 		wsUpgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool { return true },
 		},
+		presence: newPresenceTracker(rdb),
 	}
 }
 
@@ -64,32 +90,33 @@ func (g *Gateway) handleConnections(w http.ResponseWriter, r *http.Request) {
 		log.Printf("WebSocket Upgrade failed for user %s: %v", userID, err)
 		return
 	}
-	log.Printf("User %s connected.", userID)
-	g.registerConnection(r.Context(), userID, conn)
-	defer g.deregisterConnection(r.Context(), userID, conn)
+	// リクエストの context は middleware.Timeout でキャンセルされるため、接続の寿命とは切り離す
+	ctx := context.WithoutCancel(r.Context())
+	client := newClient(userID, conn)
+	log.Printf("User %s connected (conn %s).", userID, client.id)
+	g.registerConnection(ctx, client)
+	defer g.deregisterConnection(ctx, userID, client.id)
 
-	for {
-		_, message, err := conn.ReadMessage()
-		if err != nil {
-			break
-		}
-		g.routeIncomingMessage(r.Context(), userID, message)
-	}
+	go client.writePump()
+	client.readPump(func(message []byte) {
+		g.routeIncomingMessage(ctx, client, message)
+	})
 }
 
-// client -> websocket: chat
+// client -> websocket: chat read typing presence_query
 
-// server -> redis -> websocket: notification ack chat presence
+// server -> redis -> websocket: notification ack chat presence typing presence_query
 
-// websocket -> redis -> server: chat presence
-// websocket -> client: notification ack chat presence
+// websocket -> redis -> server: chat read presence typing presence_query
+// websocket -> client: notification ack chat presence typing presence_query
 
 type ClientMessage struct {
 	Type    Event           `json:"type"`
 	Payload json.RawMessage `json:"payload"`
 }
 
-func (g *Gateway) routeIncomingMessage(ctx context.Context, userID uuid.UUID, rawMessage []byte) {
+func (g *Gateway) routeIncomingMessage(ctx context.Context, client *Client, rawMessage []byte) {
+	userID := client.userID
 	var msg ClientMessage
 	if err := json.Unmarshal(rawMessage, &msg); err != nil {
 		log.Printf("Invalid message format from %s", userID)
@@ -104,56 +131,238 @@ func (g *Gateway) routeIncomingMessage(ctx context.Context, userID uuid.UUID, ra
 			return
 		}
 		payload.SenderID = userID
-		payloadBytes, err := json.Marshal(payload)
-		if err != nil {
-			log.Printf("Failed to marshal chat message payload from %s", userID)
+		g.publishIncoming(ctx, ChatIncomingChannel, userID, "chat message", payload)
+	case ReadEvent:
+		var payload ReadPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			log.Printf("Invalid read message payload from %s", userID)
 			return
 		}
-		if err := g.rdb.Publish(ctx, string(ChatIncomingChannel), string(payloadBytes)).Err(); err != nil {
-			log.Printf("Failed to publish chat message from %s: %v", userID, err)
+		payload.UserID = userID
+		g.publishIncoming(ctx, ReadIncomingChannel, userID, "read message", payload)
+	case TypingEvent:
+		var payload TypingPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			log.Printf("Invalid typing payload from %s", userID)
 			return
 		}
-		log.Printf("Routing chat message from %s", userID)
-	case ReadEvent:
-		var payload ReadPayload
+		if !client.allowTyping(payload.RecipientID, payload.IsTyping) {
+			return
+		}
+		payload.UserID = userID
+		g.publishIncoming(ctx, TypingIncomingChannel, userID, "typing event", payload)
+	case PresenceQueryEvent:
+		var payload PresenceQueryPayload
 		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
-			log.Printf("Invalid read message payload from %s", userID)
+			log.Printf("Invalid presence query payload from %s", userID)
 			return
 		}
 		payload.UserID = userID
-		payloadBytes, err := json.Marshal(payload)
-		if err != nil {
-			log.Printf("Failed to marshal read message payload from %s", userID)
+		payload.ConnID = client.id
+		g.publishIncoming(ctx, PresenceQueryIncomingChannel, userID, "presence query", payload)
+	case MessageEditEvent:
+		var payload MessageEditPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			log.Printf("Invalid message edit payload from %s", userID)
 			return
 		}
-		if err := g.rdb.Publish(ctx, string(ReadIncomingChannel), string(payloadBytes)).Err(); err != nil {
-			log.Printf("Failed to publish read message from %s: %v", userID, err)
+		payload.UserID = userID
+		g.publishIncoming(ctx, MessageEditIncomingChannel, userID, "message edit", payload)
+	case MessageDeleteEvent:
+		var payload MessageDeletePayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			log.Printf("Invalid message delete payload from %s", userID)
+			return
+		}
+		payload.UserID = userID
+		g.publishIncoming(ctx, MessageDeleteIncomingChannel, userID, "message delete", payload)
+	case ReactionEvent:
+		var payload ReactionPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			log.Printf("Invalid reaction payload from %s", userID)
 			return
 		}
-		log.Printf("Routing read message from %s", userID)
+		payload.UserID = userID
+		g.publishIncoming(ctx, ReactionIncomingChannel, userID, "reaction", payload)
 	default:
 		log.Printf("Unknown message type: %s", msg.Type)
 	}
 }
 
-func (g *Gateway) registerConnection(ctx context.Context, userID uuid.UUID, conn *websocket.Conn) {
-	g.mutex.Lock()
-	defer g.mutex.Unlock()
-	g.connections[userID] = conn
-	g.rdb.Set(ctx, fmt.Sprintf("user:status:%s", userID), "online", 0)
-	g.rdb.Publish(ctx, string(PresenceIncomingChannel), fmt.Sprintf(`{"user_id":"%s","status":"online"}`, userID))
+func (g *Gateway) publishIncoming(ctx context.Context, channel Channel, userID uuid.UUID, kind string, payload interface{}) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Failed to marshal %s payload from %s", kind, userID)
+		return
+	}
+	if err := g.rdb.Publish(ctx, string(channel), string(payloadBytes)).Err(); err != nil {
+		log.Printf("Failed to publish %s from %s: %v", kind, userID, err)
+		return
+	}
+	log.Printf("Routing %s from %s", kind, userID)
 }
 
-func (g *Gateway) deregisterConnection(ctx context.Context, userID uuid.UUID, conn *websocket.Conn) {
-	conn.Close()
+func (g *Gateway) registerConnection(ctx context.Context, client *Client) {
+	userID := client.userID
+	g.mutex.Lock()
+	if _, ok := g.connections[userID]; !ok {
+		g.connections[userID] = make(map[string]*Client)
+	}
+	g.connections[userID][client.id] = client
+	g.mutex.Unlock()
+
+	first, err := g.presence.Connect(ctx, userID, client.id)
+	if err != nil {
+		log.Printf("Failed to register presence for user %s: %v", userID, err)
+		return
+	}
+	if first {
+		g.publishPresence(ctx, userID, StatusOnline)
+	}
+}
 
+func (g *Gateway) deregisterConnection(ctx context.Context, userID uuid.UUID, connID string) {
 	g.mutex.Lock()
-	defer g.mutex.Unlock()
-	if existingConn, ok := g.connections[userID]; ok && existingConn == conn {
+	conns, ok := g.connections[userID]
+	client, exists := conns[connID]
+	if !ok || !exists {
+		g.mutex.Unlock()
+		return
+	}
+	delete(conns, connID)
+	if len(conns) == 0 {
 		delete(g.connections, userID)
-		g.rdb.Del(ctx, fmt.Sprintf("user:status:%s", userID))
-		g.rdb.Publish(ctx, string(PresenceIncomingChannel), fmt.Sprintf(`{"user_id":"%s","status":"offline"}`, userID))
-		log.Printf("User %s disconnected and deregistered.", userID)
+	}
+	g.mutex.Unlock()
+	client.Close()
+
+	last, err := g.presence.Disconnect(ctx, userID, connID)
+	if err != nil {
+		log.Printf("Failed to deregister presence for user %s: %v", userID, err)
+		return
+	}
+	log.Printf("User %s disconnected (conn %s).", userID, connID)
+	if last {
+		g.publishPresence(ctx, userID, StatusOffline)
+		log.Printf("User %s has no connections left on any gateway.", userID)
+	}
+}
+
+func (g *Gateway) publishPresence(ctx context.Context, userID uuid.UUID, status string) {
+	if err := g.rdb.Publish(ctx, string(PresenceIncomingChannel), fmt.Sprintf(`{"user_id":"%s","status":"%s"}`, userID, status)).Err(); err != nil {
+		log.Printf("Failed to publish presence for user %s: %v", userID, err)
+	}
+}
+
+// userConnections はユーザーがこのレプリカに持つ接続のスナップショットを返す
+func (g *Gateway) userConnections(userID uuid.UUID) []*Client {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	clients := make([]*Client, 0, len(g.connections[userID]))
+	for _, client := range g.connections[userID] {
+		clients = append(clients, client)
+	}
+	return clients
+}
+
+func (g *Gateway) localConnectionIDs() map[uuid.UUID][]string {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	local := make(map[uuid.UUID][]string, len(g.connections))
+	for userID, conns := range g.connections {
+		for connID := range conns {
+			local[userID] = append(local[userID], connID)
+		}
+	}
+	return local
+}
+
+// pushToUser はユーザーのこのレプリカ上の全接続の送信キューにイベントを積み、積めた接続数を返す
+// 送信キューが溢れている接続は slow consumer として切断する
+func (g *Gateway) pushToUser(ctx context.Context, userID uuid.UUID, event Event, payload json.RawMessage) int {
+	data, err := json.Marshal(ClientMessage{
+		Type:    event,
+		Payload: payload,
+	})
+	if err != nil {
+		log.Printf("Failed to marshal %s for user %s: %v", event, userID, err)
+		return 0
+	}
+	sent := 0
+	for _, client := range g.userConnections(userID) {
+		if !client.Send(data) {
+			log.Printf("Push %s dropped for user %s (conn %s): slow consumer, disconnecting.", event, userID, client.id)
+			client.CloseWithReason(websocket.CloseTryAgainLater, "send buffer overflow")
+			g.deregisterConnection(ctx, userID, client.id)
+			continue
+		}
+		sent++
+	}
+	return sent
+}
+
+// pushToConnection は特定の接続だけにイベントを送る。接続がこのレプリカになければ false を返す
+func (g *Gateway) pushToConnection(userID uuid.UUID, connID string, event Event, payload json.RawMessage) bool {
+	g.mutex.RLock()
+	client, ok := g.connections[userID][connID]
+	g.mutex.RUnlock()
+	if !ok {
+		return false
+	}
+	data, err := json.Marshal(ClientMessage{
+		Type:    event,
+		Payload: payload,
+	})
+	if err != nil {
+		log.Printf("Failed to marshal %s for user %s: %v", event, userID, err)
+		return false
+	}
+	if !client.Send(data) {
+		client.CloseWithReason(websocket.CloseTryAgainLater, "send buffer overflow")
+		return false
+	}
+	return true
+}
+
+// RunPresenceHeartbeat は接続の有効期限を定期的に延長し、他レプリカの途絶えた接続を掃除する
+func (g *Gateway) RunPresenceHeartbeat(ctx context.Context) {
+	ticker := time.NewTicker(presenceHeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.heartbeat(ctx)
+		}
+	}
+}
+
+func (g *Gateway) heartbeat(ctx context.Context) {
+	revived, err := g.presence.Refresh(ctx, g.localConnectionIDs())
+	if err != nil {
+		log.Printf("Presence heartbeat failed: %v", err)
+	}
+	for _, userID := range revived {
+		g.publishPresence(ctx, userID, StatusOnline)
+	}
+
+	offline, err := g.presence.Sweep(ctx)
+	if err != nil {
+		log.Printf("Presence sweep failed: %v", err)
+	}
+	for _, userID := range offline {
+		g.publishPresence(ctx, userID, StatusOffline)
+		log.Printf("User %s timed out and was marked offline.", userID)
+	}
+}
+
+// Close はこのレプリカの全接続を切断し、presence から取り除く
+func (g *Gateway) Close(ctx context.Context) {
+	for userID, connIDs := range g.localConnectionIDs() {
+		for _, connID := range connIDs {
+			g.deregisterConnection(ctx, userID, connID)
+		}
 	}
 }
 
@@ -0,0 +1,445 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// fakeRedis は PUBLISH だけを受け付けて記録する RESP サーバー
+type fakeRedis struct {
+	listener net.Listener
+
+	mutex     sync.Mutex
+	published map[string][]string // channel -> payload
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &fakeRedis{listener: listener, published: make(map[string][]string)}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go r.serve(conn)
+		}
+	}()
+	return r
+}
+
+func (r *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		if strings.EqualFold(args[0], "PUBLISH") && len(args) == 3 {
+			r.mutex.Lock()
+			r.published[args[1]] = append(r.published[args[1]], args[2])
+			r.mutex.Unlock()
+			fmt.Fprint(conn, ":0\r\n")
+			continue
+		}
+		fmt.Fprint(conn, "+OK\r\n")
+	}
+}
+
+// readCommand はクライアントが送る *<n>\r\n ($<len>\r\n<data>\r\n)... の形のコマンドを読む
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("unexpected command %q", line)
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, fmt.Errorf("unexpected argument %q", line)
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func (r *fakeRedis) presenceEvents(userID uuid.UUID, status string) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	want := fmt.Sprintf(`{"user_id":"%s","status":"%s"}`, userID, status)
+	count := 0
+	for _, payload := range r.published[string(PresenceIncomingChannel)] {
+		if payload == want {
+			count++
+		}
+	}
+	return count
+}
+
+// fakePresence はこのプロセスだけで数える presenceStore
+type fakePresence struct {
+	mutex sync.Mutex
+	conns map[uuid.UUID]map[string]bool
+}
+
+func newFakePresence() *fakePresence {
+	return &fakePresence{conns: make(map[uuid.UUID]map[string]bool)}
+}
+
+func (p *fakePresence) Connect(ctx context.Context, userID uuid.UUID, connID string) (bool, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	first := len(p.conns[userID]) == 0
+	if first {
+		p.conns[userID] = make(map[string]bool)
+	}
+	p.conns[userID][connID] = true
+	return first, nil
+}
+
+func (p *fakePresence) Disconnect(ctx context.Context, userID uuid.UUID, connID string) (bool, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	conns, ok := p.conns[userID]
+	if !ok || !conns[connID] {
+		return false, nil
+	}
+	delete(conns, connID)
+	if len(conns) > 0 {
+		return false, nil
+	}
+	delete(p.conns, userID)
+	return true, nil
+}
+
+func (p *fakePresence) Refresh(ctx context.Context, conns map[uuid.UUID][]string) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
+func (p *fakePresence) Sweep(ctx context.Context) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
+func (p *fakePresence) count(userID uuid.UUID) int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return len(p.conns[userID])
+}
+
+type testGateway struct {
+	*Gateway
+	redis    *fakeRedis
+	presence *fakePresence
+	url      string
+}
+
+func newTestGateway(t *testing.T) *testGateway {
+	t.Helper()
+	fr := newFakeRedis(t)
+	rdb := redis.NewClient(&redis.Options{Addr: fr.listener.Addr().String()})
+	t.Cleanup(func() { rdb.Close() })
+	presence := newFakePresence()
+	g := NewGateway(rdb)
+	g.presence = presence
+
+	// 認証は AuthMiddleware の代わりにクエリの user をそのまま使う
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := uuid.MustParse(r.URL.Query().Get("user"))
+		g.handleConnections(w, r.WithContext(context.WithValue(r.Context(), UserIDContextKey, userID)))
+	}))
+	t.Cleanup(srv.Close)
+	return &testGateway{Gateway: g, redis: fr, presence: presence, url: "ws" + strings.TrimPrefix(srv.URL, "http")}
+}
+
+// dial はユーザーとして接続し、ゲートウェイに登録された接続の ID を返す
+func (g *testGateway) dial(t *testing.T, userID uuid.UUID) (*websocket.Conn, string) {
+	t.Helper()
+	before := make(map[string]bool)
+	for _, client := range g.userConnections(userID) {
+		before[client.id] = true
+	}
+	conn, _, err := websocket.DefaultDialer.Dial(g.url+"?user="+userID.String(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	var connID string
+	waitFor(t, "connection to be registered", func() bool {
+		for _, client := range g.userConnections(userID) {
+			if !before[client.id] {
+				connID = client.id
+				return true
+			}
+		}
+		return false
+	})
+	return conn, connID
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func readEvent(t *testing.T, conn *websocket.Conn) ClientMessage {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg ClientMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return msg
+}
+
+// expectNoEvent は少し待っても何も届かないことを確かめる。タイムアウト後の conn は読めなくなる
+func expectNoEvent(t *testing.T, conn *websocket.Conn) {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	_, data, err := conn.ReadMessage()
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("unexpected message %s (err %v)", data, err)
+	}
+}
+
+func TestGateway_PushToUser_AllConnections(t *testing.T) {
+	g := newTestGateway(t)
+	alice, bob := uuid.New(), uuid.New()
+	tab1, _ := g.dial(t, alice)
+	tab2, _ := g.dial(t, alice)
+	other, _ := g.dial(t, bob)
+
+	sent := g.pushToUser(context.Background(), alice, NotificationEvent, json.RawMessage(`{"id":1}`))
+
+	if sent != 2 {
+		t.Errorf("pushToUser = %d, want 2", sent)
+	}
+	for _, conn := range []*websocket.Conn{tab1, tab2} {
+		msg := readEvent(t, conn)
+		if msg.Type != NotificationEvent || string(msg.Payload) != `{"id":1}` {
+			t.Errorf("event = %s %s", msg.Type, msg.Payload)
+		}
+	}
+	expectNoEvent(t, other)
+	// 2 本目の接続ではオンラインを流さない
+	if n := g.redis.presenceEvents(alice, StatusOnline); n != 1 {
+		t.Errorf("online events = %d, want 1", n)
+	}
+}
+
+func TestGateway_PushToUser_NotConnected(t *testing.T) {
+	g := newTestGateway(t)
+
+	if sent := g.pushToUser(context.Background(), uuid.New(), NotificationEvent, json.RawMessage(`{}`)); sent != 0 {
+		t.Errorf("pushToUser = %d, want 0", sent)
+	}
+}
+
+func TestGateway_PushToConnection(t *testing.T) {
+	g := newTestGateway(t)
+	alice, bob := uuid.New(), uuid.New()
+	asking, askingID := g.dial(t, alice)
+	otherTab, _ := g.dial(t, alice)
+
+	if !g.pushToConnection(alice, askingID, PresenceQueryEvent, json.RawMessage(`{"statuses":[]}`)) {
+		t.Fatal("pushToConnection = false")
+	}
+
+	if msg := readEvent(t, asking); msg.Type != PresenceQueryEvent {
+		t.Errorf("event = %s", msg.Type)
+	}
+	expectNoEvent(t, otherTab)
+	// 他人の接続 ID やこのレプリカにない接続には送らない
+	if g.pushToConnection(bob, askingID, PresenceQueryEvent, json.RawMessage(`{}`)) {
+		t.Error("pushed to a connection of another user")
+	}
+	if g.pushToConnection(alice, uuid.NewString(), PresenceQueryEvent, json.RawMessage(`{}`)) {
+		t.Error("pushed to an unknown connection")
+	}
+}
+
+func TestGateway_Disconnect(t *testing.T) {
+	g := newTestGateway(t)
+	alice := uuid.New()
+	tab1, _ := g.dial(t, alice)
+	tab2, tab2ID := g.dial(t, alice)
+
+	tab1.Close()
+
+	waitFor(t, "first tab to be deregistered", func() bool {
+		return len(g.userConnections(alice)) == 1
+	})
+	if clients := g.userConnections(alice); clients[0].id != tab2ID {
+		t.Errorf("remaining connection = %s, want %s", clients[0].id, tab2ID)
+	}
+	if n := g.presence.count(alice); n != 1 {
+		t.Errorf("presence connections = %d, want 1", n)
+	}
+	// 他のタブが残っていればオフラインにしない
+	if n := g.redis.presenceEvents(alice, StatusOffline); n != 0 {
+		t.Errorf("offline events = %d after closing one tab", n)
+	}
+
+	tab2.Close()
+
+	waitFor(t, "offline event", func() bool {
+		return g.redis.presenceEvents(alice, StatusOffline) == 1
+	})
+	if n := g.presence.count(alice); n != 0 {
+		t.Errorf("presence connections = %d, want 0", n)
+	}
+	if _, ok := g.localConnectionIDs()[alice]; ok {
+		t.Error("user is still in the connection map")
+	}
+}
+
+func TestGateway_Close(t *testing.T) {
+	g := newTestGateway(t)
+	alice, bob := uuid.New(), uuid.New()
+	g.dial(t, alice)
+	g.dial(t, alice)
+	g.dial(t, bob)
+
+	g.Close(context.Background())
+
+	if local := g.localConnectionIDs(); len(local) != 0 {
+		t.Errorf("connections left after Close: %v", local)
+	}
+	for _, userID := range []uuid.UUID{alice, bob} {
+		if n := g.presence.count(userID); n != 0 {
+			t.Errorf("presence connections of %s = %d, want 0", userID, n)
+		}
+		if n := g.redis.presenceEvents(userID, StatusOffline); n != 1 {
+			t.Errorf("offline events of %s = %d, want 1", userID, n)
+		}
+	}
+}
+
+// fillSendBuffer は writePump を動かさない接続を作り、送信キューを一杯にする
+func fillSendBuffer(t *testing.T, g *testGateway, userID uuid.UUID) *Client {
+	t.Helper()
+	client := newClient(userID, nil)
+	g.registerConnection(context.Background(), client)
+	for client.Send([]byte(`{}`)) {
+	}
+	return client
+}
+
+func TestGateway_PushToUser_SlowConsumer(t *testing.T) {
+	g := newTestGateway(t)
+	alice := uuid.New()
+	fast := newClient(alice, nil)
+	g.registerConnection(context.Background(), fast)
+	slow := fillSendBuffer(t, g, alice)
+
+	sent := g.pushToUser(context.Background(), alice, NotificationEvent, json.RawMessage(`{"id":1}`))
+
+	if sent != 1 {
+		t.Errorf("pushToUser = %d, want 1", sent)
+	}
+	if got := len(fast.send); got != 1 {
+		t.Errorf("fast client queue = %d, want 1", got)
+	}
+	// 溢れた接続だけを閉じて取り除く
+	select {
+	case <-slow.done:
+	default:
+		t.Fatal("slow client was not closed")
+	}
+	if slow.closeCode != websocket.CloseTryAgainLater {
+		t.Errorf("close code = %d, want %d", slow.closeCode, websocket.CloseTryAgainLater)
+	}
+	select {
+	case <-fast.done:
+		t.Error("fast client was closed")
+	default:
+	}
+	if clients := g.userConnections(alice); len(clients) != 1 || clients[0] != fast {
+		t.Errorf("connections = %v, want only the fast client", clients)
+	}
+	if n := g.presence.count(alice); n != 1 {
+		t.Errorf("presence connections = %d, want 1", n)
+	}
+	if n := g.redis.presenceEvents(alice, StatusOffline); n != 0 {
+		t.Errorf("offline events = %d while another connection is alive", n)
+	}
+}
+
+func TestGateway_PushToConnection_SlowConsumer(t *testing.T) {
+	g := newTestGateway(t)
+	alice := uuid.New()
+	slow := fillSendBuffer(t, g, alice)
+
+	if g.pushToConnection(alice, slow.id, PresenceQueryEvent, json.RawMessage(`{}`)) {
+		t.Error("pushToConnection = true for a full send buffer")
+	}
+	if slow.closeCode != websocket.CloseTryAgainLater {
+		t.Errorf("close code = %d, want %d", slow.closeCode, websocket.CloseTryAgainLater)
+	}
+}
+
+func TestClient_CloseWithReason(t *testing.T) {
+	g := newTestGateway(t)
+	alice := uuid.New()
+	conn, connID := g.dial(t, alice)
+	var client *Client
+	for _, c := range g.userConnections(alice) {
+		if c.id == connID {
+			client = c
+		}
+	}
+
+	client.CloseWithReason(websocket.CloseTryAgainLater, "send buffer overflow")
+	// 最初の理由だけが送られる
+	client.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseTryAgainLater || closeErr.Text != "send buffer overflow" {
+		t.Fatalf("read = %v, want close %d", err, websocket.CloseTryAgainLater)
+	}
+	waitFor(t, "connection to be deregistered", func() bool {
+		return len(g.userConnections(alice)) == 0
+	})
+}
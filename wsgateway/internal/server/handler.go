@@ -10,11 +10,15 @@ import (
 )
 
 type NotificationPayload struct {
-	ID          int64     `json:"id"`
-	RecipientID uuid.UUID `json:"recipient_id"`
-	SenderID    string    `json:"sender_id"`
-	Type        string    `json:"type"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID                 int64     `json:"id"`
+	RecipientID        uuid.UUID `json:"recipient_id"`
+	SenderID           string    `json:"sender_id"`
+	SenderName         string    `json:"sender_name"`
+	SenderThumbnailURL string    `json:"sender_thumbnail_url"`
+	Type               string    `json:"type"`
+	Count              int       `json:"count"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
 }
 
 func (g *Gateway) NotificationHandler(ctx context.Context, message *redis.Message) error {
@@ -24,34 +28,54 @@ func (g *Gateway) NotificationHandler(ctx context.Context, message *redis.Messag
 	}
 	userID := notification.RecipientID
 
-	g.mutex.RLock()
-	conn, ok := g.connections[userID]
-	g.mutex.RUnlock()
-
-	if !ok {
-		log.Printf("Push: User %s not connected (offline).", userID)
-		return nil
+	// どのゲートウェイにも接続していないユーザーには api が Web Push で届ける
+	if sent := g.pushToUser(ctx, userID, NotificationEvent, json.RawMessage(message.Payload)); sent == 0 {
+		log.Printf("Push: User %s not connected to this gateway.", userID)
+	} else {
+		log.Printf("Successfully pushed notification to user %s (%d connections).", userID, sent)
 	}
+	return nil
+}
+
+type NotificationReadPayload struct {
+	UserID         uuid.UUID `json:"user_id"`
+	Action         string    `json:"action"`
+	NotificationID int64     `json:"notification_id,omitempty"`
+	UnreadCount    int       `json:"unread_count"`
+	Timestamp      int64     `json:"timestamp"`
+}
 
-	data := ClientMessage{
-		Type:    NotificationEvent,
-		Payload: json.RawMessage(message.Payload),
+func (g *Gateway) NotificationReadHandler(ctx context.Context, message *redis.Message) error {
+	var read NotificationReadPayload
+	if err := json.Unmarshal([]byte(message.Payload), &read); err != nil {
+		return err
 	}
-	if err := conn.WriteJSON(data); err != nil {
-		log.Printf("Push failed for user %s: %v", userID, err)
-		g.deregisterConnection(ctx, userID, conn)
-	} else {
-		log.Printf("Successfully pushed notification to user %s.", userID)
+	userID := read.UserID
+
+	if sent := g.pushToUser(ctx, userID, NotificationReadEvent, json.RawMessage(message.Payload)); sent == 0 {
+		log.Printf("NotificationRead: User %s not connected to this gateway.", userID)
 	}
 	return nil
 }
 
 type MessagePayload struct {
-	ID          int64     `json:"id"`
-	SenderID    uuid.UUID `json:"sender_id"`
-	RecipientID uuid.UUID `json:"recipient_id"`
-	Content     string    `json:"content"`
-	SentAt      time.Time `json:"sent_at"`
+	ID            int64               `json:"id"`
+	SenderID      uuid.UUID           `json:"sender_id"`
+	RecipientID   uuid.UUID           `json:"recipient_id"`
+	Content       string              `json:"content"`
+	SentAt        time.Time           `json:"sent_at"`
+	AttachmentIDs []string            `json:"attachment_ids,omitempty"`
+	Attachments   []AttachmentPayload `json:"attachments,omitempty"`
+}
+
+// 添付ファイルの url は api が発行した期限付きの署名付き URL
+type AttachmentPayload struct {
+	ID     string `json:"id"`
+	Mime   string `json:"mime"`
+	Size   int64  `json:"size"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
 }
 
 func (g *Gateway) ChatMessageHandler(ctx context.Context, message *redis.Message) error {
@@ -62,25 +86,11 @@ func (g *Gateway) ChatMessageHandler(ctx context.Context, message *redis.Message
 	}
 	recipientID := chatMsg.RecipientID
 
-	g.mutex.RLock()
-	conn, ok := g.connections[recipientID]
-	g.mutex.RUnlock()
-
-	if !ok {
-		log.Printf("Chat: User %s not connected (offline).", recipientID)
-		return nil
-	}
-
-	data := ClientMessage{
-		Type:    ChatEvent,
-		Payload: json.RawMessage(message.Payload),
-	}
-
-	if err := conn.WriteJSON(data); err != nil {
-		log.Printf("Chat message push failed for user %s: %v", recipientID, err)
-		g.deregisterConnection(ctx, recipientID, conn)
+	// どのゲートウェイにも接続していないユーザーには api が Web Push で届ける
+	if sent := g.pushToUser(ctx, recipientID, ChatEvent, json.RawMessage(message.Payload)); sent == 0 {
+		log.Printf("Chat: User %s not connected to this gateway.", recipientID)
 	} else {
-		log.Printf("Successfully pushed chat message to user %s.", recipientID)
+		log.Printf("Successfully pushed chat message to user %s (%d connections).", recipientID, sent)
 	}
 	return nil
 }
@@ -98,23 +108,10 @@ func (g *Gateway) AckHandler(ctx context.Context, message *redis.Message) error
 	}
 	userID := ack.UserID
 
-	g.mutex.RLock()
-	conn, ok := g.connections[userID]
-	g.mutex.RUnlock()
-
-	if !ok {
-		log.Printf("Ack: User %s not connected (offline).", userID)
-		return nil
-	}
-	data := ClientMessage{
-		Type:    AckEvent,
-		Payload: json.RawMessage(message.Payload),
-	}
-	if err := conn.WriteJSON(data); err != nil {
-		log.Printf("Ack push failed for user %s: %v", userID, err)
-		g.deregisterConnection(ctx, userID, conn)
+	if sent := g.pushToUser(ctx, userID, AckEvent, json.RawMessage(message.Payload)); sent == 0 {
+		log.Printf("Ack: User %s not connected to this gateway.", userID)
 	} else {
-		log.Printf("Successfully pushed ack to user %s.", userID)
+		log.Printf("Successfully pushed ack to user %s (%d connections).", userID, sent)
 	}
 	return nil
 }
@@ -132,31 +129,20 @@ func (g *Gateway) PresenceHandler(ctx context.Context, message *redis.Message) e
 	}
 	recipientID := presence.RecipientID
 
-	g.mutex.RLock()
-	conn, ok := g.connections[recipientID]
-	g.mutex.RUnlock()
-
-	if !ok {
-		log.Printf("Presence: User %s not connected (offline).", recipientID)
-		return nil
-	}
-	data := ClientMessage{
-		Type:    PresenceEvent,
-		Payload: json.RawMessage(message.Payload),
-	}
-	if err := conn.WriteJSON(data); err != nil {
-		log.Printf("Presence push failed for user %s: %v", recipientID, err)
-		g.deregisterConnection(ctx, recipientID, conn)
+	if sent := g.pushToUser(ctx, recipientID, PresenceEvent, json.RawMessage(message.Payload)); sent == 0 {
+		log.Printf("Presence: User %s not connected to this gateway.", recipientID)
 	} else {
-		log.Printf("Successfully pushed presence to user %s.", recipientID)
+		log.Printf("Successfully pushed presence to user %s (%d connections).", recipientID, sent)
 	}
 	return nil
 }
 
+// last_read_message_id は既読位置 (watermark)。送信時に 0 なら最新まで既読にする
 type ReadPayload struct {
-	UserID      uuid.UUID `json:"user_id"`
-	RecipientID uuid.UUID `json:"recipient_id"`
-	Timestamp   int64     `json:"timestamp"`
+	UserID            uuid.UUID `json:"user_id"`
+	RecipientID       uuid.UUID `json:"recipient_id"`
+	LastReadMessageID int64     `json:"last_read_message_id"`
+	Timestamp         int64     `json:"timestamp"`
 }
 
 func (g *Gateway) ReadHandler(ctx context.Context, message *redis.Message) error {
@@ -166,23 +152,141 @@ func (g *Gateway) ReadHandler(ctx context.Context, message *redis.Message) error
 	}
 	recipientID := read.RecipientID
 
-	g.mutex.RLock()
-	conn, ok := g.connections[recipientID]
-	g.mutex.RUnlock()
+	if sent := g.pushToUser(ctx, recipientID, ReadEvent, json.RawMessage(message.Payload)); sent == 0 {
+		log.Printf("Read: User %s not connected to this gateway.", recipientID)
+	} else {
+		log.Printf("Successfully pushed read to user %s (%d connections).", recipientID, sent)
+	}
+	return nil
+}
+
+type TypingPayload struct {
+	UserID      uuid.UUID `json:"user_id"`
+	RecipientID uuid.UUID `json:"recipient_id"`
+	IsTyping    bool      `json:"is_typing"`
+	Timestamp   int64     `json:"timestamp"`
+}
 
-	if !ok {
-		log.Printf("Read: User %s not connected (offline).", recipientID)
-		return nil
+func (g *Gateway) TypingHandler(ctx context.Context, message *redis.Message) error {
+	var typing TypingPayload
+	if err := json.Unmarshal([]byte(message.Payload), &typing); err != nil {
+		return err
 	}
-	data := ClientMessage{
-		Type:    ReadEvent,
-		Payload: json.RawMessage(message.Payload),
+	recipientID := typing.RecipientID
+
+	if sent := g.pushToUser(ctx, recipientID, TypingEvent, json.RawMessage(message.Payload)); sent == 0 {
+		log.Printf("Typing: User %s not connected to this gateway.", recipientID)
 	}
-	if err := conn.WriteJSON(data); err != nil {
-		log.Printf("Read push failed for user %s: %v", recipientID, err)
-		g.deregisterConnection(ctx, recipientID, conn)
+	return nil
+}
+
+type PresenceQueryPayload struct {
+	RequestID string      `json:"request_id"`
+	ConnID    string      `json:"conn_id"`
+	UserID    uuid.UUID   `json:"user_id"`
+	UserIDs   []uuid.UUID `json:"user_ids"`
+}
+
+// 応答の presences はそのままクライアントへ中継するため、ルーティングに必要な項目だけを読む
+type PresenceQueryResultPayload struct {
+	RequestID   string    `json:"request_id"`
+	ConnID      string    `json:"conn_id"`
+	RecipientID uuid.UUID `json:"recipient_id"`
+}
+
+// PresenceQueryHandler は presence_query の応答を、問い合わせてきた接続だけに返す
+func (g *Gateway) PresenceQueryHandler(ctx context.Context, message *redis.Message) error {
+	var result PresenceQueryResultPayload
+	if err := json.Unmarshal([]byte(message.Payload), &result); err != nil {
+		return err
+	}
+	if g.pushToConnection(result.RecipientID, result.ConnID, PresenceQueryEvent, json.RawMessage(message.Payload)) {
+		log.Printf("Successfully pushed presence query result to user %s (conn %s).", result.RecipientID, result.ConnID)
+	}
+	return nil
+}
+
+// 編集・送信取り消し・リアクションは操作したユーザー (他のタブ) と相手の両方に配信する
+type MessageEditPayload struct {
+	MessageID   int64     `json:"message_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	RecipientID uuid.UUID `json:"recipient_id"`
+	Content     string    `json:"content"`
+	EditedAt    time.Time `json:"edited_at"`
+}
+
+type MessageDeletePayload struct {
+	MessageID   int64     `json:"message_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	RecipientID uuid.UUID `json:"recipient_id"`
+	DeletedAt   time.Time `json:"deleted_at"`
+}
+
+type ReactionPayload struct {
+	MessageID   int64     `json:"message_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	RecipientID uuid.UUID `json:"recipient_id"`
+	Emoji       string    `json:"emoji"`
+	Action      string    `json:"action"`
+	Timestamp   int64     `json:"timestamp"`
+}
+
+// conversationParticipants は会話イベントの配信先 (user_id と recipient_id) を読む
+type conversationParticipants struct {
+	UserID      uuid.UUID `json:"user_id"`
+	RecipientID uuid.UUID `json:"recipient_id"`
+}
+
+func (g *Gateway) pushToParticipants(ctx context.Context, event Event, message *redis.Message) error {
+	var participants conversationParticipants
+	if err := json.Unmarshal([]byte(message.Payload), &participants); err != nil {
+		return err
+	}
+	for _, userID := range []uuid.UUID{participants.UserID, participants.RecipientID} {
+		if sent := g.pushToUser(ctx, userID, event, json.RawMessage(message.Payload)); sent == 0 {
+			log.Printf("%s: User %s not connected to this gateway.", event, userID)
+		} else {
+			log.Printf("Successfully pushed %s to user %s (%d connections).", event, userID, sent)
+		}
+	}
+	return nil
+}
+
+func (g *Gateway) MessageEditHandler(ctx context.Context, message *redis.Message) error {
+	return g.pushToParticipants(ctx, MessageEditEvent, message)
+}
+
+func (g *Gateway) MessageDeleteHandler(ctx context.Context, message *redis.Message) error {
+	return g.pushToParticipants(ctx, MessageDeleteEvent, message)
+}
+
+func (g *Gateway) ReactionHandler(ctx context.Context, message *redis.Message) error {
+	return g.pushToParticipants(ctx, ReactionEvent, message)
+}
+
+// モデレーション結果は送信者 (user_id) のすべての接続に返す
+type ModerationPayload struct {
+	UserID        uuid.UUID `json:"user_id"`
+	RecipientID   uuid.UUID `json:"recipient_id"`
+	Action        string    `json:"action"`
+	Reason        string    `json:"reason,omitempty"`
+	Content       string    `json:"content,omitempty"`
+	HeldMessageID int64     `json:"held_message_id,omitempty"`
+	MessageID     int64     `json:"message_id,omitempty"`
+	SentAt        time.Time `json:"sent_at"`
+}
+
+func (g *Gateway) ModerationHandler(ctx context.Context, message *redis.Message) error {
+	var moderation ModerationPayload
+	if err := json.Unmarshal([]byte(message.Payload), &moderation); err != nil {
+		return err
+	}
+	userID := moderation.UserID
+
+	if sent := g.pushToUser(ctx, userID, ModerationEvent, json.RawMessage(message.Payload)); sent == 0 {
+		log.Printf("Moderation: User %s not connected to this gateway.", userID)
 	} else {
-		log.Printf("Successfully pushed read to user %s.", recipientID)
+		log.Printf("Successfully pushed moderation result to user %s (%d connections).", userID, sent)
 	}
 	return nil
 }
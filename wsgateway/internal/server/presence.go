@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// presence は Redis 上で全レプリカ共通に管理する
+// presence:user:<user_id> ... 接続ごとのメンバー (<instance_id>/<conn_id>) を有効期限 (unix ms) をスコアにした ZSET
+// presence:users          ... 接続中ユーザーを最終有効期限をスコアにした ZSET (クラッシュしたレプリカの掃除用)
+
+const (
+	presenceTTL               = 60 * time.Second
+	presenceHeartbeatInterval = 20 * time.Second
+	presenceUsersKey          = "presence:users"
+)
+
+const (
+	StatusOnline  = "online"
+	StatusOffline = "offline"
+)
+
+func presenceUserKey(userID uuid.UUID) string {
+	return fmt.Sprintf("presence:user:%s", userID)
+}
+
+// KEYS[1] = presence:user:<id>, KEYS[2] = presence:users
+// ARGV[1] = member, ARGV[2] = expires_at(ms), ARGV[3] = now(ms), ARGV[4] = ttl(ms), ARGV[5] = user_id
+// 追加前の有効な接続数を返す (0 ならこの接続でオンラインになった)
+var connectScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[3])
+local before = redis.call('ZCARD', KEYS[1])
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+redis.call('ZADD', KEYS[2], 'GT', ARGV[2], ARGV[5])
+return before
+`)
+
+// KEYS[1] = presence:user:<id>, KEYS[2] = presence:users
+// ARGV[1] = member (sweep 時は空文字), ARGV[2] = now(ms), ARGV[3] = user_id
+// 最後の接続が消えて presence:users から取り除いた呼び出し元だけが 1 を受け取る
+var disconnectScript = redis.NewScript(`
+if ARGV[1] ~= '' then
+	redis.call('ZREM', KEYS[1], ARGV[1])
+end
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[2])
+if redis.call('ZCARD', KEYS[1]) > 0 then
+	return 0
+end
+redis.call('DEL', KEYS[1])
+return redis.call('ZREM', KEYS[2], ARGV[3])
+`)
+
+// presenceStore は全レプリカ共通の presence の保存先。テストでは Redis の代わりに差し替える
+type presenceStore interface {
+	Connect(ctx context.Context, userID uuid.UUID, connID string) (bool, error)
+	Disconnect(ctx context.Context, userID uuid.UUID, connID string) (bool, error)
+	Refresh(ctx context.Context, conns map[uuid.UUID][]string) ([]uuid.UUID, error)
+	Sweep(ctx context.Context) ([]uuid.UUID, error)
+}
+
+type presenceTracker struct {
+	rdb        *redis.Client
+	instanceID string
+	ttl        time.Duration
+}
+
+func newPresenceTracker(rdb *redis.Client) *presenceTracker {
+	return &presenceTracker{
+		rdb:        rdb,
+		instanceID: uuid.NewString(),
+		ttl:        presenceTTL,
+	}
+}
+
+func (p *presenceTracker) member(connID string) string {
+	return p.instanceID + "/" + connID
+}
+
+// Connect は接続を登録し、ユーザーの最初の接続であれば true を返す
+func (p *presenceTracker) Connect(ctx context.Context, userID uuid.UUID, connID string) (bool, error) {
+	now := time.Now()
+	before, err := connectScript.Run(ctx, p.rdb,
+		[]string{presenceUserKey(userID), presenceUsersKey},
+		p.member(connID), now.Add(p.ttl).UnixMilli(), now.UnixMilli(), p.ttl.Milliseconds(), userID.String(),
+	).Int()
+	if err != nil {
+		return false, err
+	}
+	return before == 0, nil
+}
+
+// Disconnect は接続を解除し、全レプリカを通してユーザーの最後の接続であれば true を返す
+func (p *presenceTracker) Disconnect(ctx context.Context, userID uuid.UUID, connID string) (bool, error) {
+	removed, err := disconnectScript.Run(ctx, p.rdb,
+		[]string{presenceUserKey(userID), presenceUsersKey},
+		p.member(connID), time.Now().UnixMilli(), userID.String(),
+	).Int()
+	if err != nil {
+		return false, err
+	}
+	return removed == 1, nil
+}
+
+// Refresh はこのレプリカが持つ接続の有効期限を延長する
+// 期限切れで掃除されていた接続があれば再登録し、オンラインに戻ったユーザーを返す
+func (p *presenceTracker) Refresh(ctx context.Context, conns map[uuid.UUID][]string) ([]uuid.UUID, error) {
+	var revived []uuid.UUID
+	for userID, connIDs := range conns {
+		online := false
+		for _, connID := range connIDs {
+			first, err := p.Connect(ctx, userID, connID)
+			if err != nil {
+				return revived, err
+			}
+			online = online || first
+		}
+		if online {
+			revived = append(revived, userID)
+		}
+	}
+	return revived, nil
+}
+
+// Sweep は heartbeat が途絶えた (クラッシュしたレプリカの) 接続を掃除し、オフラインになったユーザーを返す
+func (p *presenceTracker) Sweep(ctx context.Context) ([]uuid.UUID, error) {
+	now := time.Now().UnixMilli()
+	expired, err := p.rdb.ZRangeByScore(ctx, presenceUsersKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: fmt.Sprintf("%d", now),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	var offline []uuid.UUID
+	for _, idStr := range expired {
+		userID, err := uuid.Parse(idStr)
+		if err != nil {
+			log.Printf("Presence: invalid user id %q in %s", idStr, presenceUsersKey)
+			p.rdb.ZRem(ctx, presenceUsersKey, idStr)
+			continue
+		}
+		removed, err := disconnectScript.Run(ctx, p.rdb,
+			[]string{presenceUserKey(userID), presenceUsersKey},
+			"", now, userID.String(),
+		).Int()
+		if err != nil {
+			return offline, err
+		}
+		if removed == 1 {
+			offline = append(offline, userID)
+		}
+	}
+	return offline, nil
+}
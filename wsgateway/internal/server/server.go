@@ -48,6 +48,14 @@ func (s *Server) Start() error {
 	s.gateway.SubscribeChannel(ctx, AckChannel, s.gateway.AckHandler)
 	s.gateway.SubscribeChannel(ctx, PresenceOutgoingChannel, s.gateway.PresenceHandler)
 	s.gateway.SubscribeChannel(ctx, ReadOutgoingChannel, s.gateway.ReadHandler)
+	s.gateway.SubscribeChannel(ctx, TypingOutgoingChannel, s.gateway.TypingHandler)
+	s.gateway.SubscribeChannel(ctx, PresenceQueryOutgoingChannel, s.gateway.PresenceQueryHandler)
+	s.gateway.SubscribeChannel(ctx, MessageEditOutgoingChannel, s.gateway.MessageEditHandler)
+	s.gateway.SubscribeChannel(ctx, MessageDeleteOutgoingChannel, s.gateway.MessageDeleteHandler)
+	s.gateway.SubscribeChannel(ctx, ReactionOutgoingChannel, s.gateway.ReactionHandler)
+	s.gateway.SubscribeChannel(ctx, ModerationOutgoingChannel, s.gateway.ModerationHandler)
+	s.gateway.SubscribeChannel(ctx, NotificationReadChannel, s.gateway.NotificationReadHandler)
+	go s.gateway.RunPresenceHeartbeat(ctx)
 
 	s.httpServer = &http.Server{
 		Addr:         s.conf.ServerAddr,
@@ -61,5 +69,8 @@ func (s *Server) Start() error {
 
 func (s *Server) Shutdown(ctx context.Context) error {
 	log.Println("Shutting down server gracefully...")
-	return s.httpServer.Shutdown(ctx)
+	err := s.httpServer.Shutdown(ctx)
+	// hijack 済みの WebSocket は Shutdown で閉じられないため、presence ごと明示的に片付ける
+	s.gateway.Close(ctx)
+	return err
 }